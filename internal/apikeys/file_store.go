@@ -0,0 +1,164 @@
+package apikeys
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"Perion_Assignment/internal/models"
+)
+
+// fileConfig is the format of an API key file
+type fileConfig struct {
+	Tiers map[string]models.APITier `json:"tiers"`
+	Keys  []fileKey                 `json:"keys"`
+}
+
+// fileKey is an API key in a key file
+// The secret is given either in plain text (key) or as its SHA-256 hash (key_sha256).
+type fileKey struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Tier      string `json:"tier"`
+	Key       string `json:"key,omitempty"`
+	KeySHA256 string `json:"key_sha256,omitempty"`
+	Disabled  bool   `json:"disabled,omitempty"`
+}
+
+// FileStore implements Store with keys and tiers read from a JSON file at startup
+// Usage counters are kept in memory, so they are per instance and reset on restart.
+type FileStore struct {
+	keys map[string]*models.APIKey // by key hash
+
+	usage map[string]*models.APIKeyUsage // by key ID, for the current day only
+	mutex sync.Mutex
+}
+
+// NewFileStore creates a key store from the JSON key file at path
+func NewFileStore(path string) (Store, error) {
+	return newFileStore(path)
+}
+
+// newFileStore creates the concrete implementation
+func newFileStore(path string) (*FileStore, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read API key file: %w", err)
+	}
+
+	var config fileConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse API key file: %w", err)
+	}
+
+	keys, err := config.apiKeys()
+	if err != nil {
+		return nil, fmt.Errorf("invalid API key file: %w", err)
+	}
+
+	return &FileStore{
+		keys:  keys,
+		usage: make(map[string]*models.APIKeyUsage),
+	}, nil
+}
+
+// apiKeys validates the key file and indexes its enabled keys by hash
+func (c *fileConfig) apiKeys() (map[string]*models.APIKey, error) {
+	keys := make(map[string]*models.APIKey)
+	seen := make(map[string]bool)
+
+	for i, key := range c.Keys {
+		if key.ID == "" {
+			return nil, fmt.Errorf("key %d has no id", i)
+		}
+		if seen[key.ID] {
+			return nil, fmt.Errorf("duplicate key id %q", key.ID)
+		}
+		seen[key.ID] = true
+
+		tier, ok := c.Tiers[key.Tier]
+		if !ok {
+			return nil, fmt.Errorf("key %q has unknown tier %q", key.ID, key.Tier)
+		}
+		tier.Name = key.Tier
+
+		keyHash := strings.ToLower(key.KeySHA256)
+		if key.Key != "" {
+			keyHash = HashKey(key.Key)
+		}
+		if keyHash == "" {
+			return nil, fmt.Errorf("key %q has neither key nor key_sha256", key.ID)
+		}
+		if _, exists := keys[keyHash]; exists {
+			return nil, fmt.Errorf("key %q reuses the secret of another key", key.ID)
+		}
+
+		if !key.Disabled {
+			keys[keyHash] = &models.APIKey{ID: key.ID, Name: key.Name, Tier: tier}
+		}
+	}
+
+	return keys, nil
+}
+
+// FindKey returns the enabled key with the given hash
+func (f *FileStore) FindKey(ctx context.Context, keyHash string) (*models.APIKey, error) {
+	key, ok := f.keys[keyHash]
+	if !ok {
+		return nil, models.ErrInvalidAPIKey
+	}
+	found := *key
+	return &found, nil
+}
+
+// AddRequest counts a request made with the key on the given day
+func (f *FileStore) AddRequest(ctx context.Context, keyID, day string) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.usageLocked(keyID, day).Requests++
+	return nil
+}
+
+// AddDomains counts analyzed domains unless that would exceed the quota (0 = unlimited)
+func (f *FileStore) AddDomains(ctx context.Context, keyID, day string, count, quota int) (*models.APIKeyUsage, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	usage := f.usageLocked(keyID, day)
+	if quota > 0 && usage.Domains+int64(count) > int64(quota) {
+		return nil, models.ErrQuotaExceeded
+	}
+	usage.Domains += int64(count)
+
+	snapshot := *usage
+	return &snapshot, nil
+}
+
+// Usage returns the key's usage on the given day
+func (f *FileStore) Usage(ctx context.Context, keyID, day string) (*models.APIKeyUsage, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	snapshot := *f.usageLocked(keyID, day)
+	return &snapshot, nil
+}
+
+// usageLocked returns the key's counters for the day, starting over on a new day
+// Callers must hold the mutex.
+func (f *FileStore) usageLocked(keyID, day string) *models.APIKeyUsage {
+	usage, ok := f.usage[keyID]
+	if !ok || usage.Day != day {
+		usage = &models.APIKeyUsage{KeyID: keyID, Day: day}
+		f.usage[keyID] = usage
+	}
+	return usage
+}
+
+// Close releases the store's resources
+func (f *FileStore) Close() error {
+	return nil
+}
@@ -0,0 +1,138 @@
+package apikeys
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"Perion_Assignment/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testKeyFile = `{
+	"tiers": {
+		"free": {"requests_per_sec": 2, "daily_domain_quota": 10, "max_batch_size": 5},
+		"pro": {"requests_per_sec": 50}
+	},
+	"keys": [
+		{"id": "acme", "name": "Acme", "tier": "free", "key": "acme-secret"},
+		{"id": "internal", "name": "Internal", "tier": "pro", "key_sha256": "` + "%s" + `"},
+		{"id": "revoked", "name": "Revoked", "tier": "free", "key": "revoked-secret", "disabled": true}
+	]
+}`
+
+// writeKeyFile writes an API key file and returns its path
+func writeKeyFile(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "api_keys.json")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+// setupFileStore creates a file store with the test keys
+func setupFileStore(t *testing.T) *FileStore {
+	content := fmt.Sprintf(testKeyFile, HashKey("internal-secret"))
+	store, err := newFileStore(writeKeyFile(t, content))
+	require.NoError(t, err)
+	return store
+}
+
+func TestFileStore_FindKey(t *testing.T) {
+	store := setupFileStore(t)
+	ctx := context.Background()
+
+	key, err := store.FindKey(ctx, HashKey("acme-secret"))
+	require.NoError(t, err)
+	assert.Equal(t, "acme", key.ID)
+	assert.Equal(t, "Acme", key.Name)
+	assert.Equal(t, models.APITier{Name: "free", RequestsPerSec: 2, DailyDomainQuota: 10, MaxBatchSize: 5}, key.Tier)
+
+	// Keys may be given as hashes
+	key, err = store.FindKey(ctx, HashKey("internal-secret"))
+	require.NoError(t, err)
+	assert.Equal(t, "pro", key.Tier.Name)
+
+	_, err = store.FindKey(ctx, HashKey("unknown"))
+	assert.ErrorIs(t, err, models.ErrInvalidAPIKey)
+
+	_, err = store.FindKey(ctx, HashKey("revoked-secret"))
+	assert.ErrorIs(t, err, models.ErrInvalidAPIKey)
+}
+
+func TestFileStore_AddDomains_EnforcesQuota(t *testing.T) {
+	store := setupFileStore(t)
+	ctx := context.Background()
+
+	usage, err := store.AddDomains(ctx, "acme", "2024-01-01", 6, 10)
+	require.NoError(t, err)
+	assert.Equal(t, int64(6), usage.Domains)
+
+	// Exceeding the quota counts nothing
+	_, err = store.AddDomains(ctx, "acme", "2024-01-01", 5, 10)
+	assert.ErrorIs(t, err, models.ErrQuotaExceeded)
+
+	usage, err = store.AddDomains(ctx, "acme", "2024-01-01", 4, 10)
+	require.NoError(t, err)
+	assert.Equal(t, int64(10), usage.Domains)
+
+	// The quota starts over the next day
+	usage, err = store.AddDomains(ctx, "acme", "2024-01-02", 1, 10)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), usage.Domains)
+
+	// No quota
+	usage, err = store.AddDomains(ctx, "internal", "2024-01-02", 1000, 0)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1000), usage.Domains)
+}
+
+func TestFileStore_Usage(t *testing.T) {
+	store := setupFileStore(t)
+	ctx := context.Background()
+
+	require.NoError(t, store.AddRequest(ctx, "acme", "2024-01-01"))
+	require.NoError(t, store.AddRequest(ctx, "acme", "2024-01-01"))
+	_, err := store.AddDomains(ctx, "acme", "2024-01-01", 3, 10)
+	require.NoError(t, err)
+
+	usage, err := store.Usage(ctx, "acme", "2024-01-01")
+	require.NoError(t, err)
+	assert.Equal(t, models.APIKeyUsage{KeyID: "acme", Day: "2024-01-01", Requests: 2, Domains: 3}, *usage)
+
+	usage, err = store.Usage(ctx, "internal", "2024-01-01")
+	require.NoError(t, err)
+	assert.Zero(t, usage.Requests)
+}
+
+func TestNewFileStore_InvalidFiles(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{name: "malformed", content: `{`, expected: "failed to parse API key file"},
+		{name: "unknown tier", content: `{"tiers":{},"keys":[{"id":"a","tier":"gold","key":"x"}]}`, expected: `unknown tier "gold"`},
+		{name: "missing id", content: `{"tiers":{"free":{}},"keys":[{"tier":"free","key":"x"}]}`, expected: "has no id"},
+		{name: "duplicate id", content: `{"tiers":{"free":{}},"keys":[{"id":"a","tier":"free","key":"x"},{"id":"a","tier":"free","key":"y"}]}`, expected: "duplicate key id"},
+		{name: "missing secret", content: `{"tiers":{"free":{}},"keys":[{"id":"a","tier":"free"}]}`, expected: "neither key nor key_sha256"},
+		{name: "shared secret", content: `{"tiers":{"free":{}},"keys":[{"id":"a","tier":"free","key":"x"},{"id":"b","tier":"free","key":"x"}]}`, expected: "reuses the secret"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, err := NewFileStore(writeKeyFile(t, tt.content))
+			assert.Nil(t, store)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.expected)
+		})
+	}
+}
+
+func TestNewFileStore_MissingFile(t *testing.T) {
+	_, err := NewFileStore(filepath.Join(t.TempDir(), "missing.json"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to read API key file")
+}
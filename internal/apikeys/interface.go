@@ -0,0 +1,27 @@
+package apikeys
+
+import (
+	"context"
+
+	"Perion_Assignment/internal/models"
+)
+
+// Service defines the interface for API key authentication and quotas
+// External packages should use this interface, not the concrete implementations
+type Service interface {
+	Authenticate(ctx context.Context, secret string) (*models.APIKey, error)
+	RecordRequest(ctx context.Context, key *models.APIKey) error
+	ConsumeDomains(ctx context.Context, key *models.APIKey, count int) (*models.APIKeyUsage, error)
+	RefundDomains(ctx context.Context, key *models.APIKey, count int) error
+	Usage(ctx context.Context, key *models.APIKey) (*models.APIKeyUsage, error)
+}
+
+// Store defines the persistence operations used by the key manager
+// Keys are looked up by the SHA-256 hash of their secret; days are UTC dates (YYYY-MM-DD).
+type Store interface {
+	FindKey(ctx context.Context, keyHash string) (*models.APIKey, error)
+	AddRequest(ctx context.Context, keyID, day string) error
+	AddDomains(ctx context.Context, keyID, day string, count, quota int) (*models.APIKeyUsage, error)
+	Usage(ctx context.Context, keyID, day string) (*models.APIKeyUsage, error)
+	Close() error
+}
@@ -0,0 +1,141 @@
+package apikeys
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"Perion_Assignment/internal/models"
+)
+
+// maxCachedKeys bounds the lookup cache
+const maxCachedKeys = 10000
+
+// Manager implements Service on top of a Store
+// Found keys are cached for cacheTTL so authentication doesn't hit the store on every
+// request; disabling a key takes effect within that time. Unknown keys are not cached,
+// so random keys can't fill the cache and evict the real ones.
+type Manager struct {
+	store    Store
+	cacheTTL time.Duration
+
+	cache map[string]cachedKey // by key hash
+	mutex sync.Mutex
+
+	now func() time.Time
+}
+
+// cachedKey is a key found by a lookup
+type cachedKey struct {
+	key       *models.APIKey
+	expiresAt time.Time
+}
+
+// NewManager creates a new API key manager
+func NewManager(store Store, cacheTTL time.Duration) Service {
+	return newManager(store, cacheTTL)
+}
+
+// newManager creates the concrete implementation
+func newManager(store Store, cacheTTL time.Duration) *Manager {
+	return &Manager{
+		store:    store,
+		cacheTTL: cacheTTL,
+		cache:    make(map[string]cachedKey),
+		now:      time.Now,
+	}
+}
+
+// HashKey returns the hex-encoded SHA-256 hash under which a key secret is stored
+func HashKey(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// Authenticate returns the API key with the given secret
+// Unknown and disabled keys are reported as models.ErrInvalidAPIKey.
+func (m *Manager) Authenticate(ctx context.Context, secret string) (*models.APIKey, error) {
+	if secret == "" {
+		return nil, models.ErrInvalidAPIKey
+	}
+	keyHash := HashKey(secret)
+
+	if cached, ok := m.cached(keyHash); ok {
+		return cached.key, nil
+	}
+
+	key, err := m.store.FindKey(ctx, keyHash)
+	if err != nil {
+		// Unknown keys and store failures are not cached
+		return nil, err
+	}
+
+	m.remember(keyHash, cachedKey{key: key})
+	return key, nil
+}
+
+// RecordRequest counts a request made with the key
+func (m *Manager) RecordRequest(ctx context.Context, key *models.APIKey) error {
+	return m.store.AddRequest(ctx, key.ID, m.today())
+}
+
+// ConsumeDomains counts domains analyzed with the key against its daily quota
+// When the quota would be exceeded nothing is counted and models.ErrQuotaExceeded is returned.
+func (m *Manager) ConsumeDomains(ctx context.Context, key *models.APIKey, count int) (*models.APIKeyUsage, error) {
+	return m.store.AddDomains(ctx, key.ID, m.today(), count, key.Tier.DailyDomainQuota)
+}
+
+// RefundDomains gives back domains consumed today for work that was never done
+func (m *Manager) RefundDomains(ctx context.Context, key *models.APIKey, count int) error {
+	_, err := m.store.AddDomains(ctx, key.ID, m.today(), -count, 0)
+	return err
+}
+
+// Usage returns the key's usage today
+func (m *Manager) Usage(ctx context.Context, key *models.APIKey) (*models.APIKeyUsage, error) {
+	return m.store.Usage(ctx, key.ID, m.today())
+}
+
+// cached returns an unexpired lookup result
+func (m *Manager) cached(keyHash string) (cachedKey, bool) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	cached, ok := m.cache[keyHash]
+	if !ok || !m.now().Before(cached.expiresAt) {
+		return cachedKey{}, false
+	}
+	return cached, true
+}
+
+// remember caches a lookup result, dropping expired results when the cache is full
+func (m *Manager) remember(keyHash string, result cachedKey) {
+	if m.cacheTTL <= 0 {
+		return
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	now := m.now()
+	if len(m.cache) >= maxCachedKeys {
+		for hash, cached := range m.cache {
+			if !now.Before(cached.expiresAt) {
+				delete(m.cache, hash)
+			}
+		}
+		if len(m.cache) >= maxCachedKeys {
+			return
+		}
+	}
+
+	result.expiresAt = now.Add(m.cacheTTL)
+	m.cache[keyHash] = result
+}
+
+// today returns the current UTC date, the period of the daily quota
+func (m *Manager) today() string {
+	return m.now().UTC().Format(time.DateOnly)
+}
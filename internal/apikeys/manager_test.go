@@ -0,0 +1,120 @@
+package apikeys
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"Perion_Assignment/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingStore counts key lookups and can fail them
+type countingStore struct {
+	*FileStore
+	lookups int
+	err     error
+}
+
+func (c *countingStore) FindKey(ctx context.Context, keyHash string) (*models.APIKey, error) {
+	c.lookups++
+	if c.err != nil {
+		return nil, c.err
+	}
+	return c.FileStore.FindKey(ctx, keyHash)
+}
+
+func TestManager_Authenticate_CachesLookups(t *testing.T) {
+	store := &countingStore{FileStore: setupFileStore(t)}
+	manager := newManager(store, time.Minute)
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	manager.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	key, err := manager.Authenticate(ctx, "acme-secret")
+	require.NoError(t, err)
+	assert.Equal(t, "acme", key.ID)
+
+	_, err = manager.Authenticate(ctx, "acme-secret")
+	require.NoError(t, err)
+	assert.Equal(t, 1, store.lookups)
+
+	// Unknown keys are looked up every time and don't take cache space
+	_, err = manager.Authenticate(ctx, "wrong")
+	assert.ErrorIs(t, err, models.ErrInvalidAPIKey)
+	_, err = manager.Authenticate(ctx, "wrong")
+	assert.ErrorIs(t, err, models.ErrInvalidAPIKey)
+	assert.Equal(t, 3, store.lookups)
+	assert.Len(t, manager.cache, 1)
+
+	// Lookups expire
+	now = now.Add(2 * time.Minute)
+	_, err = manager.Authenticate(ctx, "acme-secret")
+	require.NoError(t, err)
+	assert.Equal(t, 4, store.lookups)
+}
+
+func TestManager_Authenticate_DoesNotCacheStoreErrors(t *testing.T) {
+	store := &countingStore{FileStore: setupFileStore(t), err: errors.New("connection refused")}
+	manager := newManager(store, time.Minute)
+	ctx := context.Background()
+
+	_, err := manager.Authenticate(ctx, "acme-secret")
+	assert.EqualError(t, err, "connection refused")
+
+	store.err = nil
+	key, err := manager.Authenticate(ctx, "acme-secret")
+	require.NoError(t, err)
+	assert.Equal(t, "acme", key.ID)
+}
+
+func TestManager_Authenticate_EmptySecret(t *testing.T) {
+	store := &countingStore{FileStore: setupFileStore(t)}
+	manager := newManager(store, time.Minute)
+
+	_, err := manager.Authenticate(context.Background(), "")
+	assert.ErrorIs(t, err, models.ErrInvalidAPIKey)
+	assert.Zero(t, store.lookups)
+}
+
+func TestManager_ConsumeDomains_UsesDailyQuota(t *testing.T) {
+	manager := newManager(setupFileStore(t), time.Minute)
+	now := time.Date(2024, 1, 1, 23, 0, 0, 0, time.UTC)
+	manager.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	key, err := manager.Authenticate(ctx, "acme-secret")
+	require.NoError(t, err)
+
+	require.NoError(t, manager.RecordRequest(ctx, key))
+	usage, err := manager.ConsumeDomains(ctx, key, 10)
+	require.NoError(t, err)
+	assert.Equal(t, models.APIKeyUsage{KeyID: "acme", Day: "2024-01-01", Requests: 1, Domains: 10}, *usage)
+
+	_, err = manager.ConsumeDomains(ctx, key, 1)
+	assert.ErrorIs(t, err, models.ErrQuotaExceeded)
+
+	// Refunded domains can be consumed again
+	require.NoError(t, manager.RefundDomains(ctx, key, 4))
+	usage, err = manager.ConsumeDomains(ctx, key, 4)
+	require.NoError(t, err)
+	assert.Equal(t, int64(10), usage.Domains)
+
+	// A new UTC day has a fresh quota
+	now = now.Add(2 * time.Hour)
+	usage, err = manager.ConsumeDomains(ctx, key, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "2024-01-02", usage.Day)
+
+	usage, err = manager.Usage(ctx, key)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), usage.Domains)
+	assert.Zero(t, usage.Requests)
+}
+
+func TestHashKey(t *testing.T) {
+	assert.Equal(t, "2bb80d537b1da3e38bd30361aa855686bde0eacd7162fef6a25fe97bf527a25b", HashKey("secret"))
+}
@@ -0,0 +1,174 @@
+package apikeys
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"Perion_Assignment/internal/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PostgresStore implements Store with keys, tiers and usage counters kept in Postgres
+// Usage counters are shared by all instances; the quota check and the increment are a
+// single statement, so concurrent requests can't exceed the quota together.
+type PostgresStore struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresStore creates a key store in the Postgres database at connectionString
+func NewPostgresStore(connectionString string) (Store, error) {
+	return newPostgresStore(connectionString)
+}
+
+// newPostgresStore creates the concrete implementation
+func newPostgresStore(connectionString string) (*PostgresStore, error) {
+	config, err := pgxpool.ParseConfig(connectionString)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse API key database connection string: %w", err)
+	}
+	config.MaxConns = 5
+	config.MaxConnLifetime = 30 * time.Minute
+	config.MaxConnIdleTime = 5 * time.Minute
+
+	// Disable statement caching, as for the log database (poolers don't support it)
+	config.ConnConfig.DefaultQueryExecMode = pgx.QueryExecModeExec
+	config.ConnConfig.StatementCacheCapacity = 0
+
+	pool, err := pgxpool.NewWithConfig(context.Background(), config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create API key database pool: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	store := &PostgresStore{pool: pool}
+	if err := store.createTablesIfNotExist(ctx); err != nil {
+		pool.Close()
+		return nil, fmt.Errorf("failed to create API key tables: %w", err)
+	}
+
+	return store, nil
+}
+
+// createTablesIfNotExist creates the tier, key and usage tables if they don't exist
+func (p *PostgresStore) createTablesIfNotExist(ctx context.Context) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS api_key_tiers (
+			name VARCHAR(50) PRIMARY KEY,
+			requests_per_sec INTEGER NOT NULL CHECK (requests_per_sec >= 0),
+			daily_domain_quota INTEGER NOT NULL DEFAULT 0 CHECK (daily_domain_quota >= 0),
+			max_batch_size INTEGER NOT NULL DEFAULT 0 CHECK (max_batch_size >= 0)
+		);
+
+		CREATE TABLE IF NOT EXISTS api_keys (
+			id VARCHAR(100) PRIMARY KEY,
+			key_hash CHAR(64) NOT NULL UNIQUE,
+			name VARCHAR(255) NOT NULL,
+			tier VARCHAR(50) NOT NULL REFERENCES api_key_tiers(name),
+			enabled BOOLEAN NOT NULL DEFAULT TRUE,
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+		);
+
+		CREATE TABLE IF NOT EXISTS api_key_usage (
+			key_id VARCHAR(100) NOT NULL REFERENCES api_keys(id) ON DELETE CASCADE,
+			day DATE NOT NULL,
+			requests BIGINT NOT NULL DEFAULT 0,
+			domains BIGINT NOT NULL DEFAULT 0,
+			PRIMARY KEY (key_id, day)
+		);
+	`
+
+	_, err := p.pool.Exec(ctx, query)
+	return err
+}
+
+// FindKey returns the enabled key with the given hash
+func (p *PostgresStore) FindKey(ctx context.Context, keyHash string) (*models.APIKey, error) {
+	query := `
+		SELECT k.id, k.name, t.name, t.requests_per_sec, t.daily_domain_quota, t.max_batch_size
+		FROM api_keys k
+		JOIN api_key_tiers t ON t.name = k.tier
+		WHERE k.key_hash = $1 AND k.enabled
+	`
+
+	var key models.APIKey
+	err := p.pool.QueryRow(ctx, query, keyHash).Scan(
+		&key.ID,
+		&key.Name,
+		&key.Tier.Name,
+		&key.Tier.RequestsPerSec,
+		&key.Tier.DailyDomainQuota,
+		&key.Tier.MaxBatchSize,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, models.ErrInvalidAPIKey
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up API key: %w", err)
+	}
+
+	return &key, nil
+}
+
+// AddRequest counts a request made with the key on the given day
+func (p *PostgresStore) AddRequest(ctx context.Context, keyID, day string) error {
+	query := `
+		INSERT INTO api_key_usage (key_id, day, requests) VALUES ($1, $2, 1)
+		ON CONFLICT (key_id, day) DO UPDATE SET requests = api_key_usage.requests + 1
+	`
+
+	if _, err := p.pool.Exec(ctx, query, keyID, day); err != nil {
+		return fmt.Errorf("failed to record API key request: %w", err)
+	}
+	return nil
+}
+
+// AddDomains counts analyzed domains unless that would exceed the quota (0 = unlimited)
+func (p *PostgresStore) AddDomains(ctx context.Context, keyID, day string, count, quota int) (*models.APIKeyUsage, error) {
+	if quota > 0 && count > quota {
+		return nil, models.ErrQuotaExceeded
+	}
+
+	// The conditional update leaves the row untouched, and returns nothing, when over quota
+	query := `
+		INSERT INTO api_key_usage (key_id, day, domains) VALUES ($1, $2, $3)
+		ON CONFLICT (key_id, day) DO UPDATE SET domains = api_key_usage.domains + EXCLUDED.domains
+		WHERE $4 <= 0 OR api_key_usage.domains + EXCLUDED.domains <= $4
+		RETURNING requests, domains
+	`
+
+	usage := models.APIKeyUsage{KeyID: keyID, Day: day}
+	err := p.pool.QueryRow(ctx, query, keyID, day, count, quota).Scan(&usage.Requests, &usage.Domains)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, models.ErrQuotaExceeded
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to record API key domains: %w", err)
+	}
+
+	return &usage, nil
+}
+
+// Usage returns the key's usage on the given day
+func (p *PostgresStore) Usage(ctx context.Context, keyID, day string) (*models.APIKeyUsage, error) {
+	query := `SELECT requests, domains FROM api_key_usage WHERE key_id = $1 AND day = $2`
+
+	usage := models.APIKeyUsage{KeyID: keyID, Day: day}
+	err := p.pool.QueryRow(ctx, query, keyID, day).Scan(&usage.Requests, &usage.Domains)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("failed to read API key usage: %w", err)
+	}
+
+	return &usage, nil
+}
+
+// Close closes the database connection pool
+func (p *PostgresStore) Close() error {
+	p.pool.Close()
+	return nil
+}
@@ -0,0 +1,114 @@
+package cache
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// Compressed Redis payloads start with compressedMarker followed by a byte naming the algorithm.
+// JSON, gob and msgpack encodings of structs never start with a zero byte, so uncompressed
+// payloads are stored unchanged.
+const compressedMarker = 0x00
+
+// Compression algorithms for Redis payloads
+const (
+	CompressionNone = "none"
+	CompressionGzip = "gzip"
+	CompressionZstd = "zstd"
+)
+
+// compressor compresses payloads with a single algorithm
+type compressor interface {
+	id() byte
+	compress(data []byte) ([]byte, error)
+	decompress(data []byte) ([]byte, error)
+}
+
+// zstd encoders and decoders are safe for concurrent EncodeAll/DecodeAll calls and costly to create
+var (
+	zstdEncoder, _ = zstd.NewWriter(nil)
+	zstdDecoder, _ = zstd.NewReader(nil)
+)
+
+var compressors = map[string]compressor{
+	CompressionGzip: gzipCompressor{},
+	CompressionZstd: zstdCompressor{},
+}
+
+// compressorByName returns the compressor for an algorithm, or nil for CompressionNone
+func compressorByName(name string) (compressor, error) {
+	if name == "" || name == CompressionNone {
+		return nil, nil
+	}
+	if c, ok := compressors[name]; ok {
+		return c, nil
+	}
+	return nil, fmt.Errorf("unsupported cache compression: %s", name)
+}
+
+// compressPayload wraps the compressed data in the payload envelope
+func compressPayload(c compressor, data []byte) ([]byte, error) {
+	compressed, err := c.compress(data)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte{compressedMarker, c.id()}, compressed...), nil
+}
+
+// decompressPayload returns the data of a payload, decompressing it if it is enveloped
+// Payloads are decoded whatever compression is configured, so changing it keeps existing entries readable.
+func decompressPayload(payload []byte) ([]byte, error) {
+	if len(payload) < 2 || payload[0] != compressedMarker {
+		return payload, nil
+	}
+
+	for _, c := range compressors {
+		if c.id() == payload[1] {
+			return c.decompress(payload[2:])
+		}
+	}
+	return nil, fmt.Errorf("unknown payload compression %q", payload[1])
+}
+
+// gzipCompressor compresses payloads with gzip
+type gzipCompressor struct{}
+
+func (gzipCompressor) id() byte { return 'g' }
+
+func (gzipCompressor) compress(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	if _, err := writer.Write(data); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (gzipCompressor) decompress(data []byte) ([]byte, error) {
+	reader, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return io.ReadAll(reader)
+}
+
+// zstdCompressor compresses payloads with zstd
+type zstdCompressor struct{}
+
+func (zstdCompressor) id() byte { return 'z' }
+
+func (zstdCompressor) compress(data []byte) ([]byte, error) {
+	return zstdEncoder.EncodeAll(data, nil), nil
+}
+
+func (zstdCompressor) decompress(data []byte) ([]byte, error) {
+	return zstdDecoder.DecodeAll(data, nil)
+}
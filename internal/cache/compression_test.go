@@ -0,0 +1,114 @@
+package cache
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"Perion_Assignment/internal/redisclient"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompressPayload_RoundTrip(t *testing.T) {
+	data := []byte(strings.Repeat(`{"domain":"google.com","count":1},`, 100))
+
+	for name, c := range compressors {
+		t.Run(name, func(t *testing.T) {
+			payload, err := compressPayload(c, data)
+			require.NoError(t, err)
+			assert.Equal(t, byte(compressedMarker), payload[0])
+			assert.Less(t, len(payload), len(data))
+
+			decompressed, err := decompressPayload(payload)
+			require.NoError(t, err)
+			assert.Equal(t, data, decompressed)
+		})
+	}
+}
+
+func TestDecompressPayload_Uncompressed(t *testing.T) {
+	data := []byte(`{"domain":"example.com"}`)
+
+	decompressed, err := decompressPayload(data)
+
+	require.NoError(t, err)
+	assert.Equal(t, data, decompressed)
+}
+
+func TestDecompressPayload_UnknownAlgorithm(t *testing.T) {
+	_, err := decompressPayload([]byte{compressedMarker, 'x', 1, 2, 3})
+
+	assert.ErrorContains(t, err, "unknown payload compression")
+}
+
+func TestCompressorByName(t *testing.T) {
+	for _, name := range []string{"", CompressionNone} {
+		c, err := compressorByName(name)
+		require.NoError(t, err)
+		assert.Nil(t, c)
+	}
+
+	c, err := compressorByName(CompressionZstd)
+	require.NoError(t, err)
+	assert.NotNil(t, c)
+
+	_, err = compressorByName("lz4")
+	assert.Error(t, err)
+}
+
+func TestRedisCache_Compression(t *testing.T) {
+	mr := miniredis.RunT(t)
+	cache, err := newRedisCache(redisclient.FromURL("redis://"+mr.Addr()), WithCompression(CompressionGzip, 100))
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	small := []byte(`{"a":1}`)
+	large := []byte(`{"a":"` + strings.Repeat("x", 1000) + `"}`)
+	require.NoError(t, cache.Set(ctx, "small", small, time.Hour))
+	require.NoError(t, cache.Set(ctx, "large", large, time.Hour))
+
+	// Only payloads above the threshold are compressed
+	stored, err := mr.Get("small")
+	require.NoError(t, err)
+	assert.Equal(t, string(small), stored)
+
+	stored, err = mr.Get("large")
+	require.NoError(t, err)
+	assert.Less(t, len(stored), len(large))
+
+	value, err := cache.Get(ctx, "large")
+	require.NoError(t, err)
+	assert.Equal(t, string(large), value)
+
+	info, err := cache.Inspect(ctx, "large")
+	require.NoError(t, err)
+	assert.Equal(t, string(large), info.Value)
+	assert.Equal(t, int64(len(stored)), info.Size)
+}
+
+func TestRedisCache_Compression_ReadableAfterConfigChange(t *testing.T) {
+	mr := miniredis.RunT(t)
+	writer, err := newRedisCache(redisclient.FromURL("redis://"+mr.Addr()), WithCompression(CompressionZstd, 0))
+	require.NoError(t, err)
+	reader, err := newRedisCache(redisclient.FromURL("redis://" + mr.Addr()))
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	require.NoError(t, writer.Set(ctx, "key", []byte(`{"a":1}`), time.Hour))
+
+	value, err := reader.Get(ctx, "key")
+	require.NoError(t, err)
+	assert.Equal(t, `{"a":1}`, value)
+}
+
+func TestNewRedisCache_UnsupportedCompression(t *testing.T) {
+	mr := miniredis.RunT(t)
+
+	_, err := NewRedisCache(redisclient.FromURL("redis://"+mr.Addr()), WithCompression("lz4", 0))
+
+	assert.ErrorContains(t, err, "unsupported cache compression")
+}
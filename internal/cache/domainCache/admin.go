@@ -0,0 +1,123 @@
+package domainCache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"time"
+
+	"Perion_Assignment/internal/cache"
+	"Perion_Assignment/internal/models"
+)
+
+// admin implements Admin on top of a cache backend that supports administration
+type admin struct {
+	cache   cache.Admin
+	entries *cache.Typed[entry]
+	now     func() time.Time
+}
+
+// NewAdmin creates the administration service for cached domain analyses
+// The codec must match the one the domain cache writes entries with.
+func NewAdmin(backend cache.Admin, codec cache.Codec) Admin {
+	return newAdmin(backend, codec)
+}
+
+// newAdmin creates the concrete implementation
+func newAdmin(backend cache.Admin, codec cache.Codec) *admin {
+	return &admin{
+		cache:   backend,
+		entries: cache.NewTyped[entry](backend, codec),
+		now:     time.Now,
+	}
+}
+
+// Inspect describes the cache entry of a domain
+// It returns models.ErrCacheUnavailable when the domain is not cached.
+func (a *admin) Inspect(ctx context.Context, domain string) (*models.CacheEntryInfo, error) {
+	cached, info, err := a.entries.Inspect(ctx, cacheKey(domain))
+	if err != nil {
+		return nil, err
+	}
+
+	now := a.now()
+	result := &models.CacheEntryInfo{
+		Domain:              domain,
+		TTLRemainingSeconds: int64(info.TTL.Seconds()),
+		SizeBytes:           info.Size,
+	}
+
+	if cached.Failure != nil {
+		result.Status = models.CacheEntryFailure
+		result.CachedAt = cached.Failure.FailedAt
+		result.FailureClass = cached.Failure.Class
+	} else if cached.Analysis != nil {
+		result.CachedAt = cached.Analysis.Timestamp
+		result.TotalAdvertisers = cached.Analysis.TotalAdvertisers
+		switch {
+		case cached.SoftExpiresAt.IsZero() || now.Before(cached.SoftExpiresAt):
+			result.Status = models.CacheEntryFresh
+			if !cached.SoftExpiresAt.IsZero() {
+				result.FreshForSeconds = int64(cached.SoftExpiresAt.Sub(now).Seconds())
+			}
+		case now.Before(cached.HardExpiresAt):
+			result.Status = models.CacheEntryStale
+		default:
+			result.Status = models.CacheEntryExpired
+		}
+	}
+
+	if !result.CachedAt.IsZero() {
+		result.AgeSeconds = int64(now.Sub(result.CachedAt).Seconds())
+	}
+	return result, nil
+}
+
+// DeleteDomains evicts the given domains and returns how many of them were cached
+func (a *admin) DeleteDomains(ctx context.Context, domains []string) (int, error) {
+	deleted := 0
+	for _, domain := range domains {
+		key := cacheKey(domain)
+
+		_, err := a.cache.Inspect(ctx, key)
+		if errors.Is(err, models.ErrCacheUnavailable) {
+			continue
+		}
+		if err != nil {
+			return deleted, err
+		}
+
+		if err := a.cache.Delete(ctx, key); err != nil {
+			return deleted, err
+		}
+		deleted++
+	}
+	return deleted, nil
+}
+
+// PurgePattern evicts all domains matching the glob pattern
+func (a *admin) PurgePattern(ctx context.Context, pattern string) (int, error) {
+	if pattern == "" {
+		return 0, fmt.Errorf("%w: pattern must not be empty", models.ErrInvalidCachePattern)
+	}
+	if _, err := path.Match(pattern, ""); err != nil {
+		return 0, fmt.Errorf("%w: %v", models.ErrInvalidCachePattern, err)
+	}
+
+	return a.cache.DeleteMatching(ctx, keyPrefix+pattern)
+}
+
+// PurgePrefix evicts all domains starting with the prefix
+func (a *admin) PurgePrefix(ctx context.Context, prefix string) (int, error) {
+	if prefix == "" {
+		return 0, fmt.Errorf("%w: prefix must not be empty", models.ErrInvalidCachePattern)
+	}
+
+	return a.cache.DeleteMatching(ctx, keyPrefix+cache.EscapePattern(prefix)+"*")
+}
+
+// Stats returns the statistics of the cache backend
+func (a *admin) Stats(ctx context.Context) (cache.Stats, error) {
+	return a.cache.Stats(ctx)
+}
@@ -0,0 +1,135 @@
+package domainCache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"Perion_Assignment/internal/cache"
+	"Perion_Assignment/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestAdmin creates a domain cache and its admin service sharing a memory backend and clock
+func newTestAdmin(ttl time.Duration, opts ...Option) (*domainCache, *admin, *time.Time) {
+	now := time.Now()
+	backend := cache.NewMemoryCache().(cache.Admin)
+
+	d := New(backend, ttl, opts...).(*domainCache)
+	d.now = func() time.Time { return now }
+
+	a := newAdmin(backend, cache.JSONCodec)
+	a.now = func() time.Time { return now }
+
+	return d, a, &now
+}
+
+func TestAdmin_Inspect_Fresh(t *testing.T) {
+	d, a, now := newTestAdmin(time.Hour)
+	ctx := context.Background()
+
+	analysis := &models.DomainAnalysis{Domain: "cnn.com", TotalAdvertisers: 3, Timestamp: now.Add(-10 * time.Minute)}
+	require.NoError(t, d.Set(ctx, "cnn.com", analysis, 0))
+
+	info, err := a.Inspect(ctx, "cnn.com")
+	require.NoError(t, err)
+	assert.Equal(t, "cnn.com", info.Domain)
+	assert.Equal(t, models.CacheEntryFresh, info.Status)
+	assert.Equal(t, int64(600), info.AgeSeconds)
+	assert.Equal(t, int64(3600), info.FreshForSeconds)
+	assert.InDelta(t, 3600, info.TTLRemainingSeconds, 1)
+	assert.Equal(t, 3, info.TotalAdvertisers)
+	assert.Positive(t, info.SizeBytes)
+}
+
+func TestAdmin_Inspect_StaleAndExpired(t *testing.T) {
+	d, a, now := newTestAdmin(time.Minute, WithStaleWhileRevalidate(time.Minute, time.Hour))
+	ctx := context.Background()
+
+	require.NoError(t, d.Set(ctx, "cnn.com", &models.DomainAnalysis{Domain: "cnn.com", Timestamp: *now}, 0))
+
+	*now = now.Add(90 * time.Second)
+	info, err := a.Inspect(ctx, "cnn.com")
+	require.NoError(t, err)
+	assert.Equal(t, models.CacheEntryStale, info.Status)
+	assert.Zero(t, info.FreshForSeconds)
+
+	*now = now.Add(time.Minute)
+	info, err = a.Inspect(ctx, "cnn.com")
+	require.NoError(t, err)
+	assert.Equal(t, models.CacheEntryExpired, info.Status)
+}
+
+func TestAdmin_Inspect_Failure(t *testing.T) {
+	d, a, now := newTestAdmin(time.Hour)
+	ctx := context.Background()
+
+	failure := &models.LookupFailure{Class: models.FailureClassNotFound, Message: "not found", FailedAt: now.Add(-time.Minute)}
+	require.NoError(t, d.SetFailure(ctx, "missing.com", failure, 10*time.Minute))
+
+	info, err := a.Inspect(ctx, "missing.com")
+	require.NoError(t, err)
+	assert.Equal(t, models.CacheEntryFailure, info.Status)
+	assert.Equal(t, models.FailureClassNotFound, info.FailureClass)
+	assert.Equal(t, int64(60), info.AgeSeconds)
+}
+
+func TestAdmin_Inspect_NotCached(t *testing.T) {
+	_, a, _ := newTestAdmin(time.Hour)
+
+	_, err := a.Inspect(context.Background(), "cnn.com")
+
+	assert.ErrorIs(t, err, models.ErrCacheUnavailable)
+}
+
+func TestAdmin_DeleteDomains(t *testing.T) {
+	d, a, _ := newTestAdmin(time.Hour)
+	ctx := context.Background()
+
+	for _, domain := range []string{"cnn.com", "bbc.com"} {
+		require.NoError(t, d.Set(ctx, domain, &models.DomainAnalysis{Domain: domain}, 0))
+	}
+
+	deleted, err := a.DeleteDomains(ctx, []string{"cnn.com", "bbc.com", "missing.com"})
+	require.NoError(t, err)
+	assert.Equal(t, 2, deleted)
+
+	_, err = d.Get(ctx, "cnn.com")
+	assert.ErrorIs(t, err, models.ErrCacheUnavailable)
+}
+
+func TestAdmin_PurgePatternAndPrefix(t *testing.T) {
+	d, a, _ := newTestAdmin(time.Hour)
+	ctx := context.Background()
+
+	for _, domain := range []string{"cnn.com", "edition.cnn.com", "cnn.co.uk", "bbc.com"} {
+		require.NoError(t, d.Set(ctx, domain, &models.DomainAnalysis{Domain: domain}, 0))
+	}
+
+	deleted, err := a.PurgePattern(ctx, "*.cnn.com")
+	require.NoError(t, err)
+	assert.Equal(t, 1, deleted)
+
+	deleted, err = a.PurgePrefix(ctx, "cnn.")
+	require.NoError(t, err)
+	assert.Equal(t, 2, deleted)
+
+	_, err = d.Get(ctx, "bbc.com")
+	assert.NoError(t, err)
+}
+
+func TestAdmin_Purge_InvalidPattern(t *testing.T) {
+	_, a, _ := newTestAdmin(time.Hour)
+	ctx := context.Background()
+
+	_, err := a.PurgePattern(ctx, "")
+	assert.ErrorIs(t, err, models.ErrInvalidCachePattern)
+
+	_, err = a.PurgePattern(ctx, "cnn[")
+	assert.ErrorIs(t, err, models.ErrInvalidCachePattern)
+
+	_, err = a.PurgePrefix(ctx, "")
+	assert.ErrorIs(t, err, models.ErrInvalidCachePattern)
+}
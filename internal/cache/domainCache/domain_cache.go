@@ -2,7 +2,6 @@ package domainCache
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"time"
 
@@ -10,63 +9,143 @@ import (
 	"Perion_Assignment/internal/models"
 )
 
-// domainCache implements Service using a generic cache
+// schemaVersion is part of every cache key. Bump it when entry or models.DomainAnalysis
+// change incompatibly, so entries written by older deploys are ignored and expire.
+const schemaVersion = 1
+
+// keyPrefix namespaces domain entries in the generic cache
+var keyPrefix = fmt.Sprintf("domain:v%d:", schemaVersion)
+
+// domainCache implements Service using a typed cache of entries
 type domainCache struct {
-	cache cache.Service
-	ttl   time.Duration
+	cache       *cache.Typed[entry]
+	codec       cache.Codec
+	ttl         time.Duration
+	staleWindow time.Duration // Time past the soft TTL during which stale data is served
+	grace       time.Duration // Time past the hard TTL during which stale data is kept as a fallback
+	now         func() time.Time
+}
+
+// Option configures optional domain cache behaviour
+type Option func(*domainCache)
+
+// WithStaleWhileRevalidate keeps entries after their soft TTL (the ttl passed to Set, or the default).
+// For staleWindow they are served as stale while the caller refreshes them; for a further grace
+// period they are only returned as a fallback when a refresh fails.
+func WithStaleWhileRevalidate(staleWindow, grace time.Duration) Option {
+	return func(d *domainCache) {
+		d.staleWindow = staleWindow
+		d.grace = grace
+	}
+}
+
+// WithCodec sets the codec used to encode entries (JSON by default)
+func WithCodec(codec cache.Codec) Option {
+	return func(d *domainCache) {
+		d.codec = codec
+	}
+}
+
+// entry is the stored form of a cached analysis or of a failed lookup
+type entry struct {
+	Analysis      *models.DomainAnalysis `json:"analysis,omitempty"`
+	Failure       *models.LookupFailure  `json:"failure,omitempty"`
+	SoftExpiresAt time.Time              `json:"soft_expires_at"`
+	HardExpiresAt time.Time              `json:"hard_expires_at"`
 }
 
 // New creates a new domain analysis cache
-func New(cache cache.Service, ttl time.Duration) Service {
-	return &domainCache{
-		cache: cache,
+func New(backend cache.Service, ttl time.Duration, opts ...Option) Service {
+	d := &domainCache{
+		codec: cache.JSONCodec,
 		ttl:   ttl,
+		now:   time.Now,
+	}
+	for _, opt := range opts {
+		opt(d)
 	}
+	d.cache = cache.NewTyped[entry](backend, d.codec)
+	return d
 }
 
 // Get retrieves a domain analysis from the cache
 func (d *domainCache) Get(ctx context.Context, domain string) (*models.DomainAnalysis, error) {
-	cacheKey := fmt.Sprintf("domain:%s", domain)
-	value, err := d.cache.Get(ctx, cacheKey)
+	cached, err := d.cache.Get(ctx, cacheKey(domain))
+	if err != nil {
+		return nil, err
+	}
+	return d.resolve(domain, cached)
+}
+
+// Peek retrieves a domain analysis like Get, without affecting the backend's counters or recency
+// The backend must implement cache.Admin.
+func (d *domainCache) Peek(ctx context.Context, domain string) (*models.DomainAnalysis, error) {
+	cached, _, err := d.cache.Inspect(ctx, cacheKey(domain))
 	if err != nil {
 		return nil, err
 	}
+	return d.resolve(domain, cached)
+}
+
+// resolve returns the analysis or failure of a cached entry, flagged by its age
+func (d *domainCache) resolve(domain string, cached entry) (*models.DomainAnalysis, error) {
+	// The typed cache decodes a new entry on every Get, so callers may modify the result
+	if cached.Failure != nil {
+		return nil, cached.Failure
+	}
+	if cached.Analysis == nil {
+		return nil, fmt.Errorf("cached entry for %s holds neither an analysis nor a failure", domain)
+	}
+	analysis := cached.Analysis
+	
+	now := d.now()
+	if cached.SoftExpiresAt.IsZero() || now.Before(cached.SoftExpiresAt) {
+		return analysis, nil
+	}
 	
-	// Handle type conversion
-	switch v := value.(type) {
-	case *models.DomainAnalysis:
-		// Memory cache returns the actual object
-		return v, nil
-	case models.DomainAnalysis:
-		// Handle value type
-		return &v, nil
-	case string:
-		// Redis cache returns JSON string, unmarshal it
-		var analysis models.DomainAnalysis
-		if err := json.Unmarshal([]byte(v), &analysis); err != nil {
-			return nil, fmt.Errorf("failed to unmarshal cached domain analysis: %w", err)
-		}
-		return &analysis, nil
-	default:
-		return nil, fmt.Errorf("unexpected type in cache: %T", v)
+	analysis.Stale = true
+	analysis.AgeSeconds = int64(now.Sub(analysis.Timestamp).Seconds())
+	if now.Before(cached.HardExpiresAt) {
+		return analysis, nil
 	}
+	return analysis, models.ErrCacheEntryExpired
 }
 
 // Set stores a domain analysis in the cache
 func (d *domainCache) Set(ctx context.Context, domain string, analysis *models.DomainAnalysis, ttl time.Duration) error {
-	cacheKey := fmt.Sprintf("domain:%s", domain)
-	
 	// Use provided TTL or default from domainCache
 	cacheTTL := ttl
 	if cacheTTL == 0 {
 		cacheTTL = d.ttl
 	}
 	
-	return d.cache.Set(ctx, cacheKey, analysis, cacheTTL)
+	// Never store response flags of a previous stale read
+	stored := *analysis
+	stored.Stale = false
+	stored.AgeSeconds = 0
+	
+	now := d.now()
+	value := entry{
+		Analysis:      &stored,
+		SoftExpiresAt: now.Add(cacheTTL),
+		HardExpiresAt: now.Add(cacheTTL + d.staleWindow),
+	}
+	
+	// The backend keeps the entry until the grace period after the hard TTL ends
+	return d.cache.Set(ctx, cacheKey(domain), value, cacheTTL+d.staleWindow+d.grace)
+}
+
+// SetFailure stores a failed lookup for the domain; it replaces any cached analysis
+func (d *domainCache) SetFailure(ctx context.Context, domain string, failure *models.LookupFailure, ttl time.Duration) error {
+	return d.cache.Set(ctx, cacheKey(domain), entry{Failure: failure}, ttl)
 }
 
 // Delete removes a domain analysis from the cache
 func (d *domainCache) Delete(ctx context.Context, domain string) error {
-	cacheKey := fmt.Sprintf("domain:%s", domain)
-	return d.cache.Delete(ctx, cacheKey)
-}
\ No newline at end of file
+	return d.cache.Delete(ctx, cacheKey(domain))
+}
+
+// cacheKey returns the generic cache key for a domain
+func cacheKey(domain string) string {
+	return keyPrefix + domain
+}
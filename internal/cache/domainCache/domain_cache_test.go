@@ -0,0 +1,223 @@
+package domainCache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"Perion_Assignment/internal/cache"
+	"Perion_Assignment/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestCache creates a domain cache backed by memory with a controllable clock
+func newTestCache(ttl time.Duration, opts ...Option) (*domainCache, *time.Time) {
+	now := time.Now()
+	d := New(cache.NewMemoryCache(), ttl, opts...).(*domainCache)
+	d.now = func() time.Time { return now }
+	return d, &now
+}
+
+func TestDomainCache_SetAndGet_Fresh(t *testing.T) {
+	d, _ := newTestCache(time.Hour)
+	ctx := context.Background()
+
+	require.NoError(t, d.Set(ctx, "example.com", &models.DomainAnalysis{Domain: "example.com", TotalAdvertisers: 3}, 0))
+
+	analysis, err := d.Get(ctx, "example.com")
+	require.NoError(t, err)
+	assert.Equal(t, 3, analysis.TotalAdvertisers)
+	assert.False(t, analysis.Stale)
+}
+
+func TestDomainCache_Get_ReturnsCopy(t *testing.T) {
+	d, _ := newTestCache(time.Hour)
+	ctx := context.Background()
+
+	require.NoError(t, d.Set(ctx, "example.com", &models.DomainAnalysis{Domain: "example.com"}, 0))
+
+	first, err := d.Get(ctx, "example.com")
+	require.NoError(t, err)
+	first.Cached = true
+
+	second, err := d.Get(ctx, "example.com")
+	require.NoError(t, err)
+	assert.False(t, second.Cached)
+}
+
+func TestDomainCache_StaleWhileRevalidate(t *testing.T) {
+	d, now := newTestCache(time.Minute, WithStaleWhileRevalidate(10*time.Minute, time.Hour))
+	ctx := context.Background()
+
+	stored := &models.DomainAnalysis{Domain: "example.com", Timestamp: *now}
+	require.NoError(t, d.Set(ctx, "example.com", stored, 0))
+
+	// Past the soft TTL: served as stale with its age
+	*now = now.Add(2 * time.Minute)
+	analysis, err := d.Get(ctx, "example.com")
+	require.NoError(t, err)
+	assert.True(t, analysis.Stale)
+	assert.Equal(t, int64(120), analysis.AgeSeconds)
+
+	// Past the hard TTL: only usable as a fallback
+	*now = now.Add(10 * time.Minute)
+	analysis, err = d.Get(ctx, "example.com")
+	assert.ErrorIs(t, err, models.ErrCacheEntryExpired)
+	require.NotNil(t, analysis)
+	assert.True(t, analysis.Stale)
+}
+
+func TestDomainCache_Set_PerRequestTTL(t *testing.T) {
+	d, now := newTestCache(time.Hour, WithStaleWhileRevalidate(time.Minute, 0))
+	ctx := context.Background()
+
+	require.NoError(t, d.Set(ctx, "example.com", &models.DomainAnalysis{Domain: "example.com"}, 10*time.Second))
+
+	*now = now.Add(30 * time.Second)
+	analysis, err := d.Get(ctx, "example.com")
+	require.NoError(t, err)
+	assert.True(t, analysis.Stale)
+}
+
+func TestDomainCache_Set_ClearsStaleFlags(t *testing.T) {
+	d, _ := newTestCache(time.Hour)
+	ctx := context.Background()
+
+	require.NoError(t, d.Set(ctx, "example.com", &models.DomainAnalysis{Domain: "example.com", Stale: true, AgeSeconds: 50}, 0))
+
+	analysis, err := d.Get(ctx, "example.com")
+	require.NoError(t, err)
+	assert.False(t, analysis.Stale)
+	assert.Zero(t, analysis.AgeSeconds)
+}
+
+func TestDomainCache_Get_Miss(t *testing.T) {
+	d, _ := newTestCache(time.Hour)
+
+	_, err := d.Get(context.Background(), "missing.com")
+	assert.ErrorIs(t, err, models.ErrCacheUnavailable)
+}
+
+func TestDomainCache_Peek(t *testing.T) {
+	backend := cache.NewMemoryCache().(cache.Admin)
+	d := New(backend, time.Minute, WithStaleWhileRevalidate(time.Hour, time.Hour)).(*domainCache)
+	now := time.Now()
+	d.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	require.NoError(t, d.Set(ctx, "example.com", &models.DomainAnalysis{Domain: "example.com", Timestamp: now}, 0))
+
+	analysis, err := d.Peek(ctx, "example.com")
+	require.NoError(t, err)
+	assert.False(t, analysis.Stale)
+
+	// Entries age like they do for Get
+	now = now.Add(2 * time.Minute)
+	analysis, err = d.Peek(ctx, "example.com")
+	require.NoError(t, err)
+	assert.True(t, analysis.Stale)
+
+	_, err = d.Peek(ctx, "missing.com")
+	assert.ErrorIs(t, err, models.ErrCacheUnavailable)
+
+	// Neither the hit nor the miss reached the backend's counters
+	stats, err := backend.Stats(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, stats.Hits)
+	assert.Zero(t, stats.Misses)
+}
+
+func TestEntry_UnmarshalJSON(t *testing.T) {
+	softExpiresAt := time.Now().Add(time.Minute).UTC()
+	data, err := json.Marshal(&entry{
+		Analysis:      &models.DomainAnalysis{Domain: "example.com", TotalAdvertisers: 2},
+		SoftExpiresAt: softExpiresAt,
+		HardExpiresAt: softExpiresAt,
+	})
+	require.NoError(t, err)
+
+	var decoded entry
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, 2, decoded.Analysis.TotalAdvertisers)
+	assert.True(t, decoded.SoftExpiresAt.Equal(softExpiresAt))
+}
+
+func TestDomainCache_Get_IgnoresOtherSchemaVersions(t *testing.T) {
+	backend := cache.NewMemoryCache().(cache.Admin)
+	d := New(backend, time.Hour)
+	ctx := context.Background()
+
+	// Entries written by deploys before keys were versioned, or with an older schema version
+	entry := `{"analysis":{"domain":"example.com","total_advertisers":4}}`
+	require.NoError(t, backend.Set(ctx, "domain:example.com", entry, time.Hour))
+	require.NoError(t, backend.Set(ctx, fmt.Sprintf("domain:v%d:example.com", schemaVersion-1), entry, time.Hour))
+
+	_, err := d.Get(ctx, "example.com")
+	assert.ErrorIs(t, err, models.ErrCacheUnavailable)
+}
+
+func TestCacheKey(t *testing.T) {
+	assert.Equal(t, fmt.Sprintf("domain:v%d:example.com", schemaVersion), cacheKey("example.com"))
+}
+
+func TestDomainCache_Codecs(t *testing.T) {
+	for _, codec := range []cache.Codec{cache.JSONCodec, cache.GobCodec, cache.MsgpackCodec} {
+		t.Run(codec.Name(), func(t *testing.T) {
+			d, _ := newTestCache(time.Hour, WithCodec(codec))
+			ctx := context.Background()
+
+			timestamp := time.Now().UTC().Truncate(time.Millisecond)
+			analysis := &models.DomainAnalysis{
+				Domain:           "example.com",
+				TotalAdvertisers: 1,
+				Advertisers:      []models.AdvertiserInfo{{Domain: "google.com", Count: 3}},
+				Timestamp:        timestamp,
+			}
+			require.NoError(t, d.Set(ctx, "example.com", analysis, 0))
+
+			cached, err := d.Get(ctx, "example.com")
+			require.NoError(t, err)
+			assert.Equal(t, analysis.Advertisers, cached.Advertisers)
+			assert.True(t, cached.Timestamp.Equal(timestamp))
+
+			failure := &models.LookupFailure{Class: models.FailureClassDNS, Message: "no such host", FailedAt: timestamp}
+			require.NoError(t, d.SetFailure(ctx, "missing.com", failure, time.Minute))
+
+			_, err = d.Get(ctx, "missing.com")
+			var cachedFailure *models.LookupFailure
+			require.ErrorAs(t, err, &cachedFailure)
+			assert.Equal(t, models.FailureClassDNS, cachedFailure.Class)
+		})
+	}
+}
+
+func TestDomainCache_SetFailure(t *testing.T) {
+	d, _ := newTestCache(time.Hour)
+	ctx := context.Background()
+
+	failure := &models.LookupFailure{Class: models.FailureClassNotFound, Message: "ads.txt not found", FailedAt: time.Now().UTC()}
+	require.NoError(t, d.Set(ctx, "example.com", &models.DomainAnalysis{Domain: "example.com"}, 0))
+	require.NoError(t, d.SetFailure(ctx, "example.com", failure, time.Minute))
+
+	analysis, err := d.Get(ctx, "example.com")
+	assert.Nil(t, analysis)
+
+	var cached *models.LookupFailure
+	require.ErrorAs(t, err, &cached)
+	assert.Equal(t, models.FailureClassNotFound, cached.Class)
+	assert.ErrorIs(t, err, models.ErrDomainNotFound)
+}
+
+func TestEntry_UnmarshalJSON_Failure(t *testing.T) {
+	data, err := json.Marshal(&entry{Failure: &models.LookupFailure{Class: models.FailureClassDNS, Message: "no such host"}})
+	require.NoError(t, err)
+
+	var decoded entry
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	require.NotNil(t, decoded.Failure)
+	assert.Equal(t, models.FailureClassDNS, decoded.Failure.Class)
+}
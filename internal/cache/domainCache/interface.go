@@ -4,12 +4,36 @@ import (
 	"context"
 	"time"
 
+	"Perion_Assignment/internal/cache"
 	"Perion_Assignment/internal/models"
 )
 
 // Service defines the interface for domain analysis cache operations
+//
+// Get returns fresh entries as-is and entries past their soft TTL flagged as stale.
+// Entries past their hard TTL but within the grace period are returned together with
+// models.ErrCacheEntryExpired so callers can fall back to them when a refresh fails.
+// Failed lookups stored with SetFailure are returned by Get as a *models.LookupFailure error.
 type Service interface {
 	Get(ctx context.Context, domain string) (*models.DomainAnalysis, error)
 	Set(ctx context.Context, domain string, analysis *models.DomainAnalysis, ttl time.Duration) error
+	SetFailure(ctx context.Context, domain string, failure *models.LookupFailure, ttl time.Duration) error
 	Delete(ctx context.Context, domain string) error
-}
\ No newline at end of file
+}
+
+// Peeker is implemented by domain caches that can read an entry without side effects
+// Peek returns what Get would, but doesn't count a hit or a miss, mark the entry as
+// recently used or copy it into another cache tier.
+type Peeker interface {
+	Peek(ctx context.Context, domain string) (*models.DomainAnalysis, error)
+}
+
+// Admin defines administration operations on cached domain analyses
+// Patterns and prefixes apply to domain names; patterns use glob syntax (*, ?, [...]).
+type Admin interface {
+	Inspect(ctx context.Context, domain string) (*models.CacheEntryInfo, error)
+	DeleteDomains(ctx context.Context, domains []string) (int, error)
+	PurgePattern(ctx context.Context, pattern string) (int, error)
+	PurgePrefix(ctx context.Context, prefix string) (int, error)
+	Stats(ctx context.Context) (cache.Stats, error)
+}
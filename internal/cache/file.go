@@ -0,0 +1,401 @@
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"Perion_Assignment/internal/models"
+)
+
+// fileHeaderSize is the fixed part of an entry file: expiry (unix nanoseconds) and key length
+const fileHeaderSize = 12
+
+// tempFilePrefix marks files still being written; compaction removes abandoned ones
+const tempFilePrefix = ".tmp-"
+
+// fileExtension is the suffix of entry files
+const fileExtension = ".entry"
+
+// FileCache implements Service with one file per key in a local directory
+//
+// Files are named after the SHA-256 of their key and spread over 256 subdirectories.
+// Each file holds the expiry, the key and the value, and is replaced atomically by
+// writing a temporary file and renaming it, so entries survive restarts and readers
+// never see a partial write. Expired entries are removed when read and by a
+// periodic compaction.
+type FileCache struct {
+	dir  string
+	now  func() time.Time
+	done chan struct{}
+	once sync.Once
+
+	hits        atomic.Uint64
+	misses      atomic.Uint64
+	expirations atomic.Uint64
+}
+
+// NewFileCache creates a cache persisted in dir, compacting it every compactInterval
+// (0 disables background compaction)
+func NewFileCache(dir string, compactInterval time.Duration) (Service, error) {
+	return newFileCache(dir, compactInterval)
+}
+
+// newFileCache creates the concrete implementation
+func newFileCache(dir string, compactInterval time.Duration) (*FileCache, error) {
+	if dir == "" {
+		return nil, fmt.Errorf("cache directory is required")
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create cache directory: %w", err)
+	}
+
+	cache := &FileCache{
+		dir:  dir,
+		now:  time.Now,
+		done: make(chan struct{}),
+	}
+
+	if compactInterval > 0 {
+		go cache.compactPeriodically(compactInterval)
+	}
+
+	return cache, nil
+}
+
+// Get retrieves a cached value for the given key
+// Like RedisCache, values are returned in their stored (encoded) form as a string.
+func (f *FileCache) Get(ctx context.Context, key string) (interface{}, error) {
+	entry, err := f.read(f.path(key))
+	if err != nil {
+		f.misses.Add(1)
+		return nil, err
+	}
+
+	if !f.now().Before(entry.expiresAt) {
+		f.remove(f.path(key))
+		f.expirations.Add(1)
+		f.misses.Add(1)
+		return nil, models.ErrCacheUnavailable
+	}
+
+	f.hits.Add(1)
+	return string(entry.value), nil
+}
+
+// Set stores a value on disk with the specified TTL
+func (f *FileCache) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	if ttl <= 0 {
+		return fmt.Errorf("TTL must be positive, got: %v", ttl)
+	}
+
+	// Encoded values (see Typed) are stored as-is; anything else is stored as JSON
+	data, ok := value.([]byte)
+	if !ok {
+		var err error
+		data, err = json.Marshal(value)
+		if err != nil {
+			return fmt.Errorf("failed to marshal value: %w", err)
+		}
+	}
+
+	return f.write(f.path(key), key, data, f.now().Add(ttl))
+}
+
+// Delete removes an entry from disk
+func (f *FileCache) Delete(ctx context.Context, key string) error {
+	if err := os.Remove(f.path(key)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("file cache delete failed: %w", err)
+	}
+	return nil
+}
+
+// Inspect returns an entry without counting a hit
+func (f *FileCache) Inspect(ctx context.Context, key string) (*EntryInfo, error) {
+	entry, err := f.read(f.path(key))
+	if err != nil {
+		return nil, err
+	}
+
+	remaining := entry.expiresAt.Sub(f.now())
+	if remaining <= 0 {
+		return nil, models.ErrCacheUnavailable
+	}
+
+	return &EntryInfo{
+		Key:   key,
+		Value: string(entry.value),
+		Size:  entry.size,
+		TTL:   remaining,
+	}, nil
+}
+
+// DeleteMatching removes all entries whose key matches the glob pattern
+func (f *FileCache) DeleteMatching(ctx context.Context, pattern string) (int, error) {
+	if _, err := path.Match(pattern, ""); err != nil {
+		return 0, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+	}
+
+	deleted := 0
+	err := f.walk(ctx, func(file string, header fileHeader) {
+		if matched, _ := path.Match(pattern, header.key); matched {
+			f.remove(file)
+			deleted++
+		}
+	})
+	return deleted, err
+}
+
+// Stats returns the number and size of live entries and the usage counters
+func (f *FileCache) Stats(ctx context.Context) (Stats, error) {
+	stats := Stats{Backend: "file"}
+	now := f.now()
+	err := f.walk(ctx, func(file string, header fileHeader) {
+		if now.Before(header.expiresAt) {
+			stats.Entries++
+			stats.Bytes += header.size
+		}
+	})
+	if err != nil {
+		return Stats{}, err
+	}
+
+	stats.Hits = f.hits.Load()
+	stats.Misses = f.misses.Load()
+	stats.Expirations = f.expirations.Load()
+	return stats, nil
+}
+
+// Compact removes expired entries and temporary files left behind by interrupted writes
+func (f *FileCache) Compact(ctx context.Context) error {
+	now := f.now()
+	return filepath.WalkDir(f.dir, func(file string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+
+		if strings.HasPrefix(d.Name(), tempFilePrefix) {
+			// A write in progress renames its file within moments
+			if info, err := d.Info(); err == nil && now.Sub(info.ModTime()) > time.Minute {
+				f.remove(file)
+			}
+			return nil
+		}
+		if !strings.HasSuffix(d.Name(), fileExtension) {
+			return nil
+		}
+
+		header, err := readHeader(file)
+		if err != nil {
+			// Corrupt entries are unreadable anyway
+			if !errors.Is(err, fs.ErrNotExist) {
+				f.remove(file)
+			}
+			return nil
+		}
+		if !now.Before(header.expiresAt) {
+			f.remove(file)
+			f.expirations.Add(1)
+		}
+		return nil
+	})
+}
+
+// Close stops the background compaction
+func (f *FileCache) Close() error {
+	f.once.Do(func() {
+		close(f.done)
+	})
+	return nil
+}
+
+// compactPeriodically runs Compact until the cache is closed
+func (f *FileCache) compactPeriodically(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			_ = f.Compact(context.Background())
+		case <-f.done:
+			return
+		}
+	}
+}
+
+// fileHeader is the part of an entry file needed to list entries
+type fileHeader struct {
+	key       string
+	expiresAt time.Time
+	size      int64
+}
+
+// fileEntry is a complete entry read from disk
+type fileEntry struct {
+	fileHeader
+	value []byte
+}
+
+// path returns the file holding key
+func (f *FileCache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	name := hex.EncodeToString(sum[:])
+	return filepath.Join(f.dir, name[:2], name+fileExtension)
+}
+
+// read loads an entry file, returning models.ErrCacheUnavailable if it doesn't exist
+func (f *FileCache) read(file string) (*fileEntry, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, models.ErrCacheUnavailable
+		}
+		return nil, fmt.Errorf("file cache read failed: %w", err)
+	}
+
+	header, err := parseHeader(data)
+	if err != nil {
+		return nil, err
+	}
+	header.size = int64(len(data))
+
+	return &fileEntry{
+		fileHeader: header,
+		value:      data[fileHeaderSize+len(header.key):],
+	}, nil
+}
+
+// write atomically replaces the entry file for key
+func (f *FileCache) write(file, key string, value []byte, expiresAt time.Time) error {
+	dir := filepath.Dir(file)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("file cache write failed: %w", err)
+	}
+
+	data := make([]byte, fileHeaderSize, fileHeaderSize+len(key)+len(value))
+	binary.BigEndian.PutUint64(data[0:8], uint64(expiresAt.UnixNano()))
+	binary.BigEndian.PutUint32(data[8:12], uint32(len(key)))
+	data = append(data, key...)
+	data = append(data, value...)
+
+	temp, err := os.CreateTemp(dir, tempFilePrefix+"*")
+	if err != nil {
+		return fmt.Errorf("file cache write failed: %w", err)
+	}
+	if _, err := temp.Write(data); err != nil {
+		_ = temp.Close()
+		_ = os.Remove(temp.Name())
+		return fmt.Errorf("file cache write failed: %w", err)
+	}
+	if err := temp.Close(); err != nil {
+		_ = os.Remove(temp.Name())
+		return fmt.Errorf("file cache write failed: %w", err)
+	}
+	if err := os.Rename(temp.Name(), file); err != nil {
+		_ = os.Remove(temp.Name())
+		return fmt.Errorf("file cache write failed: %w", err)
+	}
+	return nil
+}
+
+// remove deletes a file, ignoring errors: a concurrent delete or rewrite wins
+func (f *FileCache) remove(file string) {
+	_ = os.Remove(file)
+}
+
+// walk calls fn with the header of every entry file
+func (f *FileCache) walk(ctx context.Context, fn func(file string, header fileHeader)) error {
+	err := filepath.WalkDir(f.dir, func(file string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if d.IsDir() || !strings.HasSuffix(d.Name(), fileExtension) {
+			return nil
+		}
+
+		header, err := readHeader(file)
+		if err != nil {
+			// Skip entries deleted or corrupted since the directory was listed
+			return nil
+		}
+		fn(file, header)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("file cache scan failed: %w", err)
+	}
+	return nil
+}
+
+// readHeader reads the expiry and key of an entry file without loading its value
+func readHeader(file string) (fileHeader, error) {
+	handle, err := os.Open(file)
+	if err != nil {
+		return fileHeader{}, err
+	}
+	defer handle.Close()
+
+	info, err := handle.Stat()
+	if err != nil {
+		return fileHeader{}, err
+	}
+
+	fixed := make([]byte, fileHeaderSize)
+	if _, err := io.ReadFull(handle, fixed); err != nil {
+		return fileHeader{}, fmt.Errorf("corrupt cache file %s: %w", file, err)
+	}
+	keyLength := binary.BigEndian.Uint32(fixed[8:12])
+	if int64(fileHeaderSize)+int64(keyLength) > info.Size() {
+		return fileHeader{}, fmt.Errorf("corrupt cache file %s", file)
+	}
+
+	key := make([]byte, keyLength)
+	if _, err := io.ReadFull(handle, key); err != nil {
+		return fileHeader{}, fmt.Errorf("corrupt cache file %s: %w", file, err)
+	}
+
+	return fileHeader{
+		key:       string(key),
+		expiresAt: time.Unix(0, int64(binary.BigEndian.Uint64(fixed[0:8]))),
+		size:      info.Size(),
+	}, nil
+}
+
+// parseHeader decodes the expiry and key at the start of an entry file
+func parseHeader(data []byte) (fileHeader, error) {
+	if len(data) < fileHeaderSize {
+		return fileHeader{}, fmt.Errorf("corrupt cache file: %d bytes", len(data))
+	}
+	keyLength := int(binary.BigEndian.Uint32(data[8:12]))
+	if len(data) < fileHeaderSize+keyLength {
+		return fileHeader{}, fmt.Errorf("corrupt cache file: key exceeds file")
+	}
+
+	return fileHeader{
+		key:       string(data[fileHeaderSize : fileHeaderSize+keyLength]),
+		expiresAt: time.Unix(0, int64(binary.BigEndian.Uint64(data[0:8]))),
+	}, nil
+}
@@ -0,0 +1,259 @@
+package cache
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"Perion_Assignment/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupFileCache creates a file cache in a temporary directory with a controllable clock
+func setupFileCache(t *testing.T) (*FileCache, *time.Time) {
+	cache, err := newFileCache(t.TempDir(), 0)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = cache.Close() })
+
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+	return cache, &now
+}
+
+func TestFileCache_SetAndGet(t *testing.T) {
+	cache, _ := setupFileCache(t)
+	ctx := context.Background()
+
+	require.NoError(t, cache.Set(ctx, "key", []byte(`{"a":1}`), time.Hour))
+
+	value, err := cache.Get(ctx, "key")
+	require.NoError(t, err)
+	assert.Equal(t, `{"a":1}`, value)
+}
+
+func TestFileCache_Set_MarshalsNonByteValues(t *testing.T) {
+	cache, _ := setupFileCache(t)
+	ctx := context.Background()
+
+	require.NoError(t, cache.Set(ctx, "key", map[string]int{"a": 1}, time.Hour))
+
+	value, err := cache.Get(ctx, "key")
+	require.NoError(t, err)
+	assert.Equal(t, `{"a":1}`, value)
+
+	err = cache.Set(ctx, "bad", make(chan int), time.Hour)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to marshal value")
+}
+
+func TestFileCache_Get_NotFound(t *testing.T) {
+	cache, _ := setupFileCache(t)
+
+	value, err := cache.Get(context.Background(), "missing")
+	assert.Nil(t, value)
+	assert.ErrorIs(t, err, models.ErrCacheUnavailable)
+}
+
+func TestFileCache_Get_Expired(t *testing.T) {
+	cache, now := setupFileCache(t)
+	ctx := context.Background()
+
+	require.NoError(t, cache.Set(ctx, "key", "value", time.Minute))
+	*now = now.Add(time.Minute)
+
+	_, err := cache.Get(ctx, "key")
+	assert.ErrorIs(t, err, models.ErrCacheUnavailable)
+
+	// The expired file is removed on read
+	_, statErr := os.Stat(cache.path("key"))
+	assert.True(t, os.IsNotExist(statErr))
+}
+
+func TestFileCache_Set_InvalidTTL(t *testing.T) {
+	cache, _ := setupFileCache(t)
+
+	err := cache.Set(context.Background(), "key", "value", 0)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "TTL must be positive")
+}
+
+func TestFileCache_Delete(t *testing.T) {
+	cache, _ := setupFileCache(t)
+	ctx := context.Background()
+
+	require.NoError(t, cache.Set(ctx, "key", "value", time.Hour))
+	require.NoError(t, cache.Delete(ctx, "key"))
+	require.NoError(t, cache.Delete(ctx, "key"))
+
+	_, err := cache.Get(ctx, "key")
+	assert.ErrorIs(t, err, models.ErrCacheUnavailable)
+}
+
+func TestFileCache_SurvivesRestart(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+
+	first, err := NewFileCache(dir, 0)
+	require.NoError(t, err)
+	require.NoError(t, first.Set(ctx, "domain:v1:cnn.com", []byte("analysis"), time.Hour))
+
+	second, err := NewFileCache(dir, 0)
+	require.NoError(t, err)
+	value, err := second.Get(ctx, "domain:v1:cnn.com")
+	require.NoError(t, err)
+	assert.Equal(t, "analysis", value)
+}
+
+func TestNewFileCache_InvalidDirectory(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "file")
+	require.NoError(t, os.WriteFile(file, nil, 0o644))
+
+	_, err := NewFileCache(filepath.Join(file, "cache"), 0)
+	assert.Error(t, err)
+
+	_, err = NewFileCache("", 0)
+	assert.Error(t, err)
+}
+
+func TestFileCache_CorruptFile(t *testing.T) {
+	cache, _ := setupFileCache(t)
+	ctx := context.Background()
+
+	require.NoError(t, cache.Set(ctx, "key", "value", time.Hour))
+	require.NoError(t, os.WriteFile(cache.path("key"), []byte("junk"), 0o644))
+
+	_, err := cache.Get(ctx, "key")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "corrupt cache file")
+
+	require.NoError(t, cache.Compact(ctx))
+	_, statErr := os.Stat(cache.path("key"))
+	assert.True(t, os.IsNotExist(statErr))
+}
+
+func TestFileCache_Compact(t *testing.T) {
+	cache, now := setupFileCache(t)
+	ctx := context.Background()
+
+	require.NoError(t, cache.Set(ctx, "short", "value", time.Minute))
+	require.NoError(t, cache.Set(ctx, "long", "value", time.Hour))
+
+	// An abandoned temporary file from an interrupted write
+	abandoned := filepath.Join(cache.dir, tempFilePrefix+"abandoned")
+	require.NoError(t, os.WriteFile(abandoned, []byte("partial"), 0o644))
+
+	*now = now.Add(2 * time.Minute)
+	require.NoError(t, cache.Compact(ctx))
+
+	_, err := os.Stat(cache.path("short"))
+	assert.True(t, os.IsNotExist(err))
+	_, err = os.Stat(cache.path("long"))
+	assert.NoError(t, err)
+	_, err = os.Stat(abandoned)
+	assert.True(t, os.IsNotExist(err))
+
+	stats, err := cache.Stats(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), stats.Entries)
+	assert.Equal(t, uint64(1), stats.Expirations)
+}
+
+func TestFileCache_CompactsInBackground(t *testing.T) {
+	cache, err := newFileCache(t.TempDir(), 20*time.Millisecond)
+	require.NoError(t, err)
+	defer cache.Close()
+	ctx := context.Background()
+
+	require.NoError(t, cache.Set(ctx, "key", "value", 10*time.Millisecond))
+
+	assert.Eventually(t, func() bool {
+		_, err := os.Stat(cache.path("key"))
+		return os.IsNotExist(err)
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestFileCache_Inspect(t *testing.T) {
+	cache, _ := setupFileCache(t)
+	ctx := context.Background()
+
+	require.NoError(t, cache.Set(ctx, "key", "value", time.Hour))
+
+	info, err := cache.Inspect(ctx, "key")
+	require.NoError(t, err)
+	assert.Equal(t, "key", info.Key)
+	assert.Equal(t, `"value"`, info.Value)
+	assert.Equal(t, int64(fileHeaderSize+len("key")+len(`"value"`)), info.Size)
+	assert.Equal(t, time.Hour, info.TTL)
+
+	_, err = cache.Inspect(ctx, "missing")
+	assert.ErrorIs(t, err, models.ErrCacheUnavailable)
+
+	stats, err := cache.Stats(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, stats.Hits)
+	assert.Zero(t, stats.Misses)
+}
+
+func TestFileCache_DeleteMatching(t *testing.T) {
+	cache, _ := setupFileCache(t)
+	ctx := context.Background()
+
+	for _, key := range []string{"domain:cnn.com", "domain:cnn.co.uk", "domain:bbc.com", "other:cnn.com"} {
+		require.NoError(t, cache.Set(ctx, key, "value", time.Hour))
+	}
+
+	deleted, err := cache.DeleteMatching(ctx, "domain:cnn.*")
+	require.NoError(t, err)
+	assert.Equal(t, 2, deleted)
+
+	_, err = cache.Get(ctx, "domain:bbc.com")
+	assert.NoError(t, err)
+	_, err = cache.Get(ctx, "other:cnn.com")
+	assert.NoError(t, err)
+
+	_, err = cache.DeleteMatching(ctx, "[")
+	assert.Error(t, err)
+}
+
+func TestFileCache_Stats(t *testing.T) {
+	cache, _ := setupFileCache(t)
+	ctx := context.Background()
+
+	require.NoError(t, cache.Set(ctx, "a", "1", time.Hour))
+	require.NoError(t, cache.Set(ctx, "b", "2", time.Hour))
+	_, _ = cache.Get(ctx, "a")
+	_, _ = cache.Get(ctx, "missing")
+
+	stats, err := cache.Stats(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "file", stats.Backend)
+	assert.Equal(t, int64(2), stats.Entries)
+	assert.Equal(t, int64(2*(fileHeaderSize+1+len(`"1"`))), stats.Bytes)
+	assert.Equal(t, uint64(1), stats.Hits)
+	assert.Equal(t, uint64(1), stats.Misses)
+}
+
+func TestFileCache_ConcurrentAccess(t *testing.T) {
+	cache, _ := setupFileCache(t)
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				assert.NoError(t, cache.Set(ctx, "shared", []byte("value"), time.Hour))
+				value, err := cache.Get(ctx, "shared")
+				if assert.NoError(t, err) {
+					assert.Equal(t, "value", value)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
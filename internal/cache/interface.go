@@ -2,6 +2,7 @@ package cache
 
 import (
 	"context"
+	"strings"
 	"time"
 )
 
@@ -11,4 +12,47 @@ type Service interface {
 	Get(ctx context.Context, key string) (interface{}, error)
 	Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error
 	Delete(ctx context.Context, key string) error
-}
\ No newline at end of file
+}
+
+// Admin defines a cache that also supports the operations of the cache admin API
+// Patterns use glob syntax (*, ?, [...]) as in Redis SCAN MATCH.
+type Admin interface {
+	Service
+	Inspect(ctx context.Context, key string) (*EntryInfo, error)
+	DeleteMatching(ctx context.Context, pattern string) (int, error)
+	Stats(ctx context.Context) (Stats, error)
+}
+
+// EntryInfo describes a single cached value without affecting its recency or hit counters
+type EntryInfo struct {
+	Key   string
+	Value interface{}
+	Size  int64         // Approximate size in bytes
+	TTL   time.Duration // Remaining time before the backend drops the entry
+}
+
+// Stats reports the size and usage counters of a cache backend
+type Stats struct {
+	Backend     string `json:"backend"`
+	Entries     int64  `json:"entries"`
+	Bytes       int64  `json:"bytes,omitempty"`
+	MaxEntries  int    `json:"max_entries,omitempty"`
+	MaxBytes    int64  `json:"max_bytes,omitempty"`
+	Hits        uint64 `json:"hits"`
+	Misses      uint64 `json:"misses"`
+	Evictions   uint64 `json:"evictions"`
+	Expirations uint64 `json:"expirations"`
+	L1          *Stats `json:"l1,omitempty"`
+}
+
+// EscapePattern escapes glob metacharacters so the text only matches itself
+func EscapePattern(text string) string {
+	var escaped strings.Builder
+	for _, r := range text {
+		if strings.ContainsRune(`*?[]\`, r) {
+			escaped.WriteByte('\\')
+		}
+		escaped.WriteRune(r)
+	}
+	return escaped.String()
+}
@@ -1,76 +1,129 @@
 package cache
 
 import (
+	"container/list"
 	"context"
+	"encoding/json"
 	"fmt"
+	"path"
 	"sync"
 	"time"
 
 	"Perion_Assignment/internal/models"
 )
 
+// entryOverhead approximates the bookkeeping cost of a single entry in bytes
+const entryOverhead = 128
+
 // MemoryCache implements Service using in-memory storage
+// When bounded, the least recently used entries are evicted once either
+// the entry count or the approximate byte size exceeds its limit.
 type MemoryCache struct {
-	data  map[string]*cacheEntry
-	mutex sync.RWMutex
+	data  map[string]*list.Element
+	order *list.List // Front is the most recently used entry
+	mutex sync.Mutex
+
+	maxEntries int   // 0 means unbounded
+	maxBytes   int64 // 0 means unbounded
+	bytes      int64
+
+	hits        uint64
+	misses      uint64
+	evictions   uint64
+	expirations uint64
 }
 
 // cacheEntry represents a single cache entry with expiration
 type cacheEntry struct {
+	key       string
 	value     interface{}
+	size      int64
 	expiresAt time.Time
 }
 
-// NewMemoryCache creates a new in-memory cache
+// NewMemoryCache creates a new unbounded in-memory cache
 func NewMemoryCache() Service {
 	return newMemoryCache()
 }
 
+// NewBoundedMemoryCache creates a new in-memory cache holding at most maxEntries entries
+// and roughly maxBytes bytes; a zero limit is not enforced
+func NewBoundedMemoryCache(maxEntries int, maxBytes int64) Service {
+	return newBoundedMemoryCache(maxEntries, maxBytes)
+}
+
 // newMemoryCache creates the concrete implementation
 func newMemoryCache() *MemoryCache {
+	return newBoundedMemoryCache(0, 0)
+}
+
+// newBoundedMemoryCache creates the concrete bounded implementation
+func newBoundedMemoryCache(maxEntries int, maxBytes int64) *MemoryCache {
 	cache := &MemoryCache{
-		data: make(map[string]*cacheEntry),
+		data:       make(map[string]*list.Element),
+		order:      list.New(),
+		maxEntries: maxEntries,
+		maxBytes:   maxBytes,
 	}
-	
+
 	// Start cleanup routine
 	go cache.cleanupExpired()
-	
+
 	return cache
 }
 
 // Get retrieves a cached value for the given key
 func (m *MemoryCache) Get(ctx context.Context, key string) (interface{}, error) {
-	m.mutex.RLock()
-	defer m.mutex.RUnlock()
-	
-	entry, exists := m.data[key]
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	element, exists := m.data[key]
 	if !exists {
+		m.misses++
 		return nil, models.ErrCacheUnavailable
 	}
-	
+
 	// Check if entry has expired
+	entry := element.Value.(*cacheEntry)
 	if time.Now().After(entry.expiresAt) {
-		// Remove expired entry (will be cleaned up by background routine)
+		m.removeElement(element)
+		m.expirations++
+		m.misses++
 		return nil, models.ErrCacheUnavailable
 	}
-	
+
+	m.order.MoveToFront(element)
+	m.hits++
 	return entry.value, nil
 }
 
 // Set stores a value in the cache with the specified TTL
 func (m *MemoryCache) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
-	m.mutex.Lock()
-	defer m.mutex.Unlock()
-	
 	if ttl <= 0 {
 		return fmt.Errorf("TTL must be positive, got: %v", ttl)
 	}
-	
-	m.data[key] = &cacheEntry{
+
+	size := estimateSize(key, value)
+	if m.maxBytes > 0 && size > m.maxBytes {
+		return fmt.Errorf("value of %d bytes exceeds cache capacity of %d bytes", size, m.maxBytes)
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if element, exists := m.data[key]; exists {
+		m.removeElement(element)
+	}
+
+	m.data[key] = m.order.PushFront(&cacheEntry{
+		key:       key,
 		value:     value,
+		size:      size,
 		expiresAt: time.Now().Add(ttl),
-	}
-	
+	})
+	m.bytes += size
+
+	m.evictOverflow()
 	return nil
 }
 
@@ -78,32 +131,148 @@ func (m *MemoryCache) Set(ctx context.Context, key string, value interface{}, tt
 func (m *MemoryCache) Delete(ctx context.Context, key string) error {
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
-	
-	delete(m.data, key)
+
+	if element, exists := m.data[key]; exists {
+		m.removeElement(element)
+	}
 	return nil
 }
 
+// evictOverflow drops least recently used entries until the cache is within its bounds
+// Caller must hold the mutex
+func (m *MemoryCache) evictOverflow() {
+	for m.overCapacity() {
+		oldest := m.order.Back()
+		if oldest == nil {
+			return
+		}
+		m.removeElement(oldest)
+		m.evictions++
+	}
+}
+
+// overCapacity reports whether either bound is exceeded
+func (m *MemoryCache) overCapacity() bool {
+	return (m.maxEntries > 0 && m.order.Len() > m.maxEntries) ||
+		(m.maxBytes > 0 && m.bytes > m.maxBytes)
+}
+
+// removeElement removes an entry from both the index and the usage list
+// Caller must hold the mutex
+func (m *MemoryCache) removeElement(element *list.Element) {
+	entry := m.order.Remove(element).(*cacheEntry)
+	delete(m.data, entry.key)
+	m.bytes -= entry.size
+}
+
 // cleanupExpired removes expired entries from the cache
 func (m *MemoryCache) cleanupExpired() {
 	ticker := time.NewTicker(5 * time.Minute) // Cleanup every 5 minutes
 	defer ticker.Stop()
-	
+
 	for range ticker.C {
-		now := time.Now()
-		
-		m.mutex.Lock()
-		for key, entry := range m.data {
-			if now.After(entry.expiresAt) {
-				delete(m.data, key)
-			}
+		m.removeExpired(time.Now())
+	}
+}
+
+// removeExpired removes all entries that expired before now
+func (m *MemoryCache) removeExpired(now time.Time) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	for element := m.order.Back(); element != nil; {
+		previous := element.Prev()
+		if now.After(element.Value.(*cacheEntry).expiresAt) {
+			m.removeElement(element)
+			m.expirations++
 		}
-		m.mutex.Unlock()
+		element = previous
 	}
 }
 
 // Size returns the current number of cached entries (for monitoring)
 func (m *MemoryCache) Size() int {
-	m.mutex.RLock()
-	defer m.mutex.RUnlock()
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
 	return len(m.data)
-}
\ No newline at end of file
+}
+
+// Inspect returns an entry without marking it as recently used or counting a hit
+func (m *MemoryCache) Inspect(ctx context.Context, key string) (*EntryInfo, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	element, exists := m.data[key]
+	if !exists {
+		return nil, models.ErrCacheUnavailable
+	}
+
+	entry := element.Value.(*cacheEntry)
+	remaining := time.Until(entry.expiresAt)
+	if remaining <= 0 {
+		return nil, models.ErrCacheUnavailable
+	}
+
+	return &EntryInfo{
+		Key:   key,
+		Value: entry.value,
+		Size:  entry.size,
+		TTL:   remaining,
+	}, nil
+}
+
+// DeleteMatching removes all entries whose key matches the glob pattern
+func (m *MemoryCache) DeleteMatching(ctx context.Context, pattern string) (int, error) {
+	if _, err := path.Match(pattern, ""); err != nil {
+		return 0, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	deleted := 0
+	for key, element := range m.data {
+		if matched, _ := path.Match(pattern, key); matched {
+			m.removeElement(element)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+// Stats returns the current size and usage counters
+func (m *MemoryCache) Stats(ctx context.Context) (Stats, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	return Stats{
+		Backend:     "memory",
+		Entries:     int64(len(m.data)),
+		Bytes:       m.bytes,
+		MaxEntries:  m.maxEntries,
+		MaxBytes:    m.maxBytes,
+		Hits:        m.hits,
+		Misses:      m.misses,
+		Evictions:   m.evictions,
+		Expirations: m.expirations,
+	}, nil
+}
+
+// estimateSize approximates the memory used by an entry
+// Strings and byte slices are measured directly; other values by their JSON encoding
+func estimateSize(key string, value interface{}) int64 {
+	size := int64(entryOverhead + len(key))
+
+	switch v := value.(type) {
+	case string:
+		return size + int64(len(v))
+	case []byte:
+		return size + int64(len(v))
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return size
+	}
+	return size + int64(len(data))
+}
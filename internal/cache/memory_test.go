@@ -2,6 +2,9 @@ package cache
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
 	"testing"
 	"time"
 
@@ -379,3 +382,158 @@ func TestMemoryCache_CleanupRoutineStarted(t *testing.T) {
 	// The goroutine is launched in newMemoryCache(),
 	// so by this point it's running
 }
+
+func TestMemoryCache_Bounded_EvictsLeastRecentlyUsed(t *testing.T) {
+	cache := newBoundedMemoryCache(2, 0)
+	ctx := context.Background()
+
+	require.NoError(t, cache.Set(ctx, "a", "1", time.Hour))
+	require.NoError(t, cache.Set(ctx, "b", "2", time.Hour))
+
+	// Reading "a" makes "b" the least recently used entry
+	_, err := cache.Get(ctx, "a")
+	require.NoError(t, err)
+
+	require.NoError(t, cache.Set(ctx, "c", "3", time.Hour))
+
+	_, err = cache.Get(ctx, "b")
+	assert.ErrorIs(t, err, models.ErrCacheUnavailable)
+	_, err = cache.Get(ctx, "a")
+	assert.NoError(t, err)
+	_, err = cache.Get(ctx, "c")
+	assert.NoError(t, err)
+
+	stats := statsOf(t, cache)
+	assert.Equal(t, int64(2), stats.Entries)
+	assert.Equal(t, uint64(1), stats.Evictions)
+}
+
+func TestMemoryCache_Bounded_EvictsBySize(t *testing.T) {
+	value := strings.Repeat("x", 1000)
+	entrySize := estimateSize("key-0", value)
+	cache := newBoundedMemoryCache(0, 3*entrySize)
+	ctx := context.Background()
+
+	for i := 0; i < 5; i++ {
+		require.NoError(t, cache.Set(ctx, fmt.Sprintf("key-%d", i), value, time.Hour))
+	}
+
+	stats := statsOf(t, cache)
+	assert.Equal(t, int64(3), stats.Entries)
+	assert.LessOrEqual(t, stats.Bytes, 3*entrySize)
+	assert.Equal(t, uint64(2), stats.Evictions)
+
+	// The oldest entries were evicted
+	_, err := cache.Get(ctx, "key-0")
+	assert.ErrorIs(t, err, models.ErrCacheUnavailable)
+	_, err = cache.Get(ctx, "key-4")
+	assert.NoError(t, err)
+}
+
+func TestMemoryCache_Bounded_ValueTooLarge(t *testing.T) {
+	cache := newBoundedMemoryCache(0, 256)
+
+	err := cache.Set(context.Background(), "big", strings.Repeat("x", 1024), time.Hour)
+
+	assert.Error(t, err)
+	assert.Equal(t, 0, cache.Size())
+}
+
+func TestMemoryCache_Overwrite_UpdatesByteAccounting(t *testing.T) {
+	cache := newMemoryCache()
+	ctx := context.Background()
+
+	require.NoError(t, cache.Set(ctx, "key", strings.Repeat("x", 100), time.Hour))
+	require.NoError(t, cache.Set(ctx, "key", strings.Repeat("x", 10), time.Hour))
+
+	assert.Equal(t, estimateSize("key", strings.Repeat("x", 10)), statsOf(t, cache).Bytes)
+
+	require.NoError(t, cache.Delete(ctx, "key"))
+	assert.Equal(t, int64(0), statsOf(t, cache).Bytes)
+}
+
+func TestMemoryCache_RemoveExpired(t *testing.T) {
+	cache := newMemoryCache()
+	ctx := context.Background()
+
+	require.NoError(t, cache.Set(ctx, "short", "1", time.Minute))
+	require.NoError(t, cache.Set(ctx, "long", "2", time.Hour))
+
+	cache.removeExpired(time.Now().Add(2 * time.Minute))
+
+	stats := statsOf(t, cache)
+	assert.Equal(t, int64(1), stats.Entries)
+	assert.Equal(t, uint64(1), stats.Expirations)
+	assert.Equal(t, estimateSize("long", "2"), stats.Bytes)
+}
+
+func TestMemoryCache_HitMissCounters(t *testing.T) {
+	cache := newMemoryCache()
+	ctx := context.Background()
+
+	require.NoError(t, cache.Set(ctx, "key", "value", time.Hour))
+	_, _ = cache.Get(ctx, "key")
+	_, _ = cache.Get(ctx, "key")
+	_, _ = cache.Get(ctx, "missing")
+
+	stats := statsOf(t, cache)
+	assert.Equal(t, "memory", stats.Backend)
+	assert.Equal(t, uint64(2), stats.Hits)
+	assert.Equal(t, uint64(1), stats.Misses)
+}
+
+func TestMemoryCache_Inspect(t *testing.T) {
+	cache := newBoundedMemoryCache(2, 0)
+	ctx := context.Background()
+
+	require.NoError(t, cache.Set(ctx, "a", "1", time.Hour))
+	require.NoError(t, cache.Set(ctx, "b", "2", time.Hour))
+
+	info, err := cache.Inspect(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, "1", info.Value)
+	assert.Equal(t, estimateSize("a", "1"), info.Size)
+	assert.InDelta(t, time.Hour.Seconds(), info.TTL.Seconds(), 1)
+
+	// Inspecting doesn't count as a use, so "a" is still evicted first
+	require.NoError(t, cache.Set(ctx, "c", "3", time.Hour))
+	_, err = cache.Inspect(ctx, "a")
+	assert.ErrorIs(t, err, models.ErrCacheUnavailable)
+	assert.Equal(t, uint64(0), statsOf(t, cache).Hits)
+}
+
+func TestMemoryCache_DeleteMatching(t *testing.T) {
+	cache := newMemoryCache()
+	ctx := context.Background()
+
+	for _, key := range []string{"domain:cnn.com", "domain:cnn.co.uk", "domain:bbc.com", "job:cnn"} {
+		require.NoError(t, cache.Set(ctx, key, "value", time.Hour))
+	}
+
+	deleted, err := cache.DeleteMatching(ctx, "domain:cnn.*")
+	require.NoError(t, err)
+	assert.Equal(t, 2, deleted)
+	assert.Equal(t, 2, cache.Size())
+
+	_, err = cache.DeleteMatching(ctx, "domain:[")
+	assert.Error(t, err)
+}
+
+func TestEstimateSize(t *testing.T) {
+	assert.Equal(t, int64(entryOverhead+3+5), estimateSize("key", "value"))
+	assert.Equal(t, int64(entryOverhead+3+4), estimateSize("key", []byte("data")))
+
+	// Other values are measured by their JSON encoding
+	analysis := &models.DomainAnalysis{Domain: "example.com"}
+	data, err := json.Marshal(analysis)
+	require.NoError(t, err)
+	assert.Equal(t, int64(entryOverhead+3+len(data)), estimateSize("key", analysis))
+}
+
+// statsOf returns the cache statistics, failing the test on error
+func statsOf(t *testing.T, cache *MemoryCache) Stats {
+	t.Helper()
+	stats, err := cache.Stats(context.Background())
+	require.NoError(t, err)
+	return stats
+}
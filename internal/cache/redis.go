@@ -3,57 +3,165 @@ package cache
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"Perion_Assignment/internal/models"
+	"Perion_Assignment/internal/redisclient"
 	"github.com/redis/go-redis/v9"
 )
 
 // RedisCache implements Service using Redis
+// Keys are stored under an optional prefix, and payloads of at least compressThreshold
+// bytes are compressed when a compression algorithm is configured.
+//
+// While Redis is unreachable (e.g. during a Sentinel or Cluster failover) reads are served
+// as misses, and for failoverBackoff after a failure Redis is not contacted at all, so
+// requests don't each wait for a connection timeout.
 type RedisCache struct {
-	client *redis.Client
+	client            redis.UniversalClient
+	keyPrefix         string
+	compression       string
+	compressor        compressor
+	compressThreshold int
+	failoverBackoff   time.Duration
+	
+	// Unix nanoseconds until which Redis is skipped after a connection failure
+	unavailableUntil atomic.Int64
+	
+	// Hits and misses are counted per instance; Redis only reports server-wide counters
+	hits   atomic.Uint64
+	misses atomic.Uint64
+}
+
+// scanBatchSize is the number of keys requested per SCAN call when deleting by pattern
+const scanBatchSize = 500
+
+// entryKeyPattern matches the keys analyses are cached under, which are the entries Stats counts
+const entryKeyPattern = "domain:*"
+
+// defaultFailoverBackoff is how long Redis is skipped after a connection failure
+const defaultFailoverBackoff = time.Second
+
+// RedisOption configures optional RedisCache behaviour
+type RedisOption func(*RedisCache)
+
+// WithKeyPrefix stores all keys under a namespace prefix, e.g. "adstxt:"
+func WithKeyPrefix(prefix string) RedisOption {
+	return func(r *RedisCache) {
+		r.keyPrefix = prefix
+	}
+}
+
+// WithCompression compresses payloads of at least threshold bytes with the given
+// algorithm (CompressionGzip, CompressionZstd or CompressionNone)
+func WithCompression(algorithm string, threshold int) RedisOption {
+	return func(r *RedisCache) {
+		r.compression = algorithm
+		r.compressThreshold = threshold
+	}
+}
+
+// WithFailoverBackoff sets how long Redis is skipped after a connection failure
+// (0 retries Redis on every call)
+func WithFailoverBackoff(backoff time.Duration) RedisOption {
+	return func(r *RedisCache) {
+		r.failoverBackoff = backoff
+	}
 }
 
 // NewRedisCache creates a new Redis-based cache
-func NewRedisCache(redisURL string) (Service, error) {
-	return newRedisCache(redisURL)
+func NewRedisCache(redisConfig redisclient.Config, opts ...RedisOption) (Service, error) {
+	return newRedisCache(redisConfig, opts...)
 }
 
 // newRedisCache creates the concrete implementation
-func newRedisCache(redisURL string) (*RedisCache, error) {
-	opts, err := redis.ParseURL(redisURL)
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse redis URL: %w", err)
+func newRedisCache(redisConfig redisclient.Config, opts ...RedisOption) (*RedisCache, error) {
+	cache := &RedisCache{
+		failoverBackoff: defaultFailoverBackoff,
+	}
+	for _, opt := range opts {
+		opt(cache)
 	}
 	
-	client := redis.NewClient(opts)
-	
-	// Test connection
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-	
-	if err := client.Ping(ctx).Err(); err != nil {
-		return nil, fmt.Errorf("failed to connect to redis: %w", err)
+	var err error
+	cache.compressor, err = compressorByName(cache.compression)
+	if err != nil {
+		return nil, err
 	}
 	
-	return &RedisCache{
-		client: client,
-	}, nil
+	cache.client, err = redisclient.New(redisConfig)
+	if err != nil {
+		return nil, err
+	}
+	return cache, nil
 }
 
 // Get retrieves a cached value for the given key
+// A failed read is reported as a miss (models.ErrCacheUnavailable)
 func (r *RedisCache) Get(ctx context.Context, key string) (interface{}, error) {
-	data, err := r.client.Get(ctx, key).Result()
+	if err := r.checkAvailable(); err != nil {
+		r.misses.Add(1)
+		return nil, err
+	}
+	
+	data, err := r.client.Get(ctx, r.key(key)).Result()
 	if err != nil {
+		r.misses.Add(1)
 		if err == redis.Nil {
 			return nil, models.ErrCacheUnavailable
 		}
-		return nil, fmt.Errorf("redis get failed: %w", err)
+		return nil, r.failed("redis get failed", err)
 	}
+	r.hits.Add(1)
 	
 	// Return the raw JSON string, let the domain layer handle unmarshaling
-	return data, nil
+	value, err := decodePayload(data)
+	if err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// getWithTTL retrieves a cached value together with its remaining TTL
+func (r *RedisCache) getWithTTL(ctx context.Context, key string) (string, time.Duration, error) {
+	if err := r.checkAvailable(); err != nil {
+		r.misses.Add(1)
+		return "", 0, err
+	}
+	
+	var get *redis.StringCmd
+	var ttl *redis.DurationCmd
+	_, err := r.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		get = pipe.Get(ctx, r.key(key))
+		ttl = pipe.PTTL(ctx, r.key(key))
+		return nil
+	})
+	if err != nil && err != redis.Nil {
+		r.misses.Add(1)
+		return "", 0, r.failed("redis get failed", err)
+	}
+	
+	data, err := get.Result()
+	if err != nil {
+		r.misses.Add(1)
+		if err == redis.Nil {
+			return "", 0, models.ErrCacheUnavailable
+		}
+		return "", 0, r.failed("redis get failed", err)
+	}
+	r.hits.Add(1)
+	
+	value, err := decodePayload(data)
+	if err != nil {
+		return "", 0, err
+	}
+	return value, ttl.Val(), nil
 }
 
 // Set stores a value in Redis with the specified TTL
@@ -62,13 +170,29 @@ func (r *RedisCache) Set(ctx context.Context, key string, value interface{}, ttl
 		return fmt.Errorf("TTL must be positive, got: %v", ttl)
 	}
 	
-	data, err := json.Marshal(value)
-	if err != nil {
-		return fmt.Errorf("failed to marshal value: %w", err)
+	// Encoded values (see Typed) are stored as-is; anything else is stored as JSON
+	data, ok := value.([]byte)
+	if !ok {
+		var err error
+		data, err = json.Marshal(value)
+		if err != nil {
+			return fmt.Errorf("failed to marshal value: %w", err)
+		}
 	}
 	
-	if err := r.client.Set(ctx, key, data, ttl).Err(); err != nil {
-		return fmt.Errorf("redis set failed: %w", err)
+	if r.compressor != nil && len(data) >= r.compressThreshold {
+		compressed, err := compressPayload(r.compressor, data)
+		if err != nil {
+			return fmt.Errorf("failed to compress value: %w", err)
+		}
+		data = compressed
+	}
+	
+	if err := r.checkAvailable(); err != nil {
+		return err
+	}
+	if err := r.client.Set(ctx, r.key(key), data, ttl).Err(); err != nil {
+		return r.failed("redis set failed", err)
 	}
 	
 	return nil
@@ -76,12 +200,224 @@ func (r *RedisCache) Set(ctx context.Context, key string, value interface{}, ttl
 
 // Delete removes an entry from Redis
 func (r *RedisCache) Delete(ctx context.Context, key string) error {
-	if err := r.client.Del(ctx, key).Err(); err != nil {
-		return fmt.Errorf("redis delete failed: %w", err)
+	if err := r.checkAvailable(); err != nil {
+		return err
+	}
+	if err := r.client.Del(ctx, r.key(key)).Err(); err != nil {
+		return r.failed("redis delete failed", err)
 	}
 	return nil
 }
 
+// Inspect returns an entry with its size and remaining TTL without counting a hit
+func (r *RedisCache) Inspect(ctx context.Context, key string) (*EntryInfo, error) {
+	var get *redis.StringCmd
+	var ttl *redis.DurationCmd
+	_, err := r.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		get = pipe.Get(ctx, r.key(key))
+		ttl = pipe.PTTL(ctx, r.key(key))
+		return nil
+	})
+	if err != nil && err != redis.Nil {
+		return nil, fmt.Errorf("redis inspect failed: %w", err)
+	}
+	
+	data, err := get.Result()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, models.ErrCacheUnavailable
+		}
+		return nil, fmt.Errorf("redis inspect failed: %w", err)
+	}
+	
+	value, err := decodePayload(data)
+	if err != nil {
+		return nil, err
+	}
+	
+	// Size is what the entry occupies in Redis, after compression
+	return &EntryInfo{
+		Key:   key,
+		Value: value,
+		Size:  int64(len(data)),
+		TTL:   ttl.Val(),
+	}, nil
+}
+
+// DeleteMatching removes all keys matching the glob pattern
+func (r *RedisCache) DeleteMatching(ctx context.Context, pattern string) (int, error) {
+	keys, err := r.deleteMatching(ctx, pattern)
+	return len(keys), err
+}
+
+// deleteMatching scans for keys matching the pattern and unlinks them in batches
+// It returns the keys deleted so far, even when a later batch fails
+func (r *RedisCache) deleteMatching(ctx context.Context, pattern string) ([]string, error) {
+	var mutex sync.Mutex
+	var deleted []string
+	err := r.scan(ctx, EscapePattern(r.keyPrefix)+pattern, func(node redis.Cmdable, keys []string) error {
+		// Keys are unlinked one by one, as a cluster node rejects multi-key commands across slots
+		_, err := node.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+			for _, key := range keys {
+				pipe.Unlink(ctx, key)
+			}
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("redis delete failed: %w", err)
+		}
+		
+		mutex.Lock()
+		defer mutex.Unlock()
+		for _, key := range keys {
+			deleted = append(deleted, strings.TrimPrefix(key, r.keyPrefix))
+		}
+		return nil
+	})
+	return deleted, err
+}
+
+// scan calls fn with each batch of keys matching the pattern
+// In cluster mode every master is scanned, concurrently, so fn must be safe for concurrent use.
+func (r *RedisCache) scan(ctx context.Context, match string, fn func(node redis.Cmdable, keys []string) error) error {
+	if cluster, ok := r.client.(*redis.ClusterClient); ok {
+		return cluster.ForEachMaster(ctx, func(ctx context.Context, node *redis.Client) error {
+			return scanNode(ctx, node, match, fn)
+		})
+	}
+	return scanNode(ctx, r.client, match, fn)
+}
+
+// scanNode calls fn with each batch of keys on a single node matching the pattern
+func scanNode(ctx context.Context, node redis.Cmdable, match string, fn func(node redis.Cmdable, keys []string) error) error {
+	var cursor uint64
+	for {
+		keys, next, err := node.Scan(ctx, cursor, match, scanBatchSize).Result()
+		if err != nil {
+			return fmt.Errorf("redis scan failed: %w", err)
+		}
+		
+		if len(keys) > 0 {
+			if err := fn(node, keys); err != nil {
+				return err
+			}
+		}
+		
+		cursor = next
+		if cursor == 0 {
+			return nil
+		}
+	}
+}
+
+// Stats returns the number of keys, this instance's hit/miss counters and the
+// server's eviction and expiration counters
+// Only analysis keys in the namespace are counted, which requires a full SCAN.
+func (r *RedisCache) Stats(ctx context.Context) (Stats, error) {
+	entries, err := r.countKeys(ctx)
+	if err != nil {
+		return Stats{}, err
+	}
+	
+	stats := Stats{
+		Backend: "redis",
+		Entries: entries,
+		Hits:    r.hits.Load(),
+		Misses:  r.misses.Load(),
+	}
+	
+	// Server counters are best effort: some deployments restrict INFO
+	if info, err := r.client.Info(ctx, "stats").Result(); err == nil {
+		stats.Evictions = parseInfoCounter(info, "evicted_keys")
+		stats.Expirations = parseInfoCounter(info, "expired_keys")
+	}
+	
+	return stats, nil
+}
+
+// countKeys returns the number of analysis keys in the cache namespace
+// Other data (jobs, rate limits, locks) may share the database, so DBSIZE would overcount.
+func (r *RedisCache) countKeys(ctx context.Context) (int64, error) {
+	var entries atomic.Int64
+	err := r.scan(ctx, EscapePattern(r.keyPrefix)+entryKeyPattern, func(node redis.Cmdable, keys []string) error {
+		entries.Add(int64(len(keys)))
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return entries.Load(), nil
+}
+
+// checkAvailable returns an error while Redis is skipped after a connection failure
+func (r *RedisCache) checkAvailable() error {
+	if time.Now().UnixNano() < r.unavailableUntil.Load() {
+		return fmt.Errorf("%w: redis is recovering from a failure", models.ErrCacheUnavailable)
+	}
+	return nil
+}
+
+// failed wraps a Redis error as models.ErrCacheUnavailable and, when Redis is
+// unreachable or failing over, skips it for the failover backoff
+func (r *RedisCache) failed(operation string, err error) error {
+	if r.failoverBackoff > 0 && isFailover(err) {
+		r.unavailableUntil.Store(time.Now().Add(r.failoverBackoff).UnixNano())
+	}
+	return fmt.Errorf("%w: %s: %v", models.ErrCacheUnavailable, operation, err)
+}
+
+// failoverReplies are error replies Redis sends while a replica is promoted or a cluster reconfigures
+var failoverReplies = []string{"READONLY", "LOADING", "MASTERDOWN", "CLUSTERDOWN", "TRYAGAIN"}
+
+// isFailover reports whether err means Redis can't currently serve requests
+// Cancelled requests and other error replies say nothing about the server's health.
+func isFailover(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	
+	var reply redis.Error
+	if errors.As(err, &reply) {
+		for _, prefix := range failoverReplies {
+			if strings.HasPrefix(reply.Error(), prefix) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// key returns the Redis key for a cache key
+func (r *RedisCache) key(key string) string {
+	return r.keyPrefix + key
+}
+
+// decodePayload returns the stored value of a payload read from Redis
+func decodePayload(data string) (string, error) {
+	if len(data) == 0 || data[0] != compressedMarker {
+		return data, nil
+	}
+	
+	value, err := decompressPayload([]byte(data))
+	if err != nil {
+		return "", fmt.Errorf("failed to decompress cached value: %w", err)
+	}
+	return string(value), nil
+}
+
+// parseInfoCounter extracts a numeric field from the output of the INFO command
+func parseInfoCounter(info, field string) uint64 {
+	for _, line := range strings.Split(info, "\n") {
+		name, value, ok := strings.Cut(strings.TrimSpace(line), ":")
+		if ok && name == field {
+			counter, _ := strconv.ParseUint(value, 10, 64)
+			return counter
+		}
+	}
+	return 0
+}
+
 // Close closes the Redis connection
 func (r *RedisCache) Close() error {
 	return r.client.Close()
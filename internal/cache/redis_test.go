@@ -3,10 +3,13 @@ package cache
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"testing"
 	"time"
 
 	"Perion_Assignment/internal/models"
+	"Perion_Assignment/internal/redisclient"
 	"github.com/alicebob/miniredis/v2"
 	"github.com/redis/go-redis/v9"
 	"github.com/stretchr/testify/assert"
@@ -33,14 +36,14 @@ func TestRedisCache_NewRedisCache_Success(t *testing.T) {
 	defer mr.Close()
 
 	redisURL := "redis://" + mr.Addr()
-	cache, err := NewRedisCache(redisURL)
+	cache, err := NewRedisCache(redisclient.FromURL(redisURL))
 
 	require.NoError(t, err)
 	assert.NotNil(t, cache)
 }
 
 func TestRedisCache_NewRedisCache_InvalidURL(t *testing.T) {
-	cache, err := NewRedisCache("invalid://url::")
+	cache, err := NewRedisCache(redisclient.FromURL("invalid://url::"))
 
 	assert.Error(t, err)
 	assert.Nil(t, cache)
@@ -49,7 +52,7 @@ func TestRedisCache_NewRedisCache_InvalidURL(t *testing.T) {
 
 func TestRedisCache_NewRedisCache_ConnectionFailed(t *testing.T) {
 	// Use invalid address that won't connect
-	cache, err := NewRedisCache("redis://localhost:99999")
+	cache, err := NewRedisCache(redisclient.FromURL("redis://localhost:99999"))
 
 	assert.Error(t, err)
 	assert.Nil(t, cache)
@@ -457,13 +460,57 @@ func TestRedisCache_Get_WithError(t *testing.T) {
 	// Close the miniredis server to force error
 	mr.Close()
 
-	// Try to get - should get error (not cache unavailable)
+	// Try to get - should be served as a miss
 	_, err := cache.Get(ctx, "test-key")
-	assert.Error(t, err)
-	assert.NotErrorIs(t, err, models.ErrCacheUnavailable)
+	assert.ErrorIs(t, err, models.ErrCacheUnavailable)
 	assert.Contains(t, err.Error(), "redis get failed")
 }
 
+func TestRedisCache_FailoverBackoff(t *testing.T) {
+	mr, cache := setupMiniRedis(t)
+	cache.failoverBackoff = 100 * time.Millisecond
+	ctx := context.Background()
+
+	require.NoError(t, cache.Set(ctx, "key", "value", time.Hour))
+
+	mr.Close()
+	_, err := cache.Get(ctx, "key")
+	assert.ErrorIs(t, err, models.ErrCacheUnavailable)
+
+	// Redis is back, but isn't contacted until the backoff ends
+	require.NoError(t, mr.Restart())
+	_, err = cache.Get(ctx, "key")
+	assert.ErrorIs(t, err, models.ErrCacheUnavailable)
+	assert.Contains(t, err.Error(), "recovering")
+	assert.ErrorIs(t, cache.Set(ctx, "other", "value", time.Hour), models.ErrCacheUnavailable)
+
+	stats, err := cache.Stats(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2), stats.Misses)
+
+	assert.Eventually(t, func() bool {
+		value, err := cache.Get(ctx, "key")
+		return err == nil && value == `"value"`
+	}, time.Second, 20*time.Millisecond)
+}
+
+func TestIsFailover(t *testing.T) {
+	mr, cache := setupMiniRedis(t)
+	ctx := context.Background()
+
+	mr.SetError("READONLY You can't write against a read only replica.")
+	_, readOnly := cache.client.Get(ctx, "key").Result()
+	mr.SetError("WRONGTYPE Operation against a key holding the wrong kind of value")
+	_, wrongType := cache.client.Get(ctx, "key").Result()
+	mr.SetError("")
+
+	assert.True(t, isFailover(errors.New("dial tcp: connection refused")))
+	assert.True(t, isFailover(readOnly))
+	assert.False(t, isFailover(wrongType))
+	assert.False(t, isFailover(context.Canceled))
+	assert.False(t, isFailover(fmt.Errorf("get: %w", context.DeadlineExceeded)))
+}
+
 func TestRedisCache_Set_WithError(t *testing.T) {
 	mr := miniredis.RunT(t)
 	defer mr.Close()
@@ -486,3 +533,99 @@ func TestRedisCache_Set_WithError(t *testing.T) {
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "redis set failed")
 }
+
+func TestRedisCache_Inspect(t *testing.T) {
+	mr, cache := setupMiniRedis(t)
+	ctx := context.Background()
+
+	require.NoError(t, cache.Set(ctx, "key", "value", time.Hour))
+
+	info, err := cache.Inspect(ctx, "key")
+	require.NoError(t, err)
+	assert.Equal(t, `"value"`, info.Value)
+	assert.Equal(t, int64(len(`"value"`)), info.Size)
+	assert.InDelta(t, time.Hour.Seconds(), info.TTL.Seconds(), 1)
+
+	mr.Del("key")
+	_, err = cache.Inspect(ctx, "key")
+	assert.ErrorIs(t, err, models.ErrCacheUnavailable)
+}
+
+func TestRedisCache_DeleteMatching(t *testing.T) {
+	mr, cache := setupMiniRedis(t)
+	ctx := context.Background()
+
+	for _, key := range []string{"domain:cnn.com", "domain:cnn.co.uk", "domain:bbc.com", "job:cnn"} {
+		require.NoError(t, mr.Set(key, "value"))
+	}
+
+	deleted, err := cache.DeleteMatching(ctx, "domain:cnn.*")
+	require.NoError(t, err)
+	assert.Equal(t, 2, deleted)
+	assert.False(t, mr.Exists("domain:cnn.com"))
+	assert.False(t, mr.Exists("domain:cnn.co.uk"))
+	assert.True(t, mr.Exists("domain:bbc.com"))
+	assert.True(t, mr.Exists("job:cnn"))
+}
+
+func TestRedisCache_Stats(t *testing.T) {
+	mr, cache := setupMiniRedis(t)
+	ctx := context.Background()
+
+	require.NoError(t, cache.Set(ctx, "domain:cnn.com", "value", time.Hour))
+	_, _ = cache.Get(ctx, "domain:cnn.com")
+	_, _ = cache.Get(ctx, "domain:missing.com")
+
+	// Other data in the same database is not counted as cache entries
+	require.NoError(t, mr.Set("job:123", "{}"))
+	require.NoError(t, mr.Set("ratelimit:ip:127.0.0.1", "1"))
+
+	stats, err := cache.Stats(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "redis", stats.Backend)
+	assert.Equal(t, int64(1), stats.Entries)
+	assert.Equal(t, uint64(1), stats.Hits)
+	assert.Equal(t, uint64(1), stats.Misses)
+}
+
+func TestParseInfoCounter(t *testing.T) {
+	info := "# Stats\r\ntotal_connections_received:10\r\nexpired_keys:42\r\nevicted_keys:7\r\n"
+
+	assert.Equal(t, uint64(7), parseInfoCounter(info, "evicted_keys"))
+	assert.Equal(t, uint64(42), parseInfoCounter(info, "expired_keys"))
+	assert.Equal(t, uint64(0), parseInfoCounter(info, "keyspace_hits"))
+}
+
+func TestRedisCache_KeyPrefix(t *testing.T) {
+	mr := miniredis.RunT(t)
+	cache, err := newRedisCache(redisclient.FromURL("redis://"+mr.Addr()), WithKeyPrefix("adstxt:"))
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	require.NoError(t, cache.Set(ctx, "domain:cnn.com", "value", time.Hour))
+	assert.True(t, mr.Exists("adstxt:domain:cnn.com"))
+	assert.False(t, mr.Exists("domain:cnn.com"))
+
+	value, err := cache.Get(ctx, "domain:cnn.com")
+	require.NoError(t, err)
+	assert.Equal(t, `"value"`, value)
+
+	info, err := cache.Inspect(ctx, "domain:cnn.com")
+	require.NoError(t, err)
+	assert.Equal(t, "domain:cnn.com", info.Key)
+
+	// Keys outside the namespace are neither counted nor deleted
+	require.NoError(t, mr.Set("domain:bbc.com", "other"))
+	stats, err := cache.Stats(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), stats.Entries)
+
+	deleted, err := cache.DeleteMatching(ctx, "domain:*")
+	require.NoError(t, err)
+	assert.Equal(t, 1, deleted)
+	assert.True(t, mr.Exists("domain:bbc.com"))
+
+	require.NoError(t, cache.Set(ctx, "domain:cnn.com", "value", time.Hour))
+	require.NoError(t, cache.Delete(ctx, "domain:cnn.com"))
+	assert.False(t, mr.Exists("adstxt:domain:cnn.com"))
+}
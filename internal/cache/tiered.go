@@ -0,0 +1,190 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"Perion_Assignment/internal/redisclient"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// invalidationChannel is the Redis pub/sub channel used to drop L1 entries on other replicas
+const invalidationChannel = "cache:invalidate"
+
+// TieredCache implements Service with an in-process L1 in front of Redis
+//
+// Reads are served from L1 when possible and otherwise fall through to Redis, populating L1.
+// Writes and deletes go to both tiers and are announced on a pub/sub channel so other
+// replicas drop their L1 copy. L1 entries never outlive l1TTL, which bounds staleness
+// if an invalidation message is lost.
+type TieredCache struct {
+	l1         *MemoryCache
+	l2         *RedisCache
+	l1TTL      time.Duration
+	instanceID string
+	channel    string
+	pubsub     *redis.PubSub
+	done       chan struct{}
+}
+
+// NewTieredCache creates a two-tier cache backed by Redis
+func NewTieredCache(redisConfig redisclient.Config, l1MaxEntries int, l1MaxBytes int64, l1TTL time.Duration, opts ...RedisOption) (Service, error) {
+	l2, err := newRedisCache(redisConfig, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	tiered, err := newTieredCache(l2, newBoundedMemoryCache(l1MaxEntries, l1MaxBytes), l1TTL)
+	if err != nil {
+		_ = l2.Close()
+		return nil, err
+	}
+	return tiered, nil
+}
+
+// newTieredCache creates the concrete implementation and subscribes to invalidations
+func newTieredCache(l2 *RedisCache, l1 *MemoryCache, l1TTL time.Duration) (*TieredCache, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// Replicas sharing a key prefix share invalidations
+	channel := l2.keyPrefix + invalidationChannel
+	pubsub := l2.client.Subscribe(ctx, channel)
+
+	// Wait for the subscription to be confirmed so no invalidation is missed after startup
+	if _, err := pubsub.Receive(ctx); err != nil {
+		_ = pubsub.Close()
+		return nil, fmt.Errorf("failed to subscribe to cache invalidations: %w", err)
+	}
+
+	t := &TieredCache{
+		l1:         l1,
+		l2:         l2,
+		l1TTL:      l1TTL,
+		instanceID: uuid.New().String(),
+		channel:    channel,
+		pubsub:     pubsub,
+		done:       make(chan struct{}),
+	}
+
+	go t.listenForInvalidations()
+
+	return t, nil
+}
+
+// Get retrieves a cached value, consulting L1 before Redis
+func (t *TieredCache) Get(ctx context.Context, key string) (interface{}, error) {
+	if value, err := t.l1.Get(ctx, key); err == nil {
+		return value, nil
+	}
+
+	data, ttl, err := t.l2.getWithTTL(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	// Populate L1, but never beyond the remaining Redis TTL
+	if l1TTL := t.localTTL(ttl); l1TTL > 0 {
+		_ = t.l1.Set(ctx, key, data, l1TTL)
+	}
+
+	return data, nil
+}
+
+// Set stores a value in both tiers and invalidates the key on other replicas
+func (t *TieredCache) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	if err := t.l2.Set(ctx, key, value, ttl); err != nil {
+		// Don't keep a local copy Redis doesn't have
+		_ = t.l1.Delete(ctx, key)
+		return err
+	}
+
+	_ = t.l1.Set(ctx, key, value, t.localTTL(ttl))
+	t.publishInvalidation(ctx, key)
+	return nil
+}
+
+// Delete removes a value from both tiers and invalidates the key on other replicas
+func (t *TieredCache) Delete(ctx context.Context, key string) error {
+	_ = t.l1.Delete(ctx, key)
+	if err := t.l2.Delete(ctx, key); err != nil {
+		return err
+	}
+
+	t.publishInvalidation(ctx, key)
+	return nil
+}
+
+// Inspect returns the Redis entry, which is authoritative for size and remaining TTL
+func (t *TieredCache) Inspect(ctx context.Context, key string) (*EntryInfo, error) {
+	return t.l2.Inspect(ctx, key)
+}
+
+// DeleteMatching removes matching keys from both tiers and invalidates them on other replicas
+func (t *TieredCache) DeleteMatching(ctx context.Context, pattern string) (int, error) {
+	if _, err := t.l1.DeleteMatching(ctx, pattern); err != nil {
+		return 0, err
+	}
+
+	keys, err := t.l2.deleteMatching(ctx, pattern)
+	for _, key := range keys {
+		t.publishInvalidation(ctx, key)
+	}
+	return len(keys), err
+}
+
+// Stats returns the Redis statistics with L1 statistics nested
+// Hits include those served by L1; misses are lookups neither tier could serve.
+func (t *TieredCache) Stats(ctx context.Context) (Stats, error) {
+	stats, err := t.l2.Stats(ctx)
+	if err != nil {
+		return Stats{}, err
+	}
+
+	l1Stats, _ := t.l1.Stats(ctx)
+	stats.Backend = "tiered"
+	stats.Hits += l1Stats.Hits
+	stats.L1 = &l1Stats
+	return stats, nil
+}
+
+// Close stops listening for invalidations and closes the Redis connection
+func (t *TieredCache) Close() error {
+	err := t.pubsub.Close()
+	<-t.done
+	if closeErr := t.l2.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// localTTL caps a TTL at the L1 TTL
+func (t *TieredCache) localTTL(ttl time.Duration) time.Duration {
+	if t.l1TTL > 0 && (ttl <= 0 || ttl > t.l1TTL) {
+		return t.l1TTL
+	}
+	return ttl
+}
+
+// publishInvalidation announces a changed key to other replicas
+// Failures are ignored: the L1 TTL bounds how long other replicas serve the old value
+func (t *TieredCache) publishInvalidation(ctx context.Context, key string) {
+	_ = t.l2.client.Publish(ctx, t.channel, t.instanceID+" "+key).Err()
+}
+
+// listenForInvalidations drops L1 entries changed by other replicas until the subscription closes
+func (t *TieredCache) listenForInvalidations() {
+	defer close(t.done)
+
+	for message := range t.pubsub.Channel() {
+		origin, key, ok := strings.Cut(message.Payload, " ")
+		if !ok || origin == t.instanceID {
+			continue
+		}
+		_ = t.l1.Delete(context.Background(), key)
+	}
+}
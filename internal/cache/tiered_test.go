@@ -0,0 +1,220 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"Perion_Assignment/internal/models"
+	"Perion_Assignment/internal/redisclient"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupTieredCache creates a tiered cache on top of the given mini redis server
+func setupTieredCache(t *testing.T, mr *miniredis.Miniredis, l1TTL time.Duration) *TieredCache {
+	l2, err := newRedisCache(redisclient.FromURL("redis://" + mr.Addr()))
+	require.NoError(t, err)
+
+	tiered, err := newTieredCache(l2, newMemoryCache(), l1TTL)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = tiered.Close() })
+
+	return tiered
+}
+
+func TestNewTieredCache_InvalidURL(t *testing.T) {
+	cache, err := NewTieredCache(redisclient.FromURL("invalid://url::"), 100, 0, time.Minute)
+
+	assert.Error(t, err)
+	assert.Nil(t, cache)
+}
+
+func TestTieredCache_SetAndGet(t *testing.T) {
+	mr := miniredis.RunT(t)
+	tiered := setupTieredCache(t, mr, time.Minute)
+	ctx := context.Background()
+
+	require.NoError(t, tiered.Set(ctx, "domain:a.com", map[string]int{"count": 1}, time.Hour))
+
+	// Written to Redis as JSON
+	stored, err := mr.Get("domain:a.com")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"count":1}`, stored)
+
+	// Served from L1 without a Redis round-trip
+	mr.Del("domain:a.com")
+	value, err := tiered.Get(ctx, "domain:a.com")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"count": 1}, value)
+}
+
+func TestTieredCache_Get_PopulatesL1FromRedis(t *testing.T) {
+	mr := miniredis.RunT(t)
+	tiered := setupTieredCache(t, mr, time.Minute)
+	ctx := context.Background()
+
+	require.NoError(t, mr.Set("domain:a.com", `{"count":2}`))
+	mr.SetTTL("domain:a.com", time.Hour)
+
+	value, err := tiered.Get(ctx, "domain:a.com")
+	require.NoError(t, err)
+	assert.Equal(t, `{"count":2}`, value)
+
+	// The second read is answered by L1
+	mr.Del("domain:a.com")
+	value, err = tiered.Get(ctx, "domain:a.com")
+	require.NoError(t, err)
+	assert.Equal(t, `{"count":2}`, value)
+}
+
+func TestTieredCache_Get_Miss(t *testing.T) {
+	mr := miniredis.RunT(t)
+	tiered := setupTieredCache(t, mr, time.Minute)
+
+	_, err := tiered.Get(context.Background(), "missing")
+	assert.ErrorIs(t, err, models.ErrCacheUnavailable)
+}
+
+func TestTieredCache_Delete(t *testing.T) {
+	mr := miniredis.RunT(t)
+	tiered := setupTieredCache(t, mr, time.Minute)
+	ctx := context.Background()
+
+	require.NoError(t, tiered.Set(ctx, "domain:a.com", "value", time.Hour))
+	require.NoError(t, tiered.Delete(ctx, "domain:a.com"))
+
+	_, err := tiered.Get(ctx, "domain:a.com")
+	assert.ErrorIs(t, err, models.ErrCacheUnavailable)
+	assert.False(t, mr.Exists("domain:a.com"))
+}
+
+func TestTieredCache_InvalidatesOtherReplicas(t *testing.T) {
+	mr := miniredis.RunT(t)
+	replicaA := setupTieredCache(t, mr, time.Hour)
+	replicaB := setupTieredCache(t, mr, time.Hour)
+	ctx := context.Background()
+
+	require.NoError(t, replicaA.Set(ctx, "domain:a.com", "v1", time.Hour))
+
+	// Replica B caches v1 in its L1
+	value, err := replicaB.Get(ctx, "domain:a.com")
+	require.NoError(t, err)
+	assert.Equal(t, `"v1"`, value)
+
+	// A new value written by replica A evicts B's L1 copy
+	require.NoError(t, replicaA.Set(ctx, "domain:a.com", "v2", time.Hour))
+	assert.Eventually(t, func() bool {
+		value, err := replicaB.Get(ctx, "domain:a.com")
+		return err == nil && value == `"v2"`
+	}, 2*time.Second, 10*time.Millisecond)
+
+	// Deletes are propagated as well
+	require.NoError(t, replicaA.Delete(ctx, "domain:a.com"))
+	assert.Eventually(t, func() bool {
+		_, err := replicaB.Get(ctx, "domain:a.com")
+		return err != nil
+	}, 2*time.Second, 10*time.Millisecond)
+}
+
+func TestTieredCache_LocalTTL(t *testing.T) {
+	tiered := &TieredCache{l1TTL: time.Minute}
+
+	assert.Equal(t, 30*time.Second, tiered.localTTL(30*time.Second))
+	assert.Equal(t, time.Minute, tiered.localTTL(time.Hour))
+	assert.Equal(t, time.Minute, tiered.localTTL(-1))
+}
+
+func TestRedisCache_GetWithTTL(t *testing.T) {
+	mr, cache := setupMiniRedis(t)
+	ctx := context.Background()
+
+	require.NoError(t, cache.Set(ctx, "key", "value", time.Hour))
+
+	data, ttl, err := cache.getWithTTL(ctx, "key")
+	require.NoError(t, err)
+	assert.Equal(t, `"value"`, data)
+	assert.InDelta(t, time.Hour.Seconds(), ttl.Seconds(), 1)
+
+	mr.Del("key")
+	_, _, err = cache.getWithTTL(ctx, "key")
+	assert.ErrorIs(t, err, models.ErrCacheUnavailable)
+}
+
+func TestTieredCache_DeleteMatching_InvalidatesOtherReplicas(t *testing.T) {
+	mr := miniredis.RunT(t)
+	first := setupTieredCache(t, mr, time.Minute)
+	second := setupTieredCache(t, mr, time.Minute)
+	ctx := context.Background()
+
+	require.NoError(t, first.Set(ctx, "domain:cnn.com", "v1", time.Hour))
+	require.NoError(t, first.Set(ctx, "domain:bbc.com", "v1", time.Hour))
+	_, err := second.Get(ctx, "domain:cnn.com")
+	require.NoError(t, err)
+
+	deleted, err := first.DeleteMatching(ctx, "domain:cnn*")
+	require.NoError(t, err)
+	assert.Equal(t, 1, deleted)
+
+	_, err = first.Get(ctx, "domain:cnn.com")
+	assert.ErrorIs(t, err, models.ErrCacheUnavailable)
+	assert.Eventually(t, func() bool {
+		_, err := second.Get(ctx, "domain:cnn.com")
+		return err != nil
+	}, time.Second, 10*time.Millisecond)
+
+	_, err = first.Get(ctx, "domain:bbc.com")
+	assert.NoError(t, err)
+}
+
+func TestTieredCache_Stats(t *testing.T) {
+	mr := miniredis.RunT(t)
+	tiered := setupTieredCache(t, mr, time.Minute)
+	ctx := context.Background()
+
+	require.NoError(t, tiered.Set(ctx, "domain:cnn.com", "value", time.Hour))
+	_, _ = tiered.Get(ctx, "domain:cnn.com")
+	_, _ = tiered.Get(ctx, "domain:missing.com")
+
+	stats, err := tiered.Stats(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "tiered", stats.Backend)
+	assert.Equal(t, int64(1), stats.Entries)
+	assert.Equal(t, uint64(1), stats.Hits)
+	assert.Equal(t, uint64(1), stats.Misses)
+	require.NotNil(t, stats.L1)
+	assert.Equal(t, int64(1), stats.L1.Entries)
+}
+
+func TestTieredCache_KeyPrefix_IsolatesInvalidations(t *testing.T) {
+	mr := miniredis.RunT(t)
+	ctx := context.Background()
+
+	newPrefixed := func(prefix string) *TieredCache {
+		l2, err := newRedisCache(redisclient.FromURL("redis://"+mr.Addr()), WithKeyPrefix(prefix))
+		require.NoError(t, err)
+		tiered, err := newTieredCache(l2, newMemoryCache(), time.Minute)
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = tiered.Close() })
+		return tiered
+	}
+	blue := newPrefixed("blue:")
+	green := newPrefixed("green:")
+	blueReplica := newPrefixed("blue:")
+
+	require.NoError(t, green.Set(ctx, "key", "green", time.Hour))
+	require.NoError(t, blueReplica.Set(ctx, "key", "blue", time.Hour))
+	require.NoError(t, blue.Set(ctx, "key", "blue-v2", time.Hour))
+
+	// The replica in the same namespace drops its L1 copy; the other namespace keeps its own
+	assert.Eventually(t, func() bool {
+		value, err := blueReplica.Get(ctx, "key")
+		return err == nil && value == `"blue-v2"`
+	}, time.Second, 10*time.Millisecond)
+
+	value, err := green.Get(ctx, "key")
+	require.NoError(t, err)
+	assert.Equal(t, "green", value)
+}
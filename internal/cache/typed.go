@@ -0,0 +1,90 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Typed is a type-safe cache of T values on top of a cache backend
+//
+// Values are encoded with the codec before they reach the backend, so every backend stores
+// the same bytes and every Get decodes a new T: callers can modify what they get back
+// without affecting the cache or other callers.
+type Typed[T any] struct {
+	backend Service
+	codec   Codec
+}
+
+// NewTyped creates a typed cache storing values encoded with codec in backend
+func NewTyped[T any](backend Service, codec Codec) *Typed[T] {
+	return &Typed[T]{
+		backend: backend,
+		codec:   codec,
+	}
+}
+
+// Get retrieves and decodes the value for the given key
+func (t *Typed[T]) Get(ctx context.Context, key string) (T, error) {
+	var value T
+
+	raw, err := t.backend.Get(ctx, key)
+	if err != nil {
+		return value, err
+	}
+
+	err = t.decode(raw, &value)
+	return value, err
+}
+
+// Set encodes and stores a value with the specified TTL
+func (t *Typed[T]) Set(ctx context.Context, key string, value T, ttl time.Duration) error {
+	data, err := t.codec.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to encode cache value with %s: %w", t.codec.Name(), err)
+	}
+	return t.backend.Set(ctx, key, data, ttl)
+}
+
+// Delete removes the value for the given key
+func (t *Typed[T]) Delete(ctx context.Context, key string) error {
+	return t.backend.Delete(ctx, key)
+}
+
+// Inspect retrieves and decodes a value together with its entry metadata
+// The backend must implement Admin.
+func (t *Typed[T]) Inspect(ctx context.Context, key string) (T, *EntryInfo, error) {
+	var value T
+
+	admin, ok := t.backend.(Admin)
+	if !ok {
+		return value, nil, fmt.Errorf("cache backend %T does not support inspection", t.backend)
+	}
+
+	info, err := admin.Inspect(ctx, key)
+	if err != nil {
+		return value, nil, err
+	}
+
+	err = t.decode(info.Value, &value)
+	return value, info, err
+}
+
+// decode converts the raw form returned by a backend into a value
+// Memory caches return the stored bytes; Redis returns them as a string.
+func (t *Typed[T]) decode(raw interface{}, value *T) error {
+	var data []byte
+	switch v := raw.(type) {
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("unexpected type in cache: %T", raw)
+	}
+
+	if err := t.codec.Unmarshal(data, value); err != nil {
+		return fmt.Errorf("failed to decode cache value with %s: %w", t.codec.Name(), err)
+	}
+	return nil
+}
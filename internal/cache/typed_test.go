@@ -0,0 +1,164 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"Perion_Assignment/internal/models"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type typedTestValue struct {
+	Name      string         `json:"name"`
+	Count     int            `json:"count"`
+	Tags      []string       `json:"tags"`
+	Labels    map[string]int `json:"labels"`
+	UpdatedAt time.Time      `json:"updated_at"`
+}
+
+func newTypedTestValue() typedTestValue {
+	return typedTestValue{
+		Name:      "example.com",
+		Count:     3,
+		Tags:      []string{"direct", "reseller"},
+		Labels:    map[string]int{"google.com": 2},
+		UpdatedAt: time.Now().UTC().Truncate(time.Millisecond),
+	}
+}
+
+// typedTestBackends returns every backend a typed cache must behave identically on
+func typedTestBackends(t *testing.T) map[string]Service {
+	mr := miniredis.RunT(t)
+	_, redisCache := setupMiniRedis(t)
+	fileCache, _ := setupFileCache(t)
+
+	return map[string]Service{
+		"memory": newMemoryCache(),
+		"redis":  redisCache,
+		"tiered": setupTieredCache(t, mr, time.Minute),
+		"file":   fileCache,
+	}
+}
+
+func TestTyped_RoundTrip(t *testing.T) {
+	for backendName, backend := range typedTestBackends(t) {
+		for _, codec := range []Codec{JSONCodec, GobCodec, MsgpackCodec} {
+			t.Run(backendName+"/"+codec.Name(), func(t *testing.T) {
+				typed := NewTyped[typedTestValue](backend, codec)
+				ctx := context.Background()
+				value := newTypedTestValue()
+
+				require.NoError(t, typed.Set(ctx, "key-"+codec.Name(), value, time.Hour))
+
+				cached, err := typed.Get(ctx, "key-"+codec.Name())
+				require.NoError(t, err)
+				assert.Equal(t, value.Name, cached.Name)
+				assert.Equal(t, value.Tags, cached.Tags)
+				assert.Equal(t, value.Labels, cached.Labels)
+				assert.True(t, value.UpdatedAt.Equal(cached.UpdatedAt))
+			})
+		}
+	}
+}
+
+func TestTyped_Get_ReturnsCopies(t *testing.T) {
+	for backendName, backend := range typedTestBackends(t) {
+		t.Run(backendName, func(t *testing.T) {
+			typed := NewTyped[*typedTestValue](backend, JSONCodec)
+			ctx := context.Background()
+			value := newTypedTestValue()
+
+			require.NoError(t, typed.Set(ctx, "key", &value, time.Hour))
+
+			// Changing the stored or the returned value doesn't affect the cache
+			value.Tags[0] = "changed"
+			first, err := typed.Get(ctx, "key")
+			require.NoError(t, err)
+			first.Count = 100
+			first.Labels["google.com"] = 100
+
+			second, err := typed.Get(ctx, "key")
+			require.NoError(t, err)
+			assert.Equal(t, 3, second.Count)
+			assert.Equal(t, "direct", second.Tags[0])
+			assert.Equal(t, 2, second.Labels["google.com"])
+		})
+	}
+}
+
+func TestTyped_Get_Miss(t *testing.T) {
+	typed := NewTyped[typedTestValue](newMemoryCache(), JSONCodec)
+
+	value, err := typed.Get(context.Background(), "missing")
+
+	assert.ErrorIs(t, err, models.ErrCacheUnavailable)
+	assert.Zero(t, value)
+}
+
+func TestTyped_Get_UnexpectedType(t *testing.T) {
+	backend := newMemoryCache()
+	ctx := context.Background()
+	require.NoError(t, backend.Set(ctx, "key", 42, time.Hour))
+
+	_, err := NewTyped[typedTestValue](backend, JSONCodec).Get(ctx, "key")
+
+	assert.ErrorContains(t, err, "unexpected type in cache")
+}
+
+func TestTyped_Get_CodecMismatch(t *testing.T) {
+	backend := newMemoryCache()
+	ctx := context.Background()
+	require.NoError(t, NewTyped[typedTestValue](backend, GobCodec).Set(ctx, "key", newTypedTestValue(), time.Hour))
+
+	_, err := NewTyped[typedTestValue](backend, JSONCodec).Get(ctx, "key")
+
+	assert.ErrorContains(t, err, "failed to decode cache value with json")
+}
+
+func TestTyped_Delete(t *testing.T) {
+	typed := NewTyped[typedTestValue](newMemoryCache(), JSONCodec)
+	ctx := context.Background()
+
+	require.NoError(t, typed.Set(ctx, "key", newTypedTestValue(), time.Hour))
+	require.NoError(t, typed.Delete(ctx, "key"))
+
+	_, err := typed.Get(ctx, "key")
+	assert.ErrorIs(t, err, models.ErrCacheUnavailable)
+}
+
+func TestTyped_Inspect(t *testing.T) {
+	typed := NewTyped[typedTestValue](newMemoryCache(), MsgpackCodec)
+	ctx := context.Background()
+
+	require.NoError(t, typed.Set(ctx, "key", newTypedTestValue(), time.Hour))
+
+	value, info, err := typed.Inspect(ctx, "key")
+	require.NoError(t, err)
+	assert.Equal(t, "example.com", value.Name)
+	assert.InDelta(t, time.Hour.Seconds(), info.TTL.Seconds(), 1)
+}
+
+func TestRedisCache_Set_StoresBytesVerbatim(t *testing.T) {
+	mr, cache := setupMiniRedis(t)
+
+	require.NoError(t, cache.Set(context.Background(), "key", []byte(`{"a":1}`), time.Hour))
+
+	stored, err := mr.Get("key")
+	require.NoError(t, err)
+	assert.Equal(t, `{"a":1}`, stored)
+}
+
+func TestCodecByName(t *testing.T) {
+	for _, name := range []string{"json", "gob", "msgpack"} {
+		codec, err := CodecByName(name)
+		require.NoError(t, err)
+		assert.Equal(t, name, codec.Name())
+	}
+
+	_, err := CodecByName("xml")
+	assert.Error(t, err)
+}
@@ -0,0 +1,183 @@
+package concurrency
+
+import (
+	"context"
+	"math"
+	"slices"
+	"sync"
+	"time"
+
+	"Perion_Assignment/internal/models"
+)
+
+const (
+	// backoffRatio is the factor the limit is multiplied by on a sign of overload
+	backoffRatio = 0.9
+
+	// latencySmoothing is the weight of a new sample in the average latency
+	latencySmoothing = 0.2
+
+	// defaultLatencyTarget is used when no latency target is configured
+	defaultLatencyTarget = 2 * time.Second
+)
+
+// AIMDConfig holds the settings of an AIMD limiter
+type AIMDConfig struct {
+	InitialLimit  int
+	MinLimit      int
+	MaxLimit      int
+	MaxQueue      int           // Operations that may wait for a slot before new ones are shed
+	LatencyTarget time.Duration // Latency above which an operation is a sign of overload
+}
+
+// AIMDLimiter implements Service with a limit adjusted by additive increase, multiplicative decrease
+//
+// Each operation completing within the latency target while the limiter is at least half
+// busy grows the limit by 1/limit, about one slot per round of operations. An operation
+// slower than the target, or timing out, shrinks it by backoffRatio, at most once per
+// average latency so one burst of slow operations counts once. Operations over the limit
+// wait in arrival order; when MaxQueue are waiting, new ones are refused.
+type AIMDLimiter struct {
+	config AIMDConfig
+
+	mutex       sync.Mutex
+	limit       float64
+	inFlight    int
+	waiters     []chan struct{}
+	latency     time.Duration // Moving average of operation latency
+	lastBackoff time.Time
+}
+
+// NewAIMDLimiter creates an AIMD limiter
+func NewAIMDLimiter(config AIMDConfig) Service {
+	return newAIMDLimiter(config)
+}
+
+// newAIMDLimiter creates the concrete implementation
+func newAIMDLimiter(config AIMDConfig) *AIMDLimiter {
+	config.MinLimit = max(config.MinLimit, 1)
+	config.MaxLimit = max(config.MaxLimit, config.MinLimit)
+	config.InitialLimit = min(max(config.InitialLimit, config.MinLimit), config.MaxLimit)
+	config.MaxQueue = max(config.MaxQueue, 0)
+	if config.LatencyTarget <= 0 {
+		config.LatencyTarget = defaultLatencyTarget
+	}
+
+	return &AIMDLimiter{
+		config:  config,
+		limit:   float64(config.InitialLimit),
+		latency: config.LatencyTarget,
+	}
+}
+
+// Acquire waits for a slot, in arrival order
+func (l *AIMDLimiter) Acquire(ctx context.Context) (Release, error) {
+	l.mutex.Lock()
+	if l.inFlight < l.slots() && len(l.waiters) == 0 {
+		l.inFlight++
+		l.mutex.Unlock()
+		return l.release(time.Now()), nil
+	}
+	if len(l.waiters) >= l.config.MaxQueue {
+		l.mutex.Unlock()
+		return nil, models.ErrOverloaded
+	}
+
+	ready := make(chan struct{}, 1)
+	l.waiters = append(l.waiters, ready)
+	l.mutex.Unlock()
+
+	select {
+	case <-ready:
+		return l.release(time.Now()), nil
+	case <-ctx.Done():
+		l.mutex.Lock()
+		defer l.mutex.Unlock()
+
+		if i := slices.Index(l.waiters, ready); i >= 0 {
+			l.waiters = slices.Delete(l.waiters, i, i+1)
+		} else {
+			// The slot was granted as the context ended; pass it on
+			l.inFlight--
+			l.admit()
+		}
+		return nil, ctx.Err()
+	}
+}
+
+// Saturated reports whether the wait queue is full
+func (l *AIMDLimiter) Saturated() bool {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	return len(l.waiters) >= l.config.MaxQueue
+}
+
+// RetryAfter estimates the time for the operations waiting and running to complete
+func (l *AIMDLimiter) RetryAfter() time.Duration {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	rounds := float64(len(l.waiters))/l.limit + 1
+	return time.Duration(rounds * float64(l.latency))
+}
+
+// Limit returns the current concurrency limit
+func (l *AIMDLimiter) Limit() int {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	return l.slots()
+}
+
+// release returns the function giving back a slot acquired at start
+func (l *AIMDLimiter) release(start time.Time) Release {
+	var once sync.Once
+	return func(outcome Outcome) {
+		once.Do(func() {
+			l.mutex.Lock()
+			defer l.mutex.Unlock()
+
+			busy := l.inFlight
+			l.inFlight--
+			l.adjust(outcome, time.Since(start), busy)
+			l.admit()
+		})
+	}
+}
+
+// adjust updates the limit with the outcome of an operation that ran with busy slots taken
+func (l *AIMDLimiter) adjust(outcome Outcome, latency time.Duration, busy int) {
+	if outcome == OutcomeIgnored {
+		return
+	}
+	l.latency += time.Duration(latencySmoothing * float64(latency-l.latency))
+
+	if outcome == OutcomeDropped || latency > l.config.LatencyTarget {
+		if time.Since(l.lastBackoff) < l.latency {
+			return
+		}
+		l.lastBackoff = time.Now()
+		l.limit = math.Max(l.limit*backoffRatio, float64(l.config.MinLimit))
+		return
+	}
+
+	// An idle limiter says nothing about how much more it could take
+	if float64(busy) >= l.limit/2 {
+		l.limit = math.Min(l.limit+1/l.limit, float64(l.config.MaxLimit))
+	}
+}
+
+// admit hands free slots to waiting operations
+func (l *AIMDLimiter) admit() {
+	for len(l.waiters) > 0 && l.inFlight < l.slots() {
+		l.inFlight++
+		l.waiters[0] <- struct{}{}
+		l.waiters = l.waiters[1:]
+	}
+}
+
+// slots returns the whole number of operations the limit allows
+func (l *AIMDLimiter) slots() int {
+	return int(l.limit)
+}
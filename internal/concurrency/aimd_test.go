@@ -0,0 +1,146 @@
+package concurrency
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"Perion_Assignment/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// acquireN takes n slots, failing the test if any has to wait
+func acquireN(t *testing.T, limiter *AIMDLimiter, n int) []Release {
+	t.Helper()
+	releases := make([]Release, 0, n)
+	for i := 0; i < n; i++ {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		release, err := limiter.Acquire(ctx)
+		cancel()
+		require.NoError(t, err)
+		releases = append(releases, release)
+	}
+	return releases
+}
+
+func TestAIMDLimiter_ShedsWhenQueueFull(t *testing.T) {
+	limiter := newAIMDLimiter(AIMDConfig{InitialLimit: 2, MinLimit: 1, MaxLimit: 10, MaxQueue: 1, LatencyTarget: time.Second})
+	releases := acquireN(t, limiter, 2)
+
+	// The third operation waits, the fourth is shed
+	acquired := make(chan Release, 1)
+	go func() {
+		release, err := limiter.Acquire(context.Background())
+		assert.NoError(t, err)
+		acquired <- release
+	}()
+	require.Eventually(t, limiter.Saturated, time.Second, time.Millisecond)
+
+	_, err := limiter.Acquire(context.Background())
+	assert.ErrorIs(t, err, models.ErrOverloaded)
+	assert.Greater(t, limiter.RetryAfter(), time.Duration(0))
+
+	// A released slot goes to the waiting operation
+	releases[0](OutcomeSuccess)
+	release := <-acquired
+	assert.False(t, limiter.Saturated())
+
+	release(OutcomeSuccess)
+	releases[1](OutcomeSuccess)
+	assert.Zero(t, limiter.inFlight)
+}
+
+func TestAIMDLimiter_AdmitsInArrivalOrder(t *testing.T) {
+	limiter := newAIMDLimiter(AIMDConfig{InitialLimit: 1, MinLimit: 1, MaxLimit: 1, MaxQueue: 10, LatencyTarget: time.Second})
+	releases := acquireN(t, limiter, 1)
+
+	order := make(chan int, 3)
+	for i := 1; i <= 3; i++ {
+		go func() {
+			release, err := limiter.Acquire(context.Background())
+			assert.NoError(t, err)
+			order <- i
+			release(OutcomeSuccess)
+		}()
+		require.Eventually(t, func() bool {
+			limiter.mutex.Lock()
+			defer limiter.mutex.Unlock()
+			return len(limiter.waiters) == i
+		}, time.Second, time.Millisecond)
+	}
+
+	releases[0](OutcomeSuccess)
+	assert.Equal(t, 1, <-order)
+	assert.Equal(t, 2, <-order)
+	assert.Equal(t, 3, <-order)
+}
+
+func TestAIMDLimiter_CancelledWaiter(t *testing.T) {
+	limiter := newAIMDLimiter(AIMDConfig{InitialLimit: 1, MinLimit: 1, MaxLimit: 1, MaxQueue: 1, LatencyTarget: time.Second})
+	releases := acquireN(t, limiter, 1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err := limiter.Acquire(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.False(t, limiter.Saturated())
+
+	// Releasing twice gives the slot back once
+	releases[0](OutcomeSuccess)
+	releases[0](OutcomeSuccess)
+	assert.Zero(t, limiter.inFlight)
+}
+
+func TestAIMDLimiter_AdditiveIncrease(t *testing.T) {
+	limiter := newAIMDLimiter(AIMDConfig{InitialLimit: 2, MinLimit: 1, MaxLimit: 3, MaxQueue: 10, LatencyTarget: time.Second})
+
+	releases := acquireN(t, limiter, 2)
+	releases[0](OutcomeSuccess)
+	assert.Equal(t, 2.5, limiter.limit)
+
+	// With a single operation running the limiter isn't busy enough to grow
+	releases[1](OutcomeSuccess)
+	assert.Equal(t, 2.5, limiter.limit)
+
+	for i := 0; i < 10; i++ {
+		for _, release := range acquireN(t, limiter, 2) {
+			release(OutcomeSuccess)
+		}
+	}
+	assert.Equal(t, 3, limiter.Limit(), "the limit doesn't exceed the maximum")
+}
+
+func TestAIMDLimiter_MultiplicativeDecrease(t *testing.T) {
+	limiter := newAIMDLimiter(AIMDConfig{InitialLimit: 10, MinLimit: 8, MaxLimit: 20, MaxQueue: 10, LatencyTarget: 50 * time.Millisecond})
+
+	releases := acquireN(t, limiter, 3)
+	releases[0](OutcomeDropped)
+	assert.Equal(t, 9, limiter.Limit())
+
+	// A burst of timeouts backs off once
+	releases[1](OutcomeDropped)
+	assert.Equal(t, 9, limiter.Limit())
+
+	// Ignored outcomes don't change the limit
+	releases[2](OutcomeIgnored)
+	assert.Equal(t, 9, limiter.Limit())
+
+	// A slow operation backs off too, but not below the minimum
+	for i := 0; i < 3; i++ {
+		limiter.lastBackoff = time.Time{}
+		release := acquireN(t, limiter, 1)[0]
+		time.Sleep(60 * time.Millisecond)
+		release(OutcomeSuccess)
+	}
+	assert.Equal(t, 8, limiter.Limit())
+}
+
+func TestNewAIMDLimiter_Defaults(t *testing.T) {
+	limiter := newAIMDLimiter(AIMDConfig{InitialLimit: 50, MaxLimit: 20})
+
+	assert.Equal(t, 20, limiter.Limit())
+	assert.Equal(t, 1, limiter.config.MinLimit)
+	assert.Equal(t, defaultLatencyTarget, limiter.config.LatencyTarget)
+}
@@ -0,0 +1,33 @@
+package concurrency
+
+import (
+	"context"
+	"time"
+)
+
+// Service defines the interface for limiting concurrent operations, e.g. outbound fetches
+// External packages should use this interface, not the concrete implementations
+type Service interface {
+	// Acquire waits for a slot and returns the function that gives it back
+	// It returns models.ErrOverloaded right away when too many operations are waiting.
+	Acquire(ctx context.Context) (Release, error)
+
+	// Saturated reports whether the wait queue is full, so new operations would be shed
+	Saturated() bool
+
+	// RetryAfter estimates how long until a shed operation could be admitted
+	RetryAfter() time.Duration
+}
+
+// Release gives a slot back, reporting the outcome of the operation that held it
+type Release func(outcome Outcome)
+
+// Outcome of an operation run under a limiter
+type Outcome int
+
+// Operation outcomes
+const (
+	OutcomeSuccess Outcome = iota // Completed, whether or not it succeeded; its latency is a load signal
+	OutcomeDropped                // Timed out: a sign of overload
+	OutcomeIgnored                // Says nothing about load, e.g. cancelled by the caller
+)
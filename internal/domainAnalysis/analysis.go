@@ -2,16 +2,21 @@ package domainAnalysis
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sort"
 	"sync"
 	"time"
 
 	"Perion_Assignment/internal/cache/domainCache"
+	"Perion_Assignment/internal/concurrency"
 	"Perion_Assignment/internal/fetcher"
+	"Perion_Assignment/internal/lock"
 	"Perion_Assignment/internal/logger"
 	"Perion_Assignment/internal/models"
 	"Perion_Assignment/internal/parser"
+
+	"golang.org/x/sync/singleflight"
 )
 
 // Service implements the AnalysisService interface
@@ -21,6 +26,27 @@ type Service struct {
 	domainCache   domainCache.Service
 	logger        logger.Service
 	maxConcurrent int
+	limits        Limits
+
+	// In-flight fetches shared between concurrent callers for the same domain
+	inflight singleflight.Group
+
+	// Domains with a background refresh of a stale cache entry in progress
+	revalidating sync.Map
+
+	// Negative cache TTLs per failure class
+	failureTTLs map[string]time.Duration
+
+	// Optional TTLs from the publisher's caching headers
+	originTTL *originTTLPolicy
+
+	// Optional process-wide limit on concurrent fetches, shared by every analysis
+	fetchLimiter concurrency.Service
+
+	// Optional cross-instance lock so only one replica fetches a domain at a time
+	locker           lock.Service
+	lockTTL          time.Duration
+	lockPollInterval time.Duration
 }
 
 // NewService creates a new analysis service
@@ -30,37 +56,120 @@ func NewService(
 	domainCache domainCache.Service,
 	logger logger.Service,
 	maxConcurrent int,
+	opts ...Option,
 ) AnalysisService {
-	return &Service{
+	s := &Service{
 		parser:        parser,
 		fetcher:       fetcher,
 		domainCache:   domainCache,
 		logger:        logger,
 		maxConcurrent: maxConcurrent,
+		limits:        DefaultLimits(),
+
+		lockTTL:          defaultLockTTL,
+		lockPollInterval: defaultLockPollInterval,
+	}
+	for _, opt := range opts {
+		opt(s)
 	}
+	return s
 }
 
 // AnalyzeDomain analyzes a single domain's ads.txt file
 func (s *Service) AnalyzeDomain(ctx context.Context, domain string) (*models.DomainAnalysis, error) {
+	return s.AnalyzeDomainWithOptions(ctx, domain, models.AnalysisOptions{})
+}
+
+// AnalyzeDomainWithOptions analyzes a single domain's ads.txt file using per-request cache and timeout options
+func (s *Service) AnalyzeDomainWithOptions(ctx context.Context, domain string, opts models.AnalysisOptions) (*models.DomainAnalysis, error) {
+	if err := s.ValidateOptions(opts); err != nil {
+		return nil, err
+	}
+
+	if opts.TimeoutMs > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.domainTimeout(opts))
+		defer cancel()
+	}
+
+	return s.analyzeDomain(ctx, domain, opts)
+}
+
+// analyzeDomain performs the analysis with already validated options
+func (s *Service) analyzeDomain(ctx context.Context, domain string, opts models.AnalysisOptions) (*models.DomainAnalysis, error) {
 	start := time.Now()
 
-	// Try to get from domain cache first
-	if cached, err := s.domainCache.Get(ctx, domain); err == nil {
-		s.logger.LogSuccess(ctx, logger.OpCacheHit, domain, "Retrieved analysis from cache", map[string]interface{}{
-			"duration_ms": time.Since(start).Milliseconds(),
+	// Try to get from domain cache first, unless the caller asked for fresh data
+	var fallback *models.DomainAnalysis
+	if !opts.ForceRefresh {
+		cached, err := s.domainCache.Get(ctx, domain)
+		var failure *models.LookupFailure
+		switch {
+		case err == nil && isFreshEnough(cached, opts):
+			if cached.Stale {
+				s.logger.LogInfo(ctx, logger.OpCacheStale, fmt.Sprintf("Serving stale analysis for domain: %s", domain), map[string]interface{}{
+					"domain":      domain,
+					"age_seconds": cached.AgeSeconds,
+				})
+				s.revalidate(domain, opts)
+			} else {
+				s.logger.LogSuccess(ctx, logger.OpCacheHit, domain, "Retrieved analysis from cache", map[string]interface{}{
+					"duration_ms": time.Since(start).Milliseconds(),
+				})
+			}
+
+			// Mark as cached and return
+			cached.Cached = true
+			return cached, nil
+		case err == nil:
+			s.logger.LogInfo(ctx, logger.OpCacheMiss, fmt.Sprintf("Cached analysis too old for domain: %s", domain), map[string]interface{}{
+				"domain":  domain,
+				"max_age": opts.MaxAge,
+			})
+		case errors.As(err, &failure) && isFailureFreshEnough(failure, opts):
+			s.logger.LogSuccess(ctx, logger.OpCacheHit, domain, "Retrieved failed lookup from negative cache", map[string]interface{}{
+				"failure_class": failure.Class,
+				"duration_ms":   time.Since(start).Milliseconds(),
+			})
+			return nil, failure
+		case errors.Is(err, models.ErrCacheEntryExpired):
+			// Past the hard TTL: fetch synchronously, but keep the old analysis in case the fetch fails
+			fallback = cached
+			s.logger.LogInfo(ctx, logger.OpCacheMiss, fmt.Sprintf("Cached analysis expired for domain: %s", domain), map[string]interface{}{
+				"domain":      domain,
+				"age_seconds": cached.AgeSeconds,
+			})
+		default:
+			s.logger.LogInfo(ctx, logger.OpCacheMiss, fmt.Sprintf("Cache miss for domain: %s", domain), map[string]interface{}{
+				"domain": domain,
+			})
+		}
+	} else {
+		s.logger.LogInfo(ctx, logger.OpCacheMiss, fmt.Sprintf("Cache bypassed for domain: %s", domain), map[string]interface{}{
+			"domain":        domain,
+			"force_refresh": true,
 		})
+	}
 
-		// Mark as cached and return
-		cached.Cached = true
-		return cached, nil
+	// Failures are not cached over an expired analysis that can still serve as a fallback.
+	// It is also served when the deadline passed during the fetch, but not to callers that went away.
+	analysis, err := s.fetchCoalesced(ctx, domain, opts, fallback == nil)
+	if err != nil && fallback != nil && !errors.Is(ctx.Err(), context.Canceled) {
+		s.logger.LogError(ctx, logger.OpCacheStale, domain, "Refresh failed, serving stale analysis", err, models.LogSeverityLow, map[string]interface{}{
+			"age_seconds": fallback.AgeSeconds,
+		})
+		fallback.Cached = true
+		return fallback, nil
 	}
+	return analysis, err
+}
 
-	s.logger.LogInfo(ctx, logger.OpCacheMiss, fmt.Sprintf("Cache miss for domain: %s", domain), map[string]interface{}{
-		"domain": domain,
-	})
+// fetchAndAnalyze fetches, parses and caches a fresh analysis of the domain
+func (s *Service) fetchAndAnalyze(ctx context.Context, domain string, opts models.AnalysisOptions) (*models.DomainAnalysis, error) {
+	start := time.Now()
 
 	// Fetch ads.txt content
-	content, err := s.fetcher.Fetch(ctx, domain)
+	response, err := s.fetchLimited(ctx, domain)
 	if err != nil {
 		s.logger.LogError(ctx, logger.OpFetchAdsTxt, domain, "Failed to fetch ads.txt", err, models.LogSeverityMedium, map[string]interface{}{
 			"duration_ms": time.Since(start).Milliseconds(),
@@ -68,6 +177,7 @@ func (s *Service) AnalyzeDomain(ctx context.Context, domain string) (*models.Dom
 		return nil, models.NewDomainError(domain, "failed to fetch ads.txt", err)
 	}
 
+	content := response.Content
 	s.logger.LogSuccess(ctx, logger.OpFetchAdsTxt, domain, "Successfully fetched ads.txt", map[string]interface{}{
 		"content_size": len(content),
 		"duration_ms":  time.Since(start).Milliseconds(),
@@ -90,9 +200,17 @@ func (s *Service) AnalyzeDomain(ctx context.Context, domain string) (*models.Dom
 
 	// Build analysis result
 	analysis := s.buildAnalysis(domain, entries)
-
-	// Cache the result
-	if err := s.domainCache.Set(ctx, domain, analysis, 0); err != nil {
+	ttl, ttlSource := s.analysisTTL(opts, response)
+	analysis.CacheTTLSeconds = int64(ttl.Seconds())
+	analysis.CacheTTLSource = ttlSource
+
+	// Cache the result, unless the publisher forbids caching and no minimum TTL is configured
+	if ttl == 0 && ttlSource != models.TTLSourceDefault {
+		s.logger.LogInfo(ctx, logger.OpDomainAnalysis, fmt.Sprintf("Not caching analysis for domain: %s", domain), map[string]interface{}{
+			"domain":           domain,
+			"cache_ttl_source": ttlSource,
+		})
+	} else if err := s.domainCache.Set(ctx, domain, analysis, ttl); err != nil {
 		s.logger.LogError(ctx, "cache_set", domain, "Failed to cache analysis result", err, models.LogSeverityLow, map[string]interface{}{
 			"duration_ms": time.Since(start).Milliseconds(),
 		})
@@ -103,15 +221,46 @@ func (s *Service) AnalyzeDomain(ctx context.Context, domain string) (*models.Dom
 		"total_advertisers": analysis.TotalAdvertisers,
 		"duration_ms":       time.Since(start).Milliseconds(),
 		"cached":            false,
+		"cache_ttl_seconds": analysis.CacheTTLSeconds,
+		"cache_ttl_source":  analysis.CacheTTLSource,
 	})
 
 	return analysis, nil
 }
 
+// fetch retrieves the ads.txt file, with its response headers when the fetcher provides them
+func (s *Service) fetch(ctx context.Context, domain string) (*fetcher.Response, error) {
+	if responseFetcher, ok := s.fetcher.(fetcher.ResponseFetcher); ok {
+		return responseFetcher.FetchResponse(ctx, domain)
+	}
+
+	content, err := s.fetcher.Fetch(ctx, domain)
+	if err != nil {
+		return nil, err
+	}
+	return &fetcher.Response{Content: content, FetchedAt: time.Now()}, nil
+}
+
 // AnalyzeDomains analyzes multiple domains concurrently
 func (s *Service) AnalyzeDomains(ctx context.Context, domains []string) (*models.BatchAnalysisResponse, error) {
+	return s.AnalyzeDomainsStream(ctx, domains, models.AnalysisOptions{}, nil)
+}
+
+// AnalyzeDomainsWithOptions analyzes multiple domains concurrently using per-request cache and timeout options
+func (s *Service) AnalyzeDomainsWithOptions(ctx context.Context, domains []string, opts models.AnalysisOptions) (*models.BatchAnalysisResponse, error) {
+	return s.AnalyzeDomainsStream(ctx, domains, opts, nil)
+}
+
+// AnalyzeDomainsStream analyzes multiple domains concurrently, invoking onResult for each
+// domain as soon as its analysis finishes. onResult is called from a single goroutine,
+// so it may write to a response without additional locking. A nil onResult is allowed.
+func (s *Service) AnalyzeDomainsStream(ctx context.Context, domains []string, opts models.AnalysisOptions, onResult func(models.DomainResult)) (*models.BatchAnalysisResponse, error) {
 	start := time.Now()
 
+	if err := s.ValidateOptions(opts); err != nil {
+		return nil, err
+	}
+
 	s.logger.LogInfo(ctx, logger.OpBatchAnalysis, fmt.Sprintf("Starting batch analysis of %d domains", len(domains)), map[string]interface{}{
 		"domains_count": len(domains),
 		"domains":       domains,
@@ -132,7 +281,7 @@ func (s *Service) AnalyzeDomains(ctx context.Context, domains []string) (*models
 	responseChan := make(chan *models.BatchAnalysisResponse, 1)
 
 	// Start response aggregator goroutine
-	go s.aggregateResults(resultsChan, len(domains), responseChan)
+	go s.aggregateResults(resultsChan, len(domains), onResult, responseChan)
 
 	// Use semaphore to limit concurrent operations
 	sem := make(chan struct{}, s.maxConcurrent)
@@ -150,31 +299,14 @@ func (s *Service) AnalyzeDomains(ctx context.Context, domains []string) (*models
 			defer func() { <-sem }()
 
 			// Create context with timeout for individual domain
-			domainCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+			domainCtx, cancel := context.WithTimeout(ctx, s.domainTimeout(opts))
 			defer cancel()
 
-			var result models.DomainResult
-			analysis, err := s.AnalyzeDomain(domainCtx, dom)
+			analysis, err := s.analyzeDomain(domainCtx, dom, opts)
 			if err != nil {
-				result = models.DomainResult{
-					Domain:    dom,
-					Error:     err.Error(),
-					Success:   false,
-					Cached:    false, // Explicitly set cached to false for failed requests
-					Timestamp: time.Now().UTC(),
-				}
-
 				s.logger.LogError(domainCtx, logger.OpBatchAnalysis, dom, "Failed to analyze domain in batch", err, models.LogSeverityMedium, nil)
-			} else {
-				result = models.DomainResult{
-					Domain:           dom,
-					TotalAdvertisers: analysis.TotalAdvertisers,
-					Advertisers:      analysis.Advertisers,
-					Cached:           analysis.Cached, // This will be true or false based on cache hit/miss
-					Success:          true,
-					Timestamp:        analysis.Timestamp,
-				}
 			}
+			result := models.NewDomainResult(dom, analysis, err)
 
 			// Send result to aggregator
 			resultsChan <- result
@@ -237,7 +369,7 @@ func (s *Service) buildAnalysis(domain string, entries []models.AdsTxtEntry) *mo
 
 
 // aggregateResults processes results from workers concurrently and builds the final response
-func (s *Service) aggregateResults(resultsChan <-chan models.DomainResult, totalDomains int, responseChan chan<- *models.BatchAnalysisResponse) {
+func (s *Service) aggregateResults(resultsChan <-chan models.DomainResult, totalDomains int, onResult func(models.DomainResult), responseChan chan<- *models.BatchAnalysisResponse) {
 	// Initialize response components
 	results := make([]models.DomainResult, 0, totalDomains)
 	advertiserCounts := make(map[string]int) // Use map for O(1) aggregation
@@ -245,6 +377,11 @@ func (s *Service) aggregateResults(resultsChan <-chan models.DomainResult, total
 
 	// Process results as they arrive
 	for result := range resultsChan {
+		// Hand the result to the streaming consumer, if any
+		if onResult != nil {
+			onResult(result)
+		}
+
 		// Add to results array
 		results = append(results, result)
 
@@ -850,3 +850,187 @@ func TestService_AnalyzeDomains_AllSuccess(t *testing.T) {
 	mockFetcher.AssertExpectations(t)
 	mockParser.AssertExpectations(t)
 }
+
+func TestService_AnalyzeDomainsStream_InvokesCallbackPerResult(t *testing.T) {
+	// Arrange
+	mockParser := &mocks2.MockParser{}
+	mockFetcher := &mocks2.MockFetcher{}
+	mockCache := &mocks2.MockDomainCache{}
+	mockLogger := &mocks2.MockLogger{}
+
+	service := NewService(mockParser, mockFetcher, mockCache, mockLogger, 2).(*Service)
+
+	ctx := context.Background()
+	domains := []string{"a.com", "b.com", "c.com"}
+
+	mockLogger.On("LogInfo", ctx, "batch_analysis", "Starting batch analysis of 3 domains", mock.Anything).Return()
+	for _, domain := range domains {
+		mockCache.On("Get", mock.Anything, domain).Return(&models.DomainAnalysis{
+			Domain:           domain,
+			TotalAdvertisers: 1,
+			Advertisers:      []models.AdvertiserInfo{{Domain: "google.com", Count: 1}},
+		}, nil)
+		mockLogger.On("LogSuccess", mock.Anything, "cache_hit", domain, "Retrieved analysis from cache", mock.Anything).Return()
+	}
+	mockLogger.On("LogSuccess", ctx, "batch_analysis", "", "Completed batch analysis", mock.Anything).Return()
+
+	// Act
+	var streamed []models.DomainResult
+	result, err := service.AnalyzeDomainsStream(ctx, domains, models.AnalysisOptions{}, func(result models.DomainResult) {
+		streamed = append(streamed, result)
+	})
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, streamed, 3)
+	assert.ElementsMatch(t, result.Results, streamed)
+	assert.Equal(t, 3, result.TotalAdvertisers)
+	for _, r := range streamed {
+		assert.True(t, r.Success)
+		assert.True(t, r.Cached)
+	}
+}
+
+func TestService_AnalyzeDomainWithOptions_ForceRefresh(t *testing.T) {
+	// Arrange
+	mockParser := &mocks2.MockParser{}
+	mockFetcher := &mocks2.MockFetcher{}
+	mockCache := &mocks2.MockDomainCache{}
+	mockLogger := &mocks2.MockLogger{}
+
+	service := NewService(mockParser, mockFetcher, mockCache, mockLogger, 10).(*Service)
+
+	domain := "example.com"
+	ctx := context.Background()
+	content := "google.com, pub-123, DIRECT"
+	entries := []models.AdsTxtEntry{{ExchangeDomain: "google.com", PublisherID: "pub-123", AccountType: "DIRECT"}}
+
+	mockLogger.On("LogInfo", ctx, "cache_miss", mock.AnythingOfType("string"), mock.Anything).Return()
+	mockFetcher.On("Fetch", ctx, domain).Return(content, nil)
+	mockLogger.On("LogSuccess", ctx, mock.Anything, domain, mock.Anything, mock.Anything).Return()
+	mockParser.On("Parse", content).Return(entries, nil)
+	mockParser.On("CountAdvertisers", entries).Return(map[string]int{"google.com": 1})
+	mockCache.On("Set", ctx, domain, mock.AnythingOfType("*models.DomainAnalysis"), 2*time.Minute).Return(nil)
+
+	// Act
+	result, err := service.AnalyzeDomainWithOptions(ctx, domain, models.AnalysisOptions{ForceRefresh: true, CacheTTL: 120})
+
+	// Assert
+	require.NoError(t, err)
+	assert.False(t, result.Cached)
+	mockCache.AssertNotCalled(t, "Get", mock.Anything, mock.Anything)
+	mockCache.AssertExpectations(t)
+	mockFetcher.AssertExpectations(t)
+}
+
+func TestService_AnalyzeDomainWithOptions_MaxAge(t *testing.T) {
+	tests := []struct {
+		name        string
+		cachedAge   time.Duration
+		expectFetch bool
+	}{
+		{name: "cached analysis fresh enough", cachedAge: 30 * time.Second, expectFetch: false},
+		{name: "cached analysis too old", cachedAge: 5 * time.Minute, expectFetch: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			mockParser := &mocks2.MockParser{}
+			mockFetcher := &mocks2.MockFetcher{}
+			mockCache := &mocks2.MockDomainCache{}
+			mockLogger := &mocks2.MockLogger{}
+
+			service := NewService(mockParser, mockFetcher, mockCache, mockLogger, 10).(*Service)
+
+			domain := "example.com"
+			ctx := context.Background()
+			cached := &models.DomainAnalysis{Domain: domain, TotalAdvertisers: 7, Timestamp: time.Now().UTC().Add(-tt.cachedAge)}
+
+			mockCache.On("Get", ctx, domain).Return(cached, nil)
+			mockLogger.On("LogSuccess", ctx, mock.Anything, domain, mock.Anything, mock.Anything).Return().Maybe()
+			mockLogger.On("LogInfo", ctx, "cache_miss", mock.AnythingOfType("string"), mock.Anything).Return().Maybe()
+			if tt.expectFetch {
+				mockFetcher.On("Fetch", ctx, domain).Return("", nil)
+				mockParser.On("Parse", "").Return([]models.AdsTxtEntry{}, nil)
+				mockParser.On("CountAdvertisers", []models.AdsTxtEntry{}).Return(map[string]int{})
+				mockCache.On("Set", ctx, domain, mock.AnythingOfType("*models.DomainAnalysis"), time.Duration(0)).Return(nil)
+			}
+
+			// Act
+			result, err := service.AnalyzeDomainWithOptions(ctx, domain, models.AnalysisOptions{MaxAge: 60})
+
+			// Assert
+			require.NoError(t, err)
+			assert.Equal(t, !tt.expectFetch, result.Cached)
+			if !tt.expectFetch {
+				mockFetcher.AssertNotCalled(t, "Fetch", mock.Anything, mock.Anything)
+			}
+			mockFetcher.AssertExpectations(t)
+			mockCache.AssertExpectations(t)
+		})
+	}
+}
+
+func TestService_AnalyzeDomainWithOptions_InvalidOptions(t *testing.T) {
+	tests := []struct {
+		name string
+		opts models.AnalysisOptions
+	}{
+		{name: "negative max_age", opts: models.AnalysisOptions{MaxAge: -1}},
+		{name: "negative timeout_ms", opts: models.AnalysisOptions{TimeoutMs: -5}},
+		{name: "timeout_ms above limit", opts: models.AnalysisOptions{TimeoutMs: 5000}},
+		{name: "negative cache_ttl", opts: models.AnalysisOptions{CacheTTL: -1}},
+		{name: "cache_ttl above limit", opts: models.AnalysisOptions{CacheTTL: 3601}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockCache := &mocks2.MockDomainCache{}
+			mockLogger := &mocks2.MockLogger{}
+			mockLogger.On("LogInfo", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return().Maybe()
+
+			service := NewService(&mocks2.MockParser{}, &mocks2.MockFetcher{}, mockCache, mockLogger, 10, WithLimits(Limits{
+				DefaultTimeout: time.Second,
+				MaxTimeout:     2 * time.Second,
+				MaxCacheTTL:    time.Hour,
+			})).(*Service)
+
+			_, err := service.AnalyzeDomainWithOptions(context.Background(), "example.com", tt.opts)
+			assert.ErrorIs(t, err, models.ErrInvalidAnalysisOptions)
+
+			_, err = service.AnalyzeDomainsWithOptions(context.Background(), []string{"example.com"}, tt.opts)
+			assert.ErrorIs(t, err, models.ErrInvalidAnalysisOptions)
+
+			mockCache.AssertNotCalled(t, "Get", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestService_AnalyzeDomainsWithOptions_DomainTimeout(t *testing.T) {
+	// Arrange
+	mockParser := &mocks2.MockParser{}
+	mockFetcher := &mocks2.MockFetcher{}
+	mockCache := &mocks2.MockDomainCache{}
+	mockLogger := &mocks2.MockLogger{}
+
+	service := NewService(mockParser, mockFetcher, mockCache, mockLogger, 10).(*Service)
+
+	domain := "slow.com"
+	mockCache.On("Get", mock.Anything, domain).Return(nil, errors.New("cache miss"))
+	mockFetcher.On("Fetch", mock.Anything, domain).Run(func(args mock.Arguments) {
+		<-args.Get(0).(context.Context).Done()
+	}).Return("", context.DeadlineExceeded)
+	mockLogger.On("LogInfo", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return()
+	mockLogger.On("LogError", mock.Anything, mock.Anything, domain, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return()
+	mockLogger.On("LogSuccess", mock.Anything, "batch_analysis", "", "Completed batch analysis", mock.Anything).Return()
+
+	// Act
+	start := time.Now()
+	result, err := service.AnalyzeDomainsWithOptions(context.Background(), []string{domain}, models.AnalysisOptions{TimeoutMs: 50})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Less(t, time.Since(start), 5*time.Second)
+	assert.Equal(t, 1, result.Summary.Failed)
+}
@@ -0,0 +1,34 @@
+package domainAnalysis
+
+import (
+	"context"
+	"errors"
+
+	"Perion_Assignment/internal/cache/domainCache"
+	"Perion_Assignment/internal/models"
+)
+
+// CountCacheMisses returns how many of the domains would be fetched rather than answered
+// from the cache with the given options; stale analyses and cached failures count as hits.
+// It peeks at the cache, so it neither logs, triggers revalidation nor affects the cache's
+// hit counters or recency. Every domain counts as a miss when the domain cache can't be
+// peeked at (it doesn't implement domainCache.Peeker).
+func (s *Service) CountCacheMisses(ctx context.Context, domains []string, opts models.AnalysisOptions) int {
+	peeker, peeks := s.domainCache.(domainCache.Peeker)
+	if opts.ForceRefresh || !peeks {
+		return len(domains)
+	}
+
+	misses := 0
+	for _, domain := range domains {
+		cached, err := peeker.Peek(ctx, domain)
+		var failure *models.LookupFailure
+		switch {
+		case err == nil && isFreshEnough(cached, opts):
+		case errors.As(err, &failure) && isFailureFreshEnough(failure, opts):
+		default:
+			misses++
+		}
+	}
+	return misses
+}
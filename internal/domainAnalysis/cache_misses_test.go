@@ -0,0 +1,38 @@
+package domainAnalysis
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"Perion_Assignment/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestService_CountCacheMisses(t *testing.T) {
+	// Arrange
+	service, _, _, mockCache := newCoalescingTestService()
+
+	mockCache.On("Peek", mock.Anything, "fresh.com").Return(&models.DomainAnalysis{Domain: "fresh.com", Timestamp: time.Now()}, nil)
+	mockCache.On("Peek", mock.Anything, "stale.com").Return(&models.DomainAnalysis{Domain: "stale.com", Timestamp: time.Now().Add(-time.Hour), Stale: true}, nil)
+	mockCache.On("Peek", mock.Anything, "failed.com").Return(nil, &models.LookupFailure{Class: models.FailureClassNotFound, FailedAt: time.Now()})
+	mockCache.On("Peek", mock.Anything, "expired.com").Return(&models.DomainAnalysis{Domain: "expired.com"}, models.ErrCacheEntryExpired)
+	mockCache.On("Peek", mock.Anything, "missing.com").Return(nil, errors.New("cache miss"))
+
+	domains := []string{"fresh.com", "stale.com", "failed.com", "expired.com", "missing.com"}
+
+	// Act & Assert
+	assert.Equal(t, 2, service.CountCacheMisses(context.Background(), domains, models.AnalysisOptions{}))
+
+	// max_age turns the hour-old stale analysis into a miss
+	assert.Equal(t, 3, service.CountCacheMisses(context.Background(), domains, models.AnalysisOptions{MaxAge: 60}))
+
+	// force_refresh bypasses the cache
+	assert.Equal(t, 5, service.CountCacheMisses(context.Background(), domains, models.AnalysisOptions{ForceRefresh: true}))
+
+	// Counting never reads the cache the way an analysis does
+	mockCache.AssertNotCalled(t, "Get", mock.Anything, mock.Anything)
+}
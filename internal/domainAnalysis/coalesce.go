@@ -0,0 +1,142 @@
+package domainAnalysis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"Perion_Assignment/internal/logger"
+	"Perion_Assignment/internal/models"
+)
+
+const (
+	// defaultLockTTL bounds how long a crashed replica can block others from fetching a domain
+	defaultLockTTL = 30 * time.Second
+
+	// defaultLockPollInterval is how often a waiting replica checks the cache for the holder's result
+	defaultLockPollInterval = 100 * time.Millisecond
+)
+
+// fetchCoalesced fetches a domain, sharing one in-flight fetch between all concurrent
+// callers in this process (including duplicate domains within a batch) that would get
+// the same result. When cacheFailures is set, a failed fetch is stored in the negative cache.
+func (s *Service) fetchCoalesced(ctx context.Context, domain string, opts models.AnalysisOptions, cacheFailures bool) (*models.DomainAnalysis, error) {
+	for {
+		resultChan := s.inflight.DoChan(coalesceKey(domain, opts, cacheFailures), func() (interface{}, error) {
+			analysis, err := s.fetchWithLock(ctx, domain, opts)
+			if err != nil && cacheFailures {
+				s.cacheFailure(ctx, domain, err)
+			}
+			return analysis, err
+		})
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case result := <-resultChan:
+			if result.Err != nil {
+				// The shared fetch ran on another caller's context; if that caller gave up,
+				// retry with our own context instead of failing
+				if result.Shared && ctx.Err() == nil && isContextError(result.Err) {
+					continue
+				}
+				return nil, result.Err
+			}
+			return result.Val.(*models.DomainAnalysis), nil
+		}
+	}
+}
+
+// coalesceKey identifies the fetches of a domain that can be shared
+// Only the options that change what a fetch stores and returns are part of the key; the
+// cache lookup options (force_refresh, max_age) are satisfied by any fresh fetch, and the
+// timeout is covered by retrying with the caller's own context.
+func coalesceKey(domain string, opts models.AnalysisOptions, cacheFailures bool) string {
+	return fmt.Sprintf("%s|cache_ttl=%d|cache_failures=%t", domain, opts.CacheTTL, cacheFailures)
+}
+
+// fetchWithLock fetches a domain while holding the cross-instance lock, if configured.
+// When another replica holds the lock, it waits for that replica's result to appear in the cache.
+func (s *Service) fetchWithLock(ctx context.Context, domain string, opts models.AnalysisOptions) (*models.DomainAnalysis, error) {
+	if s.locker == nil {
+		return s.fetchAndAnalyze(ctx, domain, opts)
+	}
+
+	waitStart := time.Now().UTC()
+	waiting := false
+	for {
+		held, err := s.locker.TryLock(ctx, "domain:"+domain, s.lockTTL)
+		if err == nil {
+			defer func() {
+				if err := held.Release(context.WithoutCancel(ctx)); err != nil {
+					s.logger.LogError(ctx, logger.OpDomainLock, domain, "Failed to release domain lock", err, models.LogSeverityLow, nil)
+				}
+			}()
+			return s.fetchAndAnalyze(ctx, domain, opts)
+		}
+
+		if !errors.Is(err, models.ErrLockHeld) {
+			// Don't fail the request if the lock backend is unavailable
+			s.logger.LogError(ctx, logger.OpDomainLock, domain, "Failed to acquire domain lock, fetching without it", err, models.LogSeverityLow, nil)
+			return s.fetchAndAnalyze(ctx, domain, opts)
+		}
+
+		if !waiting {
+			waiting = true
+			s.logger.LogInfo(ctx, logger.OpDomainLock, fmt.Sprintf("Waiting for another instance to fetch domain: %s", domain), map[string]interface{}{
+				"domain": domain,
+			})
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(s.lockPollInterval):
+		}
+
+		cached, err := s.domainCache.Get(ctx, domain)
+		var failure *models.LookupFailure
+		switch {
+		case err == nil && s.acceptsWaitedResult(cached, opts, waitStart):
+			cached.Cached = true
+			return cached, nil
+		case errors.As(err, &failure) && !failure.FailedAt.Before(waitStart):
+			// The holder's fetch failed; share its outcome instead of fetching again
+			return nil, failure
+		}
+	}
+}
+
+// acceptsWaitedResult reports whether an analysis cached by another replica satisfies the request
+func (s *Service) acceptsWaitedResult(analysis *models.DomainAnalysis, opts models.AnalysisOptions, waitStart time.Time) bool {
+	if opts.ForceRefresh {
+		// Only a fetch that finished after this request started counts as fresh
+		return !analysis.Timestamp.Before(waitStart)
+	}
+	return isFreshEnough(analysis, opts)
+}
+
+// revalidate refreshes a stale cache entry in the background
+// Concurrent requests for the same stale domain start at most one refresh
+func (s *Service) revalidate(domain string, opts models.AnalysisOptions) {
+	if _, running := s.revalidating.LoadOrStore(domain, struct{}{}); running {
+		return
+	}
+
+	go func() {
+		defer s.revalidating.Delete(domain)
+
+		ctx, cancel := context.WithTimeout(logger.WithLogEvent(context.Background(), logger.NewInternalLogEvent()), s.domainTimeout(opts))
+		defer cancel()
+
+		if _, err := s.fetchCoalesced(ctx, domain, models.AnalysisOptions{CacheTTL: opts.CacheTTL}, false); err != nil {
+			s.logger.LogError(ctx, logger.OpCacheStale, domain, "Background refresh of stale analysis failed", err, models.LogSeverityLow, nil)
+		}
+	}()
+}
+
+// isContextError reports whether err was caused by a cancelled or expired context
+func isContextError(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
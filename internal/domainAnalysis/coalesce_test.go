@@ -0,0 +1,392 @@
+package domainAnalysis
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"Perion_Assignment/internal/lock"
+	mocks2 "Perion_Assignment/internal/mocks"
+	"Perion_Assignment/internal/models"
+	"Perion_Assignment/internal/redisclient"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// newCoalescingTestService creates a service whose logger accepts any call
+func newCoalescingTestService(opts ...Option) (*Service, *mocks2.MockParser, *mocks2.MockFetcher, *mocks2.MockDomainCache) {
+	mockParser := &mocks2.MockParser{}
+	mockFetcher := &mocks2.MockFetcher{}
+	mockCache := &mocks2.MockDomainCache{}
+	mockLogger := &mocks2.MockLogger{}
+	mockLogger.On("LogInfo", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return().Maybe()
+	mockLogger.On("LogSuccess", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return().Maybe()
+	mockLogger.On("LogError", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return().Maybe()
+
+	service := NewService(mockParser, mockFetcher, mockCache, mockLogger, 10, opts...).(*Service)
+	return service, mockParser, mockFetcher, mockCache
+}
+
+func TestService_AnalyzeDomain_CoalescesConcurrentFetches(t *testing.T) {
+	// Arrange
+	service, mockParser, mockFetcher, mockCache := newCoalescingTestService()
+
+	const callers = 10
+	domain := "cnn.com"
+	entries := []models.AdsTxtEntry{{ExchangeDomain: "google.com", PublisherID: "pub-1", AccountType: "DIRECT"}}
+
+	var fetches int32
+	release := make(chan struct{})
+	mockCache.On("Get", mock.Anything, domain).Return(nil, errors.New("cache miss"))
+	mockFetcher.On("Fetch", mock.Anything, domain).Run(func(args mock.Arguments) {
+		atomic.AddInt32(&fetches, 1)
+		<-release
+	}).Return("content", nil)
+	mockParser.On("Parse", "content").Return(entries, nil)
+	mockParser.On("CountAdvertisers", entries).Return(map[string]int{"google.com": 1})
+	mockCache.On("Set", mock.Anything, domain, mock.Anything, time.Duration(0)).Return(nil)
+
+	// Act
+	var wg sync.WaitGroup
+	results := make([]*models.DomainAnalysis, callers)
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			result, err := service.AnalyzeDomain(context.Background(), domain)
+			assert.NoError(t, err)
+			results[i] = result
+		}(i)
+	}
+
+	// Let every caller join the in-flight fetch before it completes
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	// Assert
+	assert.Equal(t, int32(1), atomic.LoadInt32(&fetches))
+	for _, result := range results {
+		require.NotNil(t, result)
+		assert.Equal(t, 1, result.TotalAdvertisers)
+	}
+	mockCache.AssertNumberOfCalls(t, "Set", 1)
+}
+
+func TestService_AnalyzeDomainWithOptions_CoalescesOnlyMatchingOptions(t *testing.T) {
+	// Arrange
+	service, mockParser, mockFetcher, mockCache := newCoalescingTestService()
+
+	domain := "cnn.com"
+	release := make(chan struct{})
+	mockCache.On("Get", mock.Anything, domain).Return(nil, errors.New("cache miss"))
+	mockFetcher.On("Fetch", mock.Anything, domain).Run(func(args mock.Arguments) {
+		<-release
+	}).Return("", nil)
+	mockParser.On("Parse", "").Return([]models.AdsTxtEntry{}, nil)
+	mockParser.On("CountAdvertisers", []models.AdsTxtEntry{}).Return(map[string]int{})
+	mockCache.On("Set", mock.Anything, domain, mock.Anything, mock.Anything).Return(nil)
+
+	// Act: two callers share a fetch, a third asks for another cache TTL
+	options := []models.AnalysisOptions{{CacheTTL: 60}, {CacheTTL: 60, ForceRefresh: true}, {CacheTTL: 600}}
+	results := make([]*models.DomainAnalysis, len(options))
+	var wg sync.WaitGroup
+	for i, opts := range options {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result, err := service.AnalyzeDomainWithOptions(context.Background(), domain, opts)
+			assert.NoError(t, err)
+			results[i] = result
+		}()
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	// Assert
+	mockFetcher.AssertNumberOfCalls(t, "Fetch", 2)
+	mockCache.AssertCalled(t, "Set", mock.Anything, domain, mock.Anything, 60*time.Second)
+	mockCache.AssertCalled(t, "Set", mock.Anything, domain, mock.Anything, 600*time.Second)
+	for i, opts := range options {
+		require.NotNil(t, results[i])
+		assert.Equal(t, int64(opts.CacheTTL), results[i].CacheTTLSeconds)
+	}
+}
+
+func TestService_AnalyzeDomains_DuplicateDomainsFetchedOnce(t *testing.T) {
+	// Arrange
+	service, mockParser, mockFetcher, mockCache := newCoalescingTestService()
+
+	domain := "cnn.com"
+	release := make(chan struct{})
+	mockCache.On("Get", mock.Anything, domain).Return(nil, errors.New("cache miss"))
+	mockFetcher.On("Fetch", mock.Anything, domain).Run(func(args mock.Arguments) {
+		<-release
+	}).Return("", nil)
+	mockParser.On("Parse", "").Return([]models.AdsTxtEntry{}, nil)
+	mockParser.On("CountAdvertisers", []models.AdsTxtEntry{}).Return(map[string]int{})
+	mockCache.On("Set", mock.Anything, domain, mock.Anything, time.Duration(0)).Return(nil)
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		close(release)
+	}()
+
+	// Act
+	response, err := service.AnalyzeDomains(context.Background(), []string{domain, domain, domain})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, 3, response.Summary.Succeeded)
+	mockFetcher.AssertNumberOfCalls(t, "Fetch", 1)
+}
+
+func TestService_AnalyzeDomain_FollowerRetriesWhenLeaderCancelled(t *testing.T) {
+	// Arrange
+	service, mockParser, mockFetcher, mockCache := newCoalescingTestService()
+
+	domain := "cnn.com"
+	leaderStarted := make(chan struct{})
+	mockCache.On("Get", mock.Anything, domain).Return(nil, errors.New("cache miss"))
+	mockFetcher.On("Fetch", mock.Anything, domain).Run(func(args mock.Arguments) {
+		close(leaderStarted)
+		<-args.Get(0).(context.Context).Done()
+	}).Return("", context.Canceled).Once()
+	mockFetcher.On("Fetch", mock.Anything, domain).Return("", nil).Once()
+	mockParser.On("Parse", "").Return([]models.AdsTxtEntry{}, nil)
+	mockParser.On("CountAdvertisers", []models.AdsTxtEntry{}).Return(map[string]int{})
+	mockCache.On("Set", mock.Anything, domain, mock.Anything, time.Duration(0)).Return(nil)
+
+	leaderCtx, cancelLeader := context.WithCancel(context.Background())
+	leaderDone := make(chan error, 1)
+	go func() {
+		_, err := service.AnalyzeDomain(leaderCtx, domain)
+		leaderDone <- err
+	}()
+	<-leaderStarted
+
+	// Act
+	followerDone := make(chan error, 1)
+	go func() {
+		_, err := service.AnalyzeDomain(context.Background(), domain)
+		followerDone <- err
+	}()
+	time.Sleep(20 * time.Millisecond)
+	cancelLeader()
+
+	// Assert
+	assert.Error(t, <-leaderDone)
+	assert.NoError(t, <-followerDone)
+	mockFetcher.AssertNumberOfCalls(t, "Fetch", 2)
+}
+
+func TestService_AnalyzeDomain_WaitsForLockHolder(t *testing.T) {
+	// Arrange
+	mr := miniredis.RunT(t)
+	locker, err := lock.NewRedisLocker(redisclient.FromURL("redis://" + mr.Addr()))
+	require.NoError(t, err)
+
+	service, _, mockFetcher, mockCache := newCoalescingTestService(WithLocker(locker, time.Minute))
+	service.lockPollInterval = 10 * time.Millisecond
+
+	domain := "cnn.com"
+	ctx := context.Background()
+
+	// Another replica is fetching the domain
+	held, err := locker.TryLock(ctx, "domain:"+domain, time.Minute)
+	require.NoError(t, err)
+
+	fetched := &models.DomainAnalysis{Domain: domain, TotalAdvertisers: 4, Timestamp: time.Now().UTC()}
+	mockCache.On("Get", mock.Anything, domain).Return(nil, errors.New("cache miss")).Twice()
+	mockCache.On("Get", mock.Anything, domain).Return(fetched, nil)
+
+	// Act
+	result, err := service.AnalyzeDomain(ctx, domain)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, 4, result.TotalAdvertisers)
+	assert.True(t, result.Cached)
+	mockFetcher.AssertNotCalled(t, "Fetch", mock.Anything, mock.Anything)
+	require.NoError(t, held.Release(ctx))
+}
+
+func TestService_AnalyzeDomain_FetchesWhenLockFree(t *testing.T) {
+	// Arrange
+	mr := miniredis.RunT(t)
+	locker, err := lock.NewRedisLocker(redisclient.FromURL("redis://" + mr.Addr()))
+	require.NoError(t, err)
+
+	service, mockParser, mockFetcher, mockCache := newCoalescingTestService(WithLocker(locker, time.Minute))
+
+	domain := "cnn.com"
+	mockCache.On("Get", mock.Anything, domain).Return(nil, errors.New("cache miss"))
+	mockFetcher.On("Fetch", mock.Anything, domain).Run(func(args mock.Arguments) {
+		// The lock is held while fetching
+		assert.True(t, mr.Exists("lock:domain:"+domain))
+	}).Return("", nil)
+	mockParser.On("Parse", "").Return([]models.AdsTxtEntry{}, nil)
+	mockParser.On("CountAdvertisers", []models.AdsTxtEntry{}).Return(map[string]int{})
+	mockCache.On("Set", mock.Anything, domain, mock.Anything, time.Duration(0)).Return(nil)
+
+	// Act
+	_, err = service.AnalyzeDomain(context.Background(), domain)
+
+	// Assert
+	require.NoError(t, err)
+	mockFetcher.AssertNumberOfCalls(t, "Fetch", 1)
+	assert.False(t, mr.Exists("lock:domain:"+domain))
+}
+
+func TestService_AnalyzeDomain_LockUnavailable(t *testing.T) {
+	// Arrange
+	mr := miniredis.RunT(t)
+	locker, err := lock.NewRedisLocker(redisclient.FromURL("redis://" + mr.Addr()))
+	require.NoError(t, err)
+	mr.Close()
+
+	service, mockParser, mockFetcher, mockCache := newCoalescingTestService(WithLocker(locker, time.Minute))
+
+	domain := "cnn.com"
+	mockCache.On("Get", mock.Anything, domain).Return(nil, errors.New("cache miss"))
+	mockFetcher.On("Fetch", mock.Anything, domain).Return("", nil)
+	mockParser.On("Parse", "").Return([]models.AdsTxtEntry{}, nil)
+	mockParser.On("CountAdvertisers", []models.AdsTxtEntry{}).Return(map[string]int{})
+	mockCache.On("Set", mock.Anything, domain, mock.Anything, time.Duration(0)).Return(nil)
+
+	// Act
+	_, err = service.AnalyzeDomain(context.Background(), domain)
+
+	// Assert
+	require.NoError(t, err)
+	mockFetcher.AssertNumberOfCalls(t, "Fetch", 1)
+}
+
+func TestService_AnalyzeDomain_ServesStaleAndRevalidates(t *testing.T) {
+	// Arrange
+	service, mockParser, mockFetcher, mockCache := newCoalescingTestService()
+
+	domain := "cnn.com"
+	stale := &models.DomainAnalysis{Domain: domain, TotalAdvertisers: 2, Stale: true, AgeSeconds: 4000, Timestamp: time.Now().UTC().Add(-4000 * time.Second)}
+	refreshed := make(chan struct{})
+
+	mockCache.On("Get", mock.Anything, domain).Return(stale, nil)
+	mockFetcher.On("Fetch", mock.Anything, domain).Return("", nil).Once()
+	mockParser.On("Parse", "").Return([]models.AdsTxtEntry{}, nil)
+	mockParser.On("CountAdvertisers", []models.AdsTxtEntry{}).Return(map[string]int{})
+	mockCache.On("Set", mock.Anything, domain, mock.Anything, time.Duration(0)).Run(func(args mock.Arguments) {
+		close(refreshed)
+	}).Return(nil)
+
+	// Act
+	result, err := service.AnalyzeDomain(context.Background(), domain)
+
+	// Assert: the stale analysis is returned immediately and refreshed in the background
+	require.NoError(t, err)
+	assert.True(t, result.Stale)
+	assert.True(t, result.Cached)
+	assert.Equal(t, int64(4000), result.AgeSeconds)
+
+	select {
+	case <-refreshed:
+	case <-time.After(2 * time.Second):
+		t.Fatal("stale analysis was not refreshed in the background")
+	}
+}
+
+func TestService_AnalyzeDomain_ExpiredFallsBackOnFetchFailure(t *testing.T) {
+	// Arrange
+	service, _, mockFetcher, mockCache := newCoalescingTestService()
+
+	domain := "cnn.com"
+	expired := &models.DomainAnalysis{Domain: domain, TotalAdvertisers: 2, Stale: true, AgeSeconds: 9000}
+	mockCache.On("Get", mock.Anything, domain).Return(expired, models.ErrCacheEntryExpired)
+	mockFetcher.On("Fetch", mock.Anything, domain).Return("", errors.New("connection refused"))
+
+	// Act
+	result, err := service.AnalyzeDomain(context.Background(), domain)
+
+	// Assert
+	require.NoError(t, err)
+	assert.True(t, result.Stale)
+	assert.True(t, result.Cached)
+	assert.Equal(t, 2, result.TotalAdvertisers)
+	mockFetcher.AssertNumberOfCalls(t, "Fetch", 1)
+}
+
+func TestService_AnalyzeDomain_ExpiredFallsBackOnDeadline(t *testing.T) {
+	// Arrange
+	service, _, mockFetcher, mockCache := newCoalescingTestService()
+
+	domain := "cnn.com"
+	expired := &models.DomainAnalysis{Domain: domain, TotalAdvertisers: 2, Stale: true, AgeSeconds: 9000}
+	mockCache.On("Get", mock.Anything, domain).Return(expired, models.ErrCacheEntryExpired)
+
+	// The publisher hangs until the caller's deadline
+	mockFetcher.On("Fetch", mock.Anything, domain).Run(func(args mock.Arguments) {
+		<-args.Get(0).(context.Context).Done()
+	}).Return("", context.DeadlineExceeded)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	// Act
+	result, err := service.AnalyzeDomain(ctx, domain)
+
+	// Assert
+	require.NoError(t, err)
+	assert.True(t, result.Stale)
+	assert.True(t, result.Cached)
+	assert.Equal(t, 2, result.TotalAdvertisers)
+}
+
+func TestService_AnalyzeDomain_ExpiredNotServedToCancelledCaller(t *testing.T) {
+	// Arrange
+	service, _, mockFetcher, mockCache := newCoalescingTestService()
+
+	domain := "cnn.com"
+	mockCache.On("Get", mock.Anything, domain).Return(&models.DomainAnalysis{Domain: domain, Stale: true}, models.ErrCacheEntryExpired)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	mockFetcher.On("Fetch", mock.Anything, domain).Run(func(args mock.Arguments) {
+		cancel()
+		<-args.Get(0).(context.Context).Done()
+	}).Return("", context.Canceled)
+
+	// Act
+	result, err := service.AnalyzeDomain(ctx, domain)
+
+	// Assert
+	assert.Error(t, err)
+	assert.Nil(t, result)
+}
+
+func TestService_AnalyzeDomain_ExpiredFetchesSynchronously(t *testing.T) {
+	// Arrange
+	service, mockParser, mockFetcher, mockCache := newCoalescingTestService()
+
+	domain := "cnn.com"
+	entries := []models.AdsTxtEntry{{ExchangeDomain: "google.com", PublisherID: "pub-1", AccountType: "DIRECT"}}
+	mockCache.On("Get", mock.Anything, domain).Return(&models.DomainAnalysis{Domain: domain, Stale: true}, models.ErrCacheEntryExpired)
+	mockFetcher.On("Fetch", mock.Anything, domain).Return("content", nil)
+	mockParser.On("Parse", "content").Return(entries, nil)
+	mockParser.On("CountAdvertisers", entries).Return(map[string]int{"google.com": 1})
+	mockCache.On("Set", mock.Anything, domain, mock.Anything, time.Duration(0)).Return(nil)
+
+	// Act
+	result, err := service.AnalyzeDomain(context.Background(), domain)
+
+	// Assert
+	require.NoError(t, err)
+	assert.False(t, result.Stale)
+	assert.False(t, result.Cached)
+	assert.Equal(t, 1, result.TotalAdvertisers)
+}
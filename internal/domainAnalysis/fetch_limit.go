@@ -0,0 +1,45 @@
+package domainAnalysis
+
+import (
+	"context"
+	"errors"
+	"net"
+
+	"Perion_Assignment/internal/concurrency"
+	"Perion_Assignment/internal/fetcher"
+	"Perion_Assignment/internal/models"
+)
+
+// fetchLimited fetches the domain under the fetch limiter, reporting how the fetch went
+func (s *Service) fetchLimited(ctx context.Context, domain string) (*fetcher.Response, error) {
+	if s.fetchLimiter == nil {
+		return s.fetch(ctx, domain)
+	}
+
+	release, err := s.fetchLimiter.Acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := s.fetch(ctx, domain)
+	release(fetchOutcome(ctx, err))
+	return response, err
+}
+
+// fetchOutcome tells the limiter whether a fetch is a sign of overload
+// Timeouts are; cancellations by the caller say nothing about the load. Other failures,
+// like a missing ads.txt, completed and their latency counts like a success.
+func fetchOutcome(ctx context.Context, err error) concurrency.Outcome {
+	var netErr net.Error
+
+	switch {
+	case err == nil:
+		return concurrency.OutcomeSuccess
+	case errors.Is(err, context.Canceled) && ctx.Err() != nil:
+		return concurrency.OutcomeIgnored
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, models.ErrFetchTimeout), errors.As(err, &netErr) && netErr.Timeout():
+		return concurrency.OutcomeDropped
+	default:
+		return concurrency.OutcomeSuccess
+	}
+}
@@ -0,0 +1,97 @@
+package domainAnalysis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"Perion_Assignment/internal/concurrency"
+	"Perion_Assignment/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingLimiter admits or sheds every fetch and records the reported outcomes
+type recordingLimiter struct {
+	err      error
+	outcomes []concurrency.Outcome
+}
+
+func (l *recordingLimiter) Acquire(ctx context.Context) (concurrency.Release, error) {
+	if l.err != nil {
+		return nil, l.err
+	}
+	return func(outcome concurrency.Outcome) {
+		l.outcomes = append(l.outcomes, outcome)
+	}, nil
+}
+
+func (l *recordingLimiter) Saturated() bool           { return l.err != nil }
+func (l *recordingLimiter) RetryAfter() time.Duration { return time.Second }
+
+func TestFetchOutcome(t *testing.T) {
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		err      error
+		expected concurrency.Outcome
+	}{
+		{name: "success", ctx: context.Background(), expected: concurrency.OutcomeSuccess},
+		{name: "not found", ctx: context.Background(), err: fmt.Errorf("%w: HTTP 404", models.ErrDomainNotFound), expected: concurrency.OutcomeSuccess},
+		{name: "fetch timeout", ctx: context.Background(), err: fmt.Errorf("%w: deadline", models.ErrFetchTimeout), expected: concurrency.OutcomeDropped},
+		{name: "client timeout", ctx: context.Background(), err: fmt.Errorf("failed to fetch ads.txt: %w", timeoutError{}), expected: concurrency.OutcomeDropped},
+		{name: "caller cancelled", ctx: cancelled, err: fmt.Errorf("failed to fetch ads.txt: %w", context.Canceled), expected: concurrency.OutcomeIgnored},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, fetchOutcome(tt.ctx, tt.err))
+		})
+	}
+}
+
+func TestService_AnalyzeDomain_ReportsFetchOutcome(t *testing.T) {
+	// Arrange
+	limiter := &recordingLimiter{}
+	service, mockParser, mockFetcher, mockCache := newCoalescingTestService(WithFetchLimiter(limiter))
+
+	mockCache.On("Get", mock.Anything, mock.Anything).Return(nil, errors.New("cache miss"))
+	mockFetcher.On("Fetch", mock.Anything, "cnn.com").Return("", nil)
+	mockFetcher.On("Fetch", mock.Anything, "slow.com").Return("", fmt.Errorf("%w: deadline", models.ErrFetchTimeout))
+	mockParser.On("Parse", "").Return([]models.AdsTxtEntry{}, nil)
+	mockParser.On("CountAdvertisers", []models.AdsTxtEntry{}).Return(map[string]int{})
+	mockCache.On("Set", mock.Anything, "cnn.com", mock.Anything, time.Duration(0)).Return(nil)
+
+	// Act
+	_, err := service.AnalyzeDomain(context.Background(), "cnn.com")
+	require.NoError(t, err)
+	_, err = service.AnalyzeDomain(context.Background(), "slow.com")
+
+	// Assert
+	assert.ErrorIs(t, err, models.ErrFetchTimeout)
+	assert.Equal(t, []concurrency.Outcome{concurrency.OutcomeSuccess, concurrency.OutcomeDropped}, limiter.outcomes)
+}
+
+func TestService_AnalyzeDomain_ShedWhenOverloaded(t *testing.T) {
+	// Arrange
+	limiter := &recordingLimiter{err: models.ErrOverloaded}
+	service, _, mockFetcher, mockCache := newCoalescingTestService(WithFetchLimiter(limiter), WithNegativeCache(negativeCacheTTLs))
+
+	domain := "cnn.com"
+	mockCache.On("Get", mock.Anything, domain).Return(nil, errors.New("cache miss"))
+
+	// Act
+	_, err := service.AnalyzeDomain(context.Background(), domain)
+
+	// Assert
+	assert.ErrorIs(t, err, models.ErrOverloaded)
+	mockFetcher.AssertNotCalled(t, "Fetch", mock.Anything, mock.Anything)
+	mockCache.AssertNotCalled(t, "SetFailure", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
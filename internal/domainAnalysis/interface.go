@@ -10,5 +10,20 @@ import (
 // External packages should use this interface, not the concrete implementations
 type AnalysisService interface {
 	AnalyzeDomain(ctx context.Context, domain string) (*models.DomainAnalysis, error)
+	AnalyzeDomainWithOptions(ctx context.Context, domain string, opts models.AnalysisOptions) (*models.DomainAnalysis, error)
 	AnalyzeDomains(ctx context.Context, domains []string) (*models.BatchAnalysisResponse, error)
-}
\ No newline at end of file
+	AnalyzeDomainsWithOptions(ctx context.Context, domains []string, opts models.AnalysisOptions) (*models.BatchAnalysisResponse, error)
+	AnalyzeDomainsStream(ctx context.Context, domains []string, opts models.AnalysisOptions, onResult func(models.DomainResult)) (*models.BatchAnalysisResponse, error)
+}
+
+// OptionsValidator is implemented by analysis services that can check analysis options
+// before doing any work, e.g. so rejected requests don't use an API key's quota
+type OptionsValidator interface {
+	ValidateOptions(opts models.AnalysisOptions) error
+}
+
+// CacheChecker is implemented by analysis services that can tell in advance how many
+// domains they would have to fetch, e.g. to charge rate limits for cache misses only
+type CacheChecker interface {
+	CountCacheMisses(ctx context.Context, domains []string, opts models.AnalysisOptions) int
+}
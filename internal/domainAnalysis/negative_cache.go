@@ -0,0 +1,61 @@
+package domainAnalysis
+
+import (
+	"context"
+	"errors"
+	"net"
+	"time"
+
+	"Perion_Assignment/internal/models"
+)
+
+// classifyFailure returns the negative cache class of a failed lookup, or "" if it should not be cached
+func classifyFailure(err error) string {
+	var dnsErr *net.DNSError
+	var netErr net.Error
+
+	switch {
+	case errors.Is(err, models.ErrDomainNotFound):
+		return models.FailureClassNotFound
+	case errors.Is(err, models.ErrInvalidAdsTxtFormat):
+		return models.FailureClassParse
+	case errors.As(err, &dnsErr):
+		return models.FailureClassDNS
+	case errors.Is(err, models.ErrFetchTimeout), errors.As(err, &netErr) && netErr.Timeout():
+		return models.FailureClassTimeout
+	default:
+		return ""
+	}
+}
+
+// cacheFailure stores a failed lookup in the negative cache if its class has a TTL
+func (s *Service) cacheFailure(ctx context.Context, domain string, err error) {
+	// A caller that gave up says nothing about the domain itself
+	if ctx.Err() != nil {
+		return
+	}
+
+	// Already a cached outcome
+	var cached *models.LookupFailure
+	if errors.As(err, &cached) {
+		return
+	}
+
+	class := classifyFailure(err)
+	ttl := s.failureTTLs[class]
+	if class == "" || ttl <= 0 {
+		return
+	}
+
+	failure := &models.LookupFailure{
+		Class:    class,
+		Message:  err.Error(),
+		FailedAt: time.Now().UTC(),
+	}
+	if err := s.domainCache.SetFailure(ctx, domain, failure, ttl); err != nil {
+		s.logger.LogError(ctx, "cache_set", domain, "Failed to cache failed lookup", err, models.LogSeverityLow, map[string]interface{}{
+			"failure_class": class,
+		})
+		// Don't fail the request if caching fails
+	}
+}
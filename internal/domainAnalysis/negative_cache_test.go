@@ -0,0 +1,163 @@
+package domainAnalysis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"Perion_Assignment/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// negativeCacheTTLs enables negative caching for every failure class in tests
+var negativeCacheTTLs = map[string]time.Duration{
+	models.FailureClassNotFound: 10 * time.Minute,
+	models.FailureClassDNS:      5 * time.Minute,
+	models.FailureClassParse:    10 * time.Minute,
+	models.FailureClassTimeout:  time.Minute,
+}
+
+// timeoutError is a net.Error reporting a timeout, as returned by an HTTP client timeout
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestClassifyFailure(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected string
+	}{
+		{name: "not found", err: fmt.Errorf("%w: HTTP 404", models.ErrDomainNotFound), expected: models.FailureClassNotFound},
+		{name: "parse", err: fmt.Errorf("%w: empty content", models.ErrInvalidAdsTxtFormat), expected: models.FailureClassParse},
+		{name: "dns", err: fmt.Errorf("failed to fetch ads.txt: %w", &net.DNSError{Err: "no such host", Name: "nope.invalid", IsNotFound: true}), expected: models.FailureClassDNS},
+		{name: "fetch timeout", err: fmt.Errorf("%w: deadline", models.ErrFetchTimeout), expected: models.FailureClassTimeout},
+		{name: "client timeout", err: fmt.Errorf("failed to fetch ads.txt: %w", timeoutError{}), expected: models.FailureClassTimeout},
+		{name: "server error", err: errors.New("unexpected HTTP status: 503"), expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, classifyFailure(models.NewDomainError("example.com", "failed", tt.err)))
+		})
+	}
+}
+
+func TestService_AnalyzeDomain_CachesFailure(t *testing.T) {
+	// Arrange
+	service, _, mockFetcher, mockCache := newCoalescingTestService(WithNegativeCache(negativeCacheTTLs))
+
+	domain := "missing.com"
+	fetchErr := fmt.Errorf("%w: HTTP 404", models.ErrDomainNotFound)
+	mockCache.On("Get", mock.Anything, domain).Return(nil, errors.New("cache miss"))
+	mockFetcher.On("Fetch", mock.Anything, domain).Return("", fetchErr)
+	mockCache.On("SetFailure", mock.Anything, domain, mock.MatchedBy(func(failure *models.LookupFailure) bool {
+		return failure.Class == models.FailureClassNotFound && failure.Message == "domain missing.com: failed to fetch ads.txt: "+fetchErr.Error()
+	}), 10*time.Minute).Return(nil)
+
+	// Act
+	_, err := service.AnalyzeDomain(context.Background(), domain)
+
+	// Assert
+	assert.ErrorIs(t, err, models.ErrDomainNotFound)
+	mockCache.AssertExpectations(t)
+}
+
+func TestService_AnalyzeDomain_UnclassifiedFailureNotCached(t *testing.T) {
+	// Arrange
+	service, _, mockFetcher, mockCache := newCoalescingTestService(WithNegativeCache(negativeCacheTTLs))
+
+	domain := "flaky.com"
+	mockCache.On("Get", mock.Anything, domain).Return(nil, errors.New("cache miss"))
+	mockFetcher.On("Fetch", mock.Anything, domain).Return("", errors.New("unexpected HTTP status: 503"))
+
+	// Act
+	_, err := service.AnalyzeDomain(context.Background(), domain)
+
+	// Assert
+	assert.Error(t, err)
+	mockCache.AssertNotCalled(t, "SetFailure", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestService_AnalyzeDomain_CallerCancellationNotCached(t *testing.T) {
+	// Arrange
+	service, _, mockFetcher, mockCache := newCoalescingTestService(WithNegativeCache(negativeCacheTTLs))
+
+	domain := "slow.com"
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	mockCache.On("Get", mock.Anything, domain).Return(nil, errors.New("cache miss"))
+	mockFetcher.On("Fetch", mock.Anything, domain).Run(func(args mock.Arguments) {
+		<-args.Get(0).(context.Context).Done()
+	}).Return("", fmt.Errorf("%w: context deadline exceeded", models.ErrFetchTimeout))
+
+	// Act
+	_, err := service.AnalyzeDomain(ctx, domain)
+
+	// Assert
+	assert.Error(t, err)
+	mockCache.AssertNotCalled(t, "SetFailure", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestService_AnalyzeDomain_ServesCachedFailure(t *testing.T) {
+	// Arrange
+	service, _, mockFetcher, mockCache := newCoalescingTestService(WithNegativeCache(negativeCacheTTLs))
+
+	domain := "missing.com"
+	failure := &models.LookupFailure{Class: models.FailureClassNotFound, Message: "domain missing.com: failed to fetch ads.txt: ads.txt not found for domain: HTTP 404", FailedAt: time.Now().UTC()}
+	mockCache.On("Get", mock.Anything, domain).Return(nil, failure)
+
+	// Act
+	_, err := service.AnalyzeDomain(context.Background(), domain)
+
+	// Assert
+	assert.ErrorIs(t, err, models.ErrDomainNotFound)
+	assert.Equal(t, failure.Message, err.Error())
+	mockFetcher.AssertNotCalled(t, "Fetch", mock.Anything, mock.Anything)
+
+	result := models.NewDomainResult(domain, nil, err)
+	assert.True(t, result.Cached)
+	assert.False(t, result.Success)
+}
+
+func TestService_AnalyzeDomainWithOptions_CachedFailureBypassed(t *testing.T) {
+	tests := []struct {
+		name string
+		opts models.AnalysisOptions
+	}{
+		{name: "force refresh", opts: models.AnalysisOptions{ForceRefresh: true}},
+		{name: "older than max_age", opts: models.AnalysisOptions{MaxAge: 60}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			service, mockParser, mockFetcher, mockCache := newCoalescingTestService(WithNegativeCache(negativeCacheTTLs))
+
+			domain := "recovered.com"
+			failure := &models.LookupFailure{Class: models.FailureClassNotFound, Message: "not found", FailedAt: time.Now().UTC().Add(-5 * time.Minute)}
+			mockCache.On("Get", mock.Anything, domain).Return(nil, failure).Maybe()
+			mockFetcher.On("Fetch", mock.Anything, domain).Return("", nil)
+			mockParser.On("Parse", "").Return([]models.AdsTxtEntry{}, nil)
+			mockParser.On("CountAdvertisers", []models.AdsTxtEntry{}).Return(map[string]int{})
+			mockCache.On("Set", mock.Anything, domain, mock.Anything, time.Duration(0)).Return(nil)
+
+			// Act
+			result, err := service.AnalyzeDomainWithOptions(context.Background(), domain, tt.opts)
+
+			// Assert
+			require.NoError(t, err)
+			assert.False(t, result.Cached)
+			mockFetcher.AssertNumberOfCalls(t, "Fetch", 1)
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package domainAnalysis
+
+import (
+	"fmt"
+	"time"
+
+	"Perion_Assignment/internal/concurrency"
+	"Perion_Assignment/internal/lock"
+	"Perion_Assignment/internal/models"
+)
+
+// Option configures optional Service behaviour
+type Option func(*Service)
+
+// Limits bounds the per-request analysis options accepted by the service
+type Limits struct {
+	DefaultTimeout time.Duration // Per-domain timeout in batches when the request sets none
+	MaxTimeout     time.Duration // Upper bound for timeout_ms
+	MaxCacheTTL    time.Duration // Upper bound for cache_ttl
+}
+
+// DefaultLimits returns the limits used when none are configured
+func DefaultLimits() Limits {
+	return Limits{
+		DefaultTimeout: 30 * time.Second,
+		MaxTimeout:     60 * time.Second,
+		MaxCacheTTL:    24 * time.Hour,
+	}
+}
+
+// WithLimits sets the limits used to validate per-request analysis options
+func WithLimits(limits Limits) Option {
+	return func(s *Service) {
+		s.limits = limits
+	}
+}
+
+// WithLocker enables the cross-instance lock so only one replica fetches a domain at a time
+// Replicas that find the lock held wait for the holder's result in the shared cache
+func WithLocker(locker lock.Service, ttl time.Duration) Option {
+	return func(s *Service) {
+		s.locker = locker
+		if ttl > 0 {
+			s.lockTTL = ttl
+		}
+	}
+}
+
+// WithNegativeCache caches failed lookups with a TTL per failure class (models.FailureClass*)
+// Classes without a positive TTL are not cached
+func WithNegativeCache(ttls map[string]time.Duration) Option {
+	return func(s *Service) {
+		s.failureTTLs = ttls
+	}
+}
+
+// WithFetchLimiter runs every fetch under the limiter, on top of the per-batch concurrency
+// Fetches that can't get a slot fail with models.ErrOverloaded instead of piling up
+func WithFetchLimiter(limiter concurrency.Service) Option {
+	return func(s *Service) {
+		s.fetchLimiter = limiter
+	}
+}
+
+// ValidateOptions checks the options against the configured limits
+func (s *Service) ValidateOptions(opts models.AnalysisOptions) error {
+	if opts.MaxAge < 0 {
+		return fmt.Errorf("%w: max_age must not be negative", models.ErrInvalidAnalysisOptions)
+	}
+
+	if opts.TimeoutMs < 0 {
+		return fmt.Errorf("%w: timeout_ms must not be negative", models.ErrInvalidAnalysisOptions)
+	}
+	if timeout := time.Duration(opts.TimeoutMs) * time.Millisecond; s.limits.MaxTimeout > 0 && timeout > s.limits.MaxTimeout {
+		return fmt.Errorf("%w: timeout_ms must not exceed %d", models.ErrInvalidAnalysisOptions, s.limits.MaxTimeout.Milliseconds())
+	}
+
+	if opts.CacheTTL < 0 {
+		return fmt.Errorf("%w: cache_ttl must not be negative", models.ErrInvalidAnalysisOptions)
+	}
+	if ttl := time.Duration(opts.CacheTTL) * time.Second; s.limits.MaxCacheTTL > 0 && ttl > s.limits.MaxCacheTTL {
+		return fmt.Errorf("%w: cache_ttl must not exceed %d", models.ErrInvalidAnalysisOptions, int64(s.limits.MaxCacheTTL.Seconds()))
+	}
+
+	return nil
+}
+
+// domainTimeout returns the per-domain timeout for batch analyses
+func (s *Service) domainTimeout(opts models.AnalysisOptions) time.Duration {
+	if opts.TimeoutMs > 0 {
+		return time.Duration(opts.TimeoutMs) * time.Millisecond
+	}
+	if s.limits.DefaultTimeout > 0 {
+		return s.limits.DefaultTimeout
+	}
+	return DefaultLimits().DefaultTimeout
+}
+
+// cacheTTL returns the TTL for storing an analysis (0 means the domain cache default)
+func cacheTTL(opts models.AnalysisOptions) time.Duration {
+	return time.Duration(opts.CacheTTL) * time.Second
+}
+
+// isFailureFreshEnough reports whether a cached failure satisfies the max_age option
+func isFailureFreshEnough(failure *models.LookupFailure, opts models.AnalysisOptions) bool {
+	if opts.MaxAge <= 0 {
+		return true
+	}
+	return time.Since(failure.FailedAt) <= time.Duration(opts.MaxAge)*time.Second
+}
+
+// isFreshEnough reports whether a cached analysis satisfies the max_age option
+func isFreshEnough(analysis *models.DomainAnalysis, opts models.AnalysisOptions) bool {
+	if opts.MaxAge <= 0 {
+		return true
+	}
+	return time.Since(analysis.Timestamp) <= time.Duration(opts.MaxAge)*time.Second
+}
@@ -0,0 +1,136 @@
+package domainAnalysis
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"Perion_Assignment/internal/fetcher"
+	"Perion_Assignment/internal/models"
+)
+
+// originTTLPolicy derives the cache TTL of an analysis from the publisher's caching headers
+type originTTLPolicy struct {
+	defaultTTL time.Duration // Used when the response has no usable caching headers
+	min        time.Duration
+	max        time.Duration
+}
+
+// WithOriginTTL caches each analysis for as long as the ads.txt response's Cache-Control
+// (s-maxage or max-age, less Age) or Expires header allows, clamped to [min, max]
+// Responses without caching headers are cached for defaultTTL. A cache_ttl set on the
+// request still takes precedence.
+func WithOriginTTL(defaultTTL, min, max time.Duration) Option {
+	return func(s *Service) {
+		s.originTTL = &originTTLPolicy{
+			defaultTTL: defaultTTL,
+			min:        min,
+			max:        max,
+		}
+	}
+}
+
+// analysisTTL returns the TTL to cache a fresh analysis with and where it came from
+// A zero TTL leaves the choice to the domain cache's default.
+func (s *Service) analysisTTL(opts models.AnalysisOptions, response *fetcher.Response) (time.Duration, string) {
+	if opts.CacheTTL > 0 {
+		return cacheTTL(opts), models.TTLSourceRequest
+	}
+	if s.originTTL == nil {
+		return 0, models.TTLSourceDefault
+	}
+
+	ttl, source, ok := originTTL(response.Header, response.FetchedAt)
+	if !ok {
+		return s.originTTL.defaultTTL, models.TTLSourceDefault
+	}
+	return s.originTTL.clamp(ttl), source
+}
+
+// clamp bounds a TTL taken from the origin; a zero bound is not enforced
+func (p *originTTLPolicy) clamp(ttl time.Duration) time.Duration {
+	if p.max > 0 && ttl > p.max {
+		ttl = p.max
+	}
+	if ttl < p.min {
+		ttl = p.min
+	}
+	return ttl
+}
+
+// originTTL reads the freshness lifetime of a response from its headers
+// Cache-Control takes precedence over Expires, as in RFC 9111. no-store and no-cache
+// yield a zero TTL, as does an Expires date that is invalid or in the past.
+func originTTL(header http.Header, fetchedAt time.Time) (time.Duration, string, bool) {
+	if header == nil {
+		return 0, "", false
+	}
+
+	if maxAge, ok := cacheControlMaxAge(header.Get("Cache-Control")); ok {
+		if age, err := strconv.ParseInt(strings.TrimSpace(header.Get("Age")), 10, 64); err == nil && age > 0 {
+			maxAge -= time.Duration(age) * time.Second
+		}
+		return nonNegative(maxAge), models.TTLSourceCacheControl, true
+	}
+
+	expires := header.Get("Expires")
+	if expires == "" {
+		return 0, "", false
+	}
+	expiresAt, err := http.ParseTime(expires)
+	if err != nil {
+		return 0, models.TTLSourceExpires, true
+	}
+
+	// Compare against the origin's clock when it sent one, so clock skew doesn't matter
+	now := fetchedAt
+	if date, err := http.ParseTime(header.Get("Date")); err == nil {
+		now = date
+	}
+	return nonNegative(expiresAt.Sub(now)), models.TTLSourceExpires, true
+}
+
+// cacheControlMaxAge returns the lifetime set by a Cache-Control header
+// s-maxage applies to shared caches like ours and wins over max-age.
+func cacheControlMaxAge(cacheControl string) (time.Duration, bool) {
+	var maxAge, sharedMaxAge time.Duration
+	var hasMaxAge, hasSharedMaxAge, noStore bool
+
+	for _, directive := range strings.Split(cacheControl, ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(directive), "=")
+		seconds, err := strconv.ParseInt(strings.Trim(strings.TrimSpace(value), `"`), 10, 64)
+
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "no-store", "no-cache":
+			noStore = true
+		case "max-age":
+			if err == nil {
+				maxAge, hasMaxAge = time.Duration(seconds)*time.Second, true
+			}
+		case "s-maxage":
+			if err == nil {
+				sharedMaxAge, hasSharedMaxAge = time.Duration(seconds)*time.Second, true
+			}
+		}
+	}
+
+	switch {
+	case noStore:
+		return 0, true
+	case hasSharedMaxAge:
+		return sharedMaxAge, true
+	case hasMaxAge:
+		return maxAge, true
+	default:
+		return 0, false
+	}
+}
+
+// nonNegative returns d, or zero when d is negative
+func nonNegative(d time.Duration) time.Duration {
+	if d < 0 {
+		return 0
+	}
+	return d
+}
@@ -0,0 +1,139 @@
+package domainAnalysis
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"Perion_Assignment/internal/fetcher"
+	mocks2 "Perion_Assignment/internal/mocks"
+	"Perion_Assignment/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOriginTTL(t *testing.T) {
+	fetchedAt := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		header   http.Header
+		expected time.Duration
+		source   string
+		ok       bool
+	}{
+		{name: "no headers", header: http.Header{}, ok: false},
+		{name: "nil header", header: nil, ok: false},
+		{name: "max-age", header: http.Header{"Cache-Control": {"public, max-age=3600"}}, expected: time.Hour, source: models.TTLSourceCacheControl, ok: true},
+		{name: "s-maxage wins", header: http.Header{"Cache-Control": {"max-age=60, s-maxage=7200"}}, expected: 2 * time.Hour, source: models.TTLSourceCacheControl, ok: true},
+		{name: "age is subtracted", header: http.Header{"Cache-Control": {"max-age=3600"}, "Age": {"600"}}, expected: 50 * time.Minute, source: models.TTLSourceCacheControl, ok: true},
+		{name: "age beyond max-age", header: http.Header{"Cache-Control": {"max-age=60"}, "Age": {"600"}}, expected: 0, source: models.TTLSourceCacheControl, ok: true},
+		{name: "no-store", header: http.Header{"Cache-Control": {"no-store"}}, expected: 0, source: models.TTLSourceCacheControl, ok: true},
+		{name: "no-cache beats max-age", header: http.Header{"Cache-Control": {"no-cache, max-age=3600"}}, expected: 0, source: models.TTLSourceCacheControl, ok: true},
+		{name: "cache-control without lifetime", header: http.Header{"Cache-Control": {"public"}}, ok: false},
+		{name: "cache-control beats expires", header: http.Header{"Cache-Control": {"max-age=60"}, "Expires": {"Mon, 01 Jan 2024 14:00:00 GMT"}}, expected: time.Minute, source: models.TTLSourceCacheControl, ok: true},
+		{name: "expires against fetch time", header: http.Header{"Expires": {"Mon, 01 Jan 2024 14:00:00 GMT"}}, expected: 2 * time.Hour, source: models.TTLSourceExpires, ok: true},
+		{name: "expires against origin date", header: http.Header{"Expires": {"Mon, 01 Jan 2024 14:00:00 GMT"}, "Date": {"Mon, 01 Jan 2024 13:30:00 GMT"}}, expected: 30 * time.Minute, source: models.TTLSourceExpires, ok: true},
+		{name: "expires in the past", header: http.Header{"Expires": {"Mon, 01 Jan 2024 10:00:00 GMT"}}, expected: 0, source: models.TTLSourceExpires, ok: true},
+		{name: "invalid expires", header: http.Header{"Expires": {"0"}}, expected: 0, source: models.TTLSourceExpires, ok: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ttl, source, ok := originTTL(tt.header, fetchedAt)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.expected, ttl)
+			assert.Equal(t, tt.source, source)
+		})
+	}
+}
+
+func TestService_AnalysisTTL(t *testing.T) {
+	withMaxAge := func(maxAge string) *fetcher.Response {
+		return &fetcher.Response{Header: http.Header{"Cache-Control": {"max-age=" + maxAge}}, FetchedAt: time.Now()}
+	}
+	withoutHeaders := &fetcher.Response{FetchedAt: time.Now()}
+
+	policy := WithOriginTTL(time.Hour, 5*time.Minute, 24*time.Hour)
+	tests := []struct {
+		name     string
+		opts     []Option
+		request  models.AnalysisOptions
+		response *fetcher.Response
+		expected time.Duration
+		source   string
+	}{
+		{name: "origin headers ignored without policy", response: withMaxAge("600"), expected: 0, source: models.TTLSourceDefault},
+		{name: "request ttl without policy", request: models.AnalysisOptions{CacheTTL: 120}, response: withMaxAge("600"), expected: 2 * time.Minute, source: models.TTLSourceRequest},
+		{name: "request ttl wins over origin", opts: []Option{policy}, request: models.AnalysisOptions{CacheTTL: 120}, response: withMaxAge("600"), expected: 2 * time.Minute, source: models.TTLSourceRequest},
+		{name: "origin max-age", opts: []Option{policy}, response: withMaxAge("7200"), expected: 2 * time.Hour, source: models.TTLSourceCacheControl},
+		{name: "clamped to min", opts: []Option{policy}, response: withMaxAge("0"), expected: 5 * time.Minute, source: models.TTLSourceCacheControl},
+		{name: "clamped to max", opts: []Option{policy}, response: withMaxAge("31536000"), expected: 24 * time.Hour, source: models.TTLSourceCacheControl},
+		{name: "default without headers", opts: []Option{policy}, response: withoutHeaders, expected: time.Hour, source: models.TTLSourceDefault},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := NewService(&mocks2.MockParser{}, &mocks2.MockFetcher{}, &mocks2.MockDomainCache{}, &mocks2.MockLogger{}, 1, tt.opts...).(*Service)
+
+			ttl, source := service.analysisTTL(tt.request, tt.response)
+			assert.Equal(t, tt.expected, ttl)
+			assert.Equal(t, tt.source, source)
+		})
+	}
+}
+
+func TestService_AnalyzeDomain_CachesWithOriginTTL(t *testing.T) {
+	mockParser := &mocks2.MockParser{}
+	mockFetcher := &mocks2.MockResponseFetcher{}
+	mockCache := &mocks2.MockDomainCache{}
+	mockLogger := mocks2.NewAnyLogger()
+
+	service := NewService(mockParser, mockFetcher, mockCache, mockLogger, 1, WithOriginTTL(time.Hour, time.Minute, 24*time.Hour))
+	ctx := context.Background()
+
+	mockCache.On("Get", mock.Anything, "cnn.com").Return(nil, models.ErrCacheUnavailable)
+	mockFetcher.On("FetchResponse", mock.Anything, "cnn.com").Return(&fetcher.Response{
+		Content:   "google.com, pub-1, DIRECT",
+		Header:    http.Header{"Cache-Control": {"max-age=1800"}},
+		FetchedAt: time.Now(),
+	}, nil)
+	mockParser.On("Parse", "google.com, pub-1, DIRECT").Return([]models.AdsTxtEntry{{ExchangeDomain: "google.com", PublisherID: "pub-1", AccountType: "DIRECT"}}, nil)
+	mockParser.On("CountAdvertisers", mock.Anything).Return(map[string]int{"google.com": 1})
+	mockCache.On("Set", mock.Anything, "cnn.com", mock.AnythingOfType("*models.DomainAnalysis"), 30*time.Minute).Return(nil)
+
+	analysis, err := service.AnalyzeDomain(ctx, "cnn.com")
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(1800), analysis.CacheTTLSeconds)
+	assert.Equal(t, models.TTLSourceCacheControl, analysis.CacheTTLSource)
+	mockCache.AssertExpectations(t)
+	mockFetcher.AssertNotCalled(t, "Fetch", mock.Anything, mock.Anything)
+}
+
+func TestService_AnalyzeDomain_OriginForbidsCaching(t *testing.T) {
+	mockParser := &mocks2.MockParser{}
+	mockFetcher := &mocks2.MockResponseFetcher{}
+	mockCache := &mocks2.MockDomainCache{}
+
+	// No minimum TTL: no-store is honoured by not caching at all
+	service := NewService(mockParser, mockFetcher, mockCache, mocks2.NewAnyLogger(), 1, WithOriginTTL(time.Hour, 0, 0))
+
+	mockCache.On("Get", mock.Anything, "cnn.com").Return(nil, models.ErrCacheUnavailable)
+	mockFetcher.On("FetchResponse", mock.Anything, "cnn.com").Return(&fetcher.Response{
+		Content: "google.com, pub-1, DIRECT",
+		Header:  http.Header{"Cache-Control": {"no-store"}},
+	}, nil)
+	mockParser.On("Parse", mock.Anything).Return([]models.AdsTxtEntry{{ExchangeDomain: "google.com", PublisherID: "pub-1", AccountType: "DIRECT"}}, nil)
+	mockParser.On("CountAdvertisers", mock.Anything).Return(map[string]int{"google.com": 1})
+
+	analysis, err := service.AnalyzeDomain(context.Background(), "cnn.com")
+
+	require.NoError(t, err)
+	assert.Zero(t, analysis.CacheTTLSeconds)
+	assert.Equal(t, models.TTLSourceCacheControl, analysis.CacheTTLSource)
+	mockCache.AssertNotCalled(t, "Set", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
@@ -6,12 +6,16 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"regexp"
 	"strings"
 	"time"
 
 	"Perion_Assignment/internal/models"
 )
 
+// hostNamePattern matches DNS host names, including IPv4 addresses, with an optional trailing dot
+var hostNamePattern = regexp.MustCompile(`^[a-zA-Z0-9]([a-zA-Z0-9\-]{0,61}[a-zA-Z0-9])?(\.[a-zA-Z0-9]([a-zA-Z0-9\-]{0,61}[a-zA-Z0-9])?)*\.?$`)
+
 // HTTPFetcher implements Service using HTTP requests
 type HTTPFetcher struct {
 	client  *http.Client
@@ -42,8 +46,17 @@ func newHTTPFetcher(timeout time.Duration) *HTTPFetcher {
 
 // Fetch retrieves the ads.txt file for the given domain
 func (f *HTTPFetcher) Fetch(ctx context.Context, domain string) (string, error) {
-	if domain == "" {
-		return "", models.ErrInvalidDomain
+	response, err := f.FetchResponse(ctx, domain)
+	if err != nil {
+		return "", err
+	}
+	return response.Content, nil
+}
+
+// FetchResponse retrieves the ads.txt file for the given domain with its response headers
+func (f *HTTPFetcher) FetchResponse(ctx context.Context, domain string) (*Response, error) {
+	if err := ValidateDomain(domain); err != nil {
+		return nil, err
 	}
 
 	// Normalize domain
@@ -55,7 +68,7 @@ func (f *HTTPFetcher) Fetch(ctx context.Context, domain string) (string, error)
 	// Create request with context
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, adsTxtURL, nil)
 	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
+		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 	
 	// Set appropriate headers
@@ -67,32 +80,55 @@ func (f *HTTPFetcher) Fetch(ctx context.Context, domain string) (string, error)
 	if err != nil {
 		// Check for timeout
 		if ctx.Err() == context.DeadlineExceeded {
-			return "", fmt.Errorf("%w: %v", models.ErrFetchTimeout, err)
+			return nil, fmt.Errorf("%w: %v", models.ErrFetchTimeout, err)
 		}
-		return "", fmt.Errorf("failed to fetch ads.txt: %w", err)
+		return nil, fmt.Errorf("failed to fetch ads.txt: %w", err)
 	}
 	defer resp.Body.Close()
 	
 	// Check response status
 	if resp.StatusCode == http.StatusNotFound {
-		return "", fmt.Errorf("%w: HTTP %d", models.ErrDomainNotFound, resp.StatusCode)
+		return nil, fmt.Errorf("%w: HTTP %d", models.ErrDomainNotFound, resp.StatusCode)
 	}
 	
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("unexpected HTTP status: %d %s", resp.StatusCode, resp.Status)
+		return nil, fmt.Errorf("unexpected HTTP status: %d %s", resp.StatusCode, resp.Status)
 	}
 	
 	// Read response body with size limit
 	body, err := f.readBodyWithLimit(resp.Body, 1024*1024) // 1MB limit
 	if err != nil {
-		return "", fmt.Errorf("failed to read response body: %w", err)
+		return nil, fmt.Errorf("failed to read response body: %w", err)
 	}
 	
-	return string(body), nil
+	return &Response{
+		Content:   string(body),
+		Header:    resp.Header,
+		FetchedAt: time.Now(),
+	}, nil
+}
+
+// ValidateDomain checks that a domain, given as a host or URL like the fetcher accepts it,
+// names a valid host; it returns models.ErrInvalidDomain otherwise
+func ValidateDomain(domain string) error {
+	if domain == "" {
+		return models.ErrInvalidDomain
+	}
+
+	host := hostName(domain)
+	if host == "" || len(host) > 253 || !hostNamePattern.MatchString(host) {
+		return models.ErrInvalidDomain
+	}
+	return nil
 }
 
 // normalizeDomain removes protocol, port, and path from domain
 func (f *HTTPFetcher) normalizeDomain(domain string) string {
+	return hostName(domain)
+}
+
+// hostName returns the host of a domain given with or without protocol, port and path
+func hostName(domain string) string {
 	// Remove protocol if present
 	domain = strings.TrimPrefix(domain, "http://")
 	domain = strings.TrimPrefix(domain, "https://")
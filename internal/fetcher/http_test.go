@@ -83,6 +83,24 @@ func TestHTTPFetcher_Fetch_Success(t *testing.T) {
 	assert.Contains(t, content, "example.com, pub-789, RESELLER")
 }
 
+func TestHTTPFetcher_FetchResponse_ReturnsHeaders(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "public, max-age=3600")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("google.com, pub-123456, DIRECT"))
+	}))
+	defer server.Close()
+
+	fetcher := createTLSFetcher(5*time.Second, server)
+
+	response, err := fetcher.FetchResponse(context.Background(), "example.com")
+
+	require.NoError(t, err)
+	assert.Equal(t, "google.com, pub-123456, DIRECT", response.Content)
+	assert.Equal(t, "public, max-age=3600", response.Header.Get("Cache-Control"))
+	assert.WithinDuration(t, time.Now(), response.FetchedAt, time.Second)
+}
+
 func TestHTTPFetcher_Fetch_NotFound(t *testing.T) {
 	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusNotFound)
@@ -239,6 +257,16 @@ func TestHTTPFetcher_NormalizeDomain(t *testing.T) {
 	}
 }
 
+func TestValidateDomain(t *testing.T) {
+	for _, domain := range []string{"example.com", "https://www.example.com/ads.txt", "example.com:8080", "example.com.", "127.0.0.1"} {
+		assert.NoError(t, ValidateDomain(domain), domain)
+	}
+
+	for _, domain := range []string{"", "not a domain", "exa_mple.com", "-example.com", "https://", strings.Repeat("a", 254)} {
+		assert.ErrorIs(t, ValidateDomain(domain), models.ErrInvalidDomain, domain)
+	}
+}
+
 func TestHTTPFetcher_Fetch_Redirect(t *testing.T) {
 	// Create server that redirects once then serves content
 	redirectCount := 0
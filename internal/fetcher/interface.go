@@ -1,9 +1,26 @@
 package fetcher
 
-import "context"
+import (
+	"context"
+	"net/http"
+	"time"
+)
 
 // Service defines the interface for fetching ads.txt files
 // External packages should use this interface, not the concrete implementations
 type Service interface {
 	Fetch(ctx context.Context, domain string) (string, error)
-}
\ No newline at end of file
+}
+
+// ResponseFetcher is implemented by fetchers that can also return the response headers,
+// e.g. so the analysis can honour the publisher's caching headers
+type ResponseFetcher interface {
+	FetchResponse(ctx context.Context, domain string) (*Response, error)
+}
+
+// Response is a fetched ads.txt file together with its HTTP response headers
+type Response struct {
+	Content   string
+	Header    http.Header
+	FetchedAt time.Time
+}
@@ -0,0 +1,102 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"Perion_Assignment/internal/logger"
+	"Perion_Assignment/internal/models"
+)
+
+// GetUsage handles GET /api/usage
+// It reports the tier of the request's API key and the key's usage today.
+func (h *Handler) GetUsage(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	key := apiKeyFromContext(ctx)
+	if key == nil {
+		writeUnauthorizedAPIKey(w, r, "An API key is required")
+		return
+	}
+
+	usage, err := h.apiKeys.Usage(ctx, key)
+	if err != nil {
+		h.logger.LogError(ctx, logger.OpAPIKeyQuota, key.ID, "Failed to read API key usage", err, models.LogSeverityMedium, nil)
+		h.writeErrorResponse(w, r, http.StatusInternalServerError, "failed to read usage", err.Error())
+		return
+	}
+
+	response := models.APIKeyUsageResponse{
+		KeyID: key.ID,
+		Name:  key.Name,
+		Tier:  key.Tier,
+		Usage: *usage,
+	}
+	if quota := int64(key.Tier.DailyDomainQuota); quota > 0 {
+		remaining := max(quota-usage.Domains, 0)
+		response.DomainsRemaining = &remaining
+	}
+
+	if err := h.writeJSONResponse(w, r, http.StatusOK, response); err != nil {
+		h.logger.LogError(ctx, logger.OpAPIKeyQuota, key.ID, "Failed to encode usage response", err, models.LogSeverityLow, nil)
+	}
+}
+
+// maxBatchSize returns the number of domains allowed in a synchronous batch request
+// The tier of the request's API key overrides the server default.
+func (h *Handler) maxBatchSize(r *http.Request) int {
+	if key := apiKeyFromContext(r.Context()); key != nil && key.Tier.MaxBatchSize > 0 {
+		return key.Tier.MaxBatchSize
+	}
+	return maxBatchDomains
+}
+
+// consumeQuota counts domains against the daily quota of the request's API key
+// On failure it writes the error response and returns false. Requests without a key
+// have no quota, and the request proceeds if the usage store is unavailable.
+func (h *Handler) consumeQuota(w http.ResponseWriter, r *http.Request, domains int) bool {
+	ctx := r.Context()
+
+	key := apiKeyFromContext(ctx)
+	if key == nil || h.apiKeys == nil {
+		return true
+	}
+
+	usage, err := h.apiKeys.ConsumeDomains(ctx, key, domains)
+	if errors.Is(err, models.ErrQuotaExceeded) {
+		h.logger.LogError(ctx, logger.OpAPIKeyQuota, key.ID, "Daily domain quota exceeded", err, models.LogSeverityLow, map[string]interface{}{
+			"domains": domains,
+			"quota":   key.Tier.DailyDomainQuota,
+		})
+		w.Header().Set("X-Quota-Limit", strconv.Itoa(key.Tier.DailyDomainQuota))
+		h.writeErrorResponse(w, r, http.StatusTooManyRequests, "quota exceeded",
+			fmt.Sprintf("The %s tier allows %d domains per day", key.Tier.Name, key.Tier.DailyDomainQuota))
+		return false
+	}
+	if err != nil {
+		h.logger.LogError(ctx, logger.OpAPIKeyQuota, key.ID, "Failed to record API key domains", err, models.LogSeverityMedium, nil)
+		return true
+	}
+
+	if key.Tier.DailyDomainQuota > 0 {
+		w.Header().Set("X-Quota-Limit", strconv.Itoa(key.Tier.DailyDomainQuota))
+		w.Header().Set("X-Quota-Remaining", strconv.FormatInt(max(int64(key.Tier.DailyDomainQuota)-usage.Domains, 0), 10))
+	}
+	return true
+}
+
+// refundQuota gives back domains consumed by consumeQuota for work that wasn't started
+func (h *Handler) refundQuota(r *http.Request, domains int) {
+	ctx := r.Context()
+
+	key := apiKeyFromContext(ctx)
+	if key == nil || h.apiKeys == nil {
+		return
+	}
+
+	if err := h.apiKeys.RefundDomains(ctx, key, domains); err != nil {
+		h.logger.LogError(ctx, logger.OpAPIKeyQuota, key.ID, "Failed to refund API key domains", err, models.LogSeverityMedium, nil)
+	}
+}
@@ -0,0 +1,255 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	httpMocks "Perion_Assignment/internal/http/mocks"
+	"Perion_Assignment/internal/mocks"
+	"Perion_Assignment/internal/models"
+	"Perion_Assignment/internal/ratelimit"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+var testAPIKey = &models.APIKey{
+	ID:   "acme",
+	Name: "Acme",
+	Tier: models.APITier{Name: "free", RequestsPerSec: 2, DailyDomainQuota: 10, MaxBatchSize: 3},
+}
+
+// createAPIKeyTestServer creates a test server authenticating requests with the given key service
+func createAPIKeyTestServer(mockAnalysisService *httpMocks.MockAnalysisService, mockAPIKeys *httpMocks.MockAPIKeyService, rateLimiter ratelimit.Service, required bool) *Server {
+	mockLogger := mocks.NewAnyLogger()
+	return NewServer(
+		"localhost:0",
+		NewHandler(mockAnalysisService, mockLogger),
+		mockLogger,
+		rateLimiter,
+		10*time.Second,
+		10*time.Second,
+		WithAPIKeys(mockAPIKeys, required),
+	)
+}
+
+// serveWithKey sends a request with an optional API key
+func serveWithKey(server *Server, method, path, body, apiKey string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+	req.RemoteAddr = "192.168.1.1:12345"
+	if apiKey != "" {
+		req.Header.Set("X-API-Key", apiKey)
+	}
+	w := httptest.NewRecorder()
+	server.server.Handler.ServeHTTP(w, req)
+	return w
+}
+
+func TestAPIKeys_ValidKey(t *testing.T) {
+	mockAnalysis := &httpMocks.MockAnalysisService{}
+	mockAPIKeys := &httpMocks.MockAPIKeyService{}
+	server := createAPIKeyTestServer(mockAnalysis, mockAPIKeys, ratelimit.NewTwoTierRateLimiter(100, 100, 10, 10), false)
+
+	mockAPIKeys.On("Authenticate", mock.Anything, "secret").Return(testAPIKey, nil)
+	mockAPIKeys.On("RecordRequest", mock.Anything, testAPIKey).Return(nil)
+	mockAPIKeys.On("ConsumeDomains", mock.Anything, testAPIKey, 1).Return(&models.APIKeyUsage{Domains: 4}, nil)
+	mockAnalysis.On("AnalyzeDomainWithOptions", mock.Anything, "cnn.com", mock.Anything).Return(&models.DomainAnalysis{Domain: "cnn.com"}, nil)
+
+	w := serveWithKey(server, http.MethodGet, "/api/analyze/cnn.com", "", "secret")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "10", w.Header().Get("X-Quota-Limit"))
+	assert.Equal(t, "6", w.Header().Get("X-Quota-Remaining"))
+	// Limited by key, at the tier's rate
+	assert.Equal(t, "2", w.Header().Get("RateLimit-Limit"))
+	mockAPIKeys.AssertExpectations(t)
+}
+
+func TestAPIKeys_InvalidKey(t *testing.T) {
+	mockAnalysis := &httpMocks.MockAnalysisService{}
+	mockAPIKeys := &httpMocks.MockAPIKeyService{}
+	server := createAPIKeyTestServer(mockAnalysis, mockAPIKeys, ratelimit.NewTwoTierRateLimiter(100, 100, 10, 10), false)
+
+	mockAPIKeys.On("Authenticate", mock.Anything, "wrong").Return(nil, models.ErrInvalidAPIKey)
+
+	w := serveWithKey(server, http.MethodGet, "/api/analyze/cnn.com", "", "wrong")
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	var response map[string]string
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "unauthorized", response["error"])
+	mockAnalysis.AssertNotCalled(t, "AnalyzeDomainWithOptions", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestAPIKeys_StoreUnavailable(t *testing.T) {
+	mockAPIKeys := &httpMocks.MockAPIKeyService{}
+	server := createAPIKeyTestServer(&httpMocks.MockAnalysisService{}, mockAPIKeys, ratelimit.NewTwoTierRateLimiter(100, 100, 10, 10), false)
+
+	mockAPIKeys.On("Authenticate", mock.Anything, "secret").Return(nil, errors.New("connection refused"))
+
+	w := serveWithKey(server, http.MethodGet, "/api/analyze/cnn.com", "", "secret")
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+}
+
+func TestAPIKeys_Required(t *testing.T) {
+	mockAnalysis := &httpMocks.MockAnalysisService{}
+	server := createAPIKeyTestServer(mockAnalysis, &httpMocks.MockAPIKeyService{}, ratelimit.NewTwoTierRateLimiter(100, 100, 10, 10), true)
+
+	w := serveWithKey(server, http.MethodGet, "/api/analyze/cnn.com", "", "")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	// Health checks stay public
+	w = serveWithKey(server, http.MethodGet, "/health", "", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestAPIKeys_OptionalKeyFallsBackToIPLimits(t *testing.T) {
+	mockAnalysis := &httpMocks.MockAnalysisService{}
+	mockAPIKeys := &httpMocks.MockAPIKeyService{}
+	server := createAPIKeyTestServer(mockAnalysis, mockAPIKeys, ratelimit.NewTwoTierRateLimiter(100, 100, 10, 10), false)
+
+	mockAnalysis.On("AnalyzeDomainWithOptions", mock.Anything, "cnn.com", mock.Anything).Return(&models.DomainAnalysis{Domain: "cnn.com"}, nil)
+
+	w := serveWithKey(server, http.MethodGet, "/api/analyze/cnn.com", "", "")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "10", w.Header().Get("RateLimit-Limit"))
+	assert.Empty(t, w.Header().Get("X-Quota-Limit"))
+	mockAPIKeys.AssertNotCalled(t, "ConsumeDomains", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestAPIKeys_RateLimitedPerKey(t *testing.T) {
+	mockAnalysis := &httpMocks.MockAnalysisService{}
+	mockAPIKeys := &httpMocks.MockAPIKeyService{}
+	server := createAPIKeyTestServer(mockAnalysis, mockAPIKeys, ratelimit.NewTwoTierRateLimiter(100, 100, 10, 10), false)
+
+	mockAPIKeys.On("Authenticate", mock.Anything, "secret").Return(testAPIKey, nil)
+	mockAPIKeys.On("RecordRequest", mock.Anything, testAPIKey).Return(nil)
+	mockAPIKeys.On("Usage", mock.Anything, testAPIKey).Return(&models.APIKeyUsage{}, nil)
+
+	// The free tier allows 2 requests per second, less than the per-IP limit
+	assert.Equal(t, http.StatusOK, serveWithKey(server, http.MethodGet, "/api/usage", "", "secret").Code)
+	assert.Equal(t, http.StatusOK, serveWithKey(server, http.MethodGet, "/api/usage", "", "secret").Code)
+	w := serveWithKey(server, http.MethodGet, "/api/usage", "", "secret")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.NotEmpty(t, w.Header().Get("Retry-After"))
+
+	// Rate-limited requests don't count toward the key's usage
+	mockAPIKeys.AssertNumberOfCalls(t, "RecordRequest", 2)
+}
+
+func TestAPIKeys_QuotaExceeded(t *testing.T) {
+	mockAnalysis := &httpMocks.MockAnalysisService{}
+	mockAPIKeys := &httpMocks.MockAPIKeyService{}
+	server := createAPIKeyTestServer(mockAnalysis, mockAPIKeys, ratelimit.NewTwoTierRateLimiter(100, 100, 10, 10), false)
+
+	mockAPIKeys.On("Authenticate", mock.Anything, "secret").Return(testAPIKey, nil)
+	mockAPIKeys.On("RecordRequest", mock.Anything, testAPIKey).Return(nil)
+	mockAPIKeys.On("ConsumeDomains", mock.Anything, testAPIKey, 2).Return(nil, models.ErrQuotaExceeded)
+
+	w := serveWithKey(server, http.MethodPost, "/api/batch-analysis", `{"domains":["cnn.com","bbc.com"]}`, "secret")
+
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	var response ErrorResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "quota exceeded", response.Error)
+	assert.Contains(t, response.Message, "10 domains per day")
+	mockAnalysis.AssertNotCalled(t, "AnalyzeDomainsWithOptions", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestAPIKeys_TierMaxBatchSize(t *testing.T) {
+	mockAPIKeys := &httpMocks.MockAPIKeyService{}
+	server := createAPIKeyTestServer(&httpMocks.MockAnalysisService{}, mockAPIKeys, ratelimit.NewTwoTierRateLimiter(100, 100, 10, 10), false)
+
+	mockAPIKeys.On("Authenticate", mock.Anything, "secret").Return(testAPIKey, nil)
+	mockAPIKeys.On("RecordRequest", mock.Anything, testAPIKey).Return(nil)
+
+	w := serveWithKey(server, http.MethodPost, "/api/batch-analysis", `{"domains":["a.com","b.com","c.com","d.com"]}`, "secret")
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	var response ErrorResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "Maximum 3 domains per batch", response.Message)
+	mockAPIKeys.AssertNotCalled(t, "ConsumeDomains", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// validatingAnalysisService is an analysis service that checks options before analyzing
+type validatingAnalysisService struct {
+	*httpMocks.MockAnalysisService
+}
+
+func (validatingAnalysisService) ValidateOptions(opts models.AnalysisOptions) error {
+	if opts.TimeoutMs > 1000 {
+		return fmt.Errorf("%w: timeout_ms must not exceed 1000", models.ErrInvalidAnalysisOptions)
+	}
+	return nil
+}
+
+func TestAPIKeys_InvalidOptionsDontUseQuota(t *testing.T) {
+	mockAnalysis := &httpMocks.MockAnalysisService{}
+	mockAPIKeys := &httpMocks.MockAPIKeyService{}
+	mockLogger := mocks.NewAnyLogger()
+	server := NewServer(
+		"localhost:0",
+		NewHandler(validatingAnalysisService{mockAnalysis}, mockLogger),
+		mockLogger,
+		ratelimit.NewTwoTierRateLimiter(100, 100, 10, 10),
+		10*time.Second,
+		10*time.Second,
+		WithAPIKeys(mockAPIKeys, false),
+	)
+
+	key := *testAPIKey
+	key.Tier.RequestsPerSec = 10
+	mockAPIKeys.On("Authenticate", mock.Anything, "secret").Return(&key, nil)
+	mockAPIKeys.On("RecordRequest", mock.Anything, &key).Return(nil)
+
+	requests := []struct {
+		method, path, body string
+	}{
+		{method: http.MethodGet, path: "/api/analyze/cnn.com?timeout_ms=5000"},
+		{method: http.MethodPost, path: "/api/batch-analysis", body: `{"domains":["cnn.com"],"timeout_ms":5000}`},
+		{method: http.MethodPost, path: "/api/batch-analysis/stream", body: `{"domains":["cnn.com"],"timeout_ms":5000}`},
+	}
+	for _, req := range requests {
+		w := serveWithKey(server, req.method, req.path, req.body, "secret")
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, req.path)
+		var response ErrorResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, "invalid analysis options", response.Error)
+	}
+	mockAPIKeys.AssertNotCalled(t, "ConsumeDomains", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestAPIKeys_GetUsage(t *testing.T) {
+	mockAPIKeys := &httpMocks.MockAPIKeyService{}
+	server := createAPIKeyTestServer(&httpMocks.MockAnalysisService{}, mockAPIKeys, ratelimit.NewTwoTierRateLimiter(100, 100, 10, 10), false)
+
+	usage := &models.APIKeyUsage{KeyID: "acme", Day: "2024-01-01", Requests: 12, Domains: 7}
+	mockAPIKeys.On("Authenticate", mock.Anything, "secret").Return(testAPIKey, nil)
+	mockAPIKeys.On("RecordRequest", mock.Anything, testAPIKey).Return(nil)
+	mockAPIKeys.On("Usage", mock.Anything, testAPIKey).Return(usage, nil)
+
+	w := serveWithKey(server, http.MethodGet, "/api/usage", "", "secret")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response models.APIKeyUsageResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "acme", response.KeyID)
+	assert.Equal(t, "free", response.Tier.Name)
+	assert.Equal(t, *usage, response.Usage)
+	require.NotNil(t, response.DomainsRemaining)
+	assert.Equal(t, int64(3), *response.DomainsRemaining)
+
+	// Anonymous requests have no usage
+	w = serveWithKey(server, http.MethodGet, "/api/usage", "", "")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
@@ -0,0 +1,169 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"Perion_Assignment/internal/cache/domainCache"
+	"Perion_Assignment/internal/logger"
+	"Perion_Assignment/internal/models"
+
+	"github.com/gorilla/mux"
+)
+
+// CacheAdminHandler contains the HTTP handlers for the cache admin API
+type CacheAdminHandler struct {
+	*Handler
+	cacheAdmin domainCache.Admin
+}
+
+// NewCacheAdminHandler creates a new cache admin HTTP handler
+func NewCacheAdminHandler(handler *Handler, cacheAdmin domainCache.Admin) *CacheAdminHandler {
+	return &CacheAdminHandler{
+		Handler:    handler,
+		cacheAdmin: cacheAdmin,
+	}
+}
+
+// GetEntry handles GET /api/admin/cache/entries/{domain}
+func (h *CacheAdminHandler) GetEntry(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	domain := mux.Vars(r)["domain"]
+	if domain == "" {
+		h.writeErrorResponse(w, r, http.StatusBadRequest, "domain is required", "")
+		return
+	}
+
+	info, err := h.cacheAdmin.Inspect(ctx, domain)
+	if err != nil {
+		if errors.Is(err, models.ErrCacheUnavailable) {
+			h.writeErrorResponse(w, r, http.StatusNotFound, "domain is not cached", domain)
+			return
+		}
+		h.logger.LogError(ctx, logger.OpCacheAdmin, domain, "Failed to inspect cache entry", err, models.LogSeverityMedium, nil)
+		h.writeErrorResponse(w, r, http.StatusInternalServerError, "failed to inspect cache entry", err.Error())
+		return
+	}
+
+	if err := h.writeJSONResponse(w, r, http.StatusOK, info); err != nil {
+		h.logger.LogError(ctx, logger.OpCacheAdmin, domain, "Failed to encode cache entry response", err, models.LogSeverityLow, nil)
+	}
+}
+
+// DeleteEntry handles DELETE /api/admin/cache/entries/{domain}
+func (h *CacheAdminHandler) DeleteEntry(w http.ResponseWriter, r *http.Request) {
+	domain := mux.Vars(r)["domain"]
+	if domain == "" {
+		h.writeErrorResponse(w, r, http.StatusBadRequest, "domain is required", "")
+		return
+	}
+
+	h.deleteDomains(w, r, []string{domain})
+}
+
+// DeleteEntries handles POST /api/admin/cache/delete
+func (h *CacheAdminHandler) DeleteEntries(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var request models.CacheDeleteRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		h.logger.LogError(ctx, logger.OpCacheAdmin, "", "Invalid request body", err, models.LogSeverityLow, nil)
+		h.writeErrorResponse(w, r, http.StatusBadRequest, "invalid request body", err.Error())
+		return
+	}
+
+	if len(request.Domains) == 0 {
+		h.writeErrorResponse(w, r, http.StatusBadRequest, "domains are required", "")
+		return
+	}
+
+	h.deleteDomains(w, r, request.Domains)
+}
+
+// deleteDomains evicts the domains and writes the number of entries removed
+func (h *CacheAdminHandler) deleteDomains(w http.ResponseWriter, r *http.Request, domains []string) {
+	ctx := r.Context()
+
+	deleted, err := h.cacheAdmin.DeleteDomains(ctx, domains)
+	if err != nil {
+		h.logger.LogError(ctx, logger.OpCacheAdmin, "", "Failed to delete cache entries", err, models.LogSeverityMedium, map[string]interface{}{
+			"domains": domains,
+			"deleted": deleted,
+		})
+		h.writeErrorResponse(w, r, http.StatusInternalServerError, "failed to delete cache entries", err.Error())
+		return
+	}
+
+	h.logger.LogSuccess(ctx, logger.OpCacheAdmin, "", "Deleted cache entries", map[string]interface{}{
+		"domains": domains,
+		"deleted": deleted,
+	})
+
+	if err := h.writeJSONResponse(w, r, http.StatusOK, models.CacheDeleteResponse{Deleted: deleted}); err != nil {
+		h.logger.LogError(ctx, logger.OpCacheAdmin, "", "Failed to encode cache delete response", err, models.LogSeverityLow, nil)
+	}
+}
+
+// Purge handles POST /api/admin/cache/purge
+func (h *CacheAdminHandler) Purge(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var request models.CachePurgeRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		h.logger.LogError(ctx, logger.OpCacheAdmin, "", "Invalid request body", err, models.LogSeverityLow, nil)
+		h.writeErrorResponse(w, r, http.StatusBadRequest, "invalid request body", err.Error())
+		return
+	}
+
+	if (request.Pattern == "") == (request.Prefix == "") {
+		h.writeErrorResponse(w, r, http.StatusBadRequest, "exactly one of pattern or prefix is required", "")
+		return
+	}
+
+	var deleted int
+	var err error
+	if request.Pattern != "" {
+		deleted, err = h.cacheAdmin.PurgePattern(ctx, request.Pattern)
+	} else {
+		deleted, err = h.cacheAdmin.PurgePrefix(ctx, request.Prefix)
+	}
+
+	metadata := map[string]interface{}{
+		"pattern": request.Pattern,
+		"prefix":  request.Prefix,
+		"deleted": deleted,
+	}
+	if err != nil {
+		if errors.Is(err, models.ErrInvalidCachePattern) {
+			h.writeErrorResponse(w, r, http.StatusBadRequest, "invalid purge pattern", err.Error())
+			return
+		}
+		h.logger.LogError(ctx, logger.OpCacheAdmin, "", "Failed to purge cache entries", err, models.LogSeverityMedium, metadata)
+		h.writeErrorResponse(w, r, http.StatusInternalServerError, "failed to purge cache entries", err.Error())
+		return
+	}
+
+	h.logger.LogSuccess(ctx, logger.OpCacheAdmin, "", "Purged cache entries", metadata)
+
+	if err := h.writeJSONResponse(w, r, http.StatusOK, models.CacheDeleteResponse{Deleted: deleted}); err != nil {
+		h.logger.LogError(ctx, logger.OpCacheAdmin, "", "Failed to encode cache purge response", err, models.LogSeverityLow, nil)
+	}
+}
+
+// GetStats handles GET /api/admin/cache/stats
+func (h *CacheAdminHandler) GetStats(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	stats, err := h.cacheAdmin.Stats(ctx)
+	if err != nil {
+		h.logger.LogError(ctx, logger.OpCacheAdmin, "", "Failed to read cache statistics", err, models.LogSeverityMedium, nil)
+		h.writeErrorResponse(w, r, http.StatusInternalServerError, "failed to read cache statistics", err.Error())
+		return
+	}
+
+	if err := h.writeJSONResponse(w, r, http.StatusOK, stats); err != nil {
+		h.logger.LogError(ctx, logger.OpCacheAdmin, "", "Failed to encode cache statistics response", err, models.LogSeverityLow, nil)
+	}
+}
@@ -0,0 +1,243 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"Perion_Assignment/internal/cache"
+	httpMocks "Perion_Assignment/internal/http/mocks"
+	"Perion_Assignment/internal/mocks"
+	"Perion_Assignment/internal/models"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestCacheAdminHandler() (*CacheAdminHandler, *httpMocks.MockCacheAdmin, *mocks.MockLogger) {
+	mockAdmin := &httpMocks.MockCacheAdmin{}
+	mockLogger := &mocks.MockLogger{}
+	handler := NewCacheAdminHandler(NewHandler(&httpMocks.MockAnalysisService{}, mockLogger), mockAdmin)
+	return handler, mockAdmin, mockLogger
+}
+
+func TestCacheAdminHandler_GetEntry_Success(t *testing.T) {
+	handler, mockAdmin, _ := newTestCacheAdminHandler()
+
+	info := &models.CacheEntryInfo{Domain: "cnn.com", Status: models.CacheEntryFresh, AgeSeconds: 60, TTLRemainingSeconds: 3540, SizeBytes: 512}
+	mockAdmin.On("Inspect", mock.Anything, "cnn.com").Return(info, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/admin/cache/entries/cnn.com", nil)
+	req = mux.SetURLVars(req, map[string]string{"domain": "cnn.com"})
+	w := httptest.NewRecorder()
+
+	handler.GetEntry(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response models.CacheEntryInfo
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, *info, response)
+	mockAdmin.AssertExpectations(t)
+}
+
+func TestCacheAdminHandler_GetEntry_NotCached(t *testing.T) {
+	handler, mockAdmin, _ := newTestCacheAdminHandler()
+	mockAdmin.On("Inspect", mock.Anything, "cnn.com").Return(nil, models.ErrCacheUnavailable)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/admin/cache/entries/cnn.com", nil)
+	req = mux.SetURLVars(req, map[string]string{"domain": "cnn.com"})
+	w := httptest.NewRecorder()
+
+	handler.GetEntry(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestCacheAdminHandler_DeleteEntry(t *testing.T) {
+	handler, mockAdmin, mockLogger := newTestCacheAdminHandler()
+	mockAdmin.On("DeleteDomains", mock.Anything, []string{"cnn.com"}).Return(1, nil)
+	mockLogger.On("LogSuccess", mock.Anything, "cache_admin", "", "Deleted cache entries", mock.Anything).Return()
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/admin/cache/entries/cnn.com", nil)
+	req = mux.SetURLVars(req, map[string]string{"domain": "cnn.com"})
+	w := httptest.NewRecorder()
+
+	handler.DeleteEntry(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"deleted":1}`, w.Body.String())
+	mockAdmin.AssertExpectations(t)
+	mockLogger.AssertExpectations(t)
+}
+
+func TestCacheAdminHandler_DeleteEntries(t *testing.T) {
+	handler, mockAdmin, mockLogger := newTestCacheAdminHandler()
+	mockAdmin.On("DeleteDomains", mock.Anything, []string{"cnn.com", "bbc.com"}).Return(2, nil)
+	mockLogger.On("LogSuccess", mock.Anything, "cache_admin", "", "Deleted cache entries", mock.Anything).Return()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/admin/cache/delete", bytes.NewBufferString(`{"domains":["cnn.com","bbc.com"]}`))
+	w := httptest.NewRecorder()
+
+	handler.DeleteEntries(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"deleted":2}`, w.Body.String())
+	mockAdmin.AssertExpectations(t)
+}
+
+func TestCacheAdminHandler_DeleteEntries_NoDomains(t *testing.T) {
+	handler, mockAdmin, _ := newTestCacheAdminHandler()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/admin/cache/delete", bytes.NewBufferString(`{"domains":[]}`))
+	w := httptest.NewRecorder()
+
+	handler.DeleteEntries(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockAdmin.AssertNotCalled(t, "DeleteDomains", mock.Anything, mock.Anything)
+}
+
+func TestCacheAdminHandler_Purge(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   string
+		method string
+		arg    string
+	}{
+		{name: "pattern", body: `{"pattern":"*.cnn.com"}`, method: "PurgePattern", arg: "*.cnn.com"},
+		{name: "prefix", body: `{"prefix":"cnn."}`, method: "PurgePrefix", arg: "cnn."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, mockAdmin, mockLogger := newTestCacheAdminHandler()
+			mockAdmin.On(tt.method, mock.Anything, tt.arg).Return(3, nil)
+			mockLogger.On("LogSuccess", mock.Anything, "cache_admin", "", "Purged cache entries", mock.Anything).Return()
+
+			req := httptest.NewRequest(http.MethodPost, "/api/admin/cache/purge", bytes.NewBufferString(tt.body))
+			w := httptest.NewRecorder()
+
+			handler.Purge(w, req)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.JSONEq(t, `{"deleted":3}`, w.Body.String())
+			mockAdmin.AssertExpectations(t)
+		})
+	}
+}
+
+func TestCacheAdminHandler_Purge_BadRequest(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+	}{
+		{name: "neither pattern nor prefix", body: `{}`},
+		{name: "both pattern and prefix", body: `{"pattern":"cnn*","prefix":"cnn"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, _, _ := newTestCacheAdminHandler()
+
+			req := httptest.NewRequest(http.MethodPost, "/api/admin/cache/purge", bytes.NewBufferString(tt.body))
+			w := httptest.NewRecorder()
+
+			handler.Purge(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+	}
+
+	t.Run("invalid pattern", func(t *testing.T) {
+		handler, mockAdmin, _ := newTestCacheAdminHandler()
+		mockAdmin.On("PurgePattern", mock.Anything, "cnn[").Return(0, models.ErrInvalidCachePattern)
+
+		req := httptest.NewRequest(http.MethodPost, "/api/admin/cache/purge", bytes.NewBufferString(`{"pattern":"cnn["}`))
+		w := httptest.NewRecorder()
+
+		handler.Purge(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestCacheAdminHandler_GetStats(t *testing.T) {
+	handler, mockAdmin, _ := newTestCacheAdminHandler()
+	stats := cache.Stats{Backend: "memory", Entries: 10, Hits: 7, Misses: 3, Evictions: 1}
+	mockAdmin.On("Stats", mock.Anything).Return(stats, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/admin/cache/stats", nil)
+	w := httptest.NewRecorder()
+
+	handler.GetStats(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response cache.Stats
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, stats, response)
+}
+
+func TestCacheAdminHandler_GetStats_Error(t *testing.T) {
+	handler, mockAdmin, mockLogger := newTestCacheAdminHandler()
+	mockAdmin.On("Stats", mock.Anything).Return(cache.Stats{}, errors.New("redis down"))
+	mockLogger.On("LogError", mock.Anything, "cache_admin", "", "Failed to read cache statistics", mock.Anything, models.LogSeverityMedium, mock.Anything).Return()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/admin/cache/stats", nil)
+	w := httptest.NewRecorder()
+
+	handler.GetStats(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	mockLogger.AssertExpectations(t)
+}
+
+func TestCacheAdminRoutes_RequireToken(t *testing.T) {
+	mockLogger := &mocks.MockLogger{}
+	mockRateLimiter := &httpMocks.MockRateLimiter{}
+	mockAdmin := &httpMocks.MockCacheAdmin{}
+
+	handler := NewHandler(&httpMocks.MockAnalysisService{}, mockLogger)
+	server := NewServer("localhost:0", handler, mockLogger, mockRateLimiter, 10*time.Second, 10*time.Second)
+	server.RegisterCacheAdminRoutes(NewCacheAdminHandler(handler, mockAdmin), "secret-token")
+
+	mockLogger.On("LogInfo", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return().Maybe()
+	mockLogger.On("LogError", mock.Anything, "admin_auth", "", "Unauthorized admin request", models.ErrUnauthorized, models.LogSeverityMedium, mock.Anything).Return()
+	mockRateLimiter.On("Allow", mock.AnythingOfType("string")).Return(true)
+	mockAdmin.On("Stats", mock.Anything).Return(cache.Stats{Backend: "memory"}, nil)
+
+	tests := []struct {
+		name           string
+		authorization  string
+		expectedStatus int
+	}{
+		{name: "missing token", authorization: "", expectedStatus: http.StatusUnauthorized},
+		{name: "wrong token", authorization: "Bearer wrong", expectedStatus: http.StatusUnauthorized},
+		{name: "not a bearer token", authorization: "secret-token", expectedStatus: http.StatusUnauthorized},
+		{name: "valid token", authorization: "Bearer secret-token", expectedStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/admin/cache/stats", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			w := httptest.NewRecorder()
+
+			server.router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusUnauthorized {
+				assert.Equal(t, `Bearer realm="admin"`, w.Header().Get("WWW-Authenticate"))
+			}
+		})
+	}
+}
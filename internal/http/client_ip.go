@@ -0,0 +1,153 @@
+package http
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+
+	"Perion_Assignment/internal/ipfilter"
+)
+
+// ParseTrustedProxies parses trusted proxy CIDRs; a bare IP address trusts that address only
+func ParseTrustedProxies(values []string) ([]netip.Prefix, error) {
+	prefixes, err := ipfilter.ParsePrefixes(values)
+	if err != nil {
+		return nil, fmt.Errorf("invalid trusted proxy: %w", err)
+	}
+	return prefixes, nil
+}
+
+// clientIPResolver determines the client IP of a request
+// Forwarding headers (Forwarded, X-Forwarded-For, X-Real-IP) are only believed when the
+// connection comes from a trusted proxy, and the forwarded chain is only followed while
+// its hops are trusted proxies, so clients can't spoof their IP. A nil resolver trusts no proxy.
+type clientIPResolver struct {
+	trustedProxies []netip.Prefix
+}
+
+// newClientIPResolver creates a resolver trusting the given proxy networks
+func newClientIPResolver(trustedProxies []netip.Prefix) *clientIPResolver {
+	return &clientIPResolver{trustedProxies: trustedProxies}
+}
+
+// resolve returns the client IP of a request, and the raw forwarding chain (the forwarded
+// addresses followed by the connecting peer) when the request carries one
+//
+// The chain is walked right to left, from the peer towards the client, and the first
+// address that isn't a trusted proxy is the client. A hop that isn't an IP address
+// ("unknown" or an obfuscated identifier) stops the walk at the last known address.
+func (c *clientIPResolver) resolve(r *http.Request) (string, []string) {
+	peer := remoteHost(r)
+
+	forwarded := forwardedChain(r)
+	var chain []string
+	if len(forwarded) > 0 {
+		chain = append(forwarded, peer)
+	}
+
+	peerAddr, ok := parseHopAddr(peer)
+	if !ok || !c.trusted(peerAddr) {
+		return peer, chain
+	}
+
+	if len(forwarded) == 0 {
+		if realIP, ok := parseHopAddr(r.Header.Get("X-Real-IP")); ok {
+			return realIP.String(), chain
+		}
+		return peer, chain
+	}
+
+	client := peerAddr
+	for i := len(forwarded) - 1; i >= 0; i-- {
+		addr, ok := parseHopAddr(forwarded[i])
+		if !ok {
+			break
+		}
+		client = addr
+		if !c.trusted(addr) {
+			break
+		}
+	}
+	return client.String(), chain
+}
+
+// trusted reports whether addr belongs to a trusted proxy
+func (c *clientIPResolver) trusted(addr netip.Addr) bool {
+	if c == nil {
+		return false
+	}
+	for _, prefix := range c.trustedProxies {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// remoteHost returns the host of the connecting peer, or the full RemoteAddr if it has no port
+func remoteHost(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// forwardedChain returns the forwarded addresses of a request, client first
+// The RFC 7239 Forwarded header takes precedence over X-Forwarded-For.
+func forwardedChain(r *http.Request) []string {
+	if values := r.Header.Values("Forwarded"); len(values) > 0 {
+		return parseForwarded(values)
+	}
+
+	var chain []string
+	for _, value := range r.Header.Values("X-Forwarded-For") {
+		for _, hop := range strings.Split(value, ",") {
+			if hop = strings.TrimSpace(hop); hop != "" {
+				chain = append(chain, hop)
+			}
+		}
+	}
+	return chain
+}
+
+// parseForwarded returns the for= parameters of RFC 7239 Forwarded header values
+// An element without a for= parameter is recorded as "unknown".
+func parseForwarded(values []string) []string {
+	var chain []string
+	for _, value := range values {
+		for _, element := range strings.Split(value, ",") {
+			if strings.TrimSpace(element) == "" {
+				continue
+			}
+
+			hop := "unknown"
+			for _, pair := range strings.Split(element, ";") {
+				key, val, ok := strings.Cut(strings.TrimSpace(pair), "=")
+				if ok && strings.EqualFold(strings.TrimSpace(key), "for") {
+					hop = strings.Trim(strings.TrimSpace(val), `"`)
+				}
+			}
+			chain = append(chain, hop)
+		}
+	}
+	return chain
+}
+
+// parseHopAddr parses a forwarded address: an IP, optionally with a port or in brackets
+func parseHopAddr(value string) (netip.Addr, bool) {
+	value = strings.Trim(strings.TrimSpace(value), `"`)
+	if value == "" {
+		return netip.Addr{}, false
+	}
+
+	if addr, err := netip.ParseAddr(strings.TrimSuffix(strings.TrimPrefix(value, "["), "]")); err == nil {
+		return addr.Unmap(), true
+	}
+	if addrPort, err := netip.ParseAddrPort(value); err == nil {
+		return addrPort.Addr().Unmap(), true
+	}
+	return netip.Addr{}, false
+}
@@ -0,0 +1,150 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTrustedProxies(t *testing.T) {
+	prefixes, err := ParseTrustedProxies([]string{"10.0.0.0/8", " 192.168.1.5 ", "2001:db8::/32", "::ffff:172.16.0.1", ""})
+	require.NoError(t, err)
+	assert.Equal(t, []netip.Prefix{
+		netip.MustParsePrefix("10.0.0.0/8"),
+		netip.MustParsePrefix("192.168.1.5/32"),
+		netip.MustParsePrefix("2001:db8::/32"),
+		netip.MustParsePrefix("172.16.0.1/32"),
+	}, prefixes)
+
+	_, err = ParseTrustedProxies([]string{"10.0.0.0/33"})
+	assert.Error(t, err)
+	_, err = ParseTrustedProxies([]string{"proxy.internal"})
+	assert.Error(t, err)
+}
+
+func TestClientIPResolver_Resolve(t *testing.T) {
+	// A load balancer network and a CDN range are trusted
+	resolver := newClientIPResolver([]netip.Prefix{
+		netip.MustParsePrefix("10.0.0.0/8"),
+		netip.MustParsePrefix("2001:db8::/32"),
+	})
+
+	tests := []struct {
+		name          string
+		remoteAddr    string
+		headers       map[string][]string
+		expectedIP    string
+		expectedChain []string
+	}{
+		{
+			name:       "untrusted peer ignores headers",
+			remoteAddr: "203.0.113.9:5000",
+			headers:    map[string][]string{"X-Forwarded-For": {"1.2.3.4"}, "X-Real-IP": {"1.2.3.5"}},
+			expectedIP: "203.0.113.9",
+			// The chain is recorded even when it isn't believed
+			expectedChain: []string{"1.2.3.4", "203.0.113.9"},
+		},
+		{
+			name:          "walks trusted hops right to left",
+			remoteAddr:    "10.0.0.1:5000",
+			headers:       map[string][]string{"X-Forwarded-For": {"1.2.3.4, 198.51.100.7, 10.1.1.1"}},
+			expectedIP:    "198.51.100.7",
+			expectedChain: []string{"1.2.3.4", "198.51.100.7", "10.1.1.1", "10.0.0.1"},
+		},
+		{
+			name:          "multiple X-Forwarded-For headers",
+			remoteAddr:    "10.0.0.1:5000",
+			headers:       map[string][]string{"X-Forwarded-For": {"198.51.100.7", "10.1.1.1"}},
+			expectedIP:    "198.51.100.7",
+			expectedChain: []string{"198.51.100.7", "10.1.1.1", "10.0.0.1"},
+		},
+		{
+			name:          "all hops trusted",
+			remoteAddr:    "10.0.0.1:5000",
+			headers:       map[string][]string{"X-Forwarded-For": {"10.2.2.2, 10.1.1.1"}},
+			expectedIP:    "10.2.2.2",
+			expectedChain: []string{"10.2.2.2", "10.1.1.1", "10.0.0.1"},
+		},
+		{
+			name:          "unparseable hop stops at last known address",
+			remoteAddr:    "10.0.0.1:5000",
+			headers:       map[string][]string{"X-Forwarded-For": {"198.51.100.7, garbage, 10.1.1.1"}},
+			expectedIP:    "10.1.1.1",
+			expectedChain: []string{"198.51.100.7", "garbage", "10.1.1.1", "10.0.0.1"},
+		},
+		{
+			name:          "Forwarded header",
+			remoteAddr:    "10.0.0.1:5000",
+			headers:       map[string][]string{"Forwarded": {`for=192.0.2.43, for="[2001:db8:cafe::17]:4711";proto=https;by=10.0.0.1`}},
+			expectedIP:    "192.0.2.43",
+			expectedChain: []string{"192.0.2.43", "[2001:db8:cafe::17]:4711", "10.0.0.1"},
+		},
+		{
+			name:          "Forwarded takes precedence over X-Forwarded-For",
+			remoteAddr:    "10.0.0.1:5000",
+			headers:       map[string][]string{"Forwarded": {"For=198.51.100.8"}, "X-Forwarded-For": {"198.51.100.7"}},
+			expectedIP:    "198.51.100.8",
+			expectedChain: []string{"198.51.100.8", "10.0.0.1"},
+		},
+		{
+			name:          "Forwarded unknown hop",
+			remoteAddr:    "10.0.0.1:5000",
+			headers:       map[string][]string{"Forwarded": {"for=198.51.100.7, for=unknown, proto=https"}},
+			expectedIP:    "10.0.0.1",
+			expectedChain: []string{"198.51.100.7", "unknown", "unknown", "10.0.0.1"},
+		},
+		{
+			name:          "Forwarded with port",
+			remoteAddr:    "10.0.0.1:5000",
+			headers:       map[string][]string{"Forwarded": {`for="198.51.100.7:8443"`}},
+			expectedIP:    "198.51.100.7",
+			expectedChain: []string{"198.51.100.7:8443", "10.0.0.1"},
+		},
+		{
+			name:       "X-Real-IP from trusted peer",
+			remoteAddr: "10.0.0.1:5000",
+			headers:    map[string][]string{"X-Real-IP": {"198.51.100.7"}},
+			expectedIP: "198.51.100.7",
+		},
+		{
+			name:       "invalid X-Real-IP",
+			remoteAddr: "10.0.0.1:5000",
+			headers:    map[string][]string{"X-Real-IP": {"not-an-ip"}},
+			expectedIP: "10.0.0.1",
+		},
+		{
+			name:          "trusted IPv6 peer",
+			remoteAddr:    "[2001:db8::1]:5000",
+			headers:       map[string][]string{"X-Forwarded-For": {"2001:db9::5"}},
+			expectedIP:    "2001:db9::5",
+			expectedChain: []string{"2001:db9::5", "2001:db8::1"},
+		},
+		{
+			name:          "IPv4-mapped peer",
+			remoteAddr:    "[::ffff:10.0.0.1]:5000",
+			headers:       map[string][]string{"X-Forwarded-For": {"198.51.100.7"}},
+			expectedIP:    "198.51.100.7",
+			expectedChain: []string{"198.51.100.7", "::ffff:10.0.0.1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tt.remoteAddr
+			for key, values := range tt.headers {
+				for _, value := range values {
+					req.Header.Add(key, value)
+				}
+			}
+
+			ip, chain := resolver.resolve(req)
+			assert.Equal(t, tt.expectedIP, ip)
+			assert.Equal(t, tt.expectedChain, chain)
+		})
+	}
+}
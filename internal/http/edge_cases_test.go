@@ -121,7 +121,7 @@ func TestGetClientIP_MalformedRemoteAddr(t *testing.T) {
 			req := httptest.NewRequest(http.MethodGet, "/", nil)
 			req.RemoteAddr = tt.remoteAddr
 
-			ip := getClientIP(req)
+			ip, _ := newClientIPResolver(nil).resolve(req)
 			assert.Equal(t, tt.expected, ip)
 		})
 	}
@@ -142,7 +142,7 @@ func TestLoggingMiddleware_ContextEdgeCases(t *testing.T) {
 	mockLogger.On("LogInfo", mock.Anything, "http_request_start", "HTTP request received", mock.Anything).Return()
 	mockLogger.On("LogInfo", mock.Anything, "http_request_complete", "HTTP request processed", mock.Anything).Return()
 
-	middleware := loggingMiddleware(mockLogger)
+	middleware := loggingMiddleware(mockLogger, nil)
 	handler := middleware(testHandler)
 
 	// Test with already cancelled context
@@ -174,7 +174,7 @@ func TestRateLimitingMiddleware_NoLogEventInContext(t *testing.T) {
 	// Setup mock - even without log event, rate limiter should be called with some IP
 	mockRateLimiter.On("Allow", mock.AnythingOfType("string")).Return(true)
 
-	middleware := rateLimitingMiddleware(mockRateLimiter, mockLogger)
+	middleware := rateLimitingMiddleware(mockRateLimiter, nil, nil, nil, mockLogger)
 	handler := middleware(testHandler)
 
 	// Create request WITHOUT log event in context (edge case)
@@ -201,7 +201,7 @@ func TestHandlers_CancelledContext(t *testing.T) {
 
 	// Setup mocks
 	mockLogger.On("LogInfo", mock.Anything, "domain_analysis", mock.AnythingOfType("string"), mock.Anything).Return()
-	mockAnalysisService.On("AnalyzeDomain", mock.Anything, domain).Return(nil, context.Canceled)
+	mockAnalysisService.On("AnalyzeDomainWithOptions", mock.Anything, domain, models.AnalysisOptions{}).Return(nil, context.Canceled)
 	mockLogger.On("LogError", mock.Anything, "domain_analysis", domain, "Domain analysis failed", context.Canceled, models.LogSeverityMedium, mock.Anything).Return()
 
 	// Create request with cancelled context
@@ -242,7 +242,7 @@ func TestBatchHandlers_CancelledContext(t *testing.T) {
 
 	// Setup mocks
 	mockLogger.On("LogInfo", mock.Anything, "batch_analysis", mock.AnythingOfType("string"), mock.Anything).Return()
-	mockAnalysisService.On("AnalyzeDomains", mock.Anything, domains).Return(nil, context.Canceled)
+	mockAnalysisService.On("AnalyzeDomainsWithOptions", mock.Anything, domains, models.AnalysisOptions{}).Return(nil, context.Canceled)
 	mockLogger.On("LogError", mock.Anything, "batch_analysis", "", "Batch analysis failed", context.Canceled, models.LogSeverityMedium, mock.Anything).Return()
 
 	// Create request with cancelled context
@@ -307,8 +307,8 @@ func TestMiddlewareChain_ComplexErrorScenario(t *testing.T) {
 	mockLogger.On("LogError", mock.Anything, "panic_recovery", "", "Panic recovered in HTTP handler", mock.AnythingOfType("*errors.errorString"), models.LogSeverityHigh, mock.Anything).Return()
 
 	// Build middleware chain
-	handler := loggingMiddleware(mockLogger)(
-		rateLimitingMiddleware(mockRateLimiter, mockLogger)(
+	handler := loggingMiddleware(mockLogger, nil)(
+		rateLimitingMiddleware(mockRateLimiter, nil, nil, nil, mockLogger)(
 			corsMiddleware()(
 				recoveryMiddleware(mockLogger)(
 					panicHandler,
@@ -367,7 +367,7 @@ func TestGetClientIP_IPv6EdgeCases(t *testing.T) {
 			req := httptest.NewRequest(http.MethodGet, "/", nil)
 			req.RemoteAddr = tt.remoteAddr
 
-			ip := getClientIP(req)
+			ip, _ := newClientIPResolver(nil).resolve(req)
 			assert.Equal(t, tt.expected, ip)
 		})
 	}
@@ -2,21 +2,30 @@ package http
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
+	"Perion_Assignment/internal/apikeys"
+	"Perion_Assignment/internal/concurrency"
 	"Perion_Assignment/internal/domainAnalysis"
 	"Perion_Assignment/internal/logger"
 	"Perion_Assignment/internal/models"
 	"github.com/gorilla/mux"
 )
 
+// maxBatchDomains limits the number of domains in a synchronous batch request
+const maxBatchDomains = 100
+
 // Handler contains the HTTP handlers for the API
 type Handler struct {
 	analysisService domainAnalysis.AnalysisService
 	logger          logger.Service
+	apiKeys         apikeys.Service     // Set by WithAPIKeys; nil when API keys are disabled
+	fetchLimiter    concurrency.Service // Set by WithLoadShedding; nil when load shedding is disabled
 }
 
 // NewHandler creates a new HTTP handler
@@ -35,6 +44,7 @@ func NewHandler(
 type ErrorResponse struct {
 	Error     string    `json:"error"`
 	Message   string    `json:"message"`
+	Cached    bool      `json:"cached,omitempty"` // Failure served from the negative cache
 	Timestamp time.Time `json:"timestamp"`
 }
 
@@ -72,17 +82,36 @@ func (h *Handler) AnalyzeSingleDomain(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	opts, err := parseAnalysisOptions(r)
+	if err != nil {
+		h.writeErrorResponse(w, r, http.StatusBadRequest, "invalid analysis options", err.Error())
+		return
+	}
+
+	if !h.validateOptions(w, r, opts) || !h.consumeQuota(w, r, 1) {
+		return
+	}
+
 	h.logger.LogInfo(ctx, logger.OpDomainAnalysis, fmt.Sprintf("Starting analysis for domain: %s", domain), map[string]interface{}{
 		"domain": domain,
 	})
 
 	// Perform analysis
-	analysis, err := h.analysisService.AnalyzeDomain(ctx, domain)
+	analysis, err := h.analysisService.AnalyzeDomainWithOptions(ctx, domain, opts)
 	if err != nil {
 		h.logger.LogError(ctx, logger.OpDomainAnalysis, domain, "Domain analysis failed", err, models.LogSeverityMedium, nil)
 
 		// Determine appropriate status code based on error type
 		statusCode := h.getStatusCodeForError(err)
+		if statusCode == http.StatusServiceUnavailable {
+			w.Header().Set("Retry-After", strconv.FormatInt(overloadRetryAfter(h.fetchLimiter), 10))
+		}
+
+		var failure *models.LookupFailure
+		if errors.As(err, &failure) {
+			h.writeCachedFailureResponse(w, r, statusCode, failure)
+			return
+		}
 		h.writeErrorResponse(w, r, statusCode, "analysis failed", err.Error())
 		return
 	}
@@ -104,22 +133,9 @@ func (h *Handler) AnalyzeBatchDomains(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
 
-	// Parse request body
-	var request models.BatchAnalysisRequest
-	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
-		h.logger.LogError(ctx, logger.OpBatchAnalysis, "", "Invalid request body", err, models.LogSeverityLow, nil)
-		h.writeErrorResponse(w, r, http.StatusBadRequest, "invalid request body", err.Error())
-		return
-	}
-
-	// Validate request
-	if len(request.Domains) == 0 {
-		h.writeErrorResponse(w, r, http.StatusBadRequest, "domains array cannot be empty", "")
-		return
-	}
-
-	if len(request.Domains) > 100 { // Limit batch size
-		h.writeErrorResponse(w, r, http.StatusBadRequest, "too many domains", "Maximum 100 domains per batch")
+	// Parse and validate request body
+	request, ok := h.decodeBatchRequest(w, r)
+	if !ok || !h.validateOptions(w, r, request.AnalysisOptions) || !h.consumeQuota(w, r, len(request.Domains)) {
 		return
 	}
 
@@ -129,10 +145,15 @@ func (h *Handler) AnalyzeBatchDomains(w http.ResponseWriter, r *http.Request) {
 	})
 
 	// Perform batch analysis
-	response, err := h.analysisService.AnalyzeDomains(ctx, request.Domains)
+	response, err := h.analysisService.AnalyzeDomainsWithOptions(ctx, request.Domains, request.AnalysisOptions)
 	if err != nil {
 		h.logger.LogError(ctx, logger.OpBatchAnalysis, "", "Batch analysis failed", err, models.LogSeverityMedium, nil)
-		h.writeErrorResponse(w, r, http.StatusInternalServerError, "batch analysis failed", err.Error())
+
+		statusCode := http.StatusInternalServerError
+		if errors.Is(err, models.ErrInvalidAnalysisOptions) {
+			statusCode = http.StatusBadRequest
+		}
+		h.writeErrorResponse(w, r, statusCode, "batch analysis failed", err.Error())
 		return
 	}
 
@@ -154,6 +175,73 @@ func (h *Handler) AnalyzeBatchDomains(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// decodeBatchRequest parses and validates a batch analysis request body
+// On failure it writes the error response and returns false
+func (h *Handler) decodeBatchRequest(w http.ResponseWriter, r *http.Request) (*models.BatchAnalysisRequest, bool) {
+	var request models.BatchAnalysisRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		h.logger.LogError(r.Context(), logger.OpBatchAnalysis, "", "Invalid request body", err, models.LogSeverityLow, nil)
+		h.writeErrorResponse(w, r, http.StatusBadRequest, "invalid request body", err.Error())
+		return nil, false
+	}
+
+	// Validate request
+	if len(request.Domains) == 0 {
+		h.writeErrorResponse(w, r, http.StatusBadRequest, "domains array cannot be empty", "")
+		return nil, false
+	}
+
+	if maxDomains := h.maxBatchSize(r); len(request.Domains) > maxDomains { // Limit batch size
+		h.writeErrorResponse(w, r, http.StatusBadRequest, "too many domains", fmt.Sprintf("Maximum %d domains per batch", maxDomains))
+		return nil, false
+	}
+
+	return &request, true
+}
+
+// validateOptions checks analysis options against the service's limits before any work,
+// when the service implements domainAnalysis.OptionsValidator
+// On failure it writes the error response and returns false
+func (h *Handler) validateOptions(w http.ResponseWriter, r *http.Request, opts models.AnalysisOptions) bool {
+	validator, ok := h.analysisService.(domainAnalysis.OptionsValidator)
+	if !ok {
+		return true
+	}
+
+	if err := validator.ValidateOptions(opts); err != nil {
+		h.writeErrorResponse(w, r, http.StatusBadRequest, "invalid analysis options", err.Error())
+		return false
+	}
+	return true
+}
+
+// parseAnalysisOptions reads analysis options from the force_refresh, max_age, timeout_ms
+// and cache_ttl query parameters; limits are validated by the analysis service
+func parseAnalysisOptions(r *http.Request) (models.AnalysisOptions, error) {
+	var opts models.AnalysisOptions
+
+	if value := r.URL.Query().Get("force_refresh"); value != "" {
+		forceRefresh, err := strconv.ParseBool(value)
+		if err != nil {
+			return opts, fmt.Errorf("force_refresh must be a boolean")
+		}
+		opts.ForceRefresh = forceRefresh
+	}
+
+	var err error
+	if opts.MaxAge, err = getIntQueryParam(r, "max_age", 0); err != nil {
+		return opts, fmt.Errorf("max_age must be an integer")
+	}
+	if opts.TimeoutMs, err = getIntQueryParam(r, "timeout_ms", 0); err != nil {
+		return opts, fmt.Errorf("timeout_ms must be an integer")
+	}
+	if opts.CacheTTL, err = getIntQueryParam(r, "cache_ttl", 0); err != nil {
+		return opts, fmt.Errorf("cache_ttl must be an integer")
+	}
+
+	return opts, nil
+}
+
 // HealthCheck handles GET /health
 func (h *Handler) HealthCheck(w http.ResponseWriter, r *http.Request) {
 	// LogEvent is automatically created by logging middleware
@@ -191,10 +279,27 @@ func (h *Handler) writeErrorResponse(w http.ResponseWriter, r *http.Request, sta
 	}
 }
 
+// writeCachedFailureResponse writes an error response for a failure served from the negative cache
+func (h *Handler) writeCachedFailureResponse(w http.ResponseWriter, r *http.Request, statusCode int, failure *models.LookupFailure) {
+	response := ErrorResponse{
+		Error:     "analysis failed",
+		Message:   failure.Error(),
+		Cached:    true,
+		Timestamp: time.Now().UTC(),
+	}
+
+	if err := h.writeJSONResponse(w, r, statusCode, response); err != nil {
+		h.logger.LogError(r.Context(), "response_encoding", "", "Failed to encode error response", err, models.LogSeverityLow, nil)
+	}
+}
 
 // getStatusCodeForError determines the appropriate HTTP status code for an error
 func (h *Handler) getStatusCodeForError(err error) int {
 	switch {
+	case errors.Is(err, models.ErrInvalidAnalysisOptions):
+		return http.StatusBadRequest
+	case errors.Is(err, models.ErrOverloaded):
+		return http.StatusServiceUnavailable
 	case strings.Contains(err.Error(), "not found") || strings.Contains(err.Error(), "404"):
 		return http.StatusNotFound
 	case strings.Contains(err.Error(), "timeout"):
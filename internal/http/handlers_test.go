@@ -6,6 +6,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -41,7 +42,7 @@ func TestHandler_AnalyzeSingleDomain_Success(t *testing.T) {
 
 	// Setup mocks
 	mockLogger.On("LogInfo", mock.Anything, "domain_analysis", mock.AnythingOfType("string"), mock.Anything).Return()
-	mockAnalysisService.On("AnalyzeDomain", mock.Anything, domain).Return(expectedAnalysis, nil)
+	mockAnalysisService.On("AnalyzeDomainWithOptions", mock.Anything, domain, models.AnalysisOptions{}).Return(expectedAnalysis, nil)
 	mockLogger.On("LogSuccess", mock.Anything, "domain_analysis", domain, "Successfully analyzed domain", mock.Anything).Return()
 
 	// Create request with Gorilla Mux context
@@ -99,7 +100,7 @@ func TestHandler_AnalyzeSingleDomain_MissingDomain(t *testing.T) {
 	assert.Empty(t, response.Message)
 
 	// Verify no service calls were made
-	mockAnalysisService.AssertNotCalled(t, "AnalyzeDomain")
+	mockAnalysisService.AssertNotCalled(t, "AnalyzeDomainWithOptions")
 }
 
 func TestHandler_AnalyzeSingleDomain_ServiceError(t *testing.T) {
@@ -114,7 +115,7 @@ func TestHandler_AnalyzeSingleDomain_ServiceError(t *testing.T) {
 
 	// Setup mocks
 	mockLogger.On("LogInfo", mock.Anything, "domain_analysis", mock.AnythingOfType("string"), mock.Anything).Return()
-	mockAnalysisService.On("AnalyzeDomain", mock.Anything, domain).Return(nil, serviceError)
+	mockAnalysisService.On("AnalyzeDomainWithOptions", mock.Anything, domain, models.AnalysisOptions{}).Return(nil, serviceError)
 	mockLogger.On("LogError", mock.Anything, "domain_analysis", domain, "Domain analysis failed", serviceError, models.LogSeverityMedium, mock.Anything).Return()
 
 	// Create request
@@ -173,7 +174,7 @@ func TestHandler_AnalyzeBatchDomains_Success(t *testing.T) {
 
 	// Setup mocks
 	mockLogger.On("LogInfo", mock.Anything, "batch_analysis", mock.AnythingOfType("string"), mock.Anything).Return()
-	mockAnalysisService.On("AnalyzeDomains", mock.Anything, domains).Return(expectedResponse, nil)
+	mockAnalysisService.On("AnalyzeDomainsWithOptions", mock.Anything, domains, models.AnalysisOptions{}).Return(expectedResponse, nil)
 	mockLogger.On("LogSuccess", mock.Anything, "batch_analysis", "", mock.AnythingOfType("string"), mock.Anything).Return()
 
 	// Create request
@@ -235,7 +236,7 @@ func TestHandler_AnalyzeBatchDomains_PartialSuccess(t *testing.T) {
 
 	// Setup mocks
 	mockLogger.On("LogInfo", mock.Anything, "batch_analysis", mock.AnythingOfType("string"), mock.Anything).Return()
-	mockAnalysisService.On("AnalyzeDomains", mock.Anything, domains).Return(expectedResponse, nil)
+	mockAnalysisService.On("AnalyzeDomainsWithOptions", mock.Anything, domains, models.AnalysisOptions{}).Return(expectedResponse, nil)
 	mockLogger.On("LogSuccess", mock.Anything, "batch_analysis", "", mock.AnythingOfType("string"), mock.Anything).Return()
 
 	// Create request
@@ -295,7 +296,7 @@ func TestHandler_AnalyzeBatchDomains_InvalidJSON(t *testing.T) {
 	assert.Equal(t, "invalid request body", response.Error)
 
 	// Verify no service calls were made
-	mockAnalysisService.AssertNotCalled(t, "AnalyzeDomains")
+	mockAnalysisService.AssertNotCalled(t, "AnalyzeDomainsWithOptions")
 	mockLogger.AssertExpectations(t)
 }
 
@@ -331,7 +332,7 @@ func TestHandler_AnalyzeBatchDomains_EmptyDomains(t *testing.T) {
 	assert.Equal(t, "domains array cannot be empty", response.Error)
 
 	// Verify no service calls were made
-	mockAnalysisService.AssertNotCalled(t, "AnalyzeDomains")
+	mockAnalysisService.AssertNotCalled(t, "AnalyzeDomainsWithOptions")
 }
 
 func TestHandler_AnalyzeBatchDomains_TooManyDomains(t *testing.T) {
@@ -373,7 +374,7 @@ func TestHandler_AnalyzeBatchDomains_TooManyDomains(t *testing.T) {
 	assert.Equal(t, "Maximum 100 domains per batch", response.Message)
 
 	// Verify no service calls were made
-	mockAnalysisService.AssertNotCalled(t, "AnalyzeDomains")
+	mockAnalysisService.AssertNotCalled(t, "AnalyzeDomainsWithOptions")
 }
 
 func TestHandler_HealthCheck_Success(t *testing.T) {
@@ -491,7 +492,7 @@ func TestHandler_AnalyzeSingleDomain_WithLogEvent(t *testing.T) {
 
 	// Setup mocks - use mock.Anything for context since mux.SetURLVars modifies it
 	mockLogger.On("LogInfo", mock.Anything, "domain_analysis", mock.AnythingOfType("string"), mock.Anything).Return()
-	mockAnalysisService.On("AnalyzeDomain", mock.Anything, domain).Return(expectedAnalysis, nil)
+	mockAnalysisService.On("AnalyzeDomainWithOptions", mock.Anything, domain, models.AnalysisOptions{}).Return(expectedAnalysis, nil)
 	mockLogger.On("LogSuccess", mock.Anything, "domain_analysis", domain, "Successfully analyzed domain", mock.Anything).Return()
 
 	// Create request with context
@@ -518,3 +519,126 @@ func TestHandler_AnalyzeSingleDomain_WithLogEvent(t *testing.T) {
 	mockAnalysisService.AssertExpectations(t)
 	mockLogger.AssertExpectations(t)
 }
+
+func TestHandler_AnalyzeSingleDomain_WithOptions(t *testing.T) {
+	// Arrange
+	mockAnalysisService := &httpMocks.MockAnalysisService{}
+	mockLogger := &mocks.MockLogger{}
+
+	handler := NewHandler(mockAnalysisService, mockLogger)
+
+	domain := "example.com"
+	opts := models.AnalysisOptions{ForceRefresh: true, MaxAge: 60, TimeoutMs: 1500, CacheTTL: 300}
+
+	mockLogger.On("LogInfo", mock.Anything, "domain_analysis", mock.AnythingOfType("string"), mock.Anything).Return()
+	mockAnalysisService.On("AnalyzeDomainWithOptions", mock.Anything, domain, opts).Return(&models.DomainAnalysis{Domain: domain}, nil)
+	mockLogger.On("LogSuccess", mock.Anything, "domain_analysis", domain, "Successfully analyzed domain", mock.Anything).Return()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/analyze/"+domain+"?force_refresh=true&max_age=60&timeout_ms=1500&cache_ttl=300", nil)
+	req = mux.SetURLVars(req, map[string]string{"domain": domain})
+	w := httptest.NewRecorder()
+
+	// Act
+	handler.AnalyzeSingleDomain(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockAnalysisService.AssertExpectations(t)
+}
+
+func TestHandler_AnalyzeSingleDomain_InvalidOptions(t *testing.T) {
+	for _, query := range []string{"?force_refresh=maybe", "?max_age=abc", "?timeout_ms=1.5", "?cache_ttl=x"} {
+		t.Run(query, func(t *testing.T) {
+			mockAnalysisService := &httpMocks.MockAnalysisService{}
+			handler := NewHandler(mockAnalysisService, &mocks.MockLogger{})
+
+			req := httptest.NewRequest(http.MethodGet, "/api/analyze/example.com"+query, nil)
+			req = mux.SetURLVars(req, map[string]string{"domain": "example.com"})
+			w := httptest.NewRecorder()
+
+			handler.AnalyzeSingleDomain(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			mockAnalysisService.AssertNotCalled(t, "AnalyzeDomainWithOptions")
+		})
+	}
+}
+
+func TestHandler_AnalyzeBatchDomains_WithOptions(t *testing.T) {
+	// Arrange
+	mockAnalysisService := &httpMocks.MockAnalysisService{}
+	mockLogger := &mocks.MockLogger{}
+
+	handler := NewHandler(mockAnalysisService, mockLogger)
+
+	domains := []string{"a.com"}
+	opts := models.AnalysisOptions{ForceRefresh: true, TimeoutMs: 5000, CacheTTL: 60}
+	response := &models.BatchAnalysisResponse{Summary: models.BatchSummary{Total: 1, Succeeded: 1}}
+
+	mockLogger.On("LogInfo", mock.Anything, "batch_analysis", mock.AnythingOfType("string"), mock.Anything).Return()
+	mockAnalysisService.On("AnalyzeDomainsWithOptions", mock.Anything, domains, opts).Return(response, nil)
+	mockLogger.On("LogSuccess", mock.Anything, "batch_analysis", "", mock.AnythingOfType("string"), mock.Anything).Return()
+
+	body := `{"domains":["a.com"],"force_refresh":true,"timeout_ms":5000,"cache_ttl":60}`
+	req := httptest.NewRequest(http.MethodPost, "/api/batch-analysis", bytes.NewBufferString(body))
+	w := httptest.NewRecorder()
+
+	// Act
+	handler.AnalyzeBatchDomains(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockAnalysisService.AssertExpectations(t)
+}
+
+func TestHandler_AnalyzeBatchDomains_RejectedOptions(t *testing.T) {
+	// Arrange
+	mockAnalysisService := &httpMocks.MockAnalysisService{}
+	mockLogger := &mocks.MockLogger{}
+
+	handler := NewHandler(mockAnalysisService, mockLogger)
+
+	optsErr := fmt.Errorf("%w: timeout_ms must not exceed 60000", models.ErrInvalidAnalysisOptions)
+	mockLogger.On("LogInfo", mock.Anything, "batch_analysis", mock.AnythingOfType("string"), mock.Anything).Return()
+	mockAnalysisService.On("AnalyzeDomainsWithOptions", mock.Anything, mock.Anything, mock.Anything).Return(nil, optsErr)
+	mockLogger.On("LogError", mock.Anything, "batch_analysis", "", "Batch analysis failed", optsErr, models.LogSeverityMedium, mock.Anything).Return()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/batch-analysis", bytes.NewBufferString(`{"domains":["a.com"],"timeout_ms":999999}`))
+	w := httptest.NewRecorder()
+
+	// Act
+	handler.AnalyzeBatchDomains(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "timeout_ms")
+}
+
+func TestHandler_AnalyzeSingleDomain_CachedFailure(t *testing.T) {
+	// Arrange
+	mockAnalysisService := &httpMocks.MockAnalysisService{}
+	mockLogger := &mocks.MockLogger{}
+
+	handler := NewHandler(mockAnalysisService, mockLogger)
+
+	domain := "missing.com"
+	failure := &models.LookupFailure{Class: models.FailureClassNotFound, Message: "domain missing.com: failed to fetch ads.txt: ads.txt not found for domain: HTTP 404"}
+
+	mockLogger.On("LogInfo", mock.Anything, "domain_analysis", mock.AnythingOfType("string"), mock.Anything).Return()
+	mockAnalysisService.On("AnalyzeDomainWithOptions", mock.Anything, domain, models.AnalysisOptions{}).Return(nil, failure)
+	mockLogger.On("LogError", mock.Anything, "domain_analysis", domain, "Domain analysis failed", failure, models.LogSeverityMedium, mock.Anything).Return()
+
+	req := mux.SetURLVars(httptest.NewRequest(http.MethodGet, "/api/analyze/"+domain, nil), map[string]string{"domain": domain})
+	w := httptest.NewRecorder()
+
+	// Act
+	handler.AnalyzeSingleDomain(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	var response ErrorResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.True(t, response.Cached)
+	assert.Equal(t, failure.Message, response.Message)
+}
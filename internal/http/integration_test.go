@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"net/netip"
 	"testing"
 	"time"
 
@@ -18,7 +19,7 @@ import (
 )
 
 // createTestServer creates a test server with all dependencies mocked
-func createTestServer(t *testing.T, mockAnalysisService *httpMocks.MockAnalysisService, mockLogger *mocks.MockLogger, mockRateLimiter *httpMocks.MockRateLimiter) *Server {
+func createTestServer(t *testing.T, mockAnalysisService *httpMocks.MockAnalysisService, mockLogger *mocks.MockLogger, mockRateLimiter *httpMocks.MockRateLimiter, opts ...ServerOption) *Server {
 	handler := NewHandler(mockAnalysisService, mockLogger)
 
 	// Create server with test timeouts
@@ -29,6 +30,7 @@ func createTestServer(t *testing.T, mockAnalysisService *httpMocks.MockAnalysisS
 		mockRateLimiter,
 		10*time.Second, // readTimeout
 		10*time.Second, // writeTimeout
+		opts...,
 	)
 }
 
@@ -129,7 +131,7 @@ func TestIntegration_AnalyzeSingleDomain_Success(t *testing.T) {
 	mockLogger.On("LogInfo", mock.Anything, "http_request_start", "HTTP request received", mock.Anything).Return(); mockLogger.On("LogInfo", mock.Anything, "http_request_complete", "HTTP request processed", mock.Anything).Return()
 	mockRateLimiter.On("Allow", mock.AnythingOfType("string")).Return(true)
 	mockLogger.On("LogInfo", mock.Anything, "domain_analysis", mock.AnythingOfType("string"), mock.Anything).Return()
-	mockAnalysisService.On("AnalyzeDomain", mock.Anything, domain).Return(expectedAnalysis, nil)
+	mockAnalysisService.On("AnalyzeDomainWithOptions", mock.Anything, domain, models.AnalysisOptions{}).Return(expectedAnalysis, nil)
 	mockLogger.On("LogSuccess", mock.Anything, "domain_analysis", domain, "Successfully analyzed domain", mock.Anything).Return()
 
 	// Create request
@@ -186,7 +188,7 @@ func TestIntegration_AnalyzeSingleDomain_RateLimited(t *testing.T) {
 	// Assert
 	assert.Equal(t, http.StatusTooManyRequests, w.Code)
 	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
-	assert.Equal(t, "1", w.Header().Get("X-RateLimit-Retry-After"))
+	assert.Equal(t, "1", w.Header().Get("Retry-After"))
 
 	var response ErrorResponse
 	err := json.Unmarshal(w.Body.Bytes(), &response)
@@ -196,7 +198,7 @@ func TestIntegration_AnalyzeSingleDomain_RateLimited(t *testing.T) {
 	assert.Equal(t, "Please try again later", response.Message)
 
 	// Verify analysis service was not called
-	mockAnalysisService.AssertNotCalled(t, "AnalyzeDomain")
+	mockAnalysisService.AssertNotCalled(t, "AnalyzeDomainWithOptions")
 	mockLogger.AssertExpectations(t)
 	mockRateLimiter.AssertExpectations(t)
 }
@@ -235,7 +237,7 @@ func TestIntegration_AnalyzeBatchDomains_Success(t *testing.T) {
 	mockLogger.On("LogInfo", mock.Anything, "http_request_start", "HTTP request received", mock.Anything).Return(); mockLogger.On("LogInfo", mock.Anything, "http_request_complete", "HTTP request processed", mock.Anything).Return()
 	mockRateLimiter.On("Allow", mock.AnythingOfType("string")).Return(true)
 	mockLogger.On("LogInfo", mock.Anything, "batch_analysis", mock.AnythingOfType("string"), mock.Anything).Return()
-	mockAnalysisService.On("AnalyzeDomains", mock.Anything, domains).Return(expectedResponse, nil)
+	mockAnalysisService.On("AnalyzeDomainsWithOptions", mock.Anything, domains, models.AnalysisOptions{}).Return(expectedResponse, nil)
 	mockLogger.On("LogSuccess", mock.Anything, "batch_analysis", "", mock.AnythingOfType("string"), mock.Anything).Return()
 
 	// Create request
@@ -292,8 +294,8 @@ func TestIntegration_CorsHeaders(t *testing.T) {
 	// Assert CORS headers are applied
 	assert.Equal(t, http.StatusOK, w.Code)
 	assert.Equal(t, "*", w.Header().Get("Access-Control-Allow-Origin"))
-	assert.Equal(t, "GET, POST, OPTIONS", w.Header().Get("Access-Control-Allow-Methods"))
-	assert.Equal(t, "Content-Type, Authorization", w.Header().Get("Access-Control-Allow-Headers"))
+	assert.Equal(t, "GET, POST, DELETE, OPTIONS", w.Header().Get("Access-Control-Allow-Methods"))
+	assert.Equal(t, "Content-Type, Authorization, X-API-Key", w.Header().Get("Access-Control-Allow-Headers"))
 
 	// Verify mocks
 	mockLogger.AssertExpectations(t)
@@ -320,8 +322,8 @@ func TestIntegration_InvalidRoute(t *testing.T) {
 	assert.Equal(t, http.StatusNotFound, w.Code)
 
 	// Verify no analysis service calls for 404
-	mockAnalysisService.AssertNotCalled(t, "AnalyzeDomain")
-	mockAnalysisService.AssertNotCalled(t, "AnalyzeDomains")
+	mockAnalysisService.AssertNotCalled(t, "AnalyzeDomainWithOptions")
+	mockAnalysisService.AssertNotCalled(t, "AnalyzeDomainsWithOptions")
 }
 
 func TestIntegration_InvalidMethod(t *testing.T) {
@@ -344,7 +346,7 @@ func TestIntegration_InvalidMethod(t *testing.T) {
 	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
 
 	// Verify no analysis service calls for wrong method
-	mockAnalysisService.AssertNotCalled(t, "AnalyzeDomain")
+	mockAnalysisService.AssertNotCalled(t, "AnalyzeDomainWithOptions")
 }
 
 func TestIntegration_WithDifferentClientIPs(t *testing.T) {
@@ -366,6 +368,24 @@ func TestIntegration_WithDifferentClientIPs(t *testing.T) {
 			remoteAddr: "192.168.1.1:8080",
 			expectedIP: "203.0.113.200",
 		},
+		{
+			name:       "Forwarded header",
+			headers:    map[string]string{"Forwarded": `for="203.0.113.201:4711";proto=https`},
+			remoteAddr: "192.168.1.1:8080",
+			expectedIP: "203.0.113.201",
+		},
+		{
+			name:       "spoofed X-Forwarded-For from untrusted client",
+			headers:    map[string]string{"X-Forwarded-For": "203.0.113.195"},
+			remoteAddr: "10.0.0.5:12345",
+			expectedIP: "10.0.0.5",
+		},
+		{
+			name:       "spoofed entry before trusted proxy",
+			headers:    map[string]string{"X-Forwarded-For": "1.2.3.4, 203.0.113.195"},
+			remoteAddr: "192.168.1.1:8080",
+			expectedIP: "203.0.113.195",
+		},
 		{
 			name:       "RemoteAddr fallback",
 			headers:    map[string]string{},
@@ -381,7 +401,8 @@ func TestIntegration_WithDifferentClientIPs(t *testing.T) {
 			mockLogger := &mocks.MockLogger{}
 			mockRateLimiter := &httpMocks.MockRateLimiter{}
 
-			server := createTestServer(t, mockAnalysisService, mockLogger, mockRateLimiter)
+			// The load balancer at 192.168.1.1 is trusted
+			server := createTestServer(t, mockAnalysisService, mockLogger, mockRateLimiter, WithTrustedProxies([]netip.Prefix{netip.MustParsePrefix("192.168.1.0/24")}))
 
 			// Setup mocks - verify the correct IP is passed to rate limiter
 			mockLogger.On("LogInfo", mock.Anything, "http_request_start", "HTTP request received", mock.Anything).Return(); mockLogger.On("LogInfo", mock.Anything, "http_request_complete", "HTTP request processed", mock.Anything).Return()
@@ -424,7 +445,7 @@ func TestIntegration_ErrorScenarios(t *testing.T) {
 	mockLogger.On("LogInfo", mock.Anything, "http_request_start", "HTTP request received", mock.Anything).Return(); mockLogger.On("LogInfo", mock.Anything, "http_request_complete", "HTTP request processed", mock.Anything).Return()
 	mockRateLimiter.On("Allow", mock.AnythingOfType("string")).Return(true)
 	mockLogger.On("LogInfo", mock.Anything, "domain_analysis", mock.AnythingOfType("string"), mock.Anything).Return()
-	mockAnalysisService.On("AnalyzeDomain", mock.Anything, domain).Return(nil, serviceError)
+	mockAnalysisService.On("AnalyzeDomainWithOptions", mock.Anything, domain, models.AnalysisOptions{}).Return(nil, serviceError)
 	mockLogger.On("LogError", mock.Anything, "domain_analysis", domain, "Domain analysis failed", serviceError, models.LogSeverityMedium, mock.Anything).Return()
 
 	// Create request
@@ -480,7 +501,7 @@ func TestIntegration_MiddlewareOrder(t *testing.T) {
 	assert.Equal(t, http.StatusTooManyRequests, w.Code)
 
 	// Verify that handler was never called due to rate limiting
-	mockAnalysisService.AssertNotCalled(t, "AnalyzeDomain")
+	mockAnalysisService.AssertNotCalled(t, "AnalyzeDomainWithOptions")
 
 	// Verify mocks
 	mockLogger.AssertExpectations(t)
@@ -0,0 +1,52 @@
+package http
+
+import (
+	"net/http"
+
+	"Perion_Assignment/internal/ipfilter"
+	"Perion_Assignment/internal/logger"
+	"Perion_Assignment/internal/models"
+)
+
+// IPListsAdminHandler contains the HTTP handlers for the IP list admin API
+type IPListsAdminHandler struct {
+	*Handler
+	ipFilter ipfilter.Service
+}
+
+// NewIPListsAdminHandler creates a new IP list admin HTTP handler
+func NewIPListsAdminHandler(handler *Handler, ipFilter ipfilter.Service) *IPListsAdminHandler {
+	return &IPListsAdminHandler{
+		Handler:  handler,
+		ipFilter: ipFilter,
+	}
+}
+
+// GetLists handles GET /api/admin/ip-lists
+func (h *IPListsAdminHandler) GetLists(w http.ResponseWriter, r *http.Request) {
+	if err := h.writeJSONResponse(w, r, http.StatusOK, h.ipFilter.Lists()); err != nil {
+		h.logger.LogError(r.Context(), logger.OpIPFilter, "", "Failed to encode IP lists response", err, models.LogSeverityLow, nil)
+	}
+}
+
+// ReloadLists handles POST /api/admin/ip-lists/reload
+// On failure the previously loaded lists stay in effect.
+func (h *IPListsAdminHandler) ReloadLists(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if err := h.ipFilter.Reload(); err != nil {
+		h.logger.LogError(ctx, logger.OpIPFilter, "", "Failed to reload IP lists", err, models.LogSeverityMedium, nil)
+		h.writeErrorResponse(w, r, http.StatusInternalServerError, "failed to reload IP lists", err.Error())
+		return
+	}
+
+	lists := h.ipFilter.Lists()
+	h.logger.LogSuccess(ctx, logger.OpIPFilter, "", "Reloaded IP lists", map[string]interface{}{
+		"allow": len(lists.Allow),
+		"deny":  len(lists.Deny),
+	})
+
+	if err := h.writeJSONResponse(w, r, http.StatusOK, lists); err != nil {
+		h.logger.LogError(ctx, logger.OpIPFilter, "", "Failed to encode IP lists response", err, models.LogSeverityLow, nil)
+	}
+}
@@ -0,0 +1,133 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	httpMocks "Perion_Assignment/internal/http/mocks"
+	"Perion_Assignment/internal/ipfilter"
+	"Perion_Assignment/internal/mocks"
+	"Perion_Assignment/internal/models"
+	"Perion_Assignment/internal/ratelimit"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// createIPFilterTestServer creates a test server filtering requests with the given lists
+func createIPFilterTestServer(t *testing.T, mockAnalysisService *httpMocks.MockAnalysisService, rateLimiter ratelimit.Service, lists models.IPLists, path string) (*Server, ipfilter.Service) {
+	ipFilter, err := ipfilter.NewFilter(lists, path)
+	require.NoError(t, err)
+
+	mockLogger := mocks.NewAnyLogger()
+	handler := NewHandler(mockAnalysisService, mockLogger)
+	server := NewServer("localhost:0", handler, mockLogger, rateLimiter, 10*time.Second, 10*time.Second, WithIPFilter(ipFilter))
+	server.RegisterIPListsAdminRoutes(NewIPListsAdminHandler(handler, ipFilter), "secret-token")
+	return server, ipFilter
+}
+
+// serveFrom sends a request from the given client address
+func serveFrom(server *Server, method, path, remoteAddr string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	req.RemoteAddr = remoteAddr
+	req.Header.Set("Authorization", "Bearer secret-token")
+	w := httptest.NewRecorder()
+	server.server.Handler.ServeHTTP(w, req)
+	return w
+}
+
+func TestIPFilter_DeniedRequest(t *testing.T) {
+	mockAnalysis := &httpMocks.MockAnalysisService{}
+	mockRateLimiter := &httpMocks.MockRateLimiter{}
+	server, _ := createIPFilterTestServer(t, mockAnalysis, mockRateLimiter, models.IPLists{Deny: []string{"198.51.100.0/24"}}, "")
+
+	w := serveFrom(server, http.MethodGet, "/api/analyze/cnn.com", "198.51.100.7:12345")
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	assert.NotEmpty(t, w.Header().Get("X-Request-ID"))
+	var response map[string]string
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "forbidden", response["error"])
+
+	// Denied requests don't consume rate limit tokens
+	mockRateLimiter.AssertNotCalled(t, "Allow", mock.Anything)
+	mockAnalysis.AssertNotCalled(t, "AnalyzeDomainWithOptions", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestIPFilter_AllowlistedExemptFromPerIPLimit(t *testing.T) {
+	mockAnalysis := &httpMocks.MockAnalysisService{}
+	mockAnalysis.On("AnalyzeDomainWithOptions", mock.Anything, "cnn.com", mock.Anything).Return(&models.DomainAnalysis{Domain: "cnn.com"}, nil)
+
+	// 2 requests per IP, 5 overall
+	limiter := ratelimit.NewTwoTierRateLimiter(5, 5, 2, 2)
+	server, _ := createIPFilterTestServer(t, mockAnalysis, limiter, models.IPLists{Allow: []string{"10.0.0.0/8"}}, "")
+
+	for i := 0; i < 5; i++ {
+		w := serveFrom(server, http.MethodGet, "/api/analyze/cnn.com", "10.0.0.1:12345")
+		assert.Equal(t, http.StatusOK, w.Code, "request %d", i+1)
+		assert.Equal(t, "5", w.Header().Get("RateLimit-Limit"))
+	}
+
+	// The global limit still applies
+	w := serveFrom(server, http.MethodGet, "/api/analyze/cnn.com", "10.0.0.1:12345")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+}
+
+func TestIPFilter_AllowlistedWithoutGlobalLimiter(t *testing.T) {
+	mockAnalysis := &httpMocks.MockAnalysisService{}
+	mockAnalysis.On("AnalyzeDomainWithOptions", mock.Anything, "cnn.com", mock.Anything).Return(&models.DomainAnalysis{Domain: "cnn.com"}, nil)
+	mockRateLimiter := &httpMocks.MockRateLimiter{}
+	server, _ := createIPFilterTestServer(t, mockAnalysis, mockRateLimiter, models.IPLists{Allow: []string{"10.0.0.1"}}, "")
+
+	w := serveFrom(server, http.MethodGet, "/api/analyze/cnn.com", "10.0.0.1:12345")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockRateLimiter.AssertNotCalled(t, "Allow", mock.Anything)
+}
+
+func TestIPListsAdminHandler_GetLists(t *testing.T) {
+	server, _ := createIPFilterTestServer(t, &httpMocks.MockAnalysisService{}, &httpMocks.MockRateLimiter{}, models.IPLists{
+		Allow: []string{"10.0.0.0/8"},
+		Deny:  []string{"198.51.100.0/24"},
+	}, "")
+
+	w := serveFrom(server, http.MethodGet, "/api/admin/ip-lists", "10.0.0.1:12345")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response models.IPListsResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, []string{"10.0.0.0/8"}, response.Allow)
+	assert.Equal(t, []string{"198.51.100.0/24"}, response.Deny)
+	assert.False(t, response.LoadedAt.IsZero())
+}
+
+func TestIPListsAdminHandler_ReloadLists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ip_lists.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"deny":["198.51.100.0/24"]}`), 0o600))
+
+	server, ipFilter := createIPFilterTestServer(t, &httpMocks.MockAnalysisService{}, &httpMocks.MockRateLimiter{}, models.IPLists{Allow: []string{"10.0.0.0/8"}}, path)
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"deny":["203.0.113.0/24"]}`), 0o600))
+	w := serveFrom(server, http.MethodPost, "/api/admin/ip-lists/reload", "10.0.0.1:12345")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response models.IPListsResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, []string{"203.0.113.0/24"}, response.Deny)
+	assert.Equal(t, ipfilter.VerdictDeny, ipFilter.Check("203.0.113.1"))
+	assert.Equal(t, ipfilter.VerdictNone, ipFilter.Check("198.51.100.1"))
+
+	// An invalid file keeps the lists in effect
+	require.NoError(t, os.WriteFile(path, []byte(`{"deny":["bogus"]}`), 0o600))
+	w = serveFrom(server, http.MethodPost, "/api/admin/ip-lists/reload", "10.0.0.1:12345")
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Equal(t, ipfilter.VerdictDeny, ipFilter.Check("203.0.113.1"))
+}
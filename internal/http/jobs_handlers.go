@@ -0,0 +1,165 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"Perion_Assignment/internal/jobs"
+	"Perion_Assignment/internal/logger"
+	"Perion_Assignment/internal/models"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	// defaultResultsPageSize is used when no limit query parameter is given
+	defaultResultsPageSize = 100
+
+	// maxResultsPageSize bounds the limit query parameter
+	maxResultsPageSize = 1000
+)
+
+// JobsHandler contains the HTTP handlers for asynchronous batch jobs
+type JobsHandler struct {
+	*Handler
+	jobsService jobs.Service
+	maxDomains  int
+}
+
+// NewJobsHandler creates a new jobs HTTP handler
+func NewJobsHandler(handler *Handler, jobsService jobs.Service, maxDomains int) *JobsHandler {
+	return &JobsHandler{
+		Handler:     handler,
+		jobsService: jobsService,
+		maxDomains:  maxDomains,
+	}
+}
+
+// SubmitJob handles POST /api/jobs
+func (h *JobsHandler) SubmitJob(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var request models.JobRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		h.logger.LogError(ctx, logger.OpBatchJob, "", "Invalid request body", err, models.LogSeverityLow, nil)
+		h.writeErrorResponse(w, r, http.StatusBadRequest, "invalid request body", err.Error())
+		return
+	}
+
+	if len(request.Domains) == 0 {
+		h.writeErrorResponse(w, r, http.StatusBadRequest, "domains array cannot be empty", "")
+		return
+	}
+
+	if h.maxDomains > 0 && len(request.Domains) > h.maxDomains {
+		h.writeErrorResponse(w, r, http.StatusBadRequest, "too many domains", fmt.Sprintf("Maximum %d domains per job", h.maxDomains))
+		return
+	}
+
+	// Duplicates are dropped by Submit, so they don't count against the quota
+	domains := jobs.UniqueDomains(request.Domains)
+	if !h.consumeQuota(w, r, len(domains)) {
+		return
+	}
+
+	job, err := h.jobsService.Submit(ctx, domains)
+	if err != nil {
+		h.refundQuota(r, len(domains))
+		h.logger.LogError(ctx, logger.OpBatchJob, "", "Failed to submit batch job", err, models.LogSeverityMedium, nil)
+		h.writeErrorResponse(w, r, h.getStatusCodeForJobError(err), "failed to submit job", err.Error())
+		return
+	}
+
+	w.Header().Set("Location", "/api/jobs/"+job.ID)
+	if err := h.writeJSONResponse(w, r, http.StatusAccepted, job); err != nil {
+		h.logger.LogError(ctx, logger.OpBatchJob, job.ID, "Failed to encode job response", err, models.LogSeverityLow, nil)
+	}
+}
+
+// GetJob handles GET /api/jobs/{id}
+func (h *JobsHandler) GetJob(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id := mux.Vars(r)["id"]
+
+	job, err := h.jobsService.Get(ctx, id)
+	if err != nil {
+		h.writeErrorResponse(w, r, h.getStatusCodeForJobError(err), "failed to get job", err.Error())
+		return
+	}
+
+	if err := h.writeJSONResponse(w, r, http.StatusOK, job); err != nil {
+		h.logger.LogError(ctx, logger.OpBatchJob, id, "Failed to encode job response", err, models.LogSeverityLow, nil)
+	}
+}
+
+// GetJobResults handles GET /api/jobs/{id}/results?offset=&limit=
+func (h *JobsHandler) GetJobResults(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id := mux.Vars(r)["id"]
+
+	offset, err := getIntQueryParam(r, "offset", 0)
+	if err != nil || offset < 0 {
+		h.writeErrorResponse(w, r, http.StatusBadRequest, "invalid offset", "offset must be a non-negative integer")
+		return
+	}
+
+	limit, err := getIntQueryParam(r, "limit", defaultResultsPageSize)
+	if err != nil || limit <= 0 || limit > maxResultsPageSize {
+		h.writeErrorResponse(w, r, http.StatusBadRequest, "invalid limit", fmt.Sprintf("limit must be between 1 and %d", maxResultsPageSize))
+		return
+	}
+
+	page, err := h.jobsService.Results(ctx, id, offset, limit)
+	if err != nil {
+		h.writeErrorResponse(w, r, h.getStatusCodeForJobError(err), "failed to get job results", err.Error())
+		return
+	}
+
+	if err := h.writeJSONResponse(w, r, http.StatusOK, page); err != nil {
+		h.logger.LogError(ctx, logger.OpBatchJob, id, "Failed to encode job results response", err, models.LogSeverityLow, nil)
+	}
+}
+
+// CancelJob handles POST /api/jobs/{id}/cancel
+func (h *JobsHandler) CancelJob(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id := mux.Vars(r)["id"]
+
+	job, err := h.jobsService.Cancel(ctx, id)
+	if err != nil {
+		h.writeErrorResponse(w, r, h.getStatusCodeForJobError(err), "failed to cancel job", err.Error())
+		return
+	}
+
+	if err := h.writeJSONResponse(w, r, http.StatusOK, job); err != nil {
+		h.logger.LogError(ctx, logger.OpBatchJob, id, "Failed to encode job response", err, models.LogSeverityLow, nil)
+	}
+}
+
+// getStatusCodeForJobError determines the appropriate HTTP status code for a job error
+func (h *JobsHandler) getStatusCodeForJobError(err error) int {
+	switch {
+	case errors.Is(err, models.ErrJobNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, models.ErrJobFinished):
+		return http.StatusConflict
+	case errors.Is(err, models.ErrJobQueueFull):
+		return http.StatusServiceUnavailable
+	case errors.Is(err, models.ErrInvalidDomain):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// getIntQueryParam parses an integer query parameter, returning defaultValue when absent
+func getIntQueryParam(r *http.Request, key string, defaultValue int) (int, error) {
+	value := r.URL.Query().Get(key)
+	if value == "" {
+		return defaultValue, nil
+	}
+	return strconv.Atoi(value)
+}
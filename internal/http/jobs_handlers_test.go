@@ -0,0 +1,185 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	httpMocks "Perion_Assignment/internal/http/mocks"
+	"Perion_Assignment/internal/mocks"
+	"Perion_Assignment/internal/models"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestJobsHandler(maxDomains int) (*JobsHandler, *httpMocks.MockJobsService, *mocks.MockLogger) {
+	mockJobs := &httpMocks.MockJobsService{}
+	mockLogger := &mocks.MockLogger{}
+	handler := NewJobsHandler(NewHandler(&httpMocks.MockAnalysisService{}, mockLogger), mockJobs, maxDomains)
+	return handler, mockJobs, mockLogger
+}
+
+func TestJobsHandler_SubmitJob_Accepted(t *testing.T) {
+	handler, mockJobs, _ := newTestJobsHandler(10)
+
+	job := &models.Job{ID: "job-1", Status: models.JobStatusPending, Total: 2, Pending: 2, CreatedAt: time.Now().UTC()}
+	mockJobs.On("Submit", mock.Anything, []string{"a.com", "b.com"}).Return(job, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/jobs", bytes.NewBufferString(`{"domains":["a.com","b.com"]}`))
+	w := httptest.NewRecorder()
+
+	handler.SubmitJob(w, req)
+
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	assert.Equal(t, "/api/jobs/job-1", w.Header().Get("Location"))
+
+	var response models.Job
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "job-1", response.ID)
+	assert.Equal(t, 2, response.Pending)
+	mockJobs.AssertExpectations(t)
+}
+
+func TestJobsHandler_SubmitJob_Validation(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+	}{
+		{name: "empty domains", body: `{"domains":[]}`},
+		{name: "too many domains", body: `{"domains":["a.com","b.com","c.com"]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, mockJobs, _ := newTestJobsHandler(2)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/jobs", bytes.NewBufferString(tt.body))
+			w := httptest.NewRecorder()
+
+			handler.SubmitJob(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			mockJobs.AssertNotCalled(t, "Submit")
+		})
+	}
+}
+
+func TestJobsHandler_SubmitJob_QueueFull(t *testing.T) {
+	handler, mockJobs, mockLogger := newTestJobsHandler(10)
+
+	mockJobs.On("Submit", mock.Anything, mock.Anything).Return(nil, models.ErrJobQueueFull)
+	mockLogger.On("LogError", mock.Anything, "batch_job", "", "Failed to submit batch job", models.ErrJobQueueFull, models.LogSeverityMedium, mock.Anything).Return()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/jobs", bytes.NewBufferString(`{"domains":["a.com"]}`))
+	w := httptest.NewRecorder()
+
+	handler.SubmitJob(w, req)
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+}
+
+func TestJobsHandler_SubmitJob_ChargesUniqueDomains(t *testing.T) {
+	handler, mockJobs, mockLogger := newTestJobsHandler(10)
+	mockAPIKeys := &httpMocks.MockAPIKeyService{}
+	handler.apiKeys = mockAPIKeys
+
+	mockAPIKeys.On("ConsumeDomains", mock.Anything, testAPIKey, 2).Return(&models.APIKeyUsage{Domains: 2}, nil).Twice()
+	mockJobs.On("Submit", mock.Anything, []string{"a.com", "b.com"}).Return(&models.Job{ID: "job-1"}, nil).Once()
+
+	submit := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/jobs", bytes.NewBufferString(`{"domains":["a.com","A.com ","b.com","a.com"]}`))
+		req = req.WithContext(context.WithValue(req.Context(), apiKeyContextKey{}, testAPIKey))
+		w := httptest.NewRecorder()
+		handler.SubmitJob(w, req)
+		return w
+	}
+
+	// Duplicates aren't charged
+	assert.Equal(t, http.StatusAccepted, submit().Code)
+
+	// A job that isn't queued gives its domains back
+	mockJobs.On("Submit", mock.Anything, mock.Anything).Return(nil, models.ErrJobQueueFull).Once()
+	mockAPIKeys.On("RefundDomains", mock.Anything, testAPIKey, 2).Return(nil).Once()
+	mockLogger.On("LogError", mock.Anything, "batch_job", "", "Failed to submit batch job", models.ErrJobQueueFull, models.LogSeverityMedium, mock.Anything).Return()
+
+	assert.Equal(t, http.StatusServiceUnavailable, submit().Code)
+	mockAPIKeys.AssertExpectations(t)
+	mockJobs.AssertExpectations(t)
+}
+
+func TestJobsHandler_GetJob(t *testing.T) {
+	handler, mockJobs, _ := newTestJobsHandler(10)
+
+	mockJobs.On("Get", mock.Anything, "job-1").Return(&models.Job{ID: "job-1", Status: models.JobStatusRunning}, nil)
+	mockJobs.On("Get", mock.Anything, "missing").Return(nil, models.ErrJobNotFound)
+
+	req := mux.SetURLVars(httptest.NewRequest(http.MethodGet, "/api/jobs/job-1", nil), map[string]string{"id": "job-1"})
+	w := httptest.NewRecorder()
+	handler.GetJob(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	req = mux.SetURLVars(httptest.NewRequest(http.MethodGet, "/api/jobs/missing", nil), map[string]string{"id": "missing"})
+	w = httptest.NewRecorder()
+	handler.GetJob(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestJobsHandler_GetJobResults_Pagination(t *testing.T) {
+	handler, mockJobs, _ := newTestJobsHandler(10)
+
+	page := &models.JobResultsResponse{JobID: "job-1", Results: []models.DomainResult{{Domain: "a.com", Success: true}}, Offset: 5, Limit: 1, Total: 6}
+	mockJobs.On("Results", mock.Anything, "job-1", 5, 1).Return(page, nil)
+
+	req := mux.SetURLVars(httptest.NewRequest(http.MethodGet, "/api/jobs/job-1/results?offset=5&limit=1", nil), map[string]string{"id": "job-1"})
+	w := httptest.NewRecorder()
+
+	handler.GetJobResults(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response models.JobResultsResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, 6, response.Total)
+	assert.Len(t, response.Results, 1)
+	mockJobs.AssertExpectations(t)
+}
+
+func TestJobsHandler_GetJobResults_InvalidParams(t *testing.T) {
+	for _, query := range []string{"?offset=-1", "?limit=0", "?limit=5000", "?offset=abc"} {
+		t.Run(query, func(t *testing.T) {
+			handler, mockJobs, _ := newTestJobsHandler(10)
+
+			req := mux.SetURLVars(httptest.NewRequest(http.MethodGet, "/api/jobs/job-1/results"+query, nil), map[string]string{"id": "job-1"})
+			w := httptest.NewRecorder()
+
+			handler.GetJobResults(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			mockJobs.AssertNotCalled(t, "Results")
+		})
+	}
+}
+
+func TestJobsHandler_CancelJob(t *testing.T) {
+	handler, mockJobs, _ := newTestJobsHandler(10)
+
+	mockJobs.On("Cancel", mock.Anything, "job-1").Return(&models.Job{ID: "job-1", Status: models.JobStatusCancelled}, nil)
+	mockJobs.On("Cancel", mock.Anything, "job-2").Return(&models.Job{ID: "job-2", Status: models.JobStatusCompleted}, models.ErrJobFinished)
+
+	req := mux.SetURLVars(httptest.NewRequest(http.MethodPost, "/api/jobs/job-1/cancel", nil), map[string]string{"id": "job-1"})
+	w := httptest.NewRecorder()
+	handler.CancelJob(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	req = mux.SetURLVars(httptest.NewRequest(http.MethodPost, "/api/jobs/job-2/cancel", nil), map[string]string{"id": "job-2"})
+	w = httptest.NewRecorder()
+	handler.CancelJob(w, req)
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
@@ -0,0 +1,55 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+
+	"Perion_Assignment/internal/concurrency"
+	"Perion_Assignment/internal/logger"
+	"Perion_Assignment/internal/models"
+)
+
+// shedPolicies are the routes whose requests fetch ads.txt files, and are shed under overload
+var shedPolicies = map[string]bool{
+	PolicyAnalyze: true,
+	PolicyBatch:   true,
+}
+
+// loadSheddingMiddleware rejects analysis requests with 503 while the fetch limiter's queue
+// is full, instead of letting them wait until the server's write timeout
+// Expects LogEvent to already be in context from logging middleware
+func loadSheddingMiddleware(limiter concurrency.Service, policies *routePolicies, loggerService logger.Service) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			policy := policies.of(r)
+			if !shedPolicies[policy.Name] || !limiter.Saturated() {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			ctx := r.Context()
+			logEvent := logger.GetLogEvent(ctx)
+			retryAfter := overloadRetryAfter(limiter)
+			loggerService.LogError(ctx, logger.OpLoadShed, "", "Request shed under overload", models.ErrOverloaded, models.LogSeverityMedium, map[string]interface{}{
+				"path":        r.URL.Path,
+				"method":      r.Method,
+				"policy":      policy.Name,
+				"retry_after": retryAfter,
+			})
+
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("X-Request-ID", logEvent.ProcessID)
+			w.Header().Set("Retry-After", strconv.FormatInt(retryAfter, 10))
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte(`{"error":"overloaded","message":"The server is overloaded, please try again later"}`))
+		})
+	}
+}
+
+// overloadRetryAfter returns the Retry-After seconds for a request shed by the limiter
+func overloadRetryAfter(limiter concurrency.Service) int64 {
+	if limiter == nil {
+		return 1
+	}
+	return max(ceilSeconds(limiter.RetryAfter()), 1)
+}
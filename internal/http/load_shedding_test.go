@@ -0,0 +1,100 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"Perion_Assignment/internal/concurrency"
+	httpMocks "Perion_Assignment/internal/http/mocks"
+	"Perion_Assignment/internal/mocks"
+	"Perion_Assignment/internal/models"
+	"Perion_Assignment/internal/ratelimit"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// stubFetchLimiter is a fetch limiter whose saturation is set by the test
+type stubFetchLimiter struct {
+	saturated  bool
+	retryAfter time.Duration
+}
+
+func (l *stubFetchLimiter) Acquire(ctx context.Context) (concurrency.Release, error) {
+	if l.saturated {
+		return nil, models.ErrOverloaded
+	}
+	return func(concurrency.Outcome) {}, nil
+}
+
+func (l *stubFetchLimiter) Saturated() bool           { return l.saturated }
+func (l *stubFetchLimiter) RetryAfter() time.Duration { return l.retryAfter }
+
+func newLoadSheddingTestServer(analysis *httpMocks.MockAnalysisService, limiter concurrency.Service) *Server {
+	mockLogger := mocks.NewAnyLogger()
+	return NewServer(
+		"localhost:0",
+		NewHandler(analysis, mockLogger),
+		mockLogger,
+		ratelimit.NewTwoTierRateLimiter(100, 100, 100, 100),
+		10*time.Second,
+		10*time.Second,
+		WithLoadShedding(limiter),
+	)
+}
+
+func TestLoadShedding_SaturatedShedsAnalyses(t *testing.T) {
+	mockAnalysis := &httpMocks.MockAnalysisService{}
+	server := newLoadSheddingTestServer(mockAnalysis, &stubFetchLimiter{saturated: true, retryAfter: 2500 * time.Millisecond})
+
+	requests := []*http.Request{
+		httptest.NewRequest(http.MethodGet, "/api/analyze/cnn.com", nil),
+		httptest.NewRequest(http.MethodPost, "/api/batch-analysis", strings.NewReader(`{"domains":["cnn.com"]}`)),
+		httptest.NewRequest(http.MethodPost, "/api/batch-analysis/stream", strings.NewReader(`{"domains":["cnn.com"]}`)),
+	}
+	for _, req := range requests {
+		w := httptest.NewRecorder()
+		server.server.Handler.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code, req.URL.Path)
+		assert.Equal(t, "3", w.Header().Get("Retry-After"))
+		assert.Contains(t, w.Body.String(), `"error":"overloaded"`)
+	}
+	mockAnalysis.AssertNotCalled(t, "AnalyzeDomainWithOptions", mock.Anything, mock.Anything, mock.Anything)
+
+	// Health checks are never shed
+	w := httptest.NewRecorder()
+	server.server.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/health", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestLoadShedding_NotSaturated(t *testing.T) {
+	mockAnalysis := &httpMocks.MockAnalysisService{}
+	mockAnalysis.On("AnalyzeDomainWithOptions", mock.Anything, "cnn.com", mock.Anything).Return(&models.DomainAnalysis{Domain: "cnn.com"}, nil)
+	server := newLoadSheddingTestServer(mockAnalysis, &stubFetchLimiter{})
+
+	w := httptest.NewRecorder()
+	server.server.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/analyze/cnn.com", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get("Retry-After"))
+}
+
+func TestAnalyzeSingleDomain_OverloadedFetch(t *testing.T) {
+	// The queue filled up after the request got past the middleware
+	mockAnalysis := &httpMocks.MockAnalysisService{}
+	mockAnalysis.On("AnalyzeDomainWithOptions", mock.Anything, "cnn.com", mock.Anything).
+		Return(nil, models.NewDomainError("cnn.com", "failed to fetch ads.txt", fmt.Errorf("fetch: %w", models.ErrOverloaded)))
+	server := newLoadSheddingTestServer(mockAnalysis, &stubFetchLimiter{retryAfter: 200 * time.Millisecond})
+
+	w := httptest.NewRecorder()
+	server.server.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/analyze/cnn.com", nil))
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, "1", w.Header().Get("Retry-After"))
+}
@@ -2,13 +2,18 @@ package http
 
 import (
 	"bytes"
+	"context"
+	"crypto/subtle"
+	"errors"
 	"fmt"
 	"io"
-	"net"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
+	"Perion_Assignment/internal/apikeys"
+	"Perion_Assignment/internal/ipfilter"
 	"Perion_Assignment/internal/logger"
 	"Perion_Assignment/internal/models"
 	"Perion_Assignment/internal/ratelimit"
@@ -17,12 +22,14 @@ import (
 )
 
 // loggingMiddleware creates LogEvent and logs HTTP requests
-func loggingMiddleware(loggerService logger.Service) func(http.Handler) http.Handler {
+// The client IP is resolved by clientIPs (nil trusts no proxy).
+func loggingMiddleware(loggerService logger.Service, clientIPs *clientIPResolver) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			// Extract client IP and create LogEvent for this request
-			clientIP := getClientIP(r)
+			clientIP, proxyChain := clientIPs.resolve(r)
 			logEvent := logger.NewRequestLogEvent(clientIP)
+			logEvent.ProxyChain = proxyChain
 
 			// Add LogEvent to request context
 			ctx := logger.WithLogEvent(r.Context(), logEvent)
@@ -64,6 +71,11 @@ func loggingMiddleware(loggerService logger.Service) func(http.Handler) http.Han
 				requestMetadata["url_params"] = urlParams
 			}
 
+			// Add the forwarding chain the client IP was resolved from
+			if len(proxyChain) > 0 {
+				requestMetadata["proxy_chain"] = proxyChain
+			}
+
 			// Add body if present (and not too large)
 			if bodyStr != "" {
 				requestMetadata["body"] = bodyStr
@@ -98,8 +110,8 @@ func corsMiddleware() func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			w.Header().Set("Access-Control-Allow-Origin", "*")
-			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
-			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, DELETE, OPTIONS")
+			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-API-Key")
 
 			if r.Method == http.MethodOptions {
 				w.WriteHeader(http.StatusOK)
@@ -146,29 +158,260 @@ func recoveryMiddleware(loggerService logger.Service) func(http.Handler) http.Ha
 	}
 }
 
+// ipAllowlistedKey marks requests from allowlisted networks in the request context
+type ipAllowlistedKey struct{}
+
+// isIPAllowlisted reports whether a request comes from an allowlisted network
+func isIPAllowlisted(ctx context.Context) bool {
+	allowlisted, _ := ctx.Value(ipAllowlistedKey{}).(bool)
+	return allowlisted
+}
+
+// ipFilterMiddleware rejects requests from denylisted client IPs with 403 and marks
+// requests from allowlisted ones, which are exempt from per-IP rate limits
+// Expects LogEvent to already be in context from logging middleware
+func ipFilterMiddleware(ipFilter ipfilter.Service, loggerService logger.Service) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+			logEvent := logger.GetLogEvent(ctx)
+
+			switch ipFilter.Check(logEvent.ClientIP) {
+			case ipfilter.VerdictDeny:
+				loggerService.LogError(ctx, logger.OpIPFilter, "", "Request from denylisted IP", models.ErrIPDenied, models.LogSeverityMedium, map[string]interface{}{
+					"path":   r.URL.Path,
+					"method": r.Method,
+				})
+
+				w.Header().Set("Content-Type", "application/json")
+				w.Header().Set("X-Request-ID", logEvent.ProcessID)
+				w.WriteHeader(http.StatusForbidden)
+				_, _ = w.Write([]byte(`{"error":"forbidden","message":"Requests from this address are not allowed"}`))
+				return
+			case ipfilter.VerdictAllow:
+				r = r.WithContext(context.WithValue(ctx, ipAllowlistedKey{}, true))
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// apiKeyHeader carries the API key of a request
+const apiKeyHeader = "X-API-Key"
+
+// publicPaths can be requested without an API key even when keys are required
+var publicPaths = map[string]bool{
+	"/health": true,
+	"/ready":  true,
+}
+
+// apiKeyContextKey is used to store the authenticated API key in the request context
+type apiKeyContextKey struct{}
+
+// apiKeyFromContext returns the authenticated API key of a request, or nil
+func apiKeyFromContext(ctx context.Context) *models.APIKey {
+	key, _ := ctx.Value(apiKeyContextKey{}).(*models.APIKey)
+	return key
+}
+
+// apiKeyMiddleware authenticates requests carrying an X-API-Key header
+// A request with an unknown key is rejected; a request without a key is rejected only when
+// keys are required, and is otherwise limited by client IP.
+// Expects LogEvent to already be in context from logging middleware
+func apiKeyMiddleware(apiKeys apikeys.Service, required bool, loggerService logger.Service) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+			secret := r.Header.Get(apiKeyHeader)
+
+			if secret == "" {
+				if required && !publicPaths[r.URL.Path] && r.Method != http.MethodOptions {
+					writeUnauthorizedAPIKey(w, r, "An API key is required")
+					return
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			key, err := apiKeys.Authenticate(ctx, secret)
+			if errors.Is(err, models.ErrInvalidAPIKey) {
+				loggerService.LogError(ctx, logger.OpAPIKeyAuth, "", "Invalid API key", err, models.LogSeverityMedium, map[string]interface{}{
+					"path":   r.URL.Path,
+					"method": r.Method,
+				})
+				writeUnauthorizedAPIKey(w, r, "The API key is invalid or disabled")
+				return
+			}
+			if err != nil {
+				loggerService.LogError(ctx, logger.OpAPIKeyAuth, "", "Failed to authenticate API key", err, models.LogSeverityHigh, nil)
+
+				logEvent := logger.GetLogEvent(ctx)
+				w.Header().Set("Content-Type", "application/json")
+				w.Header().Set("X-Request-ID", logEvent.ProcessID)
+				w.WriteHeader(http.StatusServiceUnavailable)
+				_, _ = w.Write([]byte(`{"error":"authentication unavailable","message":"Please try again later"}`))
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(context.WithValue(ctx, apiKeyContextKey{}, key)))
+		})
+	}
+}
+
+// apiKeyUsageMiddleware counts the requests made with an API key
+// It runs after rate limiting and load shedding, so rejected requests aren't counted.
+func apiKeyUsageMiddleware(apiKeys apikeys.Service, loggerService logger.Service) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+
+			// Usage counters must not fail the request
+			if key := apiKeyFromContext(ctx); key != nil {
+				if err := apiKeys.RecordRequest(ctx, key); err != nil {
+					loggerService.LogError(ctx, logger.OpAPIKeyAuth, key.ID, "Failed to record API key usage", err, models.LogSeverityLow, nil)
+				}
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// writeUnauthorizedAPIKey rejects a request without a valid API key
+func writeUnauthorizedAPIKey(w http.ResponseWriter, r *http.Request, message string) {
+	logEvent := logger.GetLogEvent(r.Context())
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Request-ID", logEvent.ProcessID)
+	w.WriteHeader(http.StatusUnauthorized)
+	_, _ = fmt.Fprintf(w, `{"error":"unauthorized","message":%q}`, message)
+}
+
 // rateLimitingMiddleware applies rate limiting to requests
 // Expects LogEvent to already be in context from logging middleware
-func rateLimitingMiddleware(rateLimiter ratelimit.Service, loggerService logger.Service) func(http.Handler) http.Handler {
+// Limiters that implement ratelimit.Reporter get RateLimit-* headers on every response.
+// Requests authenticated by an API key are limited by key, at the rate of its tier,
+// when the limiter implements ratelimit.ClientLimiter.
+// Limiters that implement ratelimit.CostLimiter charge requests the tokens returned by
+// costOf (nil charges every request one token) and report the cost in X-RateLimit-Cost.
+// Requests from allowlisted networks are only subject to the global limit, and only
+// when the limiter implements ratelimit.GlobalLimiter.
+// With a queue, requests over the limit wait for their tokens instead of being rejected
+// right away, and report the time they waited in X-RateLimit-Delay.
+// Routes follow the rate limit policy in policies (nil applies PolicyDefault): exempt
+// routes aren't limited, and a policy rate replaces the per-IP limit when the limiter
+// implements ratelimit.ClientLimiter. API keys keep the rate of their tier.
+func rateLimitingMiddleware(rateLimiter ratelimit.Service, costOf requestCost, queue *ratelimit.Queue, policies *routePolicies, loggerService logger.Service) func(http.Handler) http.Handler {
+	reporter, reports := rateLimiter.(ratelimit.Reporter)
+	clientLimiter, limitsClients := rateLimiter.(ratelimit.ClientLimiter)
+	costLimiter, chargesCost := rateLimiter.(ratelimit.CostLimiter)
+	globalLimiter, limitsGlobal := rateLimiter.(ratelimit.GlobalLimiter)
+
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			ctx := r.Context()
 
+			policy := policies.of(r)
+			if policy.Exempt {
+				next.ServeHTTP(w, r)
+				return
+			}
+
 			// Get LogEvent from context (created by logging middleware)
 			logEvent := logger.GetLogEvent(ctx)
 			clientIP := logEvent.ClientIP
 
-			// Check rate limiting
-			if !rateLimiter.Allow(clientIP) {
-				loggerService.LogError(ctx, logger.OpRateLimited, "", "Rate limit exceeded", models.ErrRateLimitExceeded, models.LogSeverityMedium, map[string]interface{}{
-					"path":   r.URL.Path,
-					"method": r.Method,
+			cost := int64(1)
+			if costOf != nil && chargesCost {
+				cost = costOf(w, r)
+				w.Header().Set("X-RateLimit-Cost", strconv.FormatInt(cost, 10))
+			}
+
+			// Pick the bucket the request is limited by
+			var check func() ratelimit.Result
+			bucket := "ip:" + clientIP
+			key := apiKeyFromContext(ctx)
+			switch {
+			case isIPAllowlisted(ctx):
+				if !limitsGlobal {
+					next.ServeHTTP(w, r)
+					return
+				}
+				bucket = "global"
+				check = func() ratelimit.Result { return globalLimiter.CheckGlobal(cost) }
+			case key != nil && limitsClients && key.Tier.RequestsPerSec > 0:
+				client := ratelimit.Client{
+					ID:       "key:" + key.ID,
+					Capacity: int64(key.Tier.RequestsPerSec),
+					Rate:     int64(key.Tier.RequestsPerSec),
+				}
+				bucket = client.ID
+				if cost > 1 {
+					check = func() ratelimit.Result { return costLimiter.CheckClientCost(client, cost) }
+				} else {
+					check = func() ratelimit.Result { return clientLimiter.CheckClient(client) }
+				}
+			case policy.RequestsPerSec > 0 && limitsClients:
+				client := ratelimit.Client{
+					ID:       "policy:" + policy.Name + ":" + clientIP,
+					Capacity: policy.RequestsPerSec,
+					Rate:     policy.RequestsPerSec,
+				}
+				bucket = client.ID
+				if cost > 1 {
+					check = func() ratelimit.Result { return costLimiter.CheckClientCost(client, cost) }
+				} else {
+					check = func() ratelimit.Result { return clientLimiter.CheckClient(client) }
+				}
+			case cost > 1:
+				check = func() ratelimit.Result { return costLimiter.CheckCost(clientIP, cost) }
+			case reports:
+				check = func() ratelimit.Result { return reporter.Check(clientIP) }
+			default:
+				check = func() ratelimit.Result { return ratelimit.Result{Allowed: rateLimiter.Allow(clientIP)} }
+			}
+
+			// Check rate limiting, waiting in the queue when configured
+			var result ratelimit.Result
+			var delay time.Duration
+			rejection := models.ErrRateLimitExceeded
+			if queue != nil {
+				var err error
+				if result, delay, err = queue.Take(ctx, bucket, check); errors.Is(err, models.ErrRateLimitQueueFull) {
+					rejection = err
+				}
+				if delay > 0 {
+					w.Header().Set("X-RateLimit-Delay", strconv.FormatInt(delay.Milliseconds(), 10))
+				}
+			} else {
+				result = check()
+			}
+			if result.Tier != "" {
+				writeRateLimitHeaders(w.Header(), result)
+			}
+
+			if !result.Allowed {
+				retryAfter := max(ceilSeconds(result.RetryAfter), 1)
+				loggerService.LogError(ctx, logger.OpRateLimited, "", "Rate limit exceeded", rejection, models.LogSeverityMedium, map[string]interface{}{
+					"path":        r.URL.Path,
+					"method":      r.Method,
+					"policy":      policy.Name,
+					"tier":        result.Tier,
+					"cost":        cost,
+					"retry_after": retryAfter,
+					"queued_ms":   delay.Milliseconds(),
 				})
 
 				w.Header().Set("Content-Type", "application/json")
 				w.Header().Set("X-Request-ID", logEvent.ProcessID)
-				w.Header().Set("X-RateLimit-Retry-After", "1")
+				w.Header().Set("Retry-After", strconv.FormatInt(retryAfter, 10))
 				w.WriteHeader(http.StatusTooManyRequests)
-				_, _ = w.Write([]byte(`{"error":"rate limit exceeded","message":"Please try again later"}`))
+				if result.Tier != "" {
+					_, _ = fmt.Fprintf(w, `{"error":"rate limit exceeded","message":"Please try again later","tier":%q}`, result.Tier)
+				} else {
+					_, _ = w.Write([]byte(`{"error":"rate limit exceeded","message":"Please try again later"}`))
+				}
 				return
 			}
 
@@ -178,6 +421,47 @@ func rateLimitingMiddleware(rateLimiter ratelimit.Service, loggerService logger.
 	}
 }
 
+// writeRateLimitHeaders describes the limiting tier of a rate limit check
+// RateLimit-Reset is the number of seconds until the tier's bucket is full again.
+func writeRateLimitHeaders(header http.Header, result ratelimit.Result) {
+	header.Set("RateLimit-Limit", strconv.FormatInt(result.Limit, 10))
+	header.Set("RateLimit-Remaining", strconv.FormatInt(result.Remaining, 10))
+	header.Set("RateLimit-Reset", strconv.FormatInt(ceilSeconds(result.Reset), 10))
+	header.Set("X-RateLimit-Tier", result.Tier)
+}
+
+// ceilSeconds returns d in whole seconds, rounded up
+func ceilSeconds(d time.Duration) int64 {
+	return int64((d + time.Second - 1) / time.Second)
+}
+
+// adminAuthMiddleware requires the admin API token as a bearer token
+func adminAuthMiddleware(token string, loggerService logger.Service) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			provided, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok || subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+				ctx := r.Context()
+				logEvent := logger.GetLogEvent(ctx)
+
+				loggerService.LogError(ctx, logger.OpAdminAuth, "", "Unauthorized admin request", models.ErrUnauthorized, models.LogSeverityMedium, map[string]interface{}{
+					"path":   r.URL.Path,
+					"method": r.Method,
+				})
+
+				w.Header().Set("Content-Type", "application/json")
+				w.Header().Set("X-Request-ID", logEvent.ProcessID)
+				w.Header().Set("WWW-Authenticate", `Bearer realm="admin"`)
+				w.WriteHeader(http.StatusUnauthorized)
+				_, _ = w.Write([]byte(`{"error":"unauthorized","message":"A valid admin token is required"}`))
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
 // responseWriter wraps http.ResponseWriter to capture status code
 type responseWriter struct {
 	http.ResponseWriter
@@ -189,27 +473,14 @@ func (rw *responseWriter) WriteHeader(code int) {
 	rw.ResponseWriter.WriteHeader(code)
 }
 
-// getClientIP extracts the client IP address from the request
-func getClientIP(r *http.Request) string {
-	// Check X-Forwarded-For header first (for load balancers/proxies)
-	if xff := r.Header.Get("X-Forwarded-For"); xff != "" {
-		// Take the first IP in the list
-		ips := strings.Split(xff, ",")
-		if len(ips) > 0 {
-			return strings.TrimSpace(ips[0])
-		}
-	}
-
-	// Check X-Real-IP header
-	if xri := r.Header.Get("X-Real-IP"); xri != "" {
-		return xri
-	}
-
-	// Fall back to remote address
-	host, _, err := net.SplitHostPort(r.RemoteAddr)
-	if err != nil {
-		return r.RemoteAddr
+// Flush sends buffered data to the client so streaming handlers work through the wrapper
+func (rw *responseWriter) Flush() {
+	if flusher, ok := rw.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
 	}
+}
 
-	return host
+// Unwrap exposes the underlying ResponseWriter to http.ResponseController
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
 }
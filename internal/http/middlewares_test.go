@@ -5,12 +5,15 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"net/netip"
+	"strconv"
 	"testing"
 	"time"
 
 	httpMocks "Perion_Assignment/internal/http/mocks"
 	"Perion_Assignment/internal/logger"
 	"Perion_Assignment/internal/models"
+	"Perion_Assignment/internal/ratelimit"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -43,7 +46,7 @@ func TestLoggingMiddleware_Success(t *testing.T) {
 	})).Return()
 
 	// Create middleware
-	middleware := loggingMiddleware(mockLogger)
+	middleware := loggingMiddleware(mockLogger, nil)
 	handler := middleware(testHandler)
 
 	// Create request
@@ -69,11 +72,15 @@ func TestLoggingMiddleware_WithHeaders(t *testing.T) {
 	testHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		logEvent := logger.GetLogEvent(r.Context())
 		assert.Equal(t, "10.0.0.5", logEvent.ClientIP) // From X-Forwarded-For
+		assert.Equal(t, []string{"10.0.0.5", "192.168.1.1", "192.168.1.100"}, logEvent.ProxyChain)
 		w.WriteHeader(http.StatusCreated)
 	})
 
 	// Setup mock
-	mockLogger.On("LogInfo", mock.Anything, "http_request_start", "HTTP request received", mock.Anything).Return()
+	mockLogger.On("LogInfo", mock.Anything, "http_request_start", "HTTP request received", mock.MatchedBy(func(metadata map[string]interface{}) bool {
+		chain, ok := metadata["proxy_chain"].([]string)
+		return ok && assert.ObjectsAreEqual([]string{"10.0.0.5", "192.168.1.1", "192.168.1.100"}, chain)
+	})).Return()
 	mockLogger.On("LogInfo", mock.Anything, "http_request_complete", "HTTP request processed", mock.MatchedBy(func(metadata map[string]interface{}) bool {
 		return metadata["method"] == "POST" &&
 			metadata["status_code"] == 201 &&
@@ -81,7 +88,8 @@ func TestLoggingMiddleware_WithHeaders(t *testing.T) {
 			metadata["user_agent"] == "test-agent"
 	})).Return()
 
-	middleware := loggingMiddleware(mockLogger)
+	// The proxies in 192.168.0.0/16 are trusted
+	middleware := loggingMiddleware(mockLogger, newClientIPResolver([]netip.Prefix{netip.MustParsePrefix("192.168.0.0/16")}))
 	handler := middleware(testHandler)
 
 	// Create request with headers
@@ -119,8 +127,8 @@ func TestCorsMiddleware_Preflight(t *testing.T) {
 	// Assert
 	assert.Equal(t, http.StatusOK, w.Code)
 	assert.Equal(t, "*", w.Header().Get("Access-Control-Allow-Origin"))
-	assert.Equal(t, "GET, POST, OPTIONS", w.Header().Get("Access-Control-Allow-Methods"))
-	assert.Equal(t, "Content-Type, Authorization", w.Header().Get("Access-Control-Allow-Headers"))
+	assert.Equal(t, "GET, POST, DELETE, OPTIONS", w.Header().Get("Access-Control-Allow-Methods"))
+	assert.Equal(t, "Content-Type, Authorization, X-API-Key", w.Header().Get("Access-Control-Allow-Headers"))
 }
 
 func TestCorsMiddleware_RegularRequest(t *testing.T) {
@@ -144,8 +152,8 @@ func TestCorsMiddleware_RegularRequest(t *testing.T) {
 	assert.Equal(t, http.StatusOK, w.Code)
 	assert.Equal(t, "success", w.Body.String())
 	assert.Equal(t, "*", w.Header().Get("Access-Control-Allow-Origin"))
-	assert.Equal(t, "GET, POST, OPTIONS", w.Header().Get("Access-Control-Allow-Methods"))
-	assert.Equal(t, "Content-Type, Authorization", w.Header().Get("Access-Control-Allow-Headers"))
+	assert.Equal(t, "GET, POST, DELETE, OPTIONS", w.Header().Get("Access-Control-Allow-Methods"))
+	assert.Equal(t, "Content-Type, Authorization, X-API-Key", w.Header().Get("Access-Control-Allow-Headers"))
 }
 
 func TestRecoveryMiddleware_Panic(t *testing.T) {
@@ -231,7 +239,7 @@ func TestRateLimitingMiddleware_Allowed(t *testing.T) {
 	// Setup mocks
 	mockRateLimiter.On("Allow", clientIP).Return(true)
 
-	middleware := rateLimitingMiddleware(mockRateLimiter, mockLogger)
+	middleware := rateLimitingMiddleware(mockRateLimiter, nil, nil, nil, mockLogger)
 	handler := middleware(testHandler)
 
 	// Create request with context containing log event
@@ -277,7 +285,7 @@ func TestRateLimitingMiddleware_RateLimited(t *testing.T) {
 		return metadata["path"] == "/test" && metadata["method"] == "POST"
 	})).Return()
 
-	middleware := rateLimitingMiddleware(mockRateLimiter, mockLogger)
+	middleware := rateLimitingMiddleware(mockRateLimiter, nil, nil, nil, mockLogger)
 	handler := middleware(testHandler)
 
 	// Create request with context containing log event
@@ -299,7 +307,7 @@ func TestRateLimitingMiddleware_RateLimited(t *testing.T) {
 	// Assert
 	assert.Equal(t, http.StatusTooManyRequests, w.Code)
 	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
-	assert.Equal(t, "1", w.Header().Get("X-RateLimit-Retry-After"))
+	assert.Equal(t, "1", w.Header().Get("Retry-After"))
 
 	var response map[string]string
 	err := json.Unmarshal(w.Body.Bytes(), &response)
@@ -312,6 +320,93 @@ func TestRateLimitingMiddleware_RateLimited(t *testing.T) {
 	mockLogger.AssertExpectations(t)
 }
 
+// newRateLimitedRequest creates a request whose context carries the log event
+func newRateLimitedRequest(clientIP string) *http.Request {
+	req := httptest.NewRequest(http.MethodGet, "/test", nil)
+	logEvent := &models.LogEvent{
+		ProcessID:   "test-789",
+		ProcessType: models.ProcessTypeRequest,
+		StartTime:   time.Now().UTC(),
+		ClientIP:    clientIP,
+	}
+	return req.WithContext(logger.WithLogEvent(req.Context(), logEvent))
+}
+
+func TestRateLimitingMiddleware_ReportsRateLimitHeaders(t *testing.T) {
+	// Arrange
+	mockRateLimiter := &httpMocks.MockReportingRateLimiter{}
+	mockLogger := &mocks.MockLogger{}
+
+	testHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	mockRateLimiter.On("Check", "192.168.1.1").Return(ratelimit.Result{
+		Allowed:   true,
+		Tier:      ratelimit.TierClient,
+		Limit:     10,
+		Remaining: 7,
+		Reset:     300 * time.Millisecond,
+	})
+
+	handler := rateLimitingMiddleware(mockRateLimiter, nil, nil, nil, mockLogger)(testHandler)
+	w := httptest.NewRecorder()
+
+	// Act
+	handler.ServeHTTP(w, newRateLimitedRequest("192.168.1.1"))
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "10", w.Header().Get("RateLimit-Limit"))
+	assert.Equal(t, "7", w.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t, "1", w.Header().Get("RateLimit-Reset"))
+	assert.Equal(t, "client", w.Header().Get("X-RateLimit-Tier"))
+	assert.Empty(t, w.Header().Get("Retry-After"))
+	mockRateLimiter.AssertNotCalled(t, "Allow", mock.Anything)
+}
+
+func TestRateLimitingMiddleware_ReportsRetryAfter(t *testing.T) {
+	// Arrange
+	mockRateLimiter := &httpMocks.MockReportingRateLimiter{}
+	mockLogger := &mocks.MockLogger{}
+
+	testHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("Handler should not be called when rate limited")
+	})
+
+	mockRateLimiter.On("Check", "192.168.1.1").Return(ratelimit.Result{
+		Allowed:    false,
+		Tier:       ratelimit.TierGlobal,
+		Limit:      100,
+		Remaining:  0,
+		Reset:      20 * time.Second,
+		RetryAfter: 2100 * time.Millisecond,
+	})
+	mockLogger.On("LogError", mock.Anything, "rate_limited", "", "Rate limit exceeded", models.ErrRateLimitExceeded, models.LogSeverityMedium, mock.MatchedBy(func(metadata map[string]interface{}) bool {
+		return metadata["tier"] == "global" && metadata["retry_after"] == int64(3)
+	})).Return()
+
+	handler := rateLimitingMiddleware(mockRateLimiter, nil, nil, nil, mockLogger)(testHandler)
+	w := httptest.NewRecorder()
+
+	// Act
+	handler.ServeHTTP(w, newRateLimitedRequest("192.168.1.1"))
+
+	// Assert
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "3", w.Header().Get("Retry-After"))
+	assert.Equal(t, "100", w.Header().Get("RateLimit-Limit"))
+	assert.Equal(t, "0", w.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t, "20", w.Header().Get("RateLimit-Reset"))
+	assert.Equal(t, "global", w.Header().Get("X-RateLimit-Tier"))
+
+	var response map[string]string
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "rate limit exceeded", response["error"])
+	assert.Equal(t, "global", response["tier"])
+	mockLogger.AssertExpectations(t)
+}
+
 func TestGetClientIP_XForwardedFor(t *testing.T) {
 	tests := []struct {
 		name          string
@@ -319,17 +414,20 @@ func TestGetClientIP_XForwardedFor(t *testing.T) {
 		expectedIP    string
 	}{
 		{"single IP", "192.168.1.1", "192.168.1.1"},
-		{"multiple IPs", "10.0.0.1, 192.168.1.1, 172.16.0.1", "10.0.0.1"},
-		{"with spaces", "  203.0.113.195  , 192.168.1.1", "203.0.113.195"},
+		{"multiple IPs", "10.0.0.1, 192.168.1.1, 172.16.0.1", "172.16.0.1"},
+		{"with spaces", "  203.0.113.195  , 192.168.1.1", "192.168.1.1"},
 	}
 
+	// Only the load balancer at 192.168.1.100 is trusted
+	resolver := newClientIPResolver([]netip.Prefix{netip.MustParsePrefix("192.168.1.100/32")})
+
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			req := httptest.NewRequest(http.MethodGet, "/", nil)
 			req.Header.Set("X-Forwarded-For", tt.xForwardedFor)
 			req.RemoteAddr = "192.168.1.100:8080"
 
-			ip := getClientIP(req)
+			ip, _ := resolver.resolve(req)
 			assert.Equal(t, tt.expectedIP, ip)
 		})
 	}
@@ -340,15 +438,25 @@ func TestGetClientIP_XRealIP(t *testing.T) {
 	req.Header.Set("X-Real-IP", "203.0.113.195")
 	req.RemoteAddr = "192.168.1.100:8080"
 
-	ip := getClientIP(req)
+	ip, _ := newClientIPResolver([]netip.Prefix{netip.MustParsePrefix("192.168.1.100/32")}).resolve(req)
 	assert.Equal(t, "203.0.113.195", ip)
 }
 
+func TestGetClientIP_HeadersIgnoredWithoutTrustedProxies(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("X-Forwarded-For", "203.0.113.195")
+	req.Header.Set("X-Real-IP", "203.0.113.196")
+	req.RemoteAddr = "192.168.1.100:8080"
+
+	ip, _ := newClientIPResolver(nil).resolve(req)
+	assert.Equal(t, "192.168.1.100", ip)
+}
+
 func TestGetClientIP_RemoteAddr(t *testing.T) {
 	req := httptest.NewRequest(http.MethodGet, "/", nil)
 	req.RemoteAddr = "192.168.1.100:8080"
 
-	ip := getClientIP(req)
+	ip, _ := newClientIPResolver(nil).resolve(req)
 	assert.Equal(t, "192.168.1.100", ip)
 }
 
@@ -356,7 +464,7 @@ func TestGetClientIP_RemoteAddrWithoutPort(t *testing.T) {
 	req := httptest.NewRequest(http.MethodGet, "/", nil)
 	req.RemoteAddr = "192.168.1.100"
 
-	ip := getClientIP(req)
+	ip, _ := newClientIPResolver(nil).resolve(req)
 	assert.Equal(t, "192.168.1.100", ip) // Falls back to full RemoteAddr when SplitHostPort fails
 }
 
@@ -412,10 +520,10 @@ func TestMiddlewareChain_Integration(t *testing.T) {
 	mockRateLimiter.On("Allow", clientIP).Return(true)
 
 	// Build middleware chain: logging -> cors -> recovery -> rate limiting -> handler
-	handler := loggingMiddleware(mockLogger)(
+	handler := loggingMiddleware(mockLogger, nil)(
 		corsMiddleware()(
 			recoveryMiddleware(mockLogger)(
-				rateLimitingMiddleware(mockRateLimiter, mockLogger)(
+				rateLimitingMiddleware(mockRateLimiter, nil, nil, nil, mockLogger)(
 					finalHandler,
 				),
 			),
@@ -460,8 +568,8 @@ func TestMiddlewareChain_WithPanicAndRateLimit(t *testing.T) {
 	mockLogger.On("LogError", mock.Anything, "panic_recovery", "", "Panic recovered in HTTP handler", mock.AnythingOfType("*errors.errorString"), models.LogSeverityHigh, mock.Anything).Return()
 
 	// Build middleware chain
-	handler := loggingMiddleware(mockLogger)(
-		rateLimitingMiddleware(mockRateLimiter, mockLogger)(
+	handler := loggingMiddleware(mockLogger, nil)(
+		rateLimitingMiddleware(mockRateLimiter, nil, nil, nil, mockLogger)(
 			recoveryMiddleware(mockLogger)(
 				panicHandler,
 			),
@@ -488,3 +596,63 @@ func TestMiddlewareChain_WithPanicAndRateLimit(t *testing.T) {
 	mockLogger.AssertExpectations(t)
 	mockRateLimiter.AssertExpectations(t)
 }
+
+func TestRateLimitingMiddleware_Queue(t *testing.T) {
+	mockLogger := mocks.NewAnyLogger()
+	limiter := ratelimit.NewTwoTierRateLimiter(100, 100, 1, 10) // One token per 100ms per IP
+
+	handler := loggingMiddleware(mockLogger, nil)(rateLimitingMiddleware(limiter, nil, ratelimit.NewQueue(10, time.Second), nil, mockLogger)(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}),
+	))
+
+	serve := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/test", nil)
+		req.RemoteAddr = "192.168.1.1:12345"
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w
+	}
+
+	w := serve()
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get("X-RateLimit-Delay"))
+
+	// Over the limit, the request waits for the next token instead of being rejected
+	w = serve()
+	assert.Equal(t, http.StatusOK, w.Code)
+	delay, err := strconv.Atoi(w.Header().Get("X-RateLimit-Delay"))
+	require.NoError(t, err)
+	assert.Greater(t, delay, 0)
+	assert.Equal(t, "1", w.Header().Get("RateLimit-Limit"))
+}
+
+func TestRateLimitingMiddleware_QueueRejectsBeyondMaxDelay(t *testing.T) {
+	mockLogger := &mocks.MockLogger{}
+	mockLogger.On("LogInfo", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return()
+	mockLogger.On("LogError", mock.Anything, "rate_limited", "", "Rate limit exceeded", models.ErrRateLimitExceeded, models.LogSeverityMedium, mock.Anything).Return()
+	limiter := ratelimit.NewTwoTierRateLimiter(100, 100, 1, 1) // One token per second per IP
+
+	handler := loggingMiddleware(mockLogger, nil)(rateLimitingMiddleware(limiter, nil, ratelimit.NewQueue(10, 100*time.Millisecond), nil, mockLogger)(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}),
+	))
+
+	serve := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/test", nil)
+		req.RemoteAddr = "192.168.1.1:12345"
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w
+	}
+
+	assert.Equal(t, http.StatusOK, serve().Code)
+
+	w := serve()
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "1", w.Header().Get("Retry-After"))
+	assert.Equal(t, "client", w.Header().Get("X-RateLimit-Tier"))
+	mockLogger.AssertExpectations(t)
+}
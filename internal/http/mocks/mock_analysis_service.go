@@ -22,6 +22,15 @@ func (m *MockAnalysisService) AnalyzeDomain(ctx context.Context, domain string)
 	return args.Get(0).(*models.DomainAnalysis), args.Error(1)
 }
 
+// AnalyzeDomainWithOptions mocks the AnalyzeDomainWithOptions method of domainAnalysis.AnalysisService
+func (m *MockAnalysisService) AnalyzeDomainWithOptions(ctx context.Context, domain string, opts models.AnalysisOptions) (*models.DomainAnalysis, error) {
+	args := m.Called(ctx, domain, opts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.DomainAnalysis), args.Error(1)
+}
+
 // AnalyzeDomains mocks the AnalyzeDomains method of domainAnalysis.AnalysisService
 func (m *MockAnalysisService) AnalyzeDomains(ctx context.Context, domains []string) (*models.BatchAnalysisResponse, error) {
 	args := m.Called(ctx, domains)
@@ -29,4 +38,36 @@ func (m *MockAnalysisService) AnalyzeDomains(ctx context.Context, domains []stri
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*models.BatchAnalysisResponse), args.Error(1)
-}
\ No newline at end of file
+}
+
+// AnalyzeDomainsWithOptions mocks the AnalyzeDomainsWithOptions method of domainAnalysis.AnalysisService
+func (m *MockAnalysisService) AnalyzeDomainsWithOptions(ctx context.Context, domains []string, opts models.AnalysisOptions) (*models.BatchAnalysisResponse, error) {
+	args := m.Called(ctx, domains, opts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.BatchAnalysisResponse), args.Error(1)
+}
+
+// AnalyzeDomainsStream mocks the AnalyzeDomainsStream method of domainAnalysis.AnalysisService
+// Results configured as the third return value ([]models.DomainResult) are replayed through onResult
+func (m *MockAnalysisService) AnalyzeDomainsStream(ctx context.Context, domains []string, opts models.AnalysisOptions, onResult func(models.DomainResult)) (*models.BatchAnalysisResponse, error) {
+	args := m.Called(ctx, domains, opts, onResult)
+	if len(args) > 2 && onResult != nil {
+		if streamed, ok := args.Get(2).([]models.DomainResult); ok {
+			for _, result := range streamed {
+				onResult(result)
+			}
+		}
+	}
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.BatchAnalysisResponse), args.Error(1)
+}
+
+// CountCacheMisses mocks the CountCacheMisses method of domainAnalysis.CacheChecker
+func (m *MockAnalysisService) CountCacheMisses(ctx context.Context, domains []string, opts models.AnalysisOptions) int {
+	args := m.Called(ctx, domains, opts)
+	return args.Int(0)
+}
@@ -0,0 +1,53 @@
+package mocks
+
+import (
+	"context"
+
+	"Perion_Assignment/internal/models"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// MockAPIKeyService is a mock implementation of apikeys.Service
+type MockAPIKeyService struct {
+	mock.Mock
+}
+
+// Authenticate mocks the Authenticate method of apikeys.Service
+func (m *MockAPIKeyService) Authenticate(ctx context.Context, secret string) (*models.APIKey, error) {
+	args := m.Called(ctx, secret)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.APIKey), args.Error(1)
+}
+
+// RecordRequest mocks the RecordRequest method of apikeys.Service
+func (m *MockAPIKeyService) RecordRequest(ctx context.Context, key *models.APIKey) error {
+	args := m.Called(ctx, key)
+	return args.Error(0)
+}
+
+// ConsumeDomains mocks the ConsumeDomains method of apikeys.Service
+func (m *MockAPIKeyService) ConsumeDomains(ctx context.Context, key *models.APIKey, count int) (*models.APIKeyUsage, error) {
+	args := m.Called(ctx, key, count)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.APIKeyUsage), args.Error(1)
+}
+
+// RefundDomains mocks the RefundDomains method of apikeys.Service
+func (m *MockAPIKeyService) RefundDomains(ctx context.Context, key *models.APIKey, count int) error {
+	args := m.Called(ctx, key, count)
+	return args.Error(0)
+}
+
+// Usage mocks the Usage method of apikeys.Service
+func (m *MockAPIKeyService) Usage(ctx context.Context, key *models.APIKey) (*models.APIKeyUsage, error) {
+	args := m.Called(ctx, key)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.APIKeyUsage), args.Error(1)
+}
@@ -0,0 +1,48 @@
+package mocks
+
+import (
+	"context"
+
+	"Perion_Assignment/internal/cache"
+	"Perion_Assignment/internal/models"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// MockCacheAdmin is a mock implementation of domainCache.Admin
+type MockCacheAdmin struct {
+	mock.Mock
+}
+
+// Inspect mocks the Inspect method of domainCache.Admin
+func (m *MockCacheAdmin) Inspect(ctx context.Context, domain string) (*models.CacheEntryInfo, error) {
+	args := m.Called(ctx, domain)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.CacheEntryInfo), args.Error(1)
+}
+
+// DeleteDomains mocks the DeleteDomains method of domainCache.Admin
+func (m *MockCacheAdmin) DeleteDomains(ctx context.Context, domains []string) (int, error) {
+	args := m.Called(ctx, domains)
+	return args.Int(0), args.Error(1)
+}
+
+// PurgePattern mocks the PurgePattern method of domainCache.Admin
+func (m *MockCacheAdmin) PurgePattern(ctx context.Context, pattern string) (int, error) {
+	args := m.Called(ctx, pattern)
+	return args.Int(0), args.Error(1)
+}
+
+// PurgePrefix mocks the PurgePrefix method of domainCache.Admin
+func (m *MockCacheAdmin) PurgePrefix(ctx context.Context, prefix string) (int, error) {
+	args := m.Called(ctx, prefix)
+	return args.Int(0), args.Error(1)
+}
+
+// Stats mocks the Stats method of domainCache.Admin
+func (m *MockCacheAdmin) Stats(ctx context.Context) (cache.Stats, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(cache.Stats), args.Error(1)
+}
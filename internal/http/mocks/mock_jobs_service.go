@@ -0,0 +1,60 @@
+package mocks
+
+import (
+	"context"
+
+	"Perion_Assignment/internal/models"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// MockJobsService is a mock implementation of jobs.Service
+type MockJobsService struct {
+	mock.Mock
+}
+
+// Submit mocks the Submit method of jobs.Service
+func (m *MockJobsService) Submit(ctx context.Context, domains []string) (*models.Job, error) {
+	args := m.Called(ctx, domains)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Job), args.Error(1)
+}
+
+// Get mocks the Get method of jobs.Service
+func (m *MockJobsService) Get(ctx context.Context, id string) (*models.Job, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Job), args.Error(1)
+}
+
+// Results mocks the Results method of jobs.Service
+func (m *MockJobsService) Results(ctx context.Context, id string, offset, limit int) (*models.JobResultsResponse, error) {
+	args := m.Called(ctx, id, offset, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.JobResultsResponse), args.Error(1)
+}
+
+// Cancel mocks the Cancel method of jobs.Service
+func (m *MockJobsService) Cancel(ctx context.Context, id string) (*models.Job, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Job), args.Error(1)
+}
+
+// Start mocks the Start method of jobs.Service
+func (m *MockJobsService) Start(ctx context.Context) {
+	m.Called(ctx)
+}
+
+// Stop mocks the Stop method of jobs.Service
+func (m *MockJobsService) Stop() {
+	m.Called()
+}
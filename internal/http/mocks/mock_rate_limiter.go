@@ -3,6 +3,8 @@ package mocks
 import (
 	"context"
 
+	"Perion_Assignment/internal/ratelimit"
+
 	"github.com/stretchr/testify/mock"
 )
 
@@ -21,4 +23,14 @@ func (m *MockRateLimiter) Allow(clientIP string) bool {
 func (m *MockRateLimiter) Wait(ctx context.Context, clientIP string) error {
 	args := m.Called(ctx, clientIP)
 	return args.Error(0)
-}
\ No newline at end of file
+}
+// MockReportingRateLimiter is a mock rate limiter that also implements ratelimit.Reporter
+type MockReportingRateLimiter struct {
+	MockRateLimiter
+}
+
+// Check mocks the Check method of ratelimit.Reporter
+func (m *MockReportingRateLimiter) Check(clientIP string) ratelimit.Result {
+	args := m.Called(clientIP)
+	return args.Get(0).(ratelimit.Result)
+}
@@ -0,0 +1,36 @@
+package mocks
+
+import (
+	"context"
+
+	"Perion_Assignment/internal/models"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// MockWarmupService is a mock implementation of warmup.Service
+type MockWarmupService struct {
+	mock.Mock
+}
+
+// Start mocks the Start method of warmup.Service
+func (m *MockWarmupService) Start(ctx context.Context) {
+	m.Called(ctx)
+}
+
+// Stop mocks the Stop method of warmup.Service
+func (m *MockWarmupService) Stop() {
+	m.Called()
+}
+
+// Done mocks the Done method of warmup.Service
+func (m *MockWarmupService) Done() bool {
+	args := m.Called()
+	return args.Bool(0)
+}
+
+// Progress mocks the Progress method of warmup.Service
+func (m *MockWarmupService) Progress() models.WarmupProgress {
+	args := m.Called()
+	return args.Get(0).(models.WarmupProgress)
+}
@@ -0,0 +1,48 @@
+package mocks
+
+import (
+	"context"
+
+	"Perion_Assignment/internal/models"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// MockWatchlistService is a mock implementation of watchlist.Service
+type MockWatchlistService struct {
+	mock.Mock
+}
+
+// Add mocks the Add method of watchlist.Service
+func (m *MockWatchlistService) Add(ctx context.Context, domain string) (*models.WatchlistEntry, error) {
+	args := m.Called(ctx, domain)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.WatchlistEntry), args.Error(1)
+}
+
+// Remove mocks the Remove method of watchlist.Service
+func (m *MockWatchlistService) Remove(ctx context.Context, domain string) (*models.WatchlistEntry, error) {
+	args := m.Called(ctx, domain)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.WatchlistEntry), args.Error(1)
+}
+
+// List mocks the List method of watchlist.Service
+func (m *MockWatchlistService) List(ctx context.Context) []models.WatchlistEntry {
+	args := m.Called(ctx)
+	return args.Get(0).([]models.WatchlistEntry)
+}
+
+// Start mocks the Start method of watchlist.Service
+func (m *MockWatchlistService) Start(ctx context.Context) {
+	m.Called(ctx)
+}
+
+// Stop mocks the Stop method of watchlist.Service
+func (m *MockWatchlistService) Stop() {
+	m.Called()
+}
@@ -0,0 +1,122 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gorilla/mux"
+)
+
+// Rate limit policies the router attaches to routes
+const (
+	PolicyDefault   = "default"   // Routes without a policy of their own
+	PolicyHealth    = "health"    // Health and readiness checks
+	PolicyAnalyze   = "analyze"   // Single domain analysis
+	PolicyBatch     = "batch"     // Synchronous and streaming batch analysis
+	PolicyJobs      = "jobs"      // Asynchronous batch jobs
+	PolicyWatchlist = "watchlist" // Watchlist management
+	PolicyUsage     = "usage"     // API key usage
+	PolicyAdmin     = "admin"     // Admin API
+)
+
+// policyNames are the policies that can be configured
+var policyNames = map[string]bool{
+	PolicyDefault:   true,
+	PolicyHealth:    true,
+	PolicyAnalyze:   true,
+	PolicyBatch:     true,
+	PolicyJobs:      true,
+	PolicyWatchlist: true,
+	PolicyUsage:     true,
+	PolicyAdmin:     true,
+}
+
+// RateLimitPolicy sets how the requests of a route are rate limited
+// A policy without settings applies the global and per-IP limits.
+type RateLimitPolicy struct {
+	Name           string
+	Exempt         bool  // Not rate limited at all
+	RequestsPerSec int64 // Per-client limit replacing the per-IP one, with a bucket per policy (0 = per-IP limit)
+}
+
+// ParseRateLimitPolicies parses policies written as name=exempt or name=<requests per second>
+func ParseRateLimitPolicies(values []string) (map[string]RateLimitPolicy, error) {
+	policies := make(map[string]RateLimitPolicy, len(values))
+	for _, value := range values {
+		name, setting, ok := strings.Cut(strings.TrimSpace(value), "=")
+		name, setting = strings.TrimSpace(name), strings.TrimSpace(setting)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid rate limit policy %q: expected name=exempt or name=<requests per second>", value)
+		}
+		if !policyNames[name] {
+			return nil, fmt.Errorf("unknown rate limit policy: %s", name)
+		}
+
+		policy := RateLimitPolicy{Name: name}
+		if setting == "exempt" {
+			policy.Exempt = true
+		} else {
+			rate, err := strconv.ParseInt(setting, 10, 64)
+			if err != nil || rate <= 0 {
+				return nil, fmt.Errorf("invalid rate limit policy %q: rate must be a positive number of requests per second", value)
+			}
+			policy.RequestsPerSec = rate
+		}
+		policies[name] = policy
+	}
+	return policies, nil
+}
+
+// routePolicies holds the configured policies and the policy the router attached to each route
+// Routes are identified by their path template, so every method of a path has the same policy.
+type routePolicies struct {
+	policies map[string]RateLimitPolicy
+
+	mutex  sync.RWMutex
+	routes map[string]string // Policy name by path template
+}
+
+// newRoutePolicies creates route policies with the given configured policies
+func newRoutePolicies(policies map[string]RateLimitPolicy) *routePolicies {
+	return &routePolicies{
+		policies: policies,
+		routes:   make(map[string]string),
+	}
+}
+
+// attach sets the policy of a route
+func (p *routePolicies) attach(route *mux.Route, policy string) {
+	template, err := route.GetPathTemplate()
+	if err != nil {
+		return
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.routes[template] = policy
+}
+
+// of returns the policy of the route a request matched
+// Requests that matched no route, and routes without a policy, get PolicyDefault.
+func (p *routePolicies) of(r *http.Request) RateLimitPolicy {
+	name := PolicyDefault
+	if route := mux.CurrentRoute(r); p != nil && route != nil {
+		if template, err := route.GetPathTemplate(); err == nil {
+			p.mutex.RLock()
+			if policy, ok := p.routes[template]; ok {
+				name = policy
+			}
+			p.mutex.RUnlock()
+		}
+	}
+
+	if p != nil {
+		if policy, ok := p.policies[name]; ok {
+			return policy
+		}
+	}
+	return RateLimitPolicy{Name: name}
+}
@@ -0,0 +1,134 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	httpMocks "Perion_Assignment/internal/http/mocks"
+	"Perion_Assignment/internal/mocks"
+	"Perion_Assignment/internal/models"
+	"Perion_Assignment/internal/ratelimit"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRateLimitPolicies(t *testing.T) {
+	policies, err := ParseRateLimitPolicies([]string{"health=exempt", " analyze = 10 ", "batch=1"})
+
+	require.NoError(t, err)
+	assert.Equal(t, map[string]RateLimitPolicy{
+		"health":  {Name: "health", Exempt: true},
+		"analyze": {Name: "analyze", RequestsPerSec: 10},
+		"batch":   {Name: "batch", RequestsPerSec: 1},
+	}, policies)
+}
+
+func TestParseRateLimitPolicies_Invalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+	}{
+		{name: "unknown policy", value: "healthz=exempt"},
+		{name: "missing setting", value: "health"},
+		{name: "zero rate", value: "analyze=0"},
+		{name: "not a rate", value: "analyze=fast"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseRateLimitPolicies([]string{tt.value})
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestRateLimitPolicies_PerRoute(t *testing.T) {
+	mockAnalysis := &httpMocks.MockAnalysisService{}
+	mockAnalysis.On("AnalyzeDomainWithOptions", mock.Anything, "cnn.com", mock.Anything).Return(&models.DomainAnalysis{Domain: "cnn.com"}, nil)
+	mockLogger := mocks.NewAnyLogger()
+
+	policies, err := ParseRateLimitPolicies([]string{"health=exempt", "analyze=1"})
+	require.NoError(t, err)
+
+	server := NewServer(
+		"localhost:0",
+		NewHandler(mockAnalysis, mockLogger),
+		mockLogger,
+		ratelimit.NewTwoTierRateLimiter(100, 100, 2, 2),
+		10*time.Second,
+		10*time.Second,
+		WithRateLimitPolicies(policies),
+	)
+
+	serve := func(path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.RemoteAddr = "192.168.1.1:12345"
+		w := httptest.NewRecorder()
+		server.server.Handler.ServeHTTP(w, req)
+		return w
+	}
+
+	// Health checks are exempt and don't take tokens
+	for i := 0; i < 5; i++ {
+		w := serve("/health")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, w.Header().Get("RateLimit-Limit"))
+	}
+
+	// Analysis has its own bucket of 1 request per second
+	w := serve("/api/analyze/cnn.com")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "1", w.Header().Get("RateLimit-Limit"))
+	assert.Equal(t, http.StatusTooManyRequests, serve("/api/analyze/cnn.com").Code)
+
+	// Other routes still have the per-IP bucket, untouched by analysis requests
+	w = serve("/")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "2", w.Header().Get("RateLimit-Limit"))
+	assert.Equal(t, "1", w.Header().Get("RateLimit-Remaining"))
+
+	// The rejection is logged with its policy
+	mockLogger.AssertCalled(t, "LogError", mock.Anything, "rate_limited", "", "Rate limit exceeded", models.ErrRateLimitExceeded, models.LogSeverityMedium,
+		mock.MatchedBy(func(metadata map[string]interface{}) bool {
+			return metadata["policy"] == PolicyAnalyze
+		}))
+}
+
+func TestRoutePolicies_Of(t *testing.T) {
+	policies := newRoutePolicies(map[string]RateLimitPolicy{PolicyBatch: {Name: PolicyBatch, RequestsPerSec: 1}})
+
+	var policy RateLimitPolicy
+	recordPolicy := func(w http.ResponseWriter, r *http.Request) {
+		policy = policies.of(r)
+	}
+	router := mux.NewRouter()
+	policies.attach(router.HandleFunc("/api/batch-analysis", recordPolicy), PolicyBatch)
+	policies.attach(router.HandleFunc("/api/jobs/{id}", recordPolicy), PolicyJobs)
+	router.HandleFunc("/", recordPolicy)
+
+	tests := []struct {
+		path     string
+		expected RateLimitPolicy
+	}{
+		{path: "/api/batch-analysis", expected: RateLimitPolicy{Name: PolicyBatch, RequestsPerSec: 1}},
+		{path: "/api/jobs/abc", expected: RateLimitPolicy{Name: PolicyJobs}}, // Not configured
+		{path: "/", expected: RateLimitPolicy{Name: PolicyDefault}},          // No policy attached
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, tt.path, nil))
+			assert.Equal(t, tt.expected, policy)
+		})
+	}
+
+	// Requests that matched no route, or a server without policies, get the default policy
+	assert.Equal(t, RateLimitPolicy{Name: PolicyDefault}, policies.of(httptest.NewRequest(http.MethodGet, "/", nil)))
+	var none *routePolicies
+	assert.Equal(t, RateLimitPolicy{Name: PolicyDefault}, none.of(httptest.NewRequest(http.MethodGet, "/", nil)))
+}
@@ -0,0 +1,56 @@
+package http
+
+import (
+	"net/http"
+	"time"
+
+	"Perion_Assignment/internal/logger"
+	"Perion_Assignment/internal/models"
+	"Perion_Assignment/internal/warmup"
+)
+
+// ReadinessResponse represents a readiness check response
+type ReadinessResponse struct {
+	Status    string                 `json:"status"`
+	Timestamp time.Time              `json:"timestamp"`
+	Warmup    *models.WarmupProgress `json:"warmup,omitempty"`
+}
+
+// ReadinessHandler reports whether the instance should receive traffic
+type ReadinessHandler struct {
+	*Handler
+	warmupService warmup.Service // nil when no warm-up is configured
+	waitForWarmup bool
+}
+
+// NewReadinessHandler creates a new readiness HTTP handler
+// With waitForWarmup, the instance only reports ready once the cache warm-up has finished.
+func NewReadinessHandler(handler *Handler, warmupService warmup.Service, waitForWarmup bool) *ReadinessHandler {
+	return &ReadinessHandler{
+		Handler:       handler,
+		warmupService: warmupService,
+		waitForWarmup: waitForWarmup,
+	}
+}
+
+// ReadinessCheck handles GET /ready
+func (h *ReadinessHandler) ReadinessCheck(w http.ResponseWriter, r *http.Request) {
+	response := ReadinessResponse{
+		Status:    "ready",
+		Timestamp: time.Now().UTC(),
+	}
+	statusCode := http.StatusOK
+
+	if h.warmupService != nil {
+		progress := h.warmupService.Progress()
+		response.Warmup = &progress
+		if h.waitForWarmup && !progress.Done {
+			response.Status = "warming_up"
+			statusCode = http.StatusServiceUnavailable
+		}
+	}
+
+	if err := h.writeJSONResponse(w, r, statusCode, response); err != nil {
+		h.logger.LogError(r.Context(), logger.OpHealthCheck, "", "Failed to encode readiness response", err, models.LogSeverityLow, nil)
+	}
+}
@@ -0,0 +1,66 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	httpMocks "Perion_Assignment/internal/http/mocks"
+	"Perion_Assignment/internal/mocks"
+	"Perion_Assignment/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func checkReadiness(t *testing.T, handler *ReadinessHandler) (int, ReadinessResponse) {
+	req := httptest.NewRequest(http.MethodGet, "/ready", nil)
+	w := httptest.NewRecorder()
+
+	handler.ReadinessCheck(w, req)
+
+	var response ReadinessResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	return w.Code, response
+}
+
+func TestReadinessHandler_NoWarmup(t *testing.T) {
+	handler := NewReadinessHandler(NewHandler(&httpMocks.MockAnalysisService{}, &mocks.MockLogger{}), nil, true)
+
+	code, response := checkReadiness(t, handler)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "ready", response.Status)
+	assert.Nil(t, response.Warmup)
+}
+
+func TestReadinessHandler_Warmup(t *testing.T) {
+	running := models.WarmupProgress{Total: 10, Completed: 4, Succeeded: 4}
+	finished := models.WarmupProgress{Total: 10, Completed: 10, Succeeded: 9, Failed: 1, Done: true}
+
+	tests := []struct {
+		name           string
+		waitForWarmup  bool
+		progress       models.WarmupProgress
+		expectedStatus int
+		expectedState  string
+	}{
+		{name: "running, not waited for", waitForWarmup: false, progress: running, expectedStatus: http.StatusOK, expectedState: "ready"},
+		{name: "running, waited for", waitForWarmup: true, progress: running, expectedStatus: http.StatusServiceUnavailable, expectedState: "warming_up"},
+		{name: "finished, waited for", waitForWarmup: true, progress: finished, expectedStatus: http.StatusOK, expectedState: "ready"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockWarmup := &httpMocks.MockWarmupService{}
+			mockWarmup.On("Progress").Return(tt.progress)
+			handler := NewReadinessHandler(NewHandler(&httpMocks.MockAnalysisService{}, &mocks.MockLogger{}), mockWarmup, tt.waitForWarmup)
+
+			code, response := checkReadiness(t, handler)
+			assert.Equal(t, tt.expectedStatus, code)
+			assert.Equal(t, tt.expectedState, response.Status)
+			require.NotNil(t, response.Warmup)
+			assert.Equal(t, tt.progress.Completed, response.Warmup.Completed)
+		})
+	}
+}
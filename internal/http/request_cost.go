@@ -0,0 +1,122 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"Perion_Assignment/internal/domainAnalysis"
+	"Perion_Assignment/internal/models"
+)
+
+// BatchCost sets how many rate limit tokens a batch analysis request costs
+type BatchCost string
+
+// Batch cost modes
+const (
+	BatchCostRequest     BatchCost = "request"      // One token, like any other request
+	BatchCostDomains     BatchCost = "domains"      // One token per domain
+	BatchCostCacheMisses BatchCost = "cache_misses" // One token per domain that isn't answered from the cache
+)
+
+// BatchCostByName returns the batch cost mode with the given name
+func BatchCostByName(name string) (BatchCost, error) {
+	switch cost := BatchCost(name); cost {
+	case BatchCostRequest, BatchCostDomains, BatchCostCacheMisses:
+		return cost, nil
+	default:
+		return "", fmt.Errorf("unsupported batch cost: %s", name)
+	}
+}
+
+// maxBatchRequestBytes bounds the batch request bodies read to compute their cost
+const maxBatchRequestBytes = 4 << 20
+
+// batchCostPaths are the batch routes charged by domain, mapped to whether the route
+// rejects batches above the max batch size of the request's tier
+// Asynchronous jobs are charged like synchronous batches: the job worker pool bounds
+// throughput, not how many domains one client submits.
+var batchCostPaths = map[string]bool{
+	"/api/batch-analysis":        true,
+	"/api/batch-analysis/stream": true,
+	"/api/jobs":                  false,
+}
+
+// requestCost returns the number of rate limit tokens a request costs (at least 1)
+type requestCost func(w http.ResponseWriter, r *http.Request) int64
+
+// newBatchRequestCost returns the cost of requests under the given batch cost mode
+// It returns nil, charging every request one token, for BatchCostRequest. Cache misses
+// are counted when the analysis service implements domainAnalysis.CacheChecker, and
+// otherwise every domain is charged. At most maxBatchSize domains are looked up in the
+// cache; synchronous batches above it are charged one token since the handler rejects
+// them, and the remaining domains of larger jobs are charged as misses.
+func newBatchRequestCost(mode BatchCost, analysisService domainAnalysis.AnalysisService, maxBatchSize func(r *http.Request) int) requestCost {
+	if mode == "" || mode == BatchCostRequest {
+		return nil
+	}
+
+	cacheChecker, checksCache := analysisService.(domainAnalysis.CacheChecker)
+	countMisses := mode == BatchCostCacheMisses && checksCache
+
+	return func(w http.ResponseWriter, r *http.Request) int64 {
+		limitedBySize, isBatch := batchCostPaths[r.URL.Path]
+		if r.Method != http.MethodPost || !isBatch {
+			return 1
+		}
+
+		request, ok := peekBatchRequest(w, r)
+		if !ok {
+			// The handler rejects the request
+			return 1
+		}
+
+		maxDomains := maxBatchSize(r)
+		if limitedBySize && len(request.Domains) > maxDomains {
+			// The handler rejects the request
+			return 1
+		}
+
+		cost := len(request.Domains)
+		if countMisses {
+			checked := request.Domains[:min(len(request.Domains), maxDomains)]
+			cost = cacheChecker.CountCacheMisses(r.Context(), checked, request.AnalysisOptions) + len(request.Domains) - len(checked)
+		}
+		return int64(max(cost, 1))
+	}
+}
+
+// peekBatchRequest decodes a batch analysis request body, leaving the body for the handler
+// Bodies above maxBatchRequestBytes aren't decoded; the handler gets the part that was
+// read followed by the error, and rejects the request.
+func peekBatchRequest(w http.ResponseWriter, r *http.Request) (*models.BatchAnalysisRequest, bool) {
+	if r.Body == nil {
+		return nil, false
+	}
+
+	bodyBytes, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBatchRequestBytes))
+	r.Body.Close()
+	if err != nil {
+		r.Body = io.NopCloser(io.MultiReader(bytes.NewReader(bodyBytes), failingReader{err}))
+		return nil, false
+	}
+	r.Body = io.NopCloser(bytes.NewReader(bodyBytes))
+
+	var request models.BatchAnalysisRequest
+	if err := json.Unmarshal(bodyBytes, &request); err != nil {
+		return nil, false
+	}
+	return &request, true
+}
+
+// failingReader returns its error on every read
+type failingReader struct {
+	err error
+}
+
+// Read implements io.Reader
+func (f failingReader) Read([]byte) (int, error) {
+	return 0, f.err
+}
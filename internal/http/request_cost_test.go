@@ -0,0 +1,164 @@
+package http
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	httpMocks "Perion_Assignment/internal/http/mocks"
+	"Perion_Assignment/internal/mocks"
+	"Perion_Assignment/internal/models"
+	"Perion_Assignment/internal/ratelimit"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const sixDomainBatch = `{"domains":["a.com","b.com","c.com","d.com","e.com","f.com"]}`
+
+// maxFiveDomains limits the batches in cost tests to five domains
+func maxFiveDomains(*http.Request) int {
+	return 5
+}
+
+// maxDefaultDomains applies the default max batch size
+func maxDefaultDomains(*http.Request) int {
+	return maxBatchDomains
+}
+
+func TestBatchCostByName(t *testing.T) {
+	for _, name := range []string{"request", "domains", "cache_misses"} {
+		cost, err := BatchCostByName(name)
+		require.NoError(t, err)
+		assert.Equal(t, BatchCost(name), cost)
+	}
+
+	_, err := BatchCostByName("bytes")
+	assert.Error(t, err)
+}
+
+func TestBatchRequestCost(t *testing.T) {
+	cost := newBatchRequestCost(BatchCostDomains, &httpMocks.MockAnalysisService{}, maxDefaultDomains)
+
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		body     string
+		expected int64
+	}{
+		{name: "batch", method: http.MethodPost, path: "/api/batch-analysis", body: sixDomainBatch, expected: 6},
+		{name: "stream", method: http.MethodPost, path: "/api/batch-analysis/stream", body: sixDomainBatch, expected: 6},
+		{name: "invalid body", method: http.MethodPost, path: "/api/batch-analysis", body: `{"domains":`, expected: 1},
+		{name: "empty batch", method: http.MethodPost, path: "/api/batch-analysis", body: `{"domains":[]}`, expected: 1},
+		{name: "jobs", method: http.MethodPost, path: "/api/jobs", body: sixDomainBatch, expected: 6},
+		{name: "job status", method: http.MethodGet, path: "/api/jobs/abc", expected: 1},
+		{name: "single domain", method: http.MethodGet, path: "/api/analyze/cnn.com", expected: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			assert.Equal(t, tt.expected, cost(httptest.NewRecorder(), req))
+
+			// The body is left for the handler
+			body, err := io.ReadAll(req.Body)
+			require.NoError(t, err)
+			assert.Equal(t, tt.body, string(body))
+		})
+	}
+}
+
+func TestBatchRequestCost_CacheMisses(t *testing.T) {
+	mockAnalysis := &httpMocks.MockAnalysisService{}
+	cost := newBatchRequestCost(BatchCostCacheMisses, mockAnalysis, maxDefaultDomains)
+
+	body := `{"domains":["a.com","b.com","c.com"],"max_age":60}`
+	mockAnalysis.On("CountCacheMisses", mock.Anything, []string{"a.com", "b.com", "c.com"}, models.AnalysisOptions{MaxAge: 60}).Return(2).Once()
+	assert.Equal(t, int64(2), cost(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/api/batch-analysis", strings.NewReader(body))))
+
+	// A fully cached batch still costs one token
+	mockAnalysis.On("CountCacheMisses", mock.Anything, mock.Anything, mock.Anything).Return(0).Once()
+	assert.Equal(t, int64(1), cost(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/api/batch-analysis", strings.NewReader(body))))
+
+	mockAnalysis.AssertExpectations(t)
+}
+
+func TestBatchRequestCost_AboveMaxBatchSize(t *testing.T) {
+	mockAnalysis := &httpMocks.MockAnalysisService{}
+	cost := newBatchRequestCost(BatchCostCacheMisses, mockAnalysis, maxFiveDomains)
+
+	// The handler rejects the batch, so the cache isn't looked at
+	req := httptest.NewRequest(http.MethodPost, "/api/batch-analysis", strings.NewReader(sixDomainBatch))
+	assert.Equal(t, int64(1), cost(httptest.NewRecorder(), req))
+	mockAnalysis.AssertNotCalled(t, "CountCacheMisses", mock.Anything, mock.Anything, mock.Anything)
+
+	// Jobs may be larger: only the first five domains are looked up, the sixth is charged
+	mockAnalysis.On("CountCacheMisses", mock.Anything, []string{"a.com", "b.com", "c.com", "d.com", "e.com"}, models.AnalysisOptions{}).Return(2).Once()
+	req = httptest.NewRequest(http.MethodPost, "/api/jobs", strings.NewReader(sixDomainBatch))
+	assert.Equal(t, int64(3), cost(httptest.NewRecorder(), req))
+
+	mockAnalysis.AssertExpectations(t)
+}
+
+func TestBatchRequestCost_BodyTooLarge(t *testing.T) {
+	mockAnalysis := &httpMocks.MockAnalysisService{}
+	cost := newBatchRequestCost(BatchCostCacheMisses, mockAnalysis, maxDefaultDomains)
+
+	body := `{"domains":["` + strings.Repeat("a", maxBatchRequestBytes) + `.com"]}`
+	req := httptest.NewRequest(http.MethodPost, "/api/batch-analysis", strings.NewReader(body))
+	assert.Equal(t, int64(1), cost(httptest.NewRecorder(), req))
+	mockAnalysis.AssertNotCalled(t, "CountCacheMisses", mock.Anything, mock.Anything, mock.Anything)
+
+	// The handler fails to read the body
+	var request models.BatchAnalysisRequest
+	var maxBytesErr *http.MaxBytesError
+	assert.ErrorAs(t, json.NewDecoder(req.Body).Decode(&request), &maxBytesErr)
+}
+
+func TestBatchRequestCost_RequestMode(t *testing.T) {
+	assert.Nil(t, newBatchRequestCost(BatchCostRequest, &httpMocks.MockAnalysisService{}, maxDefaultDomains))
+	assert.Nil(t, newBatchRequestCost("", &httpMocks.MockAnalysisService{}, maxDefaultDomains))
+}
+
+func TestRateLimitingMiddleware_ChargesBatchCost(t *testing.T) {
+	mockLogger := mocks.NewAnyLogger()
+	limiter := ratelimit.NewTwoTierRateLimiter(100, 100, 10, 10)
+	cost := newBatchRequestCost(BatchCostDomains, &httpMocks.MockAnalysisService{}, maxDefaultDomains)
+
+	handlerCalled := 0
+	handler := loggingMiddleware(mockLogger, nil)(rateLimitingMiddleware(limiter, cost, nil, nil, mockLogger)(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// The handler still reads the whole batch
+			var request models.BatchAnalysisRequest
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+			assert.Len(t, request.Domains, 6)
+			handlerCalled++
+			w.WriteHeader(http.StatusOK)
+		}),
+	))
+
+	serveBatch := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/batch-analysis", strings.NewReader(sixDomainBatch))
+		req.RemoteAddr = "192.168.1.1:12345"
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w
+	}
+
+	w := serveBatch()
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "6", w.Header().Get("X-RateLimit-Cost"))
+	assert.Equal(t, "4", w.Header().Get("RateLimit-Remaining"))
+
+	// Four tokens are left, not enough for another six domains
+	w = serveBatch()
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "6", w.Header().Get("X-RateLimit-Cost"))
+	assert.Equal(t, "1", w.Header().Get("Retry-After"))
+	assert.Equal(t, 1, handlerCalled)
+}
@@ -3,8 +3,12 @@ package http
 import (
 	"context"
 	"net/http"
+	"net/netip"
 	"time"
 
+	"Perion_Assignment/internal/apikeys"
+	"Perion_Assignment/internal/concurrency"
+	"Perion_Assignment/internal/ipfilter"
 	"Perion_Assignment/internal/logger"
 	"Perion_Assignment/internal/ratelimit"
 
@@ -16,6 +20,82 @@ type Server struct {
 	handler *Handler
 	logger  logger.Service
 	server  *http.Server
+	router  *mux.Router
+
+	policies *routePolicies
+}
+
+// ServerOption configures optional server behaviour
+type ServerOption func(*serverOptions)
+
+// serverOptions holds the optional server settings
+type serverOptions struct {
+	apiKeys           apikeys.Service
+	apiKeysRequired   bool
+	batchCost         BatchCost
+	trustedProxies    []netip.Prefix
+	ipFilter          ipfilter.Service
+	rateLimitQueue    *ratelimit.Queue
+	rateLimitPolicies map[string]RateLimitPolicy
+	fetchLimiter      concurrency.Service
+}
+
+// WithAPIKeys authenticates requests by their X-API-Key header, rate limits them by key
+// and enforces their tier's batch size and daily domain quota. When required is set,
+// requests without a key are rejected, except health and readiness checks.
+func WithAPIKeys(apiKeys apikeys.Service, required bool) ServerOption {
+	return func(o *serverOptions) {
+		o.apiKeys = apiKeys
+		o.apiKeysRequired = required
+	}
+}
+
+// WithBatchCost sets how many rate limit tokens batch analysis requests cost
+// (default BatchCostRequest). It only applies to limiters implementing ratelimit.CostLimiter.
+func WithBatchCost(cost BatchCost) ServerOption {
+	return func(o *serverOptions) {
+		o.batchCost = cost
+	}
+}
+
+// WithTrustedProxies sets the proxies whose forwarding headers are believed when
+// determining client IPs. Without trusted proxies, the connecting address is the client.
+func WithTrustedProxies(trustedProxies []netip.Prefix) ServerOption {
+	return func(o *serverOptions) {
+		o.trustedProxies = trustedProxies
+	}
+}
+
+// WithIPFilter rejects requests from denylisted client IPs and exempts allowlisted ones
+// from per-IP rate limits; they remain subject to the global limit.
+func WithIPFilter(ipFilter ipfilter.Service) ServerOption {
+	return func(o *serverOptions) {
+		o.ipFilter = ipFilter
+	}
+}
+
+// WithRateLimitQueue makes requests over the rate limit wait in the queue for their
+// tokens, up to its maximum delay, before being rejected
+func WithRateLimitQueue(queue *ratelimit.Queue) ServerOption {
+	return func(o *serverOptions) {
+		o.rateLimitQueue = queue
+	}
+}
+
+// WithRateLimitPolicies configures the rate limit policies the router attaches to routes
+// Routes whose policy isn't configured get the global and per-IP limits.
+func WithRateLimitPolicies(policies map[string]RateLimitPolicy) ServerOption {
+	return func(o *serverOptions) {
+		o.rateLimitPolicies = policies
+	}
+}
+
+// WithLoadShedding rejects analysis requests with 503 and Retry-After while the wait queue
+// of the fetch limiter is full, and answers analyses it shed with 503 as well
+func WithLoadShedding(fetchLimiter concurrency.Service) ServerOption {
+	return func(o *serverOptions) {
+		o.fetchLimiter = fetchLimiter
+	}
 }
 
 // NewServer creates a new HTTP server
@@ -25,14 +105,24 @@ func NewServer(
 	logger logger.Service,
 	rateLimiter ratelimit.Service,
 	readTimeout, writeTimeout time.Duration,
+	opts ...ServerOption,
 ) *Server {
+	var options serverOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	handler.apiKeys = options.apiKeys
+	handler.fetchLimiter = options.fetchLimiter
+
 	// Create Gorilla mux router
 	router := mux.NewRouter()
 
 	// Create server
 	srv := &Server{
-		handler: handler,
-		logger:  logger,
+		handler:  handler,
+		logger:   logger,
+		router:   router,
+		policies: newRoutePolicies(options.rateLimitPolicies),
 		server: &http.Server{
 			Addr:         addr,
 			Handler:      router,
@@ -41,14 +131,29 @@ func NewServer(
 		},
 	}
 
-	// Register middleware (order matters: logging -> rate limiting -> cors -> recovery)
-	router.Use(loggingMiddleware(logger))
-	router.Use(rateLimitingMiddleware(rateLimiter, logger))
+	// Register middleware (order matters: logging -> IP filter -> API keys -> rate limiting -> load shedding -> API key usage -> cors -> recovery)
+	router.Use(loggingMiddleware(logger, newClientIPResolver(options.trustedProxies)))
+	if options.ipFilter != nil {
+		router.Use(ipFilterMiddleware(options.ipFilter, logger))
+	}
+	if options.apiKeys != nil {
+		router.Use(apiKeyMiddleware(options.apiKeys, options.apiKeysRequired, logger))
+	}
+	router.Use(rateLimitingMiddleware(rateLimiter, newBatchRequestCost(options.batchCost, handler.analysisService, handler.maxBatchSize), options.rateLimitQueue, srv.policies, logger))
+	if options.fetchLimiter != nil {
+		router.Use(loadSheddingMiddleware(options.fetchLimiter, srv.policies, logger))
+	}
+	if options.apiKeys != nil {
+		router.Use(apiKeyUsageMiddleware(options.apiKeys, logger))
+	}
 	router.Use(corsMiddleware())
 	router.Use(recoveryMiddleware(logger))
 
 	// Register routes
 	srv.registerRoutes(router)
+	if options.apiKeys != nil {
+		srv.policies.attach(router.HandleFunc("/api/usage", handler.GetUsage).Methods("GET"), PolicyUsage)
+	}
 
 	return srv
 }
@@ -56,11 +161,12 @@ func NewServer(
 // registerRoutes sets up all API routes
 func (s *Server) registerRoutes(router *mux.Router) {
 	// Health check
-	router.HandleFunc("/health", s.handler.HealthCheck).Methods("GET")
+	s.policies.attach(router.HandleFunc("/health", s.handler.HealthCheck).Methods("GET"), PolicyHealth)
 
 	// API routes
-	router.HandleFunc("/api/analyze/{domain}", s.handler.AnalyzeSingleDomain).Methods("GET")
-	router.HandleFunc("/api/batch-analysis", s.handler.AnalyzeBatchDomains).Methods("POST")
+	s.policies.attach(router.HandleFunc("/api/analyze/{domain}", s.handler.AnalyzeSingleDomain).Methods("GET"), PolicyAnalyze)
+	s.policies.attach(router.HandleFunc("/api/batch-analysis", s.handler.AnalyzeBatchDomains).Methods("POST"), PolicyBatch)
+	s.policies.attach(router.HandleFunc("/api/batch-analysis/stream", s.handler.AnalyzeBatchDomainsStream).Methods("POST"), PolicyBatch)
 
 	// Root handler
 	router.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
@@ -70,6 +176,47 @@ func (s *Server) registerRoutes(router *mux.Router) {
 	}).Methods("GET")
 }
 
+// RegisterWatchlistRoutes sets up the watchlist API routes
+func (s *Server) RegisterWatchlistRoutes(watchlistHandler *WatchlistHandler) {
+	s.policies.attach(s.router.HandleFunc("/api/watchlist", watchlistHandler.AddDomain).Methods("POST"), PolicyWatchlist)
+	s.policies.attach(s.router.HandleFunc("/api/watchlist", watchlistHandler.ListDomains).Methods("GET"), PolicyWatchlist)
+	s.policies.attach(s.router.HandleFunc("/api/watchlist/{domain}", watchlistHandler.RemoveDomain).Methods("DELETE"), PolicyWatchlist)
+}
+
+// RegisterJobsRoutes sets up the asynchronous batch job routes
+func (s *Server) RegisterJobsRoutes(jobsHandler *JobsHandler) {
+	s.policies.attach(s.router.HandleFunc("/api/jobs", jobsHandler.SubmitJob).Methods("POST"), PolicyJobs)
+	s.policies.attach(s.router.HandleFunc("/api/jobs/{id}", jobsHandler.GetJob).Methods("GET"), PolicyJobs)
+	s.policies.attach(s.router.HandleFunc("/api/jobs/{id}/results", jobsHandler.GetJobResults).Methods("GET"), PolicyJobs)
+	s.policies.attach(s.router.HandleFunc("/api/jobs/{id}/cancel", jobsHandler.CancelJob).Methods("POST"), PolicyJobs)
+}
+
+// RegisterReadinessRoutes sets up the readiness check route
+func (s *Server) RegisterReadinessRoutes(readinessHandler *ReadinessHandler) {
+	s.policies.attach(s.router.HandleFunc("/ready", readinessHandler.ReadinessCheck).Methods("GET"), PolicyHealth)
+}
+
+// RegisterCacheAdminRoutes sets up the cache admin routes, protected by the admin API token
+func (s *Server) RegisterCacheAdminRoutes(cacheAdminHandler *CacheAdminHandler, adminToken string) {
+	admin := s.router.PathPrefix("/api/admin/cache").Subrouter()
+	admin.Use(adminAuthMiddleware(adminToken, s.logger))
+
+	s.policies.attach(admin.HandleFunc("/stats", cacheAdminHandler.GetStats).Methods("GET"), PolicyAdmin)
+	s.policies.attach(admin.HandleFunc("/entries/{domain}", cacheAdminHandler.GetEntry).Methods("GET"), PolicyAdmin)
+	s.policies.attach(admin.HandleFunc("/entries/{domain}", cacheAdminHandler.DeleteEntry).Methods("DELETE"), PolicyAdmin)
+	s.policies.attach(admin.HandleFunc("/delete", cacheAdminHandler.DeleteEntries).Methods("POST"), PolicyAdmin)
+	s.policies.attach(admin.HandleFunc("/purge", cacheAdminHandler.Purge).Methods("POST"), PolicyAdmin)
+}
+
+// RegisterIPListsAdminRoutes sets up the IP list admin routes, protected by the admin API token
+func (s *Server) RegisterIPListsAdminRoutes(ipListsAdminHandler *IPListsAdminHandler, adminToken string) {
+	admin := s.router.PathPrefix("/api/admin/ip-lists").Subrouter()
+	admin.Use(adminAuthMiddleware(adminToken, s.logger))
+
+	s.policies.attach(admin.HandleFunc("", ipListsAdminHandler.GetLists).Methods("GET"), PolicyAdmin)
+	s.policies.attach(admin.HandleFunc("/reload", ipListsAdminHandler.ReloadLists).Methods("POST"), PolicyAdmin)
+}
+
 // Start starts the HTTP server
 func (s *Server) Start() error {
 	s.logger.LogInfo(context.Background(), logger.OpServerStart, "Starting HTTP server", map[string]interface{}{
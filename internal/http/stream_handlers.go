@@ -0,0 +1,182 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"Perion_Assignment/internal/logger"
+	"Perion_Assignment/internal/models"
+)
+
+// Stream formats supported by the streaming batch endpoint
+const (
+	streamFormatNDJSON = "ndjson"
+	streamFormatSSE    = "sse"
+)
+
+// Stream event names
+const (
+	streamEventResult  = "result"
+	streamEventSummary = "summary"
+	streamEventError   = "error"
+)
+
+// StreamEvent represents a single NDJSON line of a streamed batch analysis
+type StreamEvent struct {
+	Event string      `json:"event"`
+	Data  interface{} `json:"data"`
+}
+
+// AnalyzeBatchDomainsStream handles POST /api/batch-analysis/stream
+// Each domain result is written as soon as it is ready, followed by a final summary event.
+// The format is SSE when requested via "Accept: text/event-stream" or "?format=sse", NDJSON otherwise.
+func (h *Handler) AnalyzeBatchDomainsStream(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	request, ok := h.decodeBatchRequest(w, r)
+	if !ok || !h.validateOptions(w, r, request.AnalysisOptions) || !h.consumeQuota(w, r, len(request.Domains)) {
+		return
+	}
+
+	h.logger.LogInfo(ctx, logger.OpBatchAnalysis, fmt.Sprintf("Starting streamed batch analysis for %d domains", len(request.Domains)), map[string]interface{}{
+		"domains_count": len(request.Domains),
+		"domains":       request.Domains,
+	})
+
+	stream := newEventStream(w, r, negotiateStreamFormat(r))
+
+	response, err := h.analysisService.AnalyzeDomainsStream(ctx, request.Domains, request.AnalysisOptions, func(result models.DomainResult) {
+		stream.send(streamEventResult, result)
+	})
+	if err != nil {
+		h.logger.LogError(ctx, logger.OpBatchAnalysis, "", "Streamed batch analysis failed", err, models.LogSeverityMedium, nil)
+
+		// Rejected options are reported as a regular error response while headers can still be changed
+		if !stream.started && errors.Is(err, models.ErrInvalidAnalysisOptions) {
+			h.writeErrorResponse(w, r, http.StatusBadRequest, "batch analysis failed", err.Error())
+			return
+		}
+		stream.send(streamEventError, ErrorResponse{
+			Error:     "batch analysis failed",
+			Message:   err.Error(),
+			Timestamp: time.Now().UTC(),
+		})
+		return
+	}
+
+	stream.send(streamEventSummary, models.BatchStreamSummary{
+		Summary:          response.Summary,
+		Advertisers:      response.Advertisers,
+		TotalAdvertisers: response.TotalAdvertisers,
+		Timestamp:        response.Timestamp,
+	})
+
+	if stream.err != nil {
+		// Client most likely disconnected mid-stream
+		h.logger.LogError(ctx, logger.OpBatchAnalysis, "", "Failed to write streamed batch response", stream.err, models.LogSeverityLow, nil)
+		return
+	}
+
+	h.logger.LogSuccess(ctx, logger.OpBatchAnalysis, "", fmt.Sprintf("Completed streamed batch analysis: %d succeeded, %d failed", response.Summary.Succeeded, response.Summary.Failed), map[string]interface{}{
+		"total":     response.Summary.Total,
+		"succeeded": response.Summary.Succeeded,
+		"failed":    response.Summary.Failed,
+	})
+}
+
+// eventStream writes events to a long-lived response, flushing after each one
+type eventStream struct {
+	w          http.ResponseWriter
+	r          *http.Request
+	controller *http.ResponseController
+	format     string
+	started    bool
+	err        error
+}
+
+// newEventStream returns a stream whose headers are written with the first event
+// It clears the server write deadline right away: the first result may take longer than
+// the server write timeout to arrive, and each domain is bounded by its own analysis
+// timeout instead.
+func newEventStream(w http.ResponseWriter, r *http.Request, format string) *eventStream {
+	controller := http.NewResponseController(w)
+	_ = controller.SetWriteDeadline(time.Time{})
+
+	return &eventStream{
+		w:          w,
+		r:          r,
+		controller: controller,
+		format:     format,
+	}
+}
+
+// start writes the streaming headers
+func (s *eventStream) start() {
+	s.started = true
+	logEvent := logger.GetLogEvent(s.r.Context())
+
+	if s.format == streamFormatSSE {
+		s.w.Header().Set("Content-Type", "text/event-stream")
+	} else {
+		s.w.Header().Set("Content-Type", "application/x-ndjson")
+	}
+	s.w.Header().Set("Cache-Control", "no-cache")
+	s.w.Header().Set("X-Accel-Buffering", "no") // Disable nginx response buffering
+	s.w.Header().Set("X-Request-ID", logEvent.ProcessID)
+	s.w.WriteHeader(http.StatusOK)
+}
+
+// send writes a single event; after the first write error all further events are dropped
+func (s *eventStream) send(event string, data interface{}) {
+	if s.err != nil {
+		return
+	}
+	if !s.started {
+		s.start()
+	}
+
+	var payload []byte
+	var err error
+	if s.format == streamFormatSSE {
+		payload, err = json.Marshal(data)
+		if err == nil {
+			payload = []byte(fmt.Sprintf("event: %s\ndata: %s\n\n", event, payload))
+		}
+	} else {
+		payload, err = json.Marshal(StreamEvent{Event: event, Data: data})
+		if err == nil {
+			payload = append(payload, '\n')
+		}
+	}
+	if err != nil {
+		s.err = err
+		return
+	}
+
+	if _, err := s.w.Write(payload); err != nil {
+		s.err = err
+		return
+	}
+	if err := s.controller.Flush(); err != nil {
+		s.err = err
+	}
+}
+
+// negotiateStreamFormat picks the stream format from the query string or Accept header
+func negotiateStreamFormat(r *http.Request) string {
+	switch strings.ToLower(r.URL.Query().Get("format")) {
+	case streamFormatSSE:
+		return streamFormatSSE
+	case streamFormatNDJSON:
+		return streamFormatNDJSON
+	}
+
+	if strings.Contains(r.Header.Get("Accept"), "text/event-stream") {
+		return streamFormatSSE
+	}
+	return streamFormatNDJSON
+}
@@ -0,0 +1,217 @@
+package http
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	httpMocks "Perion_Assignment/internal/http/mocks"
+	"Perion_Assignment/internal/mocks"
+	"Perion_Assignment/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func setupStreamTest(t *testing.T) (*Handler, *httpMocks.MockAnalysisService, *mocks.MockLogger) {
+	mockAnalysisService := &httpMocks.MockAnalysisService{}
+	mockLogger := &mocks.MockLogger{}
+	mockLogger.On("LogInfo", mock.Anything, "batch_analysis", mock.AnythingOfType("string"), mock.Anything).Return()
+	mockLogger.On("LogSuccess", mock.Anything, "batch_analysis", "", mock.AnythingOfType("string"), mock.Anything).Return().Maybe()
+	return NewHandler(mockAnalysisService, mockLogger), mockAnalysisService, mockLogger
+}
+
+func streamedBatch() ([]models.DomainResult, *models.BatchAnalysisResponse) {
+	results := []models.DomainResult{
+		{Domain: "a.com", Success: true, TotalAdvertisers: 2, Timestamp: time.Now().UTC()},
+		{Domain: "b.com", Success: false, Error: "not found", Timestamp: time.Now().UTC()},
+	}
+	response := &models.BatchAnalysisResponse{
+		Results:          results,
+		Summary:          models.BatchSummary{Total: 2, Succeeded: 1, Failed: 1},
+		Advertisers:      []models.AdvertiserInfo{{Domain: "google.com", Count: 2}},
+		TotalAdvertisers: 2,
+		Timestamp:        time.Now().UTC(),
+	}
+	return results, response
+}
+
+func TestHandler_AnalyzeBatchDomainsStream_NDJSON(t *testing.T) {
+	handler, mockAnalysisService, _ := setupStreamTest(t)
+
+	results, response := streamedBatch()
+	domains := []string{"a.com", "b.com"}
+	mockAnalysisService.On("AnalyzeDomainsStream", mock.Anything, domains, models.AnalysisOptions{}, mock.Anything).Return(response, nil, results)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/batch-analysis/stream", bytes.NewBufferString(`{"domains":["a.com","b.com"]}`))
+	w := httptest.NewRecorder()
+
+	handler.AnalyzeBatchDomainsStream(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/x-ndjson", w.Header().Get("Content-Type"))
+	assert.NotEmpty(t, w.Header().Get("X-Request-ID"))
+
+	var events []map[string]json.RawMessage
+	scanner := bufio.NewScanner(w.Body)
+	for scanner.Scan() {
+		var event map[string]json.RawMessage
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &event))
+		events = append(events, event)
+	}
+
+	require.Len(t, events, 3)
+	assert.Equal(t, `"result"`, string(events[0]["event"]))
+	assert.Equal(t, `"result"`, string(events[1]["event"]))
+	assert.Equal(t, `"summary"`, string(events[2]["event"]))
+
+	var first models.DomainResult
+	require.NoError(t, json.Unmarshal(events[0]["data"], &first))
+	assert.Equal(t, "a.com", first.Domain)
+
+	var summary models.BatchStreamSummary
+	require.NoError(t, json.Unmarshal(events[2]["data"], &summary))
+	assert.Equal(t, 1, summary.Summary.Failed)
+	assert.Equal(t, 2, summary.TotalAdvertisers)
+}
+
+func TestHandler_AnalyzeBatchDomainsStream_SSE(t *testing.T) {
+	handler, mockAnalysisService, _ := setupStreamTest(t)
+
+	results, response := streamedBatch()
+	mockAnalysisService.On("AnalyzeDomainsStream", mock.Anything, mock.Anything, models.AnalysisOptions{}, mock.Anything).Return(response, nil, results)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/batch-analysis/stream", bytes.NewBufferString(`{"domains":["a.com","b.com"]}`))
+	req.Header.Set("Accept", "text/event-stream")
+	w := httptest.NewRecorder()
+
+	handler.AnalyzeBatchDomainsStream(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/event-stream", w.Header().Get("Content-Type"))
+
+	body := w.Body.String()
+	assert.Equal(t, 2, strings.Count(body, "event: result\n"))
+	assert.Equal(t, 1, strings.Count(body, "event: summary\n"))
+	assert.True(t, strings.HasSuffix(body, "\n\n"))
+	assert.Less(t, strings.LastIndex(body, "event: result"), strings.Index(body, "event: summary"))
+}
+
+func TestHandler_AnalyzeBatchDomainsStream_ServiceError(t *testing.T) {
+	handler, mockAnalysisService, mockLogger := setupStreamTest(t)
+
+	mockAnalysisService.On("AnalyzeDomainsStream", mock.Anything, mock.Anything, models.AnalysisOptions{}, mock.Anything).Return(nil, errors.New("boom"))
+	mockLogger.On("LogError", mock.Anything, "batch_analysis", "", "Streamed batch analysis failed", mock.Anything, models.LogSeverityMedium, mock.Anything).Return()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/batch-analysis/stream?format=ndjson", bytes.NewBufferString(`{"domains":["a.com"]}`))
+	w := httptest.NewRecorder()
+
+	handler.AnalyzeBatchDomainsStream(w, req)
+
+	// Headers are already sent, so the failure is reported as the final event
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"event":"error"`)
+	assert.Contains(t, w.Body.String(), "boom")
+}
+
+func TestHandler_AnalyzeBatchDomainsStream_InvalidRequest(t *testing.T) {
+	handler, mockAnalysisService, _ := setupStreamTest(t)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/batch-analysis/stream", bytes.NewBufferString(`{"domains":[]}`))
+	w := httptest.NewRecorder()
+
+	handler.AnalyzeBatchDomainsStream(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	mockAnalysisService.AssertNotCalled(t, "AnalyzeDomainsStream")
+}
+
+func TestNegotiateStreamFormat(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		accept   string
+		expected string
+	}{
+		{name: "default is ndjson", expected: streamFormatNDJSON},
+		{name: "accept event-stream", accept: "text/event-stream", expected: streamFormatSSE},
+		{name: "query overrides accept", query: "?format=ndjson", accept: "text/event-stream", expected: streamFormatNDJSON},
+		{name: "query sse", query: "?format=SSE", expected: streamFormatSSE},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/batch-analysis/stream"+tt.query, nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			assert.Equal(t, tt.expected, negotiateStreamFormat(req))
+		})
+	}
+}
+
+func TestResponseWriter_FlushAndUnwrap(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	wrapped := &responseWriter{ResponseWriter: recorder, statusCode: http.StatusOK}
+
+	wrapped.Flush()
+
+	assert.True(t, recorder.Flushed)
+	assert.Equal(t, recorder, wrapped.Unwrap())
+}
+
+func TestHandler_AnalyzeBatchDomainsStream_RejectedOptions(t *testing.T) {
+	handler, mockAnalysisService, mockLogger := setupStreamTest(t)
+
+	optsErr := fmt.Errorf("%w: cache_ttl must not be negative", models.ErrInvalidAnalysisOptions)
+	mockAnalysisService.On("AnalyzeDomainsStream", mock.Anything, mock.Anything, models.AnalysisOptions{CacheTTL: -1}, mock.Anything).Return(nil, optsErr)
+	mockLogger.On("LogError", mock.Anything, "batch_analysis", "", "Streamed batch analysis failed", optsErr, models.LogSeverityMedium, mock.Anything).Return()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/batch-analysis/stream", bytes.NewBufferString(`{"domains":["a.com"],"cache_ttl":-1}`))
+	w := httptest.NewRecorder()
+
+	handler.AnalyzeBatchDomainsStream(w, req)
+
+	// No events were sent yet, so the error is a regular JSON response
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+}
+
+func TestHandler_AnalyzeBatchDomainsStream_SlowFirstResult(t *testing.T) {
+	handler, mockAnalysisService, _ := setupStreamTest(t)
+
+	// The first result arrives after the server write timeout
+	results, response := streamedBatch()
+	mockAnalysisService.On("AnalyzeDomainsStream", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		After(300*time.Millisecond).Return(response, nil, results)
+
+	// HTTP/2 enforces the write timeout even before anything is written
+	server := httptest.NewUnstartedServer(http.HandlerFunc(handler.AnalyzeBatchDomainsStream))
+	server.Config.WriteTimeout = 100 * time.Millisecond
+	server.EnableHTTP2 = true
+	server.StartTLS()
+	defer server.Close()
+
+	resp, err := server.Client().Post(server.URL, "application/json", strings.NewReader(`{"domains":["a.com","b.com"]}`))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	var lines int
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		lines++
+	}
+	require.NoError(t, scanner.Err())
+	assert.Equal(t, 2, resp.ProtoMajor)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, 3, lines)
+}
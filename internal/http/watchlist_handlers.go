@@ -0,0 +1,96 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"Perion_Assignment/internal/logger"
+	"Perion_Assignment/internal/models"
+	"Perion_Assignment/internal/watchlist"
+
+	"github.com/gorilla/mux"
+)
+
+// WatchlistHandler contains the HTTP handlers for the watchlist API
+type WatchlistHandler struct {
+	*Handler
+	watchlistService watchlist.Service
+}
+
+// NewWatchlistHandler creates a new watchlist HTTP handler
+func NewWatchlistHandler(handler *Handler, watchlistService watchlist.Service) *WatchlistHandler {
+	return &WatchlistHandler{
+		Handler:          handler,
+		watchlistService: watchlistService,
+	}
+}
+
+// AddDomain handles POST /api/watchlist
+func (h *WatchlistHandler) AddDomain(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var request models.WatchlistRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		h.logger.LogError(ctx, logger.OpWatchlist, "", "Invalid request body", err, models.LogSeverityLow, nil)
+		h.writeErrorResponse(w, r, http.StatusBadRequest, "invalid request body", err.Error())
+		return
+	}
+
+	entry, err := h.watchlistService.Add(ctx, request.Domain)
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, models.ErrInvalidDomain):
+			statusCode = http.StatusBadRequest
+		case errors.Is(err, models.ErrWatchlistEntryExists):
+			statusCode = http.StatusConflict
+		}
+		h.writeErrorResponse(w, r, statusCode, "failed to add domain to watchlist", err.Error())
+		return
+	}
+
+	if err := h.writeJSONResponse(w, r, http.StatusCreated, entry); err != nil {
+		h.logger.LogError(ctx, logger.OpWatchlist, entry.Domain, "Failed to encode watchlist response", err, models.LogSeverityLow, nil)
+	}
+}
+
+// ListDomains handles GET /api/watchlist
+func (h *WatchlistHandler) ListDomains(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	entries := h.watchlistService.List(ctx)
+	response := models.WatchlistResponse{
+		Entries: entries,
+		Total:   len(entries),
+	}
+
+	if err := h.writeJSONResponse(w, r, http.StatusOK, response); err != nil {
+		h.logger.LogError(ctx, logger.OpWatchlist, "", "Failed to encode watchlist response", err, models.LogSeverityLow, nil)
+	}
+}
+
+// RemoveDomain handles DELETE /api/watchlist/{domain}
+func (h *WatchlistHandler) RemoveDomain(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	domain := mux.Vars(r)["domain"]
+	if domain == "" {
+		h.writeErrorResponse(w, r, http.StatusBadRequest, "domain is required", "")
+		return
+	}
+
+	entry, err := h.watchlistService.Remove(ctx, domain)
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		if errors.Is(err, models.ErrWatchlistEntryNotFound) {
+			statusCode = http.StatusNotFound
+		}
+		h.writeErrorResponse(w, r, statusCode, "failed to remove domain from watchlist", err.Error())
+		return
+	}
+
+	if err := h.writeJSONResponse(w, r, http.StatusOK, entry); err != nil {
+		h.logger.LogError(ctx, logger.OpWatchlist, domain, "Failed to encode watchlist response", err, models.LogSeverityLow, nil)
+	}
+}
@@ -0,0 +1,123 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	httpMocks "Perion_Assignment/internal/http/mocks"
+	"Perion_Assignment/internal/mocks"
+	"Perion_Assignment/internal/models"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestWatchlistHandler() (*WatchlistHandler, *httpMocks.MockWatchlistService, *mocks.MockLogger) {
+	mockWatchlist := &httpMocks.MockWatchlistService{}
+	mockLogger := &mocks.MockLogger{}
+	handler := NewWatchlistHandler(NewHandler(&httpMocks.MockAnalysisService{}, mockLogger), mockWatchlist)
+	return handler, mockWatchlist, mockLogger
+}
+
+func TestWatchlistHandler_AddDomain_Success(t *testing.T) {
+	handler, mockWatchlist, _ := newTestWatchlistHandler()
+
+	entry := &models.WatchlistEntry{Domain: "cnn.com", AddedAt: time.Now().UTC()}
+	mockWatchlist.On("Add", mock.Anything, "cnn.com").Return(entry, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/watchlist", bytes.NewBufferString(`{"domain":"cnn.com"}`))
+	w := httptest.NewRecorder()
+
+	handler.AddDomain(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	var response models.WatchlistEntry
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "cnn.com", response.Domain)
+	mockWatchlist.AssertExpectations(t)
+}
+
+func TestWatchlistHandler_AddDomain_Errors(t *testing.T) {
+	tests := []struct {
+		name           string
+		err            error
+		expectedStatus int
+	}{
+		{name: "invalid domain", err: models.ErrInvalidDomain, expectedStatus: http.StatusBadRequest},
+		{name: "already watched", err: models.ErrWatchlistEntryExists, expectedStatus: http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, mockWatchlist, _ := newTestWatchlistHandler()
+			mockWatchlist.On("Add", mock.Anything, "cnn.com").Return(nil, tt.err)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/watchlist", bytes.NewBufferString(`{"domain":"cnn.com"}`))
+			w := httptest.NewRecorder()
+
+			handler.AddDomain(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
+
+func TestWatchlistHandler_AddDomain_InvalidJSON(t *testing.T) {
+	handler, mockWatchlist, mockLogger := newTestWatchlistHandler()
+	mockLogger.On("LogError", mock.Anything, "watchlist", "", "Invalid request body", mock.Anything, models.LogSeverityLow, mock.Anything).Return()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/watchlist", bytes.NewBufferString(`{invalid`))
+	w := httptest.NewRecorder()
+
+	handler.AddDomain(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockWatchlist.AssertNotCalled(t, "Add")
+}
+
+func TestWatchlistHandler_ListDomains(t *testing.T) {
+	handler, mockWatchlist, _ := newTestWatchlistHandler()
+
+	mockWatchlist.On("List", mock.Anything).Return([]models.WatchlistEntry{
+		{Domain: "cnn.com"},
+		{Domain: "msn.com"},
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/watchlist", nil)
+	w := httptest.NewRecorder()
+
+	handler.ListDomains(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response models.WatchlistResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, 2, response.Total)
+	assert.Len(t, response.Entries, 2)
+}
+
+func TestWatchlistHandler_RemoveDomain(t *testing.T) {
+	handler, mockWatchlist, _ := newTestWatchlistHandler()
+
+	mockWatchlist.On("Remove", mock.Anything, "cnn.com").Return(&models.WatchlistEntry{Domain: "cnn.com"}, nil)
+	mockWatchlist.On("Remove", mock.Anything, "unknown.com").Return(nil, models.ErrWatchlistEntryNotFound)
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/watchlist/cnn.com", nil)
+	req = mux.SetURLVars(req, map[string]string{"domain": "cnn.com"})
+	w := httptest.NewRecorder()
+	handler.RemoveDomain(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	req = httptest.NewRequest(http.MethodDelete, "/api/watchlist/unknown.com", nil)
+	req = mux.SetURLVars(req, map[string]string{"domain": "unknown.com"})
+	w = httptest.NewRecorder()
+	handler.RemoveDomain(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,157 @@
+package ipfilter
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/netip"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"Perion_Assignment/internal/models"
+)
+
+// ParsePrefixes parses CIDR ranges; a bare IP address is a range of that address only
+func ParsePrefixes(values []string) ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(values))
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+
+		if prefix, err := netip.ParsePrefix(value); err == nil {
+			if prefix.Addr().Is4In6() && prefix.Bits() >= 96 {
+				prefix = netip.PrefixFrom(prefix.Addr().Unmap(), prefix.Bits()-96)
+			}
+			prefixes = append(prefixes, prefix.Masked())
+			continue
+		}
+		addr, err := netip.ParseAddr(value)
+		if err != nil {
+			return nil, fmt.Errorf("%q is neither a CIDR nor an IP address", value)
+		}
+		addr = addr.Unmap()
+		prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+	}
+	return prefixes, nil
+}
+
+// ruleSet is an immutable snapshot of the allow and deny lists
+type ruleSet struct {
+	lists    models.IPLists
+	allow    []netip.Prefix
+	deny     []netip.Prefix
+	loadedAt time.Time
+}
+
+// Filter implements Service with lists from the configuration and an optional JSON file
+// The configured lists are fixed; the file is re-read by Reload, and its entries are
+// added to them. Checks read an atomically swapped snapshot, so reloads never block requests.
+//
+// When an IP matches both lists, the most specific range wins, and deny wins a tie,
+// so an allowlisted host can be carved out of a denied network and vice versa.
+type Filter struct {
+	static models.IPLists
+	path   string
+
+	rules atomic.Pointer[ruleSet]
+}
+
+// NewFilter creates IP lists from the configured lists and the list file at path (empty for none)
+func NewFilter(static models.IPLists, path string) (Service, error) {
+	return newFilter(static, path)
+}
+
+// newFilter creates the concrete implementation
+func newFilter(static models.IPLists, path string) (*Filter, error) {
+	filter := &Filter{static: static, path: path}
+	if err := filter.Reload(); err != nil {
+		return nil, err
+	}
+	return filter, nil
+}
+
+// Reload re-reads the list file; on failure the lists in effect are kept
+func (f *Filter) Reload() error {
+	lists := models.IPLists{
+		Allow: append([]string{}, f.static.Allow...),
+		Deny:  append([]string{}, f.static.Deny...),
+	}
+
+	if f.path != "" {
+		data, err := os.ReadFile(f.path)
+		if err != nil {
+			return fmt.Errorf("failed to read IP list file: %w", err)
+		}
+		var fileLists models.IPLists
+		if err := json.Unmarshal(data, &fileLists); err != nil {
+			return fmt.Errorf("failed to parse IP list file: %w", err)
+		}
+		lists.Allow = append(lists.Allow, fileLists.Allow...)
+		lists.Deny = append(lists.Deny, fileLists.Deny...)
+	}
+
+	allow, err := ParsePrefixes(lists.Allow)
+	if err != nil {
+		return fmt.Errorf("invalid allowlist entry: %w", err)
+	}
+	deny, err := ParsePrefixes(lists.Deny)
+	if err != nil {
+		return fmt.Errorf("invalid denylist entry: %w", err)
+	}
+
+	f.rules.Store(&ruleSet{
+		lists:    lists,
+		allow:    allow,
+		deny:     deny,
+		loadedAt: time.Now().UTC(),
+	})
+	return nil
+}
+
+// Check returns the verdict of the lists for a client IP
+// Client IPs that aren't IP addresses match neither list.
+func (f *Filter) Check(clientIP string) Verdict {
+	addr, err := netip.ParseAddr(clientIP)
+	if err != nil {
+		return VerdictNone
+	}
+	addr = addr.Unmap()
+
+	rules := f.rules.Load()
+	allowBits := longestMatch(rules.allow, addr)
+	denyBits := longestMatch(rules.deny, addr)
+
+	switch {
+	case denyBits >= 0 && denyBits >= allowBits:
+		return VerdictDeny
+	case allowBits >= 0:
+		return VerdictAllow
+	default:
+		return VerdictNone
+	}
+}
+
+// longestMatch returns the prefix length of the most specific range containing addr, or -1
+func longestMatch(prefixes []netip.Prefix, addr netip.Addr) int {
+	bits := -1
+	for _, prefix := range prefixes {
+		if prefix.Bits() > bits && prefix.Contains(addr) {
+			bits = prefix.Bits()
+		}
+	}
+	return bits
+}
+
+// Lists returns the lists in effect
+func (f *Filter) Lists() *models.IPListsResponse {
+	rules := f.rules.Load()
+	return &models.IPListsResponse{
+		Allow:    rules.lists.Allow,
+		Deny:     rules.lists.Deny,
+		File:     f.path,
+		LoadedAt: rules.loadedAt,
+	}
+}
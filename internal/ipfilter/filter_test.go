@@ -0,0 +1,120 @@
+package ipfilter
+
+import (
+	"net/netip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"Perion_Assignment/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeListFile(t *testing.T, path, content string) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+}
+
+func TestParsePrefixes(t *testing.T) {
+	prefixes, err := ParsePrefixes([]string{" 10.0.0.0/8 ", "192.168.1.7", "2001:db8::/32", "::ffff:172.16.0.0/108", ""})
+	require.NoError(t, err)
+	assert.Equal(t, []netip.Prefix{
+		netip.MustParsePrefix("10.0.0.0/8"),
+		netip.MustParsePrefix("192.168.1.7/32"),
+		netip.MustParsePrefix("2001:db8::/32"),
+		netip.MustParsePrefix("172.16.0.0/12"),
+	}, prefixes)
+
+	_, err = ParsePrefixes([]string{"10.0.0.0/8", "not-an-ip"})
+	assert.Error(t, err)
+}
+
+func TestFilter_Check(t *testing.T) {
+	filter, err := newFilter(models.IPLists{
+		Allow: []string{"10.0.0.0/8", "203.0.113.10"},
+		Deny:  []string{"10.1.0.0/16", "203.0.113.0/24", "198.51.100.1"},
+	}, "")
+	require.NoError(t, err)
+
+	tests := []struct {
+		clientIP string
+		expected Verdict
+	}{
+		{"10.2.3.4", VerdictAllow},
+		{"10.1.2.3", VerdictDeny},      // More specific deny range
+		{"203.0.113.10", VerdictAllow}, // More specific allow host
+		{"203.0.113.11", VerdictDeny},
+		{"::ffff:198.51.100.1", VerdictDeny}, // IPv4-mapped address
+		{"192.0.2.1", VerdictNone},
+		{"unknown", VerdictNone},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.clientIP, func(t *testing.T) {
+			assert.Equal(t, tt.expected, filter.Check(tt.clientIP))
+		})
+	}
+}
+
+func TestFilter_DenyWinsTie(t *testing.T) {
+	filter, err := newFilter(models.IPLists{Allow: []string{"10.0.0.0/8"}, Deny: []string{"10.0.0.0/8"}}, "")
+	require.NoError(t, err)
+
+	assert.Equal(t, VerdictDeny, filter.Check("10.0.0.1"))
+}
+
+func TestFilter_InvalidEntry(t *testing.T) {
+	_, err := NewFilter(models.IPLists{Deny: []string{"10.0.0.0/33"}}, "")
+	assert.ErrorContains(t, err, "invalid denylist entry")
+}
+
+func TestFilter_Reload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ip_lists.json")
+	writeListFile(t, path, `{"allow":["192.0.2.0/24"],"deny":["198.51.100.0/24"]}`)
+
+	filter, err := newFilter(models.IPLists{Deny: []string{"203.0.113.0/24"}}, path)
+	require.NoError(t, err)
+
+	assert.Equal(t, VerdictAllow, filter.Check("192.0.2.1"))
+	assert.Equal(t, VerdictDeny, filter.Check("198.51.100.1"))
+	assert.Equal(t, VerdictDeny, filter.Check("203.0.113.1"))
+
+	lists := filter.Lists()
+	assert.Equal(t, []string{"192.0.2.0/24"}, lists.Allow)
+	assert.Equal(t, []string{"203.0.113.0/24", "198.51.100.0/24"}, lists.Deny)
+	assert.Equal(t, path, lists.File)
+
+	// File entries are replaced, configured entries are kept
+	writeListFile(t, path, `{"deny":["192.0.2.0/24"]}`)
+	require.NoError(t, filter.Reload())
+
+	assert.Equal(t, VerdictDeny, filter.Check("192.0.2.1"))
+	assert.Equal(t, VerdictNone, filter.Check("198.51.100.1"))
+	assert.Equal(t, VerdictDeny, filter.Check("203.0.113.1"))
+}
+
+func TestFilter_ReloadKeepsListsOnError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ip_lists.json")
+	writeListFile(t, path, `{"deny":["198.51.100.0/24"]}`)
+
+	filter, err := newFilter(models.IPLists{}, path)
+	require.NoError(t, err)
+
+	writeListFile(t, path, `{"deny":["not-an-ip"]}`)
+	assert.ErrorContains(t, filter.Reload(), "invalid denylist entry")
+	assert.Equal(t, VerdictDeny, filter.Check("198.51.100.1"))
+
+	writeListFile(t, path, `{"deny":`)
+	assert.ErrorContains(t, filter.Reload(), "failed to parse IP list file")
+
+	require.NoError(t, os.Remove(path))
+	assert.ErrorContains(t, filter.Reload(), "failed to read IP list file")
+	assert.Equal(t, VerdictDeny, filter.Check("198.51.100.1"))
+}
+
+func TestNewFilter_MissingFile(t *testing.T) {
+	_, err := NewFilter(models.IPLists{}, filepath.Join(t.TempDir(), "missing.json"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,24 @@
+package ipfilter
+
+import "Perion_Assignment/internal/models"
+
+// Service defines the interface for IP allow and deny lists
+// External packages should use this interface, not the concrete implementations
+type Service interface {
+	// Check returns the verdict of the lists for a client IP
+	Check(clientIP string) Verdict
+	// Lists returns the lists in effect
+	Lists() *models.IPListsResponse
+	// Reload re-reads the list file; on failure the lists in effect are kept
+	Reload() error
+}
+
+// Verdict is the result of checking a client IP against the lists
+type Verdict string
+
+// Verdicts
+const (
+	VerdictNone  Verdict = ""      // Listed in neither list
+	VerdictAllow Verdict = "allow" // Exempt from per-IP rate limits
+	VerdictDeny  Verdict = "deny"  // Rejected
+)
@@ -0,0 +1,38 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"Perion_Assignment/internal/models"
+)
+
+// Service defines the interface for asynchronous batch jobs
+// External packages should use this interface, not the concrete implementations
+type Service interface {
+	Submit(ctx context.Context, domains []string) (*models.Job, error)
+	Get(ctx context.Context, id string) (*models.Job, error)
+	Results(ctx context.Context, id string, offset, limit int) (*models.JobResultsResponse, error)
+	Cancel(ctx context.Context, id string) (*models.Job, error)
+	Start(ctx context.Context)
+	Stop()
+}
+
+// Store defines the persistence operations used by the job manager
+// CompareAndSetStatus only changes the status of jobs whose current status is one of from,
+// and reports whether it did, so concurrent status changes never overwrite each other.
+// AddResult keeps the first result stored for a domain and ignores later ones.
+// Instances sharing a store run a job only while holding its lease: Claim takes or renews
+// owner's lease and reports false while another owner's lease hasn't expired, and
+// ListUnclaimed returns the unfinished jobs nobody holds a lease on.
+type Store interface {
+	Create(ctx context.Context, job *models.Job, domains []string) error
+	Get(ctx context.Context, id string) (*models.Job, error)
+	Domains(ctx context.Context, id string) ([]string, error)
+	AddResult(ctx context.Context, id string, result models.DomainResult) error
+	Results(ctx context.Context, id string, offset, limit int) ([]models.DomainResult, error)
+	CompareAndSetStatus(ctx context.Context, id string, from []models.JobStatus, to models.JobStatus) (bool, error)
+	Claim(ctx context.Context, id, owner string, ttl time.Duration) (bool, error)
+	Release(ctx context.Context, id, owner string) error
+	ListUnclaimed(ctx context.Context) ([]string, error)
+}
@@ -0,0 +1,498 @@
+package jobs
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"Perion_Assignment/internal/domainAnalysis"
+	"Perion_Assignment/internal/logger"
+	"Perion_Assignment/internal/models"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// queueSize bounds the number of jobs waiting for a worker
+	queueSize = 1000
+
+	// defaultDomainTimeout bounds the analysis of a single domain within a job when no timeout is configured
+	defaultDomainTimeout = 30 * time.Second
+
+	// cancelPollInterval controls how often running jobs check the store for
+	// cancellation requested by another instance and renew their lease
+	cancelPollInterval = 2 * time.Second
+
+	// leaseTTL is how long a job stays claimed by an instance that stops renewing its lease,
+	// e.g. because it crashed, before another instance resumes it
+	leaseTTL = 30 * time.Second
+)
+
+// unfinishedStatuses are the statuses a job can be started or cancelled from
+// Jobs resumed after a restart are still running.
+var unfinishedStatuses = []models.JobStatus{models.JobStatusPending, models.JobStatusRunning}
+
+// Manager implements Service by running jobs on a pool of background workers
+type Manager struct {
+	store           Store
+	analysisService domainAnalysis.AnalysisService
+	logger          logger.Service
+	workers         int
+	maxConcurrent   int
+	domainTimeout   time.Duration
+
+	// Instances sharing a store run a job only while holding its lease
+	owner          string
+	leaseTTL       time.Duration
+	resumeInterval time.Duration
+
+	queue   chan string
+	queued  map[string]bool
+	running map[string]context.CancelFunc
+	mutex   sync.Mutex
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewManager creates a new job manager
+// workers bounds the number of jobs processed at once, maxConcurrent the domains analyzed at once per job
+// and domainTimeout the analysis of each domain, like the per-domain timeout of synchronous batches
+func NewManager(
+	store Store,
+	analysisService domainAnalysis.AnalysisService,
+	logger logger.Service,
+	workers int,
+	maxConcurrent int,
+	domainTimeout time.Duration,
+) Service {
+	return newManager(store, analysisService, logger, workers, maxConcurrent, domainTimeout)
+}
+
+// newManager creates the concrete implementation
+func newManager(
+	store Store,
+	analysisService domainAnalysis.AnalysisService,
+	logger logger.Service,
+	workers int,
+	maxConcurrent int,
+	domainTimeout time.Duration,
+) *Manager {
+	if workers <= 0 {
+		workers = 1
+	}
+	if maxConcurrent <= 0 {
+		maxConcurrent = 1
+	}
+	if domainTimeout <= 0 {
+		domainTimeout = defaultDomainTimeout
+	}
+
+	return &Manager{
+		store:           store,
+		analysisService: analysisService,
+		logger:          logger,
+		workers:         workers,
+		maxConcurrent:   maxConcurrent,
+		domainTimeout:   domainTimeout,
+		owner:           uuid.New().String(),
+		leaseTTL:        leaseTTL,
+		resumeInterval:  leaseTTL,
+		queue:           make(chan string, queueSize),
+		queued:          make(map[string]bool),
+		running:         make(map[string]context.CancelFunc),
+	}
+}
+
+// Submit creates a job for the given domains and queues it for processing
+// Domains are trimmed, lowercased and de-duplicated
+func (m *Manager) Submit(ctx context.Context, domains []string) (*models.Job, error) {
+	domains = UniqueDomains(domains)
+	if len(domains) == 0 {
+		return nil, models.ErrInvalidDomain
+	}
+
+	now := time.Now().UTC()
+	job := &models.Job{
+		ID:        uuid.New().String(),
+		Status:    models.JobStatusPending,
+		Total:     len(domains),
+		Pending:   len(domains),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	if err := m.store.Create(ctx, job, domains); err != nil {
+		return nil, err
+	}
+
+	if !m.enqueue(job.ID) {
+		// Don't leave an orphaned pending job behind
+		_, _ = m.store.CompareAndSetStatus(ctx, job.ID, []models.JobStatus{models.JobStatusPending}, models.JobStatusCancelled)
+		return nil, models.ErrJobQueueFull
+	}
+
+	m.logger.LogInfo(ctx, logger.OpBatchJob, "Submitted batch job", map[string]interface{}{
+		"job_id":        job.ID,
+		"domains_count": job.Total,
+	})
+
+	return job, nil
+}
+
+// Get returns the current state of a job
+func (m *Manager) Get(ctx context.Context, id string) (*models.Job, error) {
+	return m.store.Get(ctx, id)
+}
+
+// Results returns a page of the results produced so far
+func (m *Manager) Results(ctx context.Context, id string, offset, limit int) (*models.JobResultsResponse, error) {
+	job, err := m.store.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := m.store.Results(ctx, id, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.JobResultsResponse{
+		JobID:   id,
+		Status:  job.Status,
+		Results: results,
+		Offset:  offset,
+		Limit:   limit,
+		Total:   job.Succeeded + job.Failed,
+	}, nil
+}
+
+// Cancel stops a pending or running job; results gathered so far are kept
+func (m *Manager) Cancel(ctx context.Context, id string) (*models.Job, error) {
+	cancelled, err := m.store.CompareAndSetStatus(ctx, id, unfinishedStatuses, models.JobStatusCancelled)
+	if err != nil {
+		return nil, err
+	}
+	if !cancelled {
+		job, err := m.store.Get(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		return job, models.ErrJobFinished
+	}
+
+	m.mutex.Lock()
+	if cancel, ok := m.running[id]; ok {
+		cancel()
+	}
+	m.mutex.Unlock()
+
+	m.logger.LogInfo(ctx, logger.OpBatchJob, "Cancelled batch job", map[string]interface{}{
+		"job_id": id,
+	})
+
+	return m.store.Get(ctx, id)
+}
+
+// Start launches the workers and resumes jobs left unfinished by a previous run
+// Unfinished jobs whose lease has expired, e.g. because the instance running them stopped,
+// are resumed at start and then every resume interval.
+func (m *Manager) Start(ctx context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	m.cancel = cancel
+
+	m.resumeUnclaimed(ctx)
+
+	m.wg.Add(1)
+	go m.resumeLoop(ctx)
+
+	for i := 0; i < m.workers; i++ {
+		m.wg.Add(1)
+		go m.work(ctx)
+	}
+}
+
+// Stop stops the workers; running jobs stay active so they resume on the next start
+func (m *Manager) Stop() {
+	if m.cancel != nil {
+		m.cancel()
+	}
+	m.wg.Wait()
+}
+
+// enqueue queues a job for the workers; it returns false when the queue is full
+func (m *Manager) enqueue(id string) bool {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	select {
+	case m.queue <- id:
+		m.queued[id] = true
+		return true
+	default:
+		return false
+	}
+}
+
+// resumeUnclaimed queues the unfinished jobs no instance holds a lease on
+func (m *Manager) resumeUnclaimed(ctx context.Context) {
+	ids, err := m.store.ListUnclaimed(ctx)
+	if err != nil {
+		m.logger.LogError(ctx, logger.OpBatchJob, "", "Failed to list unfinished batch jobs", err, models.LogSeverityMedium, nil)
+		return
+	}
+
+	resumed := 0
+	for _, id := range ids {
+		m.mutex.Lock()
+		_, running := m.running[id]
+		queued := m.queued[id]
+		m.mutex.Unlock()
+		if running || queued {
+			// Already resumed by this instance
+			continue
+		}
+
+		if !m.enqueue(id) {
+			m.logger.LogError(ctx, logger.OpBatchJob, id, "Failed to resume batch job", models.ErrJobQueueFull, models.LogSeverityMedium, nil)
+			continue
+		}
+		resumed++
+	}
+	if resumed > 0 {
+		m.logger.LogInfo(ctx, logger.OpBatchJob, "Resuming unfinished batch jobs", map[string]interface{}{
+			"jobs_count": resumed,
+		})
+	}
+}
+
+// resumeLoop resumes unclaimed jobs every resume interval until the context is cancelled
+func (m *Manager) resumeLoop(ctx context.Context) {
+	defer m.wg.Done()
+
+	ticker := time.NewTicker(m.resumeInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.resumeUnclaimed(ctx)
+		}
+	}
+}
+
+// work processes queued jobs until the context is cancelled
+func (m *Manager) work(ctx context.Context) {
+	defer m.wg.Done()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case id := <-m.queue:
+			m.mutex.Lock()
+			delete(m.queued, id)
+			m.mutex.Unlock()
+
+			m.runJob(ctx, id)
+		}
+	}
+}
+
+// runJob analyzes every domain of a job that has no result yet
+func (m *Manager) runJob(ctx context.Context, id string) {
+	// Each job is an internal process with its own ProcessID
+	ctx = logger.WithLogEvent(ctx, logger.NewInternalLogEvent())
+	start := time.Now()
+
+	job, err := m.store.Get(ctx, id)
+	if err != nil {
+		m.logger.LogError(ctx, logger.OpBatchJob, id, "Failed to load batch job", err, models.LogSeverityMedium, nil)
+		return
+	}
+	if job.Status.IsFinished() {
+		return
+	}
+
+	jobCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	m.mutex.Lock()
+	if _, running := m.running[id]; running {
+		m.mutex.Unlock()
+		return
+	}
+	m.running[id] = cancel
+	m.mutex.Unlock()
+	defer func() {
+		m.mutex.Lock()
+		delete(m.running, id)
+		m.mutex.Unlock()
+	}()
+
+	// Only one instance runs a job; the lease is renewed while it runs
+	claimed, err := m.store.Claim(ctx, id, m.owner, m.leaseTTL)
+	if err != nil {
+		m.logger.LogError(ctx, logger.OpBatchJob, id, "Failed to claim batch job", err, models.LogSeverityMedium, nil)
+		return
+	}
+	if !claimed {
+		return
+	}
+	defer func() {
+		// Released even when stopping, so another instance can resume the job right away
+		if err := m.store.Release(context.WithoutCancel(ctx), id, m.owner); err != nil {
+			m.logger.LogError(ctx, logger.OpBatchJob, id, "Failed to release batch job", err, models.LogSeverityLow, nil)
+		}
+	}()
+
+	// Cancel may have finished the job since it was loaded
+	started, err := m.store.CompareAndSetStatus(ctx, id, unfinishedStatuses, models.JobStatusRunning)
+	if err != nil {
+		m.logger.LogError(ctx, logger.OpBatchJob, id, "Failed to mark batch job as running", err, models.LogSeverityMedium, nil)
+		return
+	}
+	if !started {
+		return
+	}
+
+	remaining, err := m.remainingDomains(ctx, id)
+	if err != nil {
+		m.logger.LogError(ctx, logger.OpBatchJob, id, "Failed to load batch job domains", err, models.LogSeverityMedium, nil)
+		return
+	}
+
+	go m.watchJob(jobCtx, id, cancel)
+
+	sem := make(chan struct{}, m.maxConcurrent)
+	var wg sync.WaitGroup
+
+	for _, domain := range remaining {
+		// Acquire semaphore unless the job is stopped
+		select {
+		case <-jobCtx.Done():
+		case sem <- struct{}{}:
+		}
+		if jobCtx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+		go func(dom string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			domainCtx, cancelDomain := context.WithTimeout(jobCtx, m.domainTimeout)
+			defer cancelDomain()
+
+			analysis, err := m.analysisService.AnalyzeDomain(domainCtx, dom)
+			if jobCtx.Err() != nil {
+				// Job was cancelled or the process is stopping - leave the domain unprocessed
+				return
+			}
+
+			if err := m.store.AddResult(ctx, id, models.NewDomainResult(dom, analysis, err)); err != nil {
+				m.logger.LogError(ctx, logger.OpBatchJob, dom, "Failed to store batch job result", err, models.LogSeverityMedium, map[string]interface{}{
+					"job_id": id,
+				})
+			}
+		}(domain)
+	}
+
+	wg.Wait()
+
+	if ctx.Err() != nil {
+		// Shutting down - keep the job active so it resumes on the next start
+		return
+	}
+	if jobCtx.Err() != nil {
+		// Cancelled - status was already updated by Cancel - or taken over by another instance
+		return
+	}
+
+	completed, err := m.store.CompareAndSetStatus(ctx, id, []models.JobStatus{models.JobStatusRunning}, models.JobStatusCompleted)
+	if err != nil {
+		m.logger.LogError(ctx, logger.OpBatchJob, id, "Failed to mark batch job as completed", err, models.LogSeverityMedium, nil)
+		return
+	}
+	if !completed {
+		// Cancelled by another instance after the last domain
+		return
+	}
+
+	m.logger.LogSuccess(ctx, logger.OpBatchJob, id, "Completed batch job", map[string]interface{}{
+		"domains_count": len(remaining),
+		"duration_ms":   time.Since(start).Milliseconds(),
+	})
+}
+
+// remainingDomains returns the job's domains that have no stored result yet
+func (m *Manager) remainingDomains(ctx context.Context, id string) ([]string, error) {
+	domains, err := m.store.Domains(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := m.store.Results(ctx, id, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(results) == 0 {
+		return domains, nil
+	}
+
+	done := make(map[string]bool, len(results))
+	for _, result := range results {
+		done[result.Domain] = true
+	}
+
+	remaining := make([]string, 0, len(domains)-len(results))
+	for _, domain := range domains {
+		if !done[domain] {
+			remaining = append(remaining, domain)
+		}
+	}
+	return remaining, nil
+}
+
+// watchJob renews the lease of a running job and cancels the job when another instance
+// marks it as cancelled in the store or has taken it over
+func (m *Manager) watchJob(ctx context.Context, id string, cancel context.CancelFunc) {
+	ticker := time.NewTicker(cancelPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if claimed, err := m.store.Claim(ctx, id, m.owner, m.leaseTTL); err == nil && !claimed {
+				cancel()
+				return
+			}
+			if job, err := m.store.Get(ctx, id); err == nil && job.Status == models.JobStatusCancelled {
+				cancel()
+				return
+			}
+		}
+	}
+}
+
+// UniqueDomains normalizes domains and removes empty entries and duplicates, like Submit does
+func UniqueDomains(domains []string) []string {
+	seen := make(map[string]bool, len(domains))
+	unique := make([]string, 0, len(domains))
+
+	for _, domain := range domains {
+		domain = strings.ToLower(strings.TrimSpace(domain))
+		if domain == "" || seen[domain] {
+			continue
+		}
+		seen[domain] = true
+		unique = append(unique, domain)
+	}
+	return unique
+}
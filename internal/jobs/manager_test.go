@@ -0,0 +1,267 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	httpMocks "Perion_Assignment/internal/http/mocks"
+	"Perion_Assignment/internal/mocks"
+	"Perion_Assignment/internal/models"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func waitForStatus(t *testing.T, service Service, id string, status models.JobStatus) *models.Job {
+	var job *models.Job
+	require.Eventually(t, func() bool {
+		var err error
+		job, err = service.Get(context.Background(), id)
+		return err == nil && job.Status == status
+	}, 5*time.Second, 10*time.Millisecond)
+	return job
+}
+
+func TestManager_Submit_ProcessesAllDomains(t *testing.T) {
+	mockAnalysis := &httpMocks.MockAnalysisService{}
+	manager := NewManager(newMemoryStore(time.Hour), mockAnalysis, mocks.NewAnyLogger(), 1, 2, time.Minute)
+
+	mockAnalysis.On("AnalyzeDomain", mock.Anything, "a.com").Return(&models.DomainAnalysis{Domain: "a.com", TotalAdvertisers: 3}, nil)
+	mockAnalysis.On("AnalyzeDomain", mock.Anything, "b.com").Return(&models.DomainAnalysis{Domain: "b.com", TotalAdvertisers: 1}, nil)
+	mockAnalysis.On("AnalyzeDomain", mock.Anything, "c.com").Return(nil, errors.New("ads.txt not found"))
+
+	ctx := context.Background()
+	manager.Start(ctx)
+	defer manager.Stop()
+
+	job, err := manager.Submit(ctx, []string{"a.com", "B.com ", "c.com", "a.com", ""})
+	require.NoError(t, err)
+	assert.Equal(t, 3, job.Total)
+	assert.Equal(t, models.JobStatusPending, job.Status)
+
+	job = waitForStatus(t, manager, job.ID, models.JobStatusCompleted)
+	assert.Equal(t, 2, job.Succeeded)
+	assert.Equal(t, 1, job.Failed)
+	assert.Equal(t, 0, job.Pending)
+	assert.NotNil(t, job.CompletedAt)
+
+	page, err := manager.Results(ctx, job.ID, 0, 2)
+	require.NoError(t, err)
+	assert.Len(t, page.Results, 2)
+	assert.Equal(t, 3, page.Total)
+
+	page, err = manager.Results(ctx, job.ID, 2, 2)
+	require.NoError(t, err)
+	assert.Len(t, page.Results, 1)
+}
+
+func TestManager_Submit_NoValidDomains(t *testing.T) {
+	manager := NewManager(newMemoryStore(time.Hour), &httpMocks.MockAnalysisService{}, mocks.NewAnyLogger(), 1, 2, time.Minute)
+
+	_, err := manager.Submit(context.Background(), []string{" ", ""})
+	assert.ErrorIs(t, err, models.ErrInvalidDomain)
+}
+
+func TestManager_Get_NotFound(t *testing.T) {
+	manager := NewManager(newMemoryStore(time.Hour), &httpMocks.MockAnalysisService{}, mocks.NewAnyLogger(), 1, 2, time.Minute)
+
+	_, err := manager.Get(context.Background(), "missing")
+	assert.ErrorIs(t, err, models.ErrJobNotFound)
+
+	_, err = manager.Results(context.Background(), "missing", 0, 10)
+	assert.ErrorIs(t, err, models.ErrJobNotFound)
+}
+
+func TestManager_Cancel_StopsRunningJob(t *testing.T) {
+	mockAnalysis := &httpMocks.MockAnalysisService{}
+	manager := NewManager(newMemoryStore(time.Hour), mockAnalysis, mocks.NewAnyLogger(), 1, 1, time.Minute)
+
+	started := make(chan struct{}, 1)
+	mockAnalysis.On("AnalyzeDomain", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		started <- struct{}{}
+		<-args.Get(0).(context.Context).Done()
+	}).Return(nil, context.Canceled)
+
+	ctx := context.Background()
+	manager.Start(ctx)
+	defer manager.Stop()
+
+	job, err := manager.Submit(ctx, []string{"a.com", "b.com", "c.com"})
+	require.NoError(t, err)
+
+	<-started
+	cancelled, err := manager.Cancel(ctx, job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.JobStatusCancelled, cancelled.Status)
+
+	// Cancelled domains are not recorded as results
+	time.Sleep(50 * time.Millisecond)
+	job, err = manager.Get(ctx, job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 0, job.Succeeded+job.Failed)
+
+	_, err = manager.Cancel(ctx, job.ID)
+	assert.ErrorIs(t, err, models.ErrJobFinished)
+}
+
+// staleStore returns jobs as they were before being cancelled, like a Get that raced with Cancel
+type staleStore struct {
+	*MemoryStore
+}
+
+// Get returns the job as still pending
+func (s staleStore) Get(ctx context.Context, id string) (*models.Job, error) {
+	job, err := s.MemoryStore.Get(ctx, id)
+	if err == nil {
+		job.Status = models.JobStatusPending
+	}
+	return job, err
+}
+
+func TestManager_RunJob_CancelledAfterLoading(t *testing.T) {
+	store := newMemoryStore(time.Hour)
+	ctx := context.Background()
+
+	now := time.Now().UTC()
+	require.NoError(t, store.Create(ctx, &models.Job{ID: "job-1", Status: models.JobStatusCancelled, Total: 1, CreatedAt: now, UpdatedAt: now}, []string{"a.com"}))
+
+	mockAnalysis := &httpMocks.MockAnalysisService{}
+	manager := newManager(staleStore{store}, mockAnalysis, mocks.NewAnyLogger(), 1, 1, time.Minute)
+
+	manager.runJob(ctx, "job-1")
+
+	// The cancellation is kept and no domain is analyzed
+	job, err := store.Get(ctx, "job-1")
+	require.NoError(t, err)
+	assert.Equal(t, models.JobStatusCancelled, job.Status)
+	mockAnalysis.AssertNotCalled(t, "AnalyzeDomain", mock.Anything, mock.Anything)
+}
+
+func TestManager_Start_ResumesUnfinishedJobs(t *testing.T) {
+	store := newMemoryStore(time.Hour)
+	ctx := context.Background()
+
+	// Simulate a job interrupted after one of its domains was processed
+	now := time.Now().UTC()
+	job := &models.Job{ID: "job-1", Status: models.JobStatusRunning, Total: 2, CreatedAt: now, UpdatedAt: now}
+	require.NoError(t, store.Create(ctx, job, []string{"a.com", "b.com"}))
+	require.NoError(t, store.AddResult(ctx, "job-1", models.DomainResult{Domain: "a.com", Success: true}))
+
+	mockAnalysis := &httpMocks.MockAnalysisService{}
+	mockAnalysis.On("AnalyzeDomain", mock.Anything, "b.com").Return(&models.DomainAnalysis{Domain: "b.com"}, nil).Once()
+
+	manager := NewManager(store, mockAnalysis, mocks.NewAnyLogger(), 1, 2, time.Minute)
+	manager.Start(ctx)
+	defer manager.Stop()
+
+	resumed := waitForStatus(t, manager, "job-1", models.JobStatusCompleted)
+	assert.Equal(t, 2, resumed.Succeeded)
+	mockAnalysis.AssertExpectations(t)
+	mockAnalysis.AssertNotCalled(t, "AnalyzeDomain", mock.Anything, "a.com")
+}
+
+// newSharedRedisStore creates a store on the given mini redis, like another instance would
+func newSharedRedisStore(mr *miniredis.Miniredis) *RedisStore {
+	return &RedisStore{client: redis.NewClient(&redis.Options{Addr: mr.Addr()}), retention: time.Hour}
+}
+
+func TestManager_SharedRedisStore_RunsJobOnce(t *testing.T) {
+	mr := miniredis.RunT(t)
+	ctx := context.Background()
+
+	// A job left running by an instance that was replaced
+	now := time.Now().UTC()
+	domains := []string{"a.com", "b.com", "c.com", "d.com"}
+	job := &models.Job{ID: "job-1", Status: models.JobStatusRunning, Total: len(domains), CreatedAt: now, UpdatedAt: now}
+	require.NoError(t, newSharedRedisStore(mr).Create(ctx, job, domains))
+
+	var mutex sync.Mutex
+	calls := make(map[string]int)
+	mockAnalysis := &httpMocks.MockAnalysisService{}
+	mockAnalysis.On("AnalyzeDomain", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		mutex.Lock()
+		calls[args.String(1)]++
+		mutex.Unlock()
+		time.Sleep(20 * time.Millisecond)
+	}).Return(&models.DomainAnalysis{}, nil)
+
+	// Both instances resumed the job while it was unclaimed
+	first := newManager(newSharedRedisStore(mr), mockAnalysis, mocks.NewAnyLogger(), 1, 1, time.Minute)
+	second := newManager(newSharedRedisStore(mr), mockAnalysis, mocks.NewAnyLogger(), 1, 1, time.Minute)
+
+	var wg sync.WaitGroup
+	for _, manager := range []*Manager{first, second} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			manager.runJob(ctx, "job-1")
+		}()
+	}
+	wg.Wait()
+
+	completed, err := first.Get(ctx, "job-1")
+	require.NoError(t, err)
+	assert.Equal(t, models.JobStatusCompleted, completed.Status)
+	assert.Equal(t, len(domains), completed.Succeeded)
+	assert.Equal(t, 0, completed.Pending)
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	for _, domain := range domains {
+		assert.Equal(t, 1, calls[domain], domain)
+	}
+}
+
+func TestManager_ResumesJobsWithExpiredLease(t *testing.T) {
+	mr := miniredis.RunT(t)
+	store := newSharedRedisStore(mr)
+	ctx := context.Background()
+
+	// The instance running the job stopped without releasing its lease
+	now := time.Now().UTC()
+	require.NoError(t, store.Create(ctx, &models.Job{ID: "job-1", Status: models.JobStatusRunning, Total: 1, CreatedAt: now, UpdatedAt: now}, []string{"a.com"}))
+	claimed, err := store.Claim(ctx, "job-1", "crashed-instance", leaseTTL)
+	require.NoError(t, err)
+	require.True(t, claimed)
+
+	mockAnalysis := &httpMocks.MockAnalysisService{}
+	mockAnalysis.On("AnalyzeDomain", mock.Anything, "a.com").Return(&models.DomainAnalysis{Domain: "a.com"}, nil).Once()
+
+	manager := newManager(store, mockAnalysis, mocks.NewAnyLogger(), 1, 1, time.Minute)
+	manager.resumeInterval = 10 * time.Millisecond
+	manager.Start(ctx)
+	defer manager.Stop()
+
+	// Left alone while the lease is live
+	time.Sleep(50 * time.Millisecond)
+	mockAnalysis.AssertNotCalled(t, "AnalyzeDomain", mock.Anything, mock.Anything)
+
+	mr.FastForward(leaseTTL)
+	waitForStatus(t, manager, "job-1", models.JobStatusCompleted)
+	mockAnalysis.AssertExpectations(t)
+}
+
+func TestManager_AppliesDomainTimeout(t *testing.T) {
+	mockAnalysis := &httpMocks.MockAnalysisService{}
+	manager := NewManager(newMemoryStore(time.Hour), mockAnalysis, mocks.NewAnyLogger(), 1, 1, 50*time.Millisecond)
+
+	mockAnalysis.On("AnalyzeDomain", mock.Anything, "slow.com").Run(func(args mock.Arguments) {
+		<-args.Get(0).(context.Context).Done()
+	}).Return(nil, context.DeadlineExceeded)
+
+	ctx := context.Background()
+	manager.Start(ctx)
+	defer manager.Stop()
+
+	job, err := manager.Submit(ctx, []string{"slow.com"})
+	require.NoError(t, err)
+
+	job = waitForStatus(t, manager, job.ID, models.JobStatusCompleted)
+	assert.Equal(t, 1, job.Failed)
+}
@@ -0,0 +1,220 @@
+package jobs
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"Perion_Assignment/internal/models"
+)
+
+// MemoryStore implements Store using in-memory storage
+// Jobs do not survive a process restart with this store
+type MemoryStore struct {
+	jobs      map[string]*memoryJob
+	mutex     sync.RWMutex
+	retention time.Duration
+}
+
+// memoryJob holds a job together with its input, results and lease
+type memoryJob struct {
+	job     models.Job
+	domains []string
+	results []models.DomainResult
+	done    map[string]bool
+
+	owner          string
+	leaseExpiresAt time.Time
+}
+
+// NewMemoryStore creates a new in-memory job store
+// Finished jobs are removed once they are older than retention
+func NewMemoryStore(retention time.Duration) Store {
+	return newMemoryStore(retention)
+}
+
+// newMemoryStore creates the concrete implementation
+func newMemoryStore(retention time.Duration) *MemoryStore {
+	store := &MemoryStore{
+		jobs:      make(map[string]*memoryJob),
+		retention: retention,
+	}
+
+	// Start cleanup routine
+	go store.cleanupFinished()
+
+	return store
+}
+
+// Create stores a new job and its domains
+func (m *MemoryStore) Create(ctx context.Context, job *models.Job, domains []string) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.jobs[job.ID] = &memoryJob{
+		job:     *job,
+		domains: append([]string(nil), domains...),
+		results: make([]models.DomainResult, 0, len(domains)),
+		done:    make(map[string]bool, len(domains)),
+	}
+	return nil
+}
+
+// Get retrieves a job by ID
+func (m *MemoryStore) Get(ctx context.Context, id string) (*models.Job, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	stored, exists := m.jobs[id]
+	if !exists {
+		return nil, models.ErrJobNotFound
+	}
+
+	job := stored.job
+	job.Pending = job.Total - job.Succeeded - job.Failed
+	return &job, nil
+}
+
+// Domains returns the domains submitted with a job
+func (m *MemoryStore) Domains(ctx context.Context, id string) ([]string, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	stored, exists := m.jobs[id]
+	if !exists {
+		return nil, models.ErrJobNotFound
+	}
+	return append([]string(nil), stored.domains...), nil
+}
+
+// AddResult appends a domain result and updates the job counters, unless the domain already has a result
+func (m *MemoryStore) AddResult(ctx context.Context, id string, result models.DomainResult) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	stored, exists := m.jobs[id]
+	if !exists {
+		return models.ErrJobNotFound
+	}
+	if stored.done[result.Domain] {
+		return nil
+	}
+	stored.done[result.Domain] = true
+
+	stored.results = append(stored.results, result)
+	if result.Success {
+		stored.job.Succeeded++
+	} else {
+		stored.job.Failed++
+	}
+	stored.job.UpdatedAt = time.Now().UTC()
+	return nil
+}
+
+// Results returns a page of results in completion order; a non-positive limit returns all remaining
+func (m *MemoryStore) Results(ctx context.Context, id string, offset, limit int) ([]models.DomainResult, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	stored, exists := m.jobs[id]
+	if !exists {
+		return nil, models.ErrJobNotFound
+	}
+
+	if offset >= len(stored.results) {
+		return []models.DomainResult{}, nil
+	}
+
+	end := len(stored.results)
+	if limit > 0 && offset+limit < end {
+		end = offset + limit
+	}
+	return append([]models.DomainResult(nil), stored.results[offset:end]...), nil
+}
+
+// CompareAndSetStatus updates the job status if it is one of from, recording the completion time for finished jobs
+func (m *MemoryStore) CompareAndSetStatus(ctx context.Context, id string, from []models.JobStatus, to models.JobStatus) (bool, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	stored, exists := m.jobs[id]
+	if !exists {
+		return false, models.ErrJobNotFound
+	}
+	if !slices.Contains(from, stored.job.Status) {
+		return false, nil
+	}
+
+	now := time.Now().UTC()
+	stored.job.Status = to
+	stored.job.UpdatedAt = now
+	if to.IsFinished() {
+		stored.job.CompletedAt = &now
+	}
+	return true, nil
+}
+
+// Claim takes or renews owner's lease on a job unless another owner holds an unexpired lease
+func (m *MemoryStore) Claim(ctx context.Context, id, owner string, ttl time.Duration) (bool, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	stored, exists := m.jobs[id]
+	if !exists {
+		return false, models.ErrJobNotFound
+	}
+
+	now := time.Now()
+	if stored.owner != "" && stored.owner != owner && now.Before(stored.leaseExpiresAt) {
+		return false, nil
+	}
+	stored.owner = owner
+	stored.leaseExpiresAt = now.Add(ttl)
+	return true, nil
+}
+
+// Release gives up owner's lease on a job
+func (m *MemoryStore) Release(ctx context.Context, id, owner string) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if stored, exists := m.jobs[id]; exists && stored.owner == owner {
+		stored.owner = ""
+	}
+	return nil
+}
+
+// ListUnclaimed returns the IDs of jobs that have not finished and have no unexpired lease
+func (m *MemoryStore) ListUnclaimed(ctx context.Context) ([]string, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	now := time.Now()
+	var ids []string
+	for id, stored := range m.jobs {
+		claimed := stored.owner != "" && now.Before(stored.leaseExpiresAt)
+		if !stored.job.Status.IsFinished() && !claimed {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+// cleanupFinished removes finished jobs older than the retention period
+func (m *MemoryStore) cleanupFinished() {
+	ticker := time.NewTicker(5 * time.Minute) // Cleanup every 5 minutes
+	defer ticker.Stop()
+
+	for range ticker.C {
+		cutoff := time.Now().Add(-m.retention)
+
+		m.mutex.Lock()
+		for id, stored := range m.jobs {
+			if stored.job.CompletedAt != nil && stored.job.CompletedAt.Before(cutoff) {
+				delete(m.jobs, id)
+			}
+		}
+		m.mutex.Unlock()
+	}
+}
@@ -0,0 +1,346 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"Perion_Assignment/internal/models"
+	"Perion_Assignment/internal/redisclient"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// activeJobsKey is the Redis set holding the IDs of unfinished jobs
+const activeJobsKey = "jobs:active"
+
+// setStatusScript sets the status of a job whose current status is one of ARGV[6..]
+// Finished jobs lose their lease.
+//
+// KEYS: job hash, domains list, results data hash, results order list, owner, active job set
+// ARGV: job ID, new status, now, 1 when the new status is finished, retention in ms
+//
+// It returns -1 for unknown jobs, 0 when the current status doesn't match and 1 once set.
+var setStatusScript = redis.NewScript(`
+local current = redis.call("HGET", KEYS[1], "status")
+if not current then
+	return -1
+end
+
+local matches = false
+for i = 6, #ARGV do
+	if ARGV[i] == current then
+		matches = true
+	end
+end
+if not matches then
+	return 0
+end
+
+redis.call("HSET", KEYS[1], "status", ARGV[2], "updated_at", ARGV[3])
+if ARGV[4] == "1" then
+	redis.call("HSET", KEYS[1], "completed_at", ARGV[3])
+	redis.call("DEL", KEYS[5])
+	redis.call("SREM", KEYS[6], ARGV[1])
+	local retention = tonumber(ARGV[5])
+	if retention > 0 then
+		for i = 1, 4 do
+			redis.call("PEXPIRE", KEYS[i], retention)
+		end
+	end
+end
+return 1
+`)
+
+// addResultScript stores the result of a domain unless it already has one
+//
+// KEYS: job hash, results data hash, results order list
+// ARGV: domain, JSON-encoded result, counter to increment, now
+var addResultScript = redis.NewScript(`
+if redis.call("HSETNX", KEYS[2], ARGV[1], ARGV[2]) == 0 then
+	return 0
+end
+redis.call("RPUSH", KEYS[3], ARGV[1])
+redis.call("HINCRBY", KEYS[1], ARGV[3], 1)
+redis.call("HSET", KEYS[1], "updated_at", ARGV[4])
+return 1
+`)
+
+// claimScript takes or renews the lease of ARGV[1] unless another owner holds it
+var claimScript = redis.NewScript(`
+local owner = redis.call("GET", KEYS[1])
+if owner and owner ~= ARGV[1] then
+	return 0
+end
+redis.call("SET", KEYS[1], ARGV[1], "PX", ARGV[2])
+return 1
+`)
+
+// releaseScript deletes the lease only if it is still held by ARGV[1]
+var releaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// RedisStore implements Store using Redis so jobs survive process restarts
+//
+// Layout per job:
+//
+//	job:<id>                hash of status, counters and timestamps
+//	job:<id>:domains        list of submitted domains
+//	job:<id>:results:data   hash of JSON-encoded results by domain
+//	job:<id>:results:order  list of domains with a result, in completion order
+//	job:<id>:owner          lease of the instance running the job
+//
+// Updates are transactions and scripts spanning these keys and the active job set, so the
+// store can't be used with Redis Cluster, where they would hash to different slots.
+type RedisStore struct {
+	client    redis.UniversalClient
+	retention time.Duration
+}
+
+// NewRedisStore creates a new Redis-backed job store
+// Finished jobs expire once they are older than retention
+func NewRedisStore(redisConfig redisclient.Config, retention time.Duration) (Store, error) {
+	return newRedisStore(redisConfig, retention)
+}
+
+// newRedisStore creates the concrete implementation
+func newRedisStore(redisConfig redisclient.Config, retention time.Duration) (*RedisStore, error) {
+	client, err := redisclient.New(redisConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RedisStore{
+		client:    client,
+		retention: retention,
+	}, nil
+}
+
+// Create stores a new job and its domains
+func (r *RedisStore) Create(ctx context.Context, job *models.Job, domains []string) error {
+	_, err := r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, jobKey(job.ID), map[string]interface{}{
+			"status":     string(job.Status),
+			"total":      job.Total,
+			"succeeded":  job.Succeeded,
+			"failed":     job.Failed,
+			"created_at": formatTime(job.CreatedAt),
+			"updated_at": formatTime(job.UpdatedAt),
+		})
+		if len(domains) > 0 {
+			values := make([]interface{}, len(domains))
+			for i, domain := range domains {
+				values[i] = domain
+			}
+			pipe.RPush(ctx, domainsKey(job.ID), values...)
+		}
+		pipe.SAdd(ctx, activeJobsKey, job.ID)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("redis create job failed: %w", err)
+	}
+	return nil
+}
+
+// Get retrieves a job by ID
+func (r *RedisStore) Get(ctx context.Context, id string) (*models.Job, error) {
+	fields, err := r.client.HGetAll(ctx, jobKey(id)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("redis get job failed: %w", err)
+	}
+	if len(fields) == 0 {
+		return nil, models.ErrJobNotFound
+	}
+
+	job := &models.Job{
+		ID:     id,
+		Status: models.JobStatus(fields["status"]),
+	}
+	job.Total, _ = strconv.Atoi(fields["total"])
+	job.Succeeded, _ = strconv.Atoi(fields["succeeded"])
+	job.Failed, _ = strconv.Atoi(fields["failed"])
+	job.Pending = job.Total - job.Succeeded - job.Failed
+	job.CreatedAt = parseTime(fields["created_at"])
+	job.UpdatedAt = parseTime(fields["updated_at"])
+	if completedAt := fields["completed_at"]; completedAt != "" {
+		t := parseTime(completedAt)
+		job.CompletedAt = &t
+	}
+
+	return job, nil
+}
+
+// Domains returns the domains submitted with a job
+func (r *RedisStore) Domains(ctx context.Context, id string) ([]string, error) {
+	domains, err := r.client.LRange(ctx, domainsKey(id), 0, -1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("redis get job domains failed: %w", err)
+	}
+	return domains, nil
+}
+
+// AddResult stores a domain result and updates the job counters, unless the domain already has a result
+func (r *RedisStore) AddResult(ctx context.Context, id string, result models.DomainResult) error {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("failed to marshal job result: %w", err)
+	}
+
+	counter := "failed"
+	if result.Success {
+		counter = "succeeded"
+	}
+
+	keys := []string{jobKey(id), resultDataKey(id), resultOrderKey(id)}
+	if err := addResultScript.Run(ctx, r.client, keys, result.Domain, data, counter, formatTime(time.Now().UTC())).Err(); err != nil {
+		return fmt.Errorf("redis add job result failed: %w", err)
+	}
+	return nil
+}
+
+// Results returns a page of results in completion order; a non-positive limit returns all remaining
+func (r *RedisStore) Results(ctx context.Context, id string, offset, limit int) ([]models.DomainResult, error) {
+	stop := int64(-1)
+	if limit > 0 {
+		stop = int64(offset + limit - 1)
+	}
+
+	domains, err := r.client.LRange(ctx, resultOrderKey(id), int64(offset), stop).Result()
+	if err != nil {
+		return nil, fmt.Errorf("redis get job results failed: %w", err)
+	}
+	if len(domains) == 0 {
+		return []models.DomainResult{}, nil
+	}
+
+	values, err := r.client.HMGet(ctx, resultDataKey(id), domains...).Result()
+	if err != nil {
+		return nil, fmt.Errorf("redis get job results failed: %w", err)
+	}
+
+	results := make([]models.DomainResult, 0, len(values))
+	for _, value := range values {
+		data, ok := value.(string)
+		if !ok {
+			continue
+		}
+		var result models.DomainResult
+		if err := json.Unmarshal([]byte(data), &result); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal job result: %w", err)
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// CompareAndSetStatus updates the job status if it is one of from; finished jobs leave the
+// active set and expire after the retention period
+func (r *RedisStore) CompareAndSetStatus(ctx context.Context, id string, from []models.JobStatus, to models.JobStatus) (bool, error) {
+	finished := 0
+	if to.IsFinished() {
+		finished = 1
+	}
+
+	args := []interface{}{id, string(to), formatTime(time.Now().UTC()), finished, r.retention.Milliseconds()}
+	for _, status := range from {
+		args = append(args, string(status))
+	}
+
+	keys := []string{jobKey(id), domainsKey(id), resultDataKey(id), resultOrderKey(id), ownerKey(id), activeJobsKey}
+	set, err := setStatusScript.Run(ctx, r.client, keys, args...).Int()
+	if err != nil {
+		return false, fmt.Errorf("redis set job status failed: %w", err)
+	}
+	if set < 0 {
+		return false, models.ErrJobNotFound
+	}
+	return set == 1, nil
+}
+
+// Claim takes or renews owner's lease on a job unless another owner holds an unexpired lease
+func (r *RedisStore) Claim(ctx context.Context, id, owner string, ttl time.Duration) (bool, error) {
+	claimed, err := claimScript.Run(ctx, r.client, []string{ownerKey(id)}, owner, ttl.Milliseconds()).Int()
+	if err != nil {
+		return false, fmt.Errorf("redis claim job failed: %w", err)
+	}
+	return claimed == 1, nil
+}
+
+// Release gives up owner's lease on a job
+func (r *RedisStore) Release(ctx context.Context, id, owner string) error {
+	if err := releaseScript.Run(ctx, r.client, []string{ownerKey(id)}, owner).Err(); err != nil {
+		return fmt.Errorf("redis release job failed: %w", err)
+	}
+	return nil
+}
+
+// ListUnclaimed returns the IDs of jobs that have not finished and have no unexpired lease
+func (r *RedisStore) ListUnclaimed(ctx context.Context) ([]string, error) {
+	ids, err := r.client.SMembers(ctx, activeJobsKey).Result()
+	if err != nil {
+		return nil, fmt.Errorf("redis list active jobs failed: %w", err)
+	}
+	if len(ids) == 0 {
+		return ids, nil
+	}
+
+	owned := make([]*redis.IntCmd, len(ids))
+	_, err = r.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, id := range ids {
+			owned[i] = pipe.Exists(ctx, ownerKey(id))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("redis list job leases failed: %w", err)
+	}
+
+	unclaimed := make([]string, 0, len(ids))
+	for i, id := range ids {
+		if owned[i].Val() == 0 {
+			unclaimed = append(unclaimed, id)
+		}
+	}
+	return unclaimed, nil
+}
+
+// Close closes the Redis connection
+func (r *RedisStore) Close() error {
+	return r.client.Close()
+}
+
+func jobKey(id string) string {
+	return "job:" + id
+}
+
+func domainsKey(id string) string {
+	return "job:" + id + ":domains"
+}
+
+func resultDataKey(id string) string {
+	return "job:" + id + ":results:data"
+}
+
+func resultOrderKey(id string) string {
+	return "job:" + id + ":results:order"
+}
+
+func ownerKey(id string) string {
+	return "job:" + id + ":owner"
+}
+
+func formatTime(t time.Time) string {
+	return t.Format(time.RFC3339Nano)
+}
+
+func parseTime(value string) time.Time {
+	t, _ := time.Parse(time.RFC3339Nano, value)
+	return t
+}
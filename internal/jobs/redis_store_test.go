@@ -0,0 +1,196 @@
+package jobs
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"Perion_Assignment/internal/models"
+	"Perion_Assignment/internal/redisclient"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupMiniRedis creates a mini redis server for testing
+func setupMiniRedis(t *testing.T) (*miniredis.Miniredis, *RedisStore) {
+	mr := miniredis.RunT(t)
+
+	client := redis.NewClient(&redis.Options{
+		Addr: mr.Addr(),
+	})
+
+	return mr, &RedisStore{client: client, retention: time.Hour}
+}
+
+func TestRedisStore_NewRedisStore_InvalidURL(t *testing.T) {
+	store, err := NewRedisStore(redisclient.FromURL("invalid://url::"), time.Hour)
+
+	assert.Error(t, err)
+	assert.Nil(t, store)
+	assert.Contains(t, err.Error(), "failed to parse redis URL")
+}
+
+func TestRedisStore_CreateGetAndResults(t *testing.T) {
+	mr, store := setupMiniRedis(t)
+	defer mr.Close()
+
+	ctx := context.Background()
+	now := time.Now().UTC()
+	job := &models.Job{ID: "job-1", Status: models.JobStatusPending, Total: 3, CreatedAt: now, UpdatedAt: now}
+
+	require.NoError(t, store.Create(ctx, job, []string{"a.com", "b.com", "c.com"}))
+
+	domains, err := store.Domains(ctx, "job-1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a.com", "b.com", "c.com"}, domains)
+
+	require.NoError(t, store.AddResult(ctx, "job-1", models.DomainResult{Domain: "a.com", Success: true, TotalAdvertisers: 5}))
+	require.NoError(t, store.AddResult(ctx, "job-1", models.DomainResult{Domain: "b.com", Success: false, Error: "not found"}))
+
+	loaded, err := store.Get(ctx, "job-1")
+	require.NoError(t, err)
+	assert.Equal(t, models.JobStatusPending, loaded.Status)
+	assert.Equal(t, 1, loaded.Succeeded)
+	assert.Equal(t, 1, loaded.Failed)
+	assert.Equal(t, 1, loaded.Pending)
+	assert.WithinDuration(t, now, loaded.CreatedAt, time.Second)
+
+	results, err := store.Results(ctx, "job-1", 1, 10)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "b.com", results[0].Domain)
+
+	all, err := store.Results(ctx, "job-1", 0, 0)
+	require.NoError(t, err)
+	assert.Len(t, all, 2)
+	assert.Equal(t, 5, all[0].TotalAdvertisers)
+}
+
+func TestRedisStore_AddResult_IgnoresDuplicates(t *testing.T) {
+	mr, store := setupMiniRedis(t)
+	defer mr.Close()
+
+	ctx := context.Background()
+	now := time.Now().UTC()
+	require.NoError(t, store.Create(ctx, &models.Job{ID: "job-1", Status: models.JobStatusRunning, Total: 2, CreatedAt: now, UpdatedAt: now}, []string{"a.com", "b.com"}))
+
+	// Two instances analyzed the same domain
+	require.NoError(t, store.AddResult(ctx, "job-1", models.DomainResult{Domain: "a.com", Success: true}))
+	require.NoError(t, store.AddResult(ctx, "job-1", models.DomainResult{Domain: "a.com", Success: false, Error: "timeout"}))
+
+	job, err := store.Get(ctx, "job-1")
+	require.NoError(t, err)
+	assert.Equal(t, 1, job.Succeeded)
+	assert.Equal(t, 0, job.Failed)
+	assert.Equal(t, 1, job.Pending)
+
+	results, err := store.Results(ctx, "job-1", 0, 0)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.True(t, results[0].Success)
+}
+
+func TestRedisStore_ClaimAndRelease(t *testing.T) {
+	mr, store := setupMiniRedis(t)
+	defer mr.Close()
+
+	ctx := context.Background()
+	now := time.Now().UTC()
+	require.NoError(t, store.Create(ctx, &models.Job{ID: "job-1", Status: models.JobStatusRunning, Total: 1, CreatedAt: now, UpdatedAt: now}, []string{"a.com"}))
+
+	claimed, err := store.Claim(ctx, "job-1", "instance-a", 10*time.Second)
+	require.NoError(t, err)
+	assert.True(t, claimed)
+
+	// Claimed jobs aren't resumed, and only their owner renews the lease
+	unclaimed, err := store.ListUnclaimed(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, unclaimed)
+
+	claimed, err = store.Claim(ctx, "job-1", "instance-b", 10*time.Second)
+	require.NoError(t, err)
+	assert.False(t, claimed)
+
+	claimed, err = store.Claim(ctx, "job-1", "instance-a", 10*time.Second)
+	require.NoError(t, err)
+	assert.True(t, claimed)
+
+	// An expired lease can be taken over
+	mr.FastForward(11 * time.Second)
+
+	unclaimed, err = store.ListUnclaimed(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"job-1"}, unclaimed)
+
+	claimed, err = store.Claim(ctx, "job-1", "instance-b", 10*time.Second)
+	require.NoError(t, err)
+	assert.True(t, claimed)
+
+	// Only the owner releases the lease
+	require.NoError(t, store.Release(ctx, "job-1", "instance-a"))
+	assert.True(t, mr.Exists(ownerKey("job-1")))
+
+	require.NoError(t, store.Release(ctx, "job-1", "instance-b"))
+	assert.False(t, mr.Exists(ownerKey("job-1")))
+}
+
+func TestRedisStore_CompareAndSetStatus_FinishedJobsLeaveActiveSetAndExpire(t *testing.T) {
+	mr, store := setupMiniRedis(t)
+	defer mr.Close()
+
+	ctx := context.Background()
+	now := time.Now().UTC()
+	require.NoError(t, store.Create(ctx, &models.Job{ID: "job-1", Status: models.JobStatusPending, Total: 1, CreatedAt: now, UpdatedAt: now}, []string{"a.com"}))
+
+	active, err := store.ListUnclaimed(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"job-1"}, active)
+
+	set, err := store.CompareAndSetStatus(ctx, "job-1", []models.JobStatus{models.JobStatusPending}, models.JobStatusCompleted)
+	require.NoError(t, err)
+	assert.True(t, set)
+
+	active, err = store.ListUnclaimed(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, active)
+
+	job, err := store.Get(ctx, "job-1")
+	require.NoError(t, err)
+	assert.Equal(t, models.JobStatusCompleted, job.Status)
+	assert.NotNil(t, job.CompletedAt)
+
+	mr.FastForward(2 * time.Hour)
+
+	_, err = store.Get(ctx, "job-1")
+	assert.ErrorIs(t, err, models.ErrJobNotFound)
+	assert.False(t, mr.Exists(domainsKey("job-1")))
+}
+
+func TestRedisStore_CompareAndSetStatus_OtherStatus(t *testing.T) {
+	mr, store := setupMiniRedis(t)
+	defer mr.Close()
+
+	ctx := context.Background()
+	now := time.Now().UTC()
+	require.NoError(t, store.Create(ctx, &models.Job{ID: "job-1", Status: models.JobStatusCancelled, Total: 1, CreatedAt: now, UpdatedAt: now}, []string{"a.com"}))
+
+	// A cancelled job can't be started
+	set, err := store.CompareAndSetStatus(ctx, "job-1", []models.JobStatus{models.JobStatusPending, models.JobStatusRunning}, models.JobStatusRunning)
+	require.NoError(t, err)
+	assert.False(t, set)
+
+	job, err := store.Get(ctx, "job-1")
+	require.NoError(t, err)
+	assert.Equal(t, models.JobStatusCancelled, job.Status)
+}
+
+func TestRedisStore_CompareAndSetStatus_NotFound(t *testing.T) {
+	mr, store := setupMiniRedis(t)
+	defer mr.Close()
+
+	_, err := store.CompareAndSetStatus(context.Background(), "missing", []models.JobStatus{models.JobStatusPending}, models.JobStatusCancelled)
+	assert.ErrorIs(t, err, models.ErrJobNotFound)
+}
@@ -0,0 +1,19 @@
+package lock
+
+import (
+	"context"
+	"time"
+)
+
+// Service defines the interface for cross-instance locks
+// External packages should use this interface, not the concrete implementations
+type Service interface {
+	// TryLock acquires the lock for key without waiting
+	// It returns models.ErrLockHeld when another holder owns the lock
+	TryLock(ctx context.Context, key string, ttl time.Duration) (Lock, error)
+}
+
+// Lock is a held lock that expires after its TTL unless released earlier
+type Lock interface {
+	Release(ctx context.Context) error
+}
@@ -0,0 +1,89 @@
+package lock
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"Perion_Assignment/internal/models"
+	"Perion_Assignment/internal/redisclient"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// keyPrefix namespaces lock keys in Redis
+const keyPrefix = "lock:"
+
+// releaseScript deletes the lock only if it is still owned by the caller's token,
+// so an expired lock re-acquired by another instance is never released by mistake
+var releaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// RedisLocker implements Service using SET NX with a per-holder token
+type RedisLocker struct {
+	client redis.UniversalClient
+}
+
+// NewRedisLocker creates a new Redis-based locker
+func NewRedisLocker(redisConfig redisclient.Config) (Service, error) {
+	return newRedisLocker(redisConfig)
+}
+
+// newRedisLocker creates the concrete implementation
+func newRedisLocker(redisConfig redisclient.Config) (*RedisLocker, error) {
+	client, err := redisclient.New(redisConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RedisLocker{
+		client: client,
+	}, nil
+}
+
+// TryLock acquires the lock for key if nobody holds it
+func (r *RedisLocker) TryLock(ctx context.Context, key string, ttl time.Duration) (Lock, error) {
+	if ttl <= 0 {
+		return nil, fmt.Errorf("TTL must be positive, got: %v", ttl)
+	}
+
+	token := uuid.New().String()
+	acquired, err := r.client.SetNX(ctx, keyPrefix+key, token, ttl).Result()
+	if err != nil {
+		return nil, fmt.Errorf("redis lock failed: %w", err)
+	}
+	if !acquired {
+		return nil, models.ErrLockHeld
+	}
+
+	return &redisLock{
+		client: r.client,
+		key:    keyPrefix + key,
+		token:  token,
+	}, nil
+}
+
+// Close closes the Redis connection
+func (r *RedisLocker) Close() error {
+	return r.client.Close()
+}
+
+// redisLock is a lock held in Redis
+type redisLock struct {
+	client redis.UniversalClient
+	key    string
+	token  string
+}
+
+// Release frees the lock if it is still held by this holder
+func (l *redisLock) Release(ctx context.Context) error {
+	if err := releaseScript.Run(ctx, l.client, []string{l.key}, l.token).Err(); err != nil {
+		return fmt.Errorf("redis unlock failed: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package lock
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"Perion_Assignment/internal/models"
+	"Perion_Assignment/internal/redisclient"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupMiniRedis creates a mini redis server for testing
+func setupMiniRedis(t *testing.T) (*miniredis.Miniredis, *RedisLocker) {
+	mr := miniredis.RunT(t)
+
+	client := redis.NewClient(&redis.Options{
+		Addr: mr.Addr(),
+	})
+
+	return mr, &RedisLocker{client: client}
+}
+
+func TestRedisLocker_NewRedisLocker_InvalidURL(t *testing.T) {
+	locker, err := NewRedisLocker(redisclient.FromURL("invalid://url::"))
+
+	assert.Error(t, err)
+	assert.Nil(t, locker)
+	assert.Contains(t, err.Error(), "failed to parse redis URL")
+}
+
+func TestRedisLocker_TryLock_Exclusive(t *testing.T) {
+	_, locker := setupMiniRedis(t)
+	ctx := context.Background()
+
+	held, err := locker.TryLock(ctx, "domain:cnn.com", time.Minute)
+	require.NoError(t, err)
+
+	_, err = locker.TryLock(ctx, "domain:cnn.com", time.Minute)
+	assert.ErrorIs(t, err, models.ErrLockHeld)
+
+	// Other keys are independent
+	other, err := locker.TryLock(ctx, "domain:msn.com", time.Minute)
+	require.NoError(t, err)
+	require.NoError(t, other.Release(ctx))
+
+	require.NoError(t, held.Release(ctx))
+
+	again, err := locker.TryLock(ctx, "domain:cnn.com", time.Minute)
+	require.NoError(t, err)
+	assert.NotNil(t, again)
+}
+
+func TestRedisLocker_TryLock_Expires(t *testing.T) {
+	mr, locker := setupMiniRedis(t)
+	ctx := context.Background()
+
+	_, err := locker.TryLock(ctx, "domain:cnn.com", 10*time.Second)
+	require.NoError(t, err)
+
+	mr.FastForward(11 * time.Second)
+
+	_, err = locker.TryLock(ctx, "domain:cnn.com", 10*time.Second)
+	assert.NoError(t, err)
+}
+
+func TestRedisLocker_Release_OnlyOwnLock(t *testing.T) {
+	mr, locker := setupMiniRedis(t)
+	ctx := context.Background()
+
+	stale, err := locker.TryLock(ctx, "domain:cnn.com", 10*time.Second)
+	require.NoError(t, err)
+
+	// The first lock expires and another instance takes over
+	mr.FastForward(11 * time.Second)
+	_, err = locker.TryLock(ctx, "domain:cnn.com", 10*time.Second)
+	require.NoError(t, err)
+
+	// Releasing the stale lock must not free the new holder's lock
+	require.NoError(t, stale.Release(ctx))
+	assert.True(t, mr.Exists("lock:domain:cnn.com"))
+}
+
+func TestRedisLocker_TryLock_InvalidTTL(t *testing.T) {
+	_, locker := setupMiniRedis(t)
+
+	_, err := locker.TryLock(context.Background(), "domain:cnn.com", 0)
+	assert.Error(t, err)
+}
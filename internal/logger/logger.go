@@ -81,14 +81,27 @@ func (l *DatabaseLogger) Close() error {
 
 // LogOperations defines constants for common operations
 const (
-	OpDomainAnalysis = "domain_analysis"
-	OpBatchAnalysis  = "batch_analysis"
-	OpCacheHit       = "cache_hit"
-	OpCacheMiss      = "cache_miss"
-	OpRateLimited    = "rate_limited"
-	OpFetchAdsTxt    = "fetch_ads_txt"
-	OpParseAdsTxt    = "parse_ads_txt"
-	OpServerStart    = "server_start"
-	OpServerShutdown = "server_shutdown"
-	OpHealthCheck    = "health_check"
+	OpDomainAnalysis  = "domain_analysis"
+	OpBatchAnalysis   = "batch_analysis"
+	OpCacheHit        = "cache_hit"
+	OpCacheMiss       = "cache_miss"
+	OpCacheStale      = "cache_stale"
+	OpRateLimited     = "rate_limited"
+	OpFetchAdsTxt     = "fetch_ads_txt"
+	OpParseAdsTxt     = "parse_ads_txt"
+	OpServerStart     = "server_start"
+	OpServerShutdown  = "server_shutdown"
+	OpHealthCheck     = "health_check"
+	OpWatchlist       = "watchlist"
+	OpWatchlistCheck  = "watchlist_check"
+	OpWatchlistNotify = "watchlist_notify"
+	OpBatchJob        = "batch_job"
+	OpDomainLock      = "domain_lock"
+	OpCacheAdmin      = "cache_admin"
+	OpAdminAuth       = "admin_auth"
+	OpCacheWarmup     = "cache_warmup"
+	OpAPIKeyAuth      = "api_key_auth"
+	OpAPIKeyQuota     = "api_key_quota"
+	OpIPFilter        = "ip_filter"
+	OpLoadShed        = "load_shed"
 )
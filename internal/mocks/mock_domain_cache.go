@@ -23,12 +23,27 @@ func (m *MockDomainCache) Get(ctx context.Context, domain string) (*models.Domai
 	return args.Get(0).(*models.DomainAnalysis), args.Error(1)
 }
 
+// Peek mocks the Peek method of domainCache.Peeker
+func (m *MockDomainCache) Peek(ctx context.Context, domain string) (*models.DomainAnalysis, error) {
+	args := m.Called(ctx, domain)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.DomainAnalysis), args.Error(1)
+}
+
 // Set mocks the Set method of domainCache.Service
 func (m *MockDomainCache) Set(ctx context.Context, domain string, analysis *models.DomainAnalysis, ttl time.Duration) error {
 	args := m.Called(ctx, domain, analysis, ttl)
 	return args.Error(0)
 }
 
+// SetFailure mocks the SetFailure method of domainCache.Service
+func (m *MockDomainCache) SetFailure(ctx context.Context, domain string, failure *models.LookupFailure, ttl time.Duration) error {
+	args := m.Called(ctx, domain, failure, ttl)
+	return args.Error(0)
+}
+
 // Delete mocks the Delete method of domainCache.Service
 func (m *MockDomainCache) Delete(ctx context.Context, domain string) error {
 	args := m.Called(ctx, domain)
@@ -3,6 +3,8 @@ package mocks
 import (
 	"context"
 
+	"Perion_Assignment/internal/fetcher"
+
 	"github.com/stretchr/testify/mock"
 )
 
@@ -15,4 +17,17 @@ type MockFetcher struct {
 func (m *MockFetcher) Fetch(ctx context.Context, domain string) (string, error) {
 	args := m.Called(ctx, domain)
 	return args.String(0), args.Error(1)
-}
\ No newline at end of file
+}
+// MockResponseFetcher is a mock fetcher that also implements fetcher.ResponseFetcher
+type MockResponseFetcher struct {
+	MockFetcher
+}
+
+// FetchResponse mocks the FetchResponse method of fetcher.ResponseFetcher
+func (m *MockResponseFetcher) FetchResponse(ctx context.Context, domain string) (*fetcher.Response, error) {
+	args := m.Called(ctx, domain)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*fetcher.Response), args.Error(1)
+}
@@ -13,6 +13,15 @@ type MockLogger struct {
 	mock.Mock
 }
 
+// NewAnyLogger creates a MockLogger that accepts any LogInfo, LogSuccess and LogError call
+func NewAnyLogger() *MockLogger {
+	mockLogger := &MockLogger{}
+	mockLogger.On("LogInfo", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return().Maybe()
+	mockLogger.On("LogSuccess", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return().Maybe()
+	mockLogger.On("LogError", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return().Maybe()
+	return mockLogger
+}
+
 // LogInfo mocks the LogInfo method of logger.Service
 func (m *MockLogger) LogInfo(ctx context.Context, operation, message string, metadata map[string]interface{}) {
 	m.Called(ctx, operation, message, metadata)
@@ -3,6 +3,7 @@ package models
 import (
 	"errors"
 	"fmt"
+	"time"
 )
 
 var (
@@ -18,11 +19,56 @@ var (
 	// ErrRateLimitExceeded indicates that rate limit has been exceeded
 	ErrRateLimitExceeded = errors.New("rate limit exceeded")
 	
+	// ErrRateLimitQueueFull indicates that too many requests are already waiting for rate limit tokens
+	ErrRateLimitQueueFull = errors.New("rate limit queue full")
+	
 	// ErrCacheUnavailable indicates that cache service is unavailable
 	ErrCacheUnavailable = errors.New("cache service unavailable")
 	
+	// ErrCacheEntryExpired indicates that a cached entry is past its hard TTL and may only be used as a fallback
+	ErrCacheEntryExpired = errors.New("cache entry expired")
+	
 	// ErrInvalidAdsTxtFormat indicates that ads.txt content is malformed
 	ErrInvalidAdsTxtFormat = errors.New("invalid ads.txt format")
+	
+	// ErrInvalidAnalysisOptions indicates that analysis options are malformed or exceed server limits
+	ErrInvalidAnalysisOptions = errors.New("invalid analysis options")
+	
+	// ErrInvalidCachePattern indicates that a cache purge pattern or prefix is empty or malformed
+	ErrInvalidCachePattern = errors.New("invalid cache pattern")
+	
+	// ErrUnauthorized indicates that a request lacks valid admin credentials
+	ErrUnauthorized = errors.New("unauthorized")
+	
+	// ErrIPDenied indicates that a request comes from a denylisted IP address
+	ErrIPDenied = errors.New("ip address denied")
+	
+	// ErrInvalidAPIKey indicates that an API key is unknown or disabled
+	ErrInvalidAPIKey = errors.New("invalid API key")
+	
+	// ErrQuotaExceeded indicates that an API key has used up its daily domain quota
+	ErrQuotaExceeded = errors.New("daily domain quota exceeded")
+	
+	// ErrLockHeld indicates that a cross-instance lock is owned by another holder
+	ErrLockHeld = errors.New("lock held by another instance")
+	
+	// ErrWatchlistEntryExists indicates that the domain is already in the watchlist
+	ErrWatchlistEntryExists = errors.New("domain already in watchlist")
+	
+	// ErrWatchlistEntryNotFound indicates that the domain is not in the watchlist
+	ErrWatchlistEntryNotFound = errors.New("domain not in watchlist")
+	
+	// ErrJobNotFound indicates that the requested batch job does not exist
+	ErrJobNotFound = errors.New("job not found")
+	
+	// ErrJobFinished indicates that the batch job has already completed or been cancelled
+	ErrJobFinished = errors.New("job already finished")
+	
+	// ErrJobQueueFull indicates that no more batch jobs can be accepted right now
+	ErrJobQueueFull = errors.New("job queue is full")
+	
+	// ErrOverloaded indicates that too many fetches are waiting, so new ones are shed
+	ErrOverloaded = errors.New("server overloaded")
 )
 
 // DomainError represents an error specific to a domain operation
@@ -50,4 +96,37 @@ func NewDomainError(domain, message string, err error) *DomainError {
 		Message: message,
 		Err:     err,
 	}
-}
\ No newline at end of file
+}
+// Failure classes of lookups kept in the negative cache
+const (
+	FailureClassNotFound = "not_found"
+	FailureClassDNS      = "dns"
+	FailureClassParse    = "parse"
+	FailureClassTimeout  = "timeout"
+)
+
+// LookupFailure represents a failed domain analysis served from the negative cache
+// It carries the original error message and still matches the original sentinel errors
+type LookupFailure struct {
+	Class    string    `json:"class"`
+	Message  string    `json:"message"`
+	FailedAt time.Time `json:"failed_at"`
+}
+
+func (e *LookupFailure) Error() string {
+	return e.Message
+}
+
+// Is matches the sentinel error of the failure class
+func (e *LookupFailure) Is(target error) bool {
+	switch e.Class {
+	case FailureClassNotFound:
+		return target == ErrDomainNotFound
+	case FailureClassParse:
+		return target == ErrInvalidAdsTxtFormat
+	case FailureClassTimeout:
+		return target == ErrFetchTimeout
+	default:
+		return false
+	}
+}
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"errors"
 	"time"
 )
 
@@ -16,12 +17,34 @@ type DomainAnalysis struct {
 	TotalAdvertisers int               `json:"total_advertisers"`
 	Advertisers      []AdvertiserInfo  `json:"advertisers"`
 	Cached           bool              `json:"cached"`
+	Stale            bool              `json:"stale,omitempty"`       // Served past its soft TTL
+	AgeSeconds       int64             `json:"age_seconds,omitempty"` // Age of stale data in seconds
+	CacheTTLSeconds  int64             `json:"cache_ttl_seconds,omitempty"` // TTL the analysis was cached with (0 = server default)
+	CacheTTLSource   string            `json:"cache_ttl_source,omitempty"`  // Where the TTL came from (TTLSource*)
 	Timestamp        time.Time         `json:"timestamp"`
 }
 
+// Sources of the TTL an analysis is cached with
+const (
+	TTLSourceRequest      = "request"       // The request's cache_ttl option
+	TTLSourceCacheControl = "cache-control" // The ads.txt response's Cache-Control header
+	TTLSourceExpires      = "expires"       // The ads.txt response's Expires header
+	TTLSourceDefault      = "default"       // The server's CACHE_TTL
+)
+
+// AnalysisOptions controls cache usage and timeouts for an analysis request
+// Zero values mean "use the server defaults"
+type AnalysisOptions struct {
+	ForceRefresh bool `json:"force_refresh,omitempty"` // Skip the cache lookup and fetch fresh data
+	MaxAge       int  `json:"max_age,omitempty"`       // Accept cached analyses only if newer than this many seconds
+	TimeoutMs    int  `json:"timeout_ms,omitempty"`    // Per-domain analysis timeout in milliseconds
+	CacheTTL     int  `json:"cache_ttl,omitempty"`     // TTL in seconds for the stored analysis
+}
+
 // BatchAnalysisRequest represents a request for analyzing multiple domains
 type BatchAnalysisRequest struct {
 	Domains []string `json:"domains"`
+	AnalysisOptions
 }
 
 // DomainResult represents a single domain result in batch analysis
@@ -30,11 +53,39 @@ type DomainResult struct {
 	TotalAdvertisers int                `json:"total_advertisers,omitempty"`
 	Advertisers      []AdvertiserInfo   `json:"advertisers,omitempty"`
 	Cached           bool               `json:"cached"`
+	Stale            bool               `json:"stale,omitempty"`
+	AgeSeconds       int64              `json:"age_seconds,omitempty"`
 	Error            string             `json:"error,omitempty"`
 	Success          bool               `json:"success"`
 	Timestamp        time.Time          `json:"timestamp,omitempty"`
 }
 
+// NewDomainResult builds a batch result entry from an analysis outcome
+func NewDomainResult(domain string, analysis *DomainAnalysis, err error) DomainResult {
+	if err != nil {
+		// Failures are only marked cached when served from the negative cache
+		var failure *LookupFailure
+		return DomainResult{
+			Domain:    domain,
+			Error:     err.Error(),
+			Success:   false,
+			Cached:    errors.As(err, &failure),
+			Timestamp: time.Now().UTC(),
+		}
+	}
+
+	return DomainResult{
+		Domain:           domain,
+		TotalAdvertisers: analysis.TotalAdvertisers,
+		Advertisers:      analysis.Advertisers,
+		Cached:           analysis.Cached, // This will be true or false based on cache hit/miss
+		Stale:            analysis.Stale,
+		AgeSeconds:       analysis.AgeSeconds,
+		Success:          true,
+		Timestamp:        analysis.Timestamp,
+	}
+}
+
 // BatchSummary provides summary statistics for batch operations
 type BatchSummary struct {
 	Total     int `json:"total"`
@@ -51,12 +102,190 @@ type BatchAnalysisResponse struct {
 	Timestamp        time.Time        `json:"timestamp"`
 }
 
+// BatchStreamSummary is the final event of a streamed batch analysis
+type BatchStreamSummary struct {
+	Summary          BatchSummary     `json:"summary"`
+	Advertisers      []AdvertiserInfo `json:"advertisers"` // Summarized across all domains
+	TotalAdvertisers int              `json:"total_advertisers"`
+	Timestamp        time.Time        `json:"timestamp"`
+}
+
+// JobStatus represents the lifecycle state of an asynchronous batch job
+type JobStatus string
+
+const (
+	JobStatusPending   JobStatus = "pending"
+	JobStatusRunning   JobStatus = "running"
+	JobStatusCompleted JobStatus = "completed"
+	JobStatusCancelled JobStatus = "cancelled"
+)
+
+// IsFinished reports whether the job will not make further progress
+func (s JobStatus) IsFinished() bool {
+	return s == JobStatusCompleted || s == JobStatusCancelled
+}
+
+// JobRequest represents a request to submit an asynchronous batch job
+type JobRequest struct {
+	Domains []string `json:"domains"`
+}
+
+// Job represents the state and progress of an asynchronous batch job
+type Job struct {
+	ID          string     `json:"id"`
+	Status      JobStatus  `json:"status"`
+	Total       int        `json:"total"`
+	Succeeded   int        `json:"succeeded"`
+	Failed      int        `json:"failed"`
+	Pending     int        `json:"pending"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
+
+// JobResultsResponse represents a page of results for an asynchronous batch job
+type JobResultsResponse struct {
+	JobID   string         `json:"job_id"`
+	Status  JobStatus      `json:"status"`
+	Results []DomainResult `json:"results"`
+	Offset  int            `json:"offset"`
+	Limit   int            `json:"limit"`
+	Total   int            `json:"total"` // Number of results available so far
+}
+
 // AdsTxtEntry represents a single line in an ads.txt file
 type AdsTxtEntry struct {
-	ExchangeDomain    string
-	PublisherID       string
-	AccountType       string
-	CertificationAuth string
+	ExchangeDomain    string `json:"exchange_domain"`
+	PublisherID       string `json:"publisher_id"`
+	AccountType       string `json:"account_type"`
+	CertificationAuth string `json:"certification_authority_id,omitempty"`
+}
+
+// WatchlistRequest represents a request to register a domain in the watchlist
+type WatchlistRequest struct {
+	Domain string `json:"domain"`
+}
+
+// WatchlistEntry represents a domain monitored for ads.txt changes
+type WatchlistEntry struct {
+	Domain        string     `json:"domain"`
+	AddedAt       time.Time  `json:"added_at"`
+	LastCheckedAt *time.Time `json:"last_checked_at,omitempty"`
+	LastChangedAt *time.Time `json:"last_changed_at,omitempty"`
+	RecordsCount  int        `json:"records_count"`
+	LastError     string     `json:"last_error,omitempty"`
+}
+
+// WatchlistResponse represents the list of watched domains
+type WatchlistResponse struct {
+	Entries []WatchlistEntry `json:"entries"`
+	Total   int              `json:"total"`
+}
+
+// ChangeNotification describes the ads.txt records that changed between two snapshots
+type ChangeNotification struct {
+	Event             string        `json:"event"`
+	Domain            string        `json:"domain"`
+	Added             []AdsTxtEntry `json:"added"`
+	Removed           []AdsTxtEntry `json:"removed"`
+	PreviousCheckedAt time.Time     `json:"previous_checked_at"`
+	CheckedAt         time.Time     `json:"checked_at"`
+}
+
+// Cache entry states reported by the cache admin API
+const (
+	CacheEntryFresh   = "fresh"   // Within its TTL
+	CacheEntryStale   = "stale"   // Past its TTL, still served while it is refreshed
+	CacheEntryExpired = "expired" // Only kept as a fallback for failed refreshes
+	CacheEntryFailure = "failure" // A cached failed lookup
+)
+
+// CacheEntryInfo describes the cache entry of a single domain
+type CacheEntryInfo struct {
+	Domain              string    `json:"domain"`
+	Status              string    `json:"status"`
+	CachedAt            time.Time `json:"cached_at"`
+	AgeSeconds          int64     `json:"age_seconds"`
+	FreshForSeconds     int64     `json:"fresh_for_seconds,omitempty"` // Time until the entry turns stale
+	TTLRemainingSeconds int64     `json:"ttl_remaining_seconds"`       // Time until the entry is removed
+	SizeBytes           int64     `json:"size_bytes"`
+	TotalAdvertisers    int       `json:"total_advertisers,omitempty"`
+	FailureClass        string    `json:"failure_class,omitempty"`
+}
+
+// CacheDeleteRequest represents a request to evict several domains from the cache
+type CacheDeleteRequest struct {
+	Domains []string `json:"domains"`
+}
+
+// CachePurgeRequest represents a request to evict all domains matching a glob pattern or a prefix
+type CachePurgeRequest struct {
+	Pattern string `json:"pattern,omitempty"`
+	Prefix  string `json:"prefix,omitempty"`
+}
+
+// CacheDeleteResponse reports how many cache entries were removed
+type CacheDeleteResponse struct {
+	Deleted int `json:"deleted"`
+}
+
+// WarmupProgress reports how far the startup cache warm-up has got
+type WarmupProgress struct {
+	Total      int        `json:"total"`
+	Completed  int        `json:"completed"`
+	Succeeded  int        `json:"succeeded"`
+	Failed     int        `json:"failed"`
+	Done       bool       `json:"done"`
+	StartedAt  *time.Time `json:"started_at,omitempty"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+	Error      string     `json:"error,omitempty"` // Set when the domain list could not be read
+}
+
+// APITier defines the limits shared by all API keys of a tier
+// A zero quota or batch size means no tier-specific limit.
+type APITier struct {
+	Name             string `json:"name"`
+	RequestsPerSec   int    `json:"requests_per_sec"`
+	DailyDomainQuota int    `json:"daily_domain_quota"`
+	MaxBatchSize     int    `json:"max_batch_size"`
+}
+
+// APIKey represents an authenticated API client
+type APIKey struct {
+	ID   string  `json:"id"`
+	Name string  `json:"name"`
+	Tier APITier `json:"tier"`
+}
+
+// APIKeyUsage counts the usage of an API key during one UTC day
+type APIKeyUsage struct {
+	KeyID    string `json:"key_id"`
+	Day      string `json:"day"` // YYYY-MM-DD
+	Requests int64  `json:"requests"`
+	Domains  int64  `json:"domains"`
+}
+
+// APIKeyUsageResponse reports an API key's tier and its usage today
+type APIKeyUsageResponse struct {
+	KeyID            string      `json:"key_id"`
+	Name             string      `json:"name"`
+	Tier             APITier     `json:"tier"`
+	Usage            APIKeyUsage `json:"usage"`
+	DomainsRemaining *int64      `json:"domains_remaining,omitempty"` // Unset without a daily quota
+}
+
+// IPLists holds allowlisted and denylisted CIDR ranges and IP addresses
+type IPLists struct {
+	Allow []string `json:"allow"`
+	Deny  []string `json:"deny"`
+}
+
+// IPListsResponse represents the IP lists in effect
+type IPListsResponse struct {
+	Allow    []string  `json:"allow"`
+	Deny     []string  `json:"deny"`
+	File     string    `json:"file,omitempty"` // Reloadable list file
+	LoadedAt time.Time `json:"loaded_at"`
 }
 
 // LogSeverity represents the severity level of a log entry
@@ -82,6 +311,7 @@ type LogEvent struct {
 	ProcessType ProcessType `json:"process_type"`
 	StartTime   time.Time   `json:"start_time"`
 	ClientIP    string      `json:"client_ip,omitempty"`
+	ProxyChain  []string    `json:"proxy_chain,omitempty"` // Forwarded addresses then the connecting peer, as received
 }
 
 // LogEntry represents a structured log entry for database storage
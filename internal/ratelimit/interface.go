@@ -7,4 +7,37 @@ import "context"
 type Service interface {
 	Allow(clientIP string) bool
 	Wait(ctx context.Context, clientIP string) error
-}
\ No newline at end of file
+}
+
+// Reporter is implemented by limiters that report the state of their buckets,
+// so responses can carry RateLimit-* and Retry-After headers
+type Reporter interface {
+	Check(clientIP string) Result
+}
+
+// ClientLimiter is implemented by limiters that can limit a client with its own limits
+// instead of the per-IP ones, e.g. an API key with the rate of its tier
+type ClientLimiter interface {
+	CheckClient(client Client) Result
+}
+
+// CostLimiter is implemented by limiters that can charge a request several tokens,
+// e.g. one per domain of a batch request. A cost above a bucket's capacity is admitted
+// once the bucket is full and takes the bucket into debt, so the whole cost is paid.
+type CostLimiter interface {
+	CheckCost(clientIP string, cost int64) Result
+	CheckClientCost(client Client, cost int64) Result
+}
+
+// GlobalLimiter is implemented by limiters that can check the global limit alone,
+// for clients exempt from per-client limits, e.g. allowlisted networks
+type GlobalLimiter interface {
+	CheckGlobal(cost int64) Result
+}
+
+// Client identifies a rate-limited client and the size and refill rate of its bucket
+type Client struct {
+	ID       string // Bucket identity, e.g. "key:<api key id>"
+	Capacity int64
+	Rate     int64
+}
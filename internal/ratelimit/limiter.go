@@ -40,6 +40,31 @@ func (tb *TokenBucket) Allow() bool {
 	return false
 }
 
+// check refills the bucket and reports whether it holds cost tokens, taking them when take is set
+// A cost above the capacity is admitted once the bucket is full and takes the bucket into
+// debt, instead of never being allowed.
+func (tb *TokenBucket) check(cost int64, take bool) bucketState {
+	tb.mutex.Lock()
+	defer tb.mutex.Unlock()
+	
+	tb.refill()
+	
+	need := admissionCost(cost, tb.capacity)
+	state := bucketState{allowed: tb.tokens >= need, limit: tb.capacity}
+	if take && state.allowed {
+		tb.tokens -= chargedCost(cost)
+	}
+	state.remaining = max(tb.tokens, 0)
+	
+	// Time since the last refill counts towards the next token
+	elapsed := time.Since(tb.lastRefill)
+	state.reset = nonNegative(refillTime(float64(tb.capacity-tb.tokens), tb.refillRate) - elapsed)
+	if !state.allowed {
+		state.retryAfter = nonNegative(refillTime(float64(need-tb.tokens), tb.refillRate) - elapsed)
+	}
+	return state
+}
+
 // refill adds tokens based on time elapsed since last refill
 func (tb *TokenBucket) refill() {
 	now := time.Now()
@@ -76,38 +101,60 @@ func NewTwoTierRateLimiter(globalCapacity, globalRate, perIPCapacity, perIPRate
 
 // Allow checks both global and per-IP rate limits
 func (trl *TwoTierRateLimiter) Allow(clientIP string) bool {
+	return trl.Check(clientIP).Allowed
+}
+
+// Check checks both global and per-IP rate limits and reports the state of the buckets
+func (trl *TwoTierRateLimiter) Check(clientIP string) Result {
+	return trl.CheckCost(clientIP, 1)
+}
+
+// CheckCost is Check for a request costing several tokens
+func (trl *TwoTierRateLimiter) CheckCost(clientIP string, cost int64) Result {
+	return trl.check(trl.getOrCreateIPBucket(clientIP), cost)
+}
+
+// CheckClient checks the global limit and the client's own limit
+func (trl *TwoTierRateLimiter) CheckClient(client Client) Result {
+	return trl.CheckClientCost(client, 1)
+}
+
+// CheckClientCost is CheckClient for a request costing several tokens
+func (trl *TwoTierRateLimiter) CheckClientCost(client Client, cost int64) Result {
+	return trl.check(trl.getOrCreateClientBucket(client), cost)
+}
+
+// CheckGlobal checks the global limit only
+func (trl *TwoTierRateLimiter) CheckGlobal(cost int64) Result {
+	return newResult(trl.globalBucket.check(cost, true), unlimited)
+}
+
+// check takes cost tokens from the global bucket and the client's bucket, or from neither
+func (trl *TwoTierRateLimiter) check(clientBucket *TokenBucket, cost int64) Result {
 	// Check global limit first
-	if !trl.globalBucket.Allow() {
-		return false
+	global := trl.globalBucket.check(cost, true)
+	if !global.allowed {
+		return newResult(global, clientBucket.check(cost, false))
 	}
 	
-	// Check per-IP limit
-	ipBucket := trl.getOrCreateIPBucket(clientIP)
-	if !ipBucket.Allow() {
-		// If per-IP limit exceeded, we should return the global token
-		// since we consumed it but can't proceed
-		trl.returnGlobalToken()
-		return false
+	// Check per-client limit
+	client := clientBucket.check(cost, true)
+	if !client.allowed {
+		// If per-client limit exceeded, we should return the global tokens
+		// since we consumed them but can't proceed
+		global.remaining = max(trl.returnGlobalTokens(chargedCost(cost)), 0)
 	}
 	
-	return true
+	return newResult(global, client)
 }
 
 // Wait blocks until a token becomes available for the given IP
+// It sleeps until the buckets report the token available, and fails right away when
+// that is past ctx's deadline.
 func (trl *TwoTierRateLimiter) Wait(ctx context.Context, clientIP string) error {
-	ticker := time.NewTicker(100 * time.Millisecond)
-	defer ticker.Stop()
-	
-	for {
-		select {
-		case <-ctx.Done():
-			return ctx.Err()
-		case <-ticker.C:
-			if trl.Allow(clientIP) {
-				return nil
-			}
-		}
-	}
+	check := func() Result { return trl.Check(clientIP) }
+	_, err := waitFor(ctx, check(), check)
+	return err
 }
 
 // getOrCreateIPBucket gets or creates a token bucket for the given IP
@@ -123,14 +170,32 @@ func (trl *TwoTierRateLimiter) getOrCreateIPBucket(clientIP string) *TokenBucket
 	return actual.(*TokenBucket)
 }
 
-// returnGlobalToken returns a token to the global bucket (compensation for per-IP limit)
-func (trl *TwoTierRateLimiter) returnGlobalToken() {
+// getOrCreateClientBucket gets or creates the token bucket of a client
+// Client buckets share the map with IP buckets; their IDs never look like an IP.
+// A bucket is replaced when the client's limits change, e.g. after a tier upgrade.
+func (trl *TwoTierRateLimiter) getOrCreateClientBucket(client Client) *TokenBucket {
+	if bucket, ok := trl.ipBuckets.Load(client.ID); ok {
+		existing := bucket.(*TokenBucket)
+		if existing.capacity == client.Capacity && existing.refillRate == client.Rate {
+			return existing
+		}
+		trl.ipBuckets.Delete(client.ID)
+	}
+	
+	newBucket := NewTokenBucket(client.Capacity, client.Rate)
+	actual, _ := trl.ipBuckets.LoadOrStore(client.ID, newBucket)
+	
+	return actual.(*TokenBucket)
+}
+
+// returnGlobalTokens returns tokens to the global bucket (compensation for per-IP limit)
+// and reports the tokens it then holds
+func (trl *TwoTierRateLimiter) returnGlobalTokens(tokens int64) int64 {
 	trl.globalBucket.mutex.Lock()
 	defer trl.globalBucket.mutex.Unlock()
 	
-	if trl.globalBucket.tokens < trl.globalBucket.capacity {
-		trl.globalBucket.tokens++
-	}
+	trl.globalBucket.tokens = min(trl.globalBucket.capacity, trl.globalBucket.tokens+tokens)
+	return trl.globalBucket.tokens
 }
 
 // cleanupIPBuckets removes old IP buckets to prevent memory leaks
@@ -108,6 +108,106 @@ func TestTwoTierRateLimiter_GlobalLimit(t *testing.T) {
 	}
 }
 
+func TestTwoTierRateLimiter_CheckClient(t *testing.T) {
+	// Global: 100 req/sec, Per-IP: 1 req/sec
+	limiter := NewTwoTierRateLimiter(100, 100, 1, 1)
+	client := Client{ID: "key:acme", Capacity: 3, Rate: 3}
+	
+	// The client's own limit applies instead of the per-IP limit
+	for i := 0; i < 3; i++ {
+		if !limiter.CheckClient(client).Allowed {
+			t.Errorf("Request %d for client should be allowed", i+1)
+		}
+	}
+	
+	result := limiter.CheckClient(client)
+	if result.Allowed {
+		t.Error("4th request for client should be denied")
+	}
+	if result.Tier != TierClient || result.Limit != 3 {
+		t.Errorf("Expected client tier with limit 3, got %s with limit %d", result.Tier, result.Limit)
+	}
+	
+	// A new tier replaces the client's bucket
+	if !limiter.CheckClient(Client{ID: "key:acme", Capacity: 10, Rate: 10}).Allowed {
+		t.Error("Request after a limit change should be allowed")
+	}
+}
+
+func TestTwoTierRateLimiter_CheckCost(t *testing.T) {
+	// Global: 100 req/sec, Per-IP: 10 req/sec
+	limiter := NewTwoTierRateLimiter(100, 100, 10, 10)
+	
+	result := limiter.CheckCost("192.168.1.1", 6)
+	if !result.Allowed || result.Remaining != 4 {
+		t.Errorf("Request costing 6 should be allowed with 4 tokens left, got allowed=%v remaining=%d", result.Allowed, result.Remaining)
+	}
+	
+	// Not enough tokens left for another 6
+	result = limiter.CheckCost("192.168.1.1", 6)
+	if result.Allowed {
+		t.Error("Second request costing 6 should be denied")
+	}
+	if result.RetryAfter <= 100*time.Millisecond {
+		t.Errorf("Retry-After should cover the 2 missing tokens, got %v", result.RetryAfter)
+	}
+	
+	// The denied request gave its global tokens back
+	if tokens := limiter.globalBucket.tokens; tokens < 94 {
+		t.Errorf("Global tokens of the denied request should be returned, %d left", tokens)
+	}
+	
+	// Single tokens are still available
+	if !limiter.Allow("192.168.1.1") {
+		t.Error("Request costing 1 should be allowed")
+	}
+}
+
+func TestTwoTierRateLimiter_CheckCost_AboveCapacity(t *testing.T) {
+	// Global: 100 req/sec, Per-IP: 10 req/sec
+	limiter := NewTwoTierRateLimiter(100, 100, 10, 10)
+	
+	// A cost above the bucket's capacity is admitted with a full bucket and paid in full
+	result := limiter.CheckCost("192.168.1.1", 50)
+	if !result.Allowed {
+		t.Error("Request costing more than the capacity should be allowed with a full bucket")
+	}
+	if result.Tier != TierClient || result.Remaining != 0 {
+		t.Errorf("Expected the empty client bucket to be reported, got tier=%s remaining=%d", result.Tier, result.Remaining)
+	}
+	
+	// The bucket is 40 tokens in debt, so the next token is 4.1 seconds away
+	result = limiter.Check("192.168.1.1")
+	if result.Allowed {
+		t.Error("Bucket should be in debt after a request costing more than the capacity")
+	}
+	if result.RetryAfter < 4*time.Second || result.RetryAfter > 5*time.Second {
+		t.Errorf("Expected a retry after about 4.1s, got %v", result.RetryAfter)
+	}
+	
+	// The global bucket paid the whole cost too
+	if remaining := limiter.CheckGlobal(1).Remaining; remaining != 49 {
+		t.Errorf("Expected 49 global tokens left, got %d", remaining)
+	}
+}
+
+func TestTwoTierRateLimiter_CheckGlobal(t *testing.T) {
+	// Global: 5 req/sec, Per-IP: 1 req/sec
+	limiter := NewTwoTierRateLimiter(5, 5, 1, 1)
+	
+	// Only the global limit applies
+	for i := 0; i < 5; i++ {
+		result := limiter.CheckGlobal(1)
+		if !result.Allowed || result.Tier != TierGlobal {
+			t.Errorf("Request %d should be allowed by the global tier, got allowed=%v tier=%s", i+1, result.Allowed, result.Tier)
+		}
+	}
+	
+	if limiter.CheckGlobal(1).Allowed {
+		t.Error("6th request should be denied by the global limit")
+	}
+}
+
 func TestTwoTierRateLimiter_Wait(t *testing.T) {
 	limiter := NewTwoTierRateLimiter(1, 10, 1, 10) // Very fast refill for testing
 	
@@ -270,7 +370,6 @@ func TestTwoTierRateLimiter_Wait_ActualBlocking(t *testing.T) {
 	}
 
 	// Should have blocked for approximately 1 second (with some tolerance)
-	// Note: Wait() polls periodically, so it may take slightly longer
 	if duration < 800*time.Millisecond {
 		t.Errorf("Wait should have blocked for ~1 second, but only blocked for %v", duration)
 	}
@@ -283,6 +382,25 @@ func TestTwoTierRateLimiter_Wait_ActualBlocking(t *testing.T) {
 	// so we've verified the blocking behavior
 }
 
+// TestTwoTierRateLimiter_Wait_FailsFastPastDeadline tests that Wait doesn't sleep for a token it can't get in time
+func TestTwoTierRateLimiter_Wait_FailsFastPastDeadline(t *testing.T) {
+	limiter := NewTwoTierRateLimiter(1, 1, 1, 1)
+	limiter.Allow("192.168.1.1")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err := limiter.Wait(ctx, "192.168.1.1")
+
+	if err != context.DeadlineExceeded {
+		t.Errorf("Expected context.DeadlineExceeded, got %v", err)
+	}
+	if duration := time.Since(start); duration > 100*time.Millisecond {
+		t.Errorf("Wait should fail right away, but took %v", duration)
+	}
+}
+
 // TestTwoTierRateLimiter_Wait_MultipleGoroutines tests Wait with multiple goroutines
 func TestTwoTierRateLimiter_Wait_MultipleGoroutines(t *testing.T) {
 	limiter := NewTwoTierRateLimiter(5, 5, 2, 2)
@@ -392,4 +510,46 @@ func TestTwoTierRateLimiter_ReturnTokenOnPerIPDenial(t *testing.T) {
 	if !limiter.Allow("192.168.1.2") {
 		t.Error("Different IP should be allowed (global token was returned)")
 	}
-}
\ No newline at end of file
+}
+// TestTwoTierRateLimiter_Check_ReportsClientTier tests the bucket state reported for the per-IP tier
+func TestTwoTierRateLimiter_Check_ReportsClientTier(t *testing.T) {
+	// Global: 100 req/sec, Per-IP: 2 req/sec
+	limiter := NewTwoTierRateLimiter(100, 100, 2, 2)
+
+	result := limiter.Check("192.168.1.1")
+	if !result.Allowed || result.Tier != TierClient || result.Limit != 2 || result.Remaining != 1 {
+		t.Errorf("Unexpected result for first request: %+v", result)
+	}
+	if result.Reset <= 0 || result.Reset > 500*time.Millisecond {
+		t.Errorf("Reset should be at most the time to refill one token, got %v", result.Reset)
+	}
+
+	limiter.Check("192.168.1.1")
+	result = limiter.Check("192.168.1.1")
+	if result.Allowed || result.Tier != TierClient || result.Remaining != 0 {
+		t.Errorf("Third request should be denied by the per-IP tier: %+v", result)
+	}
+	if result.RetryAfter <= 0 || result.RetryAfter > 500*time.Millisecond {
+		t.Errorf("RetryAfter should be at most the time to refill one token, got %v", result.RetryAfter)
+	}
+}
+
+// TestTwoTierRateLimiter_Check_ReportsGlobalTier tests the bucket state reported for the global tier
+func TestTwoTierRateLimiter_Check_ReportsGlobalTier(t *testing.T) {
+	// Global: 2 req/sec, Per-IP: 10 req/sec
+	limiter := NewTwoTierRateLimiter(2, 1, 10, 10)
+
+	result := limiter.Check("192.168.1.1")
+	if !result.Allowed || result.Tier != TierGlobal || result.Remaining != 1 {
+		t.Errorf("The global tier has the fewest tokens left: %+v", result)
+	}
+
+	limiter.Check("192.168.1.2")
+	result = limiter.Check("192.168.1.3")
+	if result.Allowed || result.Tier != TierGlobal || result.Limit != 2 {
+		t.Errorf("Third request should be denied by the global tier: %+v", result)
+	}
+	if result.RetryAfter <= 0 || result.RetryAfter > time.Second {
+		t.Errorf("RetryAfter should be at most one second, got %v", result.RetryAfter)
+	}
+}
@@ -0,0 +1,165 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"Perion_Assignment/internal/models"
+)
+
+// minRetryDelay is how long a denied check waits when the limiter reports no retry time
+const minRetryDelay = time.Millisecond
+
+// waitFor waits until the tokens of a denied check are available and checks again,
+// until check allows the request. It gives up right away, with context.DeadlineExceeded,
+// when the tokens won't be available before ctx's deadline.
+func waitFor(ctx context.Context, result Result, check func() Result) (Result, error) {
+	for !result.Allowed {
+		delay := result.RetryAfter
+		if delay <= 0 {
+			delay = minRetryDelay
+		}
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+			return result, context.DeadlineExceeded
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return result, ctx.Err()
+		case <-timer.C:
+		}
+		result = check()
+	}
+	return result, nil
+}
+
+// Queue holds requests over the rate limit until their tokens are available, up to a
+// maximum delay, instead of rejecting them right away
+//
+// Requests of the same client are served in arrival order: a request is only checked
+// once the client's requests queued before it have been served or given up, so a
+// stream of new requests can't starve a waiting one. The number of waiting requests
+// is bounded; requests arriving at a full queue, and requests whose tokens won't be
+// available within the maximum delay, are rejected right away.
+type Queue struct {
+	maxWaiting int64
+	maxDelay   time.Duration
+	waiting    atomic.Int64
+
+	mutex sync.Mutex
+	lines map[string]*queueLine
+}
+
+// queueLine orders the requests of one client
+type queueLine struct {
+	turn  chan struct{} // Holds a value while one of the client's requests is being checked
+	users int           // Requests being checked or waiting for their turn
+}
+
+// NewQueue creates a queue holding at most maxWaiting requests for at most maxDelay each
+func NewQueue(maxWaiting int, maxDelay time.Duration) *Queue {
+	return &Queue{
+		maxWaiting: int64(maxWaiting),
+		maxDelay:   maxDelay,
+		lines:      make(map[string]*queueLine),
+	}
+}
+
+// Take checks a request of the given client, waiting for its tokens when check denies it
+// client identifies the bucket check takes tokens from. Take returns the last check's
+// result, how long the request waited, and why it was rejected:
+// models.ErrRateLimitQueueFull, models.ErrRateLimitExceeded when the tokens won't be
+// available within the maximum delay, or ctx's error. A request rejected before it was
+// checked gets a zero Result.
+func (q *Queue) Take(ctx context.Context, client string, check func() Result) (Result, time.Duration, error) {
+	start := time.Now()
+	line := q.join(client)
+	defer q.leave(client, line)
+
+	// Served right away when none of the client's requests are waiting and the tokens are there
+	var result Result
+	first := false
+	select {
+	case line.turn <- struct{}{}:
+		first = true
+		if result = check(); result.Allowed {
+			<-line.turn
+			return result, 0, nil
+		}
+	default:
+	}
+
+	if q.waiting.Add(1) > q.maxWaiting {
+		q.waiting.Add(-1)
+		if first {
+			<-line.turn
+		}
+		return result, 0, models.ErrRateLimitQueueFull
+	}
+	defer q.waiting.Add(-1)
+
+	waitCtx, cancel := context.WithTimeout(ctx, q.maxDelay)
+	defer cancel()
+
+	if !first {
+		select {
+		case line.turn <- struct{}{}:
+		case <-waitCtx.Done():
+			return result, time.Since(start), q.rejection(ctx, waitCtx.Err())
+		}
+		result = check()
+	}
+	defer func() { <-line.turn }()
+
+	result, err := waitFor(waitCtx, result, check)
+	if err != nil {
+		return result, time.Since(start), q.rejection(ctx, err)
+	}
+	return result, time.Since(start), nil
+}
+
+// rejection returns why a queued request gave up: its own context ending, or the maximum delay
+func (q *Queue) rejection(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return models.ErrRateLimitExceeded
+	}
+	return err
+}
+
+// join returns the line of a client, creating it for the client's first request
+func (q *Queue) join(client string) *queueLine {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	line, ok := q.lines[client]
+	if !ok {
+		line = &queueLine{turn: make(chan struct{}, 1)}
+		q.lines[client] = line
+	}
+	line.users++
+	return line
+}
+
+// leave removes a request from its client's line, dropping the line after the last one
+func (q *Queue) leave(client string, line *queueLine) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	line.users--
+	if line.users == 0 {
+		delete(q.lines, client)
+	}
+}
+
+// Waiting returns the number of requests waiting in the queue
+func (q *Queue) Waiting() int {
+	return int(q.waiting.Load())
+}
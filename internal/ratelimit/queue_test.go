@@ -0,0 +1,128 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"Perion_Assignment/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ipCheck returns a check of the limiter for the given IP
+func ipCheck(limiter *TwoTierRateLimiter, clientIP string) func() Result {
+	return func() Result { return limiter.Check(clientIP) }
+}
+
+func TestQueue_AllowedRightAway(t *testing.T) {
+	queue := NewQueue(10, time.Second)
+	limiter := NewTwoTierRateLimiter(10, 10, 2, 2)
+
+	result, delay, err := queue.Take(context.Background(), "ip:1.2.3.4", ipCheck(limiter, "1.2.3.4"))
+
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+	assert.Zero(t, delay)
+	assert.Zero(t, queue.Waiting())
+}
+
+func TestQueue_WaitsForToken(t *testing.T) {
+	queue := NewQueue(10, time.Second)
+	limiter := NewTwoTierRateLimiter(10, 10, 1, 10) // One token per 100ms
+	check := ipCheck(limiter, "1.2.3.4")
+
+	_, _, err := queue.Take(context.Background(), "ip:1.2.3.4", check)
+	require.NoError(t, err)
+
+	result, delay, err := queue.Take(context.Background(), "ip:1.2.3.4", check)
+
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+	assert.GreaterOrEqual(t, delay, 50*time.Millisecond)
+	assert.Less(t, delay, 300*time.Millisecond)
+}
+
+func TestQueue_RejectsBeyondMaxDelay(t *testing.T) {
+	queue := NewQueue(10, 100*time.Millisecond)
+	limiter := NewTwoTierRateLimiter(10, 10, 1, 1) // One token per second
+	check := ipCheck(limiter, "1.2.3.4")
+
+	_, _, err := queue.Take(context.Background(), "ip:1.2.3.4", check)
+	require.NoError(t, err)
+
+	start := time.Now()
+	result, _, err := queue.Take(context.Background(), "ip:1.2.3.4", check)
+
+	assert.ErrorIs(t, err, models.ErrRateLimitExceeded)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, TierClient, result.Tier)
+	// The token won't come in time, so the request isn't held
+	assert.Less(t, time.Since(start), 50*time.Millisecond)
+}
+
+func TestQueue_Full(t *testing.T) {
+	queue := NewQueue(1, 2*time.Second)
+	limiter := NewTwoTierRateLimiter(10, 10, 1, 2) // One token per 500ms
+	check := ipCheck(limiter, "1.2.3.4")
+
+	_, _, err := queue.Take(context.Background(), "ip:1.2.3.4", check)
+	require.NoError(t, err)
+
+	done := make(chan error, 1)
+	go func() {
+		_, _, err := queue.Take(context.Background(), "ip:1.2.3.4", check)
+		done <- err
+	}()
+	require.Eventually(t, func() bool { return queue.Waiting() == 1 }, time.Second, time.Millisecond)
+
+	_, _, err = queue.Take(context.Background(), "ip:5.6.7.8", ipCheck(limiter, "5.6.7.8"))
+	assert.NoError(t, err, "requests that don't have to wait are served")
+
+	_, _, err = queue.Take(context.Background(), "ip:1.2.3.4", check)
+	assert.ErrorIs(t, err, models.ErrRateLimitQueueFull)
+
+	assert.NoError(t, <-done)
+	assert.Zero(t, queue.Waiting())
+}
+
+func TestQueue_ServesClientInArrivalOrder(t *testing.T) {
+	queue := NewQueue(10, 2*time.Second)
+	limiter := NewTwoTierRateLimiter(10, 10, 1, 10) // One token per 100ms
+	check := ipCheck(limiter, "1.2.3.4")
+
+	_, _, err := queue.Take(context.Background(), "ip:1.2.3.4", check)
+	require.NoError(t, err)
+
+	order := make(chan int, 3)
+	for i := 1; i <= 3; i++ {
+		go func() {
+			_, _, err := queue.Take(context.Background(), "ip:1.2.3.4", check)
+			assert.NoError(t, err)
+			order <- i
+		}()
+		require.Eventually(t, func() bool { return queue.Waiting() == i }, time.Second, time.Millisecond)
+	}
+
+	assert.Equal(t, 1, <-order)
+	assert.Equal(t, 2, <-order)
+	assert.Equal(t, 3, <-order)
+}
+
+func TestQueue_RequestCanceled(t *testing.T) {
+	queue := NewQueue(10, 5*time.Second)
+	limiter := NewTwoTierRateLimiter(10, 10, 1, 1)
+	check := ipCheck(limiter, "1.2.3.4")
+
+	_, _, err := queue.Take(context.Background(), "ip:1.2.3.4", check)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	_, delay, err := queue.Take(ctx, "ip:1.2.3.4", check)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Less(t, delay, 500*time.Millisecond)
+	assert.Zero(t, queue.Waiting())
+}
@@ -0,0 +1,277 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"Perion_Assignment/internal/redisclient"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// redisKeyPrefix namespaces rate limit buckets in Redis
+// The {ratelimit} hash tag keeps the global bucket and every per-IP bucket in one
+// Redis Cluster slot, so a single script can update both atomically.
+const redisKeyPrefix = "ratelimit:{ratelimit}:"
+
+// defaultFailoverBackoff is how long Redis is skipped after a failure
+const defaultFailoverBackoff = time.Second
+
+// takeScript refills the global and per-IP token buckets and takes the request's cost
+// from each, or from neither when either is short. A cost above a bucket's capacity is
+// admitted once the bucket is full and takes it into debt. Buckets are hashes of the token count and the refill time
+// in milliseconds; Redis' clock is used so replicas with skewed clocks share the same
+// buckets consistently.
+//
+// KEYS: global bucket, per-IP bucket (omitted to check the global bucket only)
+// ARGV: global capacity, global rate, per-IP capacity, per-IP rate (tokens per second), cost
+// Returns: allowed, then for the global and per-IP bucket: whole tokens left, ms until full, ms until the cost is available
+var takeScript = redis.NewScript(`
+local time = redis.call("TIME")
+local now = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+
+local function refill(key, capacity, rate)
+	local state = redis.call("HMGET", key, "tokens", "ts")
+	local tokens = tonumber(state[1])
+	local ts = tonumber(state[2])
+	if tokens == nil or ts == nil then
+		return capacity
+	end
+	local elapsed = math.max(0, now - ts)
+	return math.min(capacity, tokens + elapsed * rate / 1000)
+end
+
+local function save(key, tokens, capacity, rate)
+	redis.call("HSET", key, "tokens", tostring(tokens), "ts", now)
+	-- Keep the bucket, and any debt, until it would be full again anyway
+	local ttl = 86400000
+	if rate > 0 then
+		ttl = math.ceil((capacity - tokens) * 1000 / rate) + 1000
+	end
+	redis.call("PEXPIRE", key, ttl)
+end
+
+local globalCapacity, globalRate = tonumber(ARGV[1]), tonumber(ARGV[2])
+local ipCapacity, ipRate = tonumber(ARGV[3]), tonumber(ARGV[4])
+local cost = tonumber(ARGV[5])
+local charged = math.max(1, cost)
+local globalCost = math.max(1, math.min(cost, globalCapacity))
+local ipCost = math.max(1, math.min(cost, ipCapacity))
+
+local ipLimited = #KEYS > 1
+
+local globalTokens = refill(KEYS[1], globalCapacity, globalRate)
+local ipTokens = ipCost
+if ipLimited then
+	ipTokens = refill(KEYS[2], ipCapacity, ipRate)
+end
+
+local allowed = 0
+if globalTokens >= globalCost and ipTokens >= ipCost then
+	globalTokens = globalTokens - charged
+	ipTokens = ipTokens - charged
+	allowed = 1
+end
+
+save(KEYS[1], globalTokens, globalCapacity, globalRate)
+if ipLimited then
+	save(KEYS[2], ipTokens, ipCapacity, ipRate)
+end
+
+local function state(tokens, capacity, rate, need)
+	local untilFull, untilCost = 0, 0
+	if rate > 0 then
+		untilFull = math.ceil((capacity - tokens) * 1000 / rate)
+		untilCost = math.max(0, math.ceil((need - tokens) * 1000 / rate))
+	end
+	return {math.max(0, math.floor(tokens)), untilFull, untilCost}
+end
+
+local globalState = state(globalTokens, globalCapacity, globalRate, globalCost)
+local ipState = state(ipTokens, ipCapacity, ipRate, ipCost)
+return {allowed, globalState[1], globalState[2], globalState[3], ipState[1], ipState[2], ipState[3]}
+`)
+
+// RedisRateLimiter implements Service with global and per-IP token buckets kept in Redis,
+// so the limits hold across all replicas
+//
+// While Redis is unreachable, requests are limited by an in-process TwoTierRateLimiter
+// with the same limits (which then apply per replica), and for failoverBackoff after a
+// failure Redis is not contacted at all.
+type RedisRateLimiter struct {
+	client          redis.UniversalClient
+	fallback        *TwoTierRateLimiter
+	globalCapacity  int64
+	globalRate      int64
+	perIPCapacity   int64
+	perIPRate       int64
+	failoverBackoff time.Duration
+
+	// Unix nanoseconds until which Redis is skipped after a failure
+	unavailableUntil atomic.Int64
+}
+
+// RedisOption configures optional RedisRateLimiter behaviour
+type RedisOption func(*RedisRateLimiter)
+
+// WithFailoverBackoff sets how long Redis is skipped after a failure
+// (0 retries Redis on every call)
+func WithFailoverBackoff(backoff time.Duration) RedisOption {
+	return func(r *RedisRateLimiter) {
+		r.failoverBackoff = backoff
+	}
+}
+
+// NewRedisRateLimiter creates a new Redis-backed two-tier rate limiter
+func NewRedisRateLimiter(
+	redisConfig redisclient.Config,
+	globalCapacity, globalRate, perIPCapacity, perIPRate int64,
+	opts ...RedisOption,
+) (Service, error) {
+	return newRedisRateLimiter(redisConfig, globalCapacity, globalRate, perIPCapacity, perIPRate, opts...)
+}
+
+// newRedisRateLimiter creates the concrete implementation
+func newRedisRateLimiter(
+	redisConfig redisclient.Config,
+	globalCapacity, globalRate, perIPCapacity, perIPRate int64,
+	opts ...RedisOption,
+) (*RedisRateLimiter, error) {
+	client, err := redisclient.New(redisConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	limiter := &RedisRateLimiter{
+		client:          client,
+		fallback:        NewTwoTierRateLimiter(globalCapacity, globalRate, perIPCapacity, perIPRate),
+		globalCapacity:  globalCapacity,
+		globalRate:      globalRate,
+		perIPCapacity:   perIPCapacity,
+		perIPRate:       perIPRate,
+		failoverBackoff: defaultFailoverBackoff,
+	}
+	for _, opt := range opts {
+		opt(limiter)
+	}
+	return limiter, nil
+}
+
+// Allow checks both global and per-IP rate limits
+func (r *RedisRateLimiter) Allow(clientIP string) bool {
+	return r.Check(clientIP).Allowed
+}
+
+// Check checks both global and per-IP rate limits and reports the state of the buckets
+func (r *RedisRateLimiter) Check(clientIP string) Result {
+	return r.CheckCost(clientIP, 1)
+}
+
+// CheckCost is Check for a request costing several tokens
+func (r *RedisRateLimiter) CheckCost(clientIP string, cost int64) Result {
+	if time.Now().UnixNano() < r.unavailableUntil.Load() {
+		return r.fallback.CheckCost(clientIP, cost)
+	}
+
+	result, err := r.take(context.Background(), "ip:"+clientIP, r.perIPCapacity, r.perIPRate, cost)
+	if err != nil {
+		r.failed()
+		return r.fallback.CheckCost(clientIP, cost)
+	}
+	return result
+}
+
+// CheckClient checks the global limit and the client's own limit
+func (r *RedisRateLimiter) CheckClient(client Client) Result {
+	return r.CheckClientCost(client, 1)
+}
+
+// CheckClientCost is CheckClient for a request costing several tokens
+func (r *RedisRateLimiter) CheckClientCost(client Client, cost int64) Result {
+	if time.Now().UnixNano() < r.unavailableUntil.Load() {
+		return r.fallback.CheckClientCost(client, cost)
+	}
+
+	result, err := r.take(context.Background(), client.ID, client.Capacity, client.Rate, cost)
+	if err != nil {
+		r.failed()
+		return r.fallback.CheckClientCost(client, cost)
+	}
+	return result
+}
+
+// CheckGlobal checks the global limit only
+func (r *RedisRateLimiter) CheckGlobal(cost int64) Result {
+	if time.Now().UnixNano() < r.unavailableUntil.Load() {
+		return r.fallback.CheckGlobal(cost)
+	}
+
+	result, err := r.take(context.Background(), "", 0, 0, cost)
+	if err != nil {
+		r.failed()
+		return r.fallback.CheckGlobal(cost)
+	}
+	return result
+}
+
+// failed skips Redis for the failover backoff
+func (r *RedisRateLimiter) failed() {
+	if r.failoverBackoff > 0 {
+		r.unavailableUntil.Store(time.Now().Add(r.failoverBackoff).UnixNano())
+	}
+}
+
+// Wait blocks until a token becomes available for the given IP
+// It sleeps until the buckets report the token available, and fails right away when
+// that is past ctx's deadline.
+func (r *RedisRateLimiter) Wait(ctx context.Context, clientIP string) error {
+	check := func() Result { return r.Check(clientIP) }
+	_, err := waitFor(ctx, check(), check)
+	return err
+}
+
+// take runs the token bucket script for the global bucket and the client's bucket
+// An empty clientID checks the global bucket only.
+func (r *RedisRateLimiter) take(ctx context.Context, clientID string, capacity, rate, cost int64) (Result, error) {
+	keys := []string{redisKeyPrefix + "global"}
+	if clientID != "" {
+		keys = append(keys, redisKeyPrefix+clientID)
+	}
+	reply, err := takeScript.Run(ctx, r.client, keys, r.globalCapacity, r.globalRate, capacity, rate, cost).Int64Slice()
+	if err != nil {
+		return Result{}, fmt.Errorf("redis rate limit failed: %w", err)
+	}
+	if len(reply) != 7 {
+		return Result{}, fmt.Errorf("redis rate limit failed: unexpected reply %v", reply)
+	}
+
+	allowed := reply[0] == 1
+	global := scriptBucketState(reply[1:4], r.globalCapacity)
+	if clientID == "" {
+		global.allowed = allowed
+		return newResult(global, unlimited), nil
+	}
+
+	// The script takes tokens from both buckets or neither, so a short bucket is what denied the request
+	client := scriptBucketState(reply[4:7], capacity)
+	global.allowed = allowed || reply[3] == 0
+	client.allowed = allowed || reply[6] == 0
+	return newResult(global, client), nil
+}
+
+// scriptBucketState converts a bucket's tokens left, ms until full and ms until the cost is available
+func scriptBucketState(reply []int64, capacity int64) bucketState {
+	return bucketState{
+		limit:      capacity,
+		remaining:  reply[0],
+		reset:      time.Duration(reply[1]) * time.Millisecond,
+		retryAfter: time.Duration(reply[2]) * time.Millisecond,
+	}
+}
+
+// Close closes the Redis connection
+func (r *RedisRateLimiter) Close() error {
+	return r.client.Close()
+}
@@ -0,0 +1,224 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"Perion_Assignment/internal/redisclient"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+// setupRedisRateLimiter creates a Redis limiter backed by a mini redis server
+func setupRedisRateLimiter(t *testing.T, globalCapacity, globalRate, perIPCapacity, perIPRate int64) (*miniredis.Miniredis, *RedisRateLimiter) {
+	mr := miniredis.RunT(t)
+	mr.SetTime(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+
+	client := redis.NewClient(&redis.Options{
+		Addr: mr.Addr(),
+	})
+
+	return mr, &RedisRateLimiter{
+		client:          client,
+		fallback:        NewTwoTierRateLimiter(globalCapacity, globalRate, perIPCapacity, perIPRate),
+		globalCapacity:  globalCapacity,
+		globalRate:      globalRate,
+		perIPCapacity:   perIPCapacity,
+		perIPRate:       perIPRate,
+		failoverBackoff: time.Minute,
+	}
+}
+
+func TestRedisRateLimiter_NewRedisRateLimiter_InvalidURL(t *testing.T) {
+	limiter, err := NewRedisRateLimiter(redisclient.FromURL("invalid://url::"), 10, 10, 1, 1)
+
+	assert.Error(t, err)
+	assert.Nil(t, limiter)
+	assert.Contains(t, err.Error(), "failed to parse redis URL")
+}
+
+func TestRedisRateLimiter_PerIPLimit(t *testing.T) {
+	_, limiter := setupRedisRateLimiter(t, 100, 100, 3, 3)
+
+	for i := 0; i < 3; i++ {
+		assert.True(t, limiter.Allow("192.168.1.1"), "request %d should be allowed", i+1)
+	}
+	assert.False(t, limiter.Allow("192.168.1.1"))
+
+	// Other IPs have their own bucket
+	assert.True(t, limiter.Allow("192.168.1.2"))
+}
+
+func TestRedisRateLimiter_GlobalLimit(t *testing.T) {
+	_, limiter := setupRedisRateLimiter(t, 5, 5, 3, 3)
+
+	for i := 0; i < 3; i++ {
+		assert.True(t, limiter.Allow("192.168.1.1"))
+	}
+	for i := 0; i < 2; i++ {
+		assert.True(t, limiter.Allow("192.168.1.2"))
+	}
+	assert.False(t, limiter.Allow("192.168.1.2"), "global limit should be reached")
+}
+
+func TestRedisRateLimiter_PerIPDenialKeepsGlobalToken(t *testing.T) {
+	_, limiter := setupRedisRateLimiter(t, 3, 3, 2, 2)
+
+	assert.True(t, limiter.Allow("192.168.1.1"))
+	assert.True(t, limiter.Allow("192.168.1.1"))
+	assert.False(t, limiter.Allow("192.168.1.1"))
+
+	// The denied request did not consume the last global token
+	assert.True(t, limiter.Allow("192.168.1.2"))
+}
+
+func TestRedisRateLimiter_Refill(t *testing.T) {
+	mr, limiter := setupRedisRateLimiter(t, 100, 100, 2, 2)
+
+	assert.True(t, limiter.Allow("192.168.1.1"))
+	assert.True(t, limiter.Allow("192.168.1.1"))
+	assert.False(t, limiter.Allow("192.168.1.1"))
+
+	// Half a second refills one token at 2 per second
+	mr.SetTime(time.Date(2024, 1, 1, 12, 0, 0, int(500*time.Millisecond), time.UTC))
+	assert.True(t, limiter.Allow("192.168.1.1"))
+	assert.False(t, limiter.Allow("192.168.1.1"))
+}
+
+func TestRedisRateLimiter_SharedAcrossInstances(t *testing.T) {
+	mr, first := setupRedisRateLimiter(t, 100, 100, 2, 2)
+	second := &RedisRateLimiter{
+		client:         redis.NewClient(&redis.Options{Addr: mr.Addr()}),
+		fallback:       NewTwoTierRateLimiter(100, 100, 2, 2),
+		globalCapacity: 100,
+		globalRate:     100,
+		perIPCapacity:  2,
+		perIPRate:      2,
+	}
+
+	assert.True(t, first.Allow("192.168.1.1"))
+	assert.True(t, second.Allow("192.168.1.1"))
+	assert.False(t, first.Allow("192.168.1.1"))
+	assert.False(t, second.Allow("192.168.1.1"))
+}
+
+func TestRedisRateLimiter_BucketsExpire(t *testing.T) {
+	mr, limiter := setupRedisRateLimiter(t, 100, 10, 2, 1)
+
+	assert.True(t, limiter.Allow("192.168.1.1"))
+
+	ttl := mr.TTL(redisKeyPrefix + "ip:192.168.1.1")
+	assert.Greater(t, ttl, time.Duration(0))
+	assert.LessOrEqual(t, ttl, 3*time.Second)
+}
+
+func TestRedisRateLimiter_FallsBackWhenRedisIsDown(t *testing.T) {
+	mr, limiter := setupRedisRateLimiter(t, 100, 100, 2, 2)
+	mr.Close()
+
+	// The in-process limiter enforces the same limits
+	assert.True(t, limiter.Allow("192.168.1.1"))
+	assert.True(t, limiter.Allow("192.168.1.1"))
+	assert.False(t, limiter.Allow("192.168.1.1"))
+
+	// Redis is skipped for the failover backoff
+	assert.Greater(t, limiter.unavailableUntil.Load(), time.Now().UnixNano())
+}
+
+func TestRedisRateLimiter_Check_ReportsBucketState(t *testing.T) {
+	_, limiter := setupRedisRateLimiter(t, 100, 100, 2, 2)
+
+	result := limiter.Check("192.168.1.1")
+	assert.True(t, result.Allowed)
+	assert.Equal(t, TierClient, result.Tier)
+	assert.Equal(t, int64(2), result.Limit)
+	assert.Equal(t, int64(1), result.Remaining)
+	assert.Equal(t, 500*time.Millisecond, result.Reset)
+	assert.Zero(t, result.RetryAfter)
+
+	limiter.Check("192.168.1.1")
+	result = limiter.Check("192.168.1.1")
+	assert.False(t, result.Allowed)
+	assert.Equal(t, TierClient, result.Tier)
+	assert.Equal(t, int64(0), result.Remaining)
+	assert.Equal(t, time.Second, result.Reset)
+	assert.Equal(t, 500*time.Millisecond, result.RetryAfter)
+}
+
+func TestRedisRateLimiter_Check_ReportsGlobalTier(t *testing.T) {
+	_, limiter := setupRedisRateLimiter(t, 1, 1, 10, 10)
+
+	assert.True(t, limiter.Check("192.168.1.1").Allowed)
+
+	result := limiter.Check("192.168.1.2")
+	assert.False(t, result.Allowed)
+	assert.Equal(t, TierGlobal, result.Tier)
+	assert.Equal(t, int64(1), result.Limit)
+	assert.Equal(t, time.Second, result.RetryAfter)
+}
+
+func TestRedisRateLimiter_CheckClient(t *testing.T) {
+	_, limiter := setupRedisRateLimiter(t, 100, 100, 1, 1)
+	client := Client{ID: "key:acme", Capacity: 3, Rate: 3}
+
+	for i := 0; i < 3; i++ {
+		assert.True(t, limiter.CheckClient(client).Allowed)
+	}
+
+	result := limiter.CheckClient(client)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, TierClient, result.Tier)
+	assert.Equal(t, int64(3), result.Limit)
+
+	// The client's bucket is separate from the IP buckets
+	assert.True(t, limiter.Check("192.168.1.1").Allowed)
+}
+
+func TestRedisRateLimiter_CheckCost(t *testing.T) {
+	_, limiter := setupRedisRateLimiter(t, 100, 100, 10, 10)
+
+	result := limiter.CheckCost("192.168.1.1", 6)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, int64(4), result.Remaining)
+
+	result = limiter.CheckCost("192.168.1.1", 6)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, TierClient, result.Tier)
+	assert.InDelta(t, 200*time.Millisecond, result.RetryAfter, float64(20*time.Millisecond))
+
+	// Neither bucket paid for the denied request
+	assert.True(t, limiter.CheckCost("192.168.1.1", 4).Allowed)
+}
+
+func TestRedisRateLimiter_CheckCost_AboveCapacity(t *testing.T) {
+	_, limiter := setupRedisRateLimiter(t, 100, 100, 10, 10)
+
+	// Admitted with a full bucket and paid in full, leaving the bucket 40 tokens in debt
+	result := limiter.CheckCost("192.168.1.1", 50)
+	assert.True(t, result.Allowed)
+	assert.Zero(t, result.Remaining)
+
+	result = limiter.Check("192.168.1.1")
+	assert.False(t, result.Allowed)
+	assert.Equal(t, 4100*time.Millisecond, result.RetryAfter)
+	assert.Equal(t, int64(49), limiter.CheckGlobal(1).Remaining)
+}
+
+func TestRedisRateLimiter_CheckGlobal(t *testing.T) {
+	mr, limiter := setupRedisRateLimiter(t, 3, 3, 1, 1)
+
+	for i := 0; i < 3; i++ {
+		result := limiter.CheckGlobal(1)
+		assert.True(t, result.Allowed)
+		assert.Equal(t, TierGlobal, result.Tier)
+	}
+
+	result := limiter.CheckGlobal(1)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, TierGlobal, result.Tier)
+
+	// No per-client bucket is created
+	assert.Equal(t, []string{redisKeyPrefix + "global"}, mr.Keys())
+}
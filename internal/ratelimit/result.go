@@ -0,0 +1,88 @@
+package ratelimit
+
+import (
+	"math"
+	"time"
+)
+
+// Tiers a rate limit result can refer to
+const (
+	TierGlobal = "global"
+	TierClient = "client"
+)
+
+// Result describes a rate limit check
+// Limit, Remaining and Reset refer to Tier: the exhausted tier when the request is
+// denied, otherwise the tier with the fewest tokens left.
+type Result struct {
+	Allowed    bool
+	Tier       string
+	Limit      int64         // Bucket capacity
+	Remaining  int64         // Whole tokens left after this request
+	Reset      time.Duration // Until the bucket is full again
+	RetryAfter time.Duration // Until the request can succeed (0 when allowed)
+}
+
+// bucketState is the state of one token bucket after a check
+type bucketState struct {
+	allowed    bool
+	limit      int64
+	remaining  int64
+	reset      time.Duration
+	retryAfter time.Duration
+}
+
+// unlimited is the state of a per-client bucket that never limits
+var unlimited = bucketState{allowed: true, limit: math.MaxInt64, remaining: math.MaxInt64}
+
+// newResult combines the global and per-client bucket states of a check
+func newResult(global, client bucketState) Result {
+	tier, state := TierClient, client
+	switch {
+	case !global.allowed && (client.allowed || global.retryAfter > client.retryAfter):
+		tier, state = TierGlobal, global
+	case global.allowed && client.allowed && global.remaining < client.remaining:
+		tier, state = TierGlobal, global
+	}
+
+	result := Result{
+		Allowed:   global.allowed && client.allowed,
+		Tier:      tier,
+		Limit:     state.limit,
+		Remaining: state.remaining,
+		Reset:     state.reset,
+	}
+	if !result.Allowed {
+		result.RetryAfter = state.retryAfter
+	}
+	return result
+}
+
+// refillTime returns how long a bucket refilling at rate tokens per second takes to gain tokens
+func refillTime(tokens float64, rate int64) time.Duration {
+	if tokens <= 0 || rate <= 0 {
+		return 0
+	}
+	return time.Duration(tokens / float64(rate) * float64(time.Second))
+}
+
+// admissionCost returns the tokens a bucket of the given capacity must hold to admit a request
+// A cost above the capacity only needs a full bucket, so such requests can be admitted at all.
+func admissionCost(cost, capacity int64) int64 {
+	return max(min(cost, capacity), 1)
+}
+
+// chargedCost returns the tokens an admitted request takes from a bucket
+// Every request costs at least one token. A cost above the capacity puts the bucket into
+// debt, so the client waits for the whole cost to refill before its next request.
+func chargedCost(cost int64) int64 {
+	return max(cost, 1)
+}
+
+// nonNegative returns d, or zero when d is negative
+func nonNegative(d time.Duration) time.Duration {
+	if d < 0 {
+		return 0
+	}
+	return d
+}
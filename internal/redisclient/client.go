@@ -0,0 +1,193 @@
+package redisclient
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Supported deployment modes
+const (
+	ModeStandalone = "standalone"
+	ModeSentinel   = "sentinel"
+	ModeCluster    = "cluster"
+)
+
+// Config describes how to connect to Redis
+//
+// In standalone mode the server is taken from URL (redis:// or rediss://), or from the
+// first of Addrs when no URL is set. Sentinel mode connects to the master named MasterName
+// through the sentinels in Addrs; cluster mode uses Addrs as seed nodes. Non-zero fields
+// override the values parsed from URL.
+type Config struct {
+	Mode       string
+	URL        string
+	Addrs      []string
+	MasterName string
+
+	// ACL credentials; the sentinel password is used for sentinels only
+	Username         string
+	Password         string
+	SentinelPassword string
+	DB               int
+
+	TLS                   bool
+	TLSServerName         string
+	TLSInsecureSkipVerify bool
+
+	// Connection pool tuning (0 keeps the driver defaults)
+	PoolSize     int
+	MinIdleConns int
+	DialTimeout  time.Duration
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+	PoolTimeout  time.Duration
+	MaxRetries   int
+}
+
+// FromURL returns a standalone configuration for a redis:// URL
+func FromURL(redisURL string) Config {
+	return Config{Mode: ModeStandalone, URL: redisURL}
+}
+
+// New creates a client for the configured mode and checks that Redis is reachable
+func New(cfg Config) (redis.UniversalClient, error) {
+	opts, err := universalOptions(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	var client redis.UniversalClient
+	switch cfg.Mode {
+	case ModeSentinel:
+		client = redis.NewFailoverClient(opts.Failover())
+	case ModeCluster:
+		client = redis.NewClusterClient(opts.Cluster())
+	default:
+		client = redis.NewClient(opts.Simple())
+	}
+
+	// Test connection
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := client.Ping(ctx).Err(); err != nil {
+		_ = client.Close()
+		return nil, fmt.Errorf("failed to connect to redis: %w", err)
+	}
+
+	return client, nil
+}
+
+// universalOptions validates the configuration and converts it to driver options
+func universalOptions(cfg Config) (*redis.UniversalOptions, error) {
+	opts := &redis.UniversalOptions{}
+
+	switch cfg.Mode {
+	case "", ModeStandalone:
+		if cfg.URL != "" {
+			parsed, err := redis.ParseURL(cfg.URL)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse redis URL: %w", err)
+			}
+			opts = fromOptions(parsed)
+		} else if len(cfg.Addrs) > 0 {
+			opts.Addrs = cfg.Addrs[:1]
+		} else {
+			return nil, fmt.Errorf("redis URL or address is required in standalone mode")
+		}
+	case ModeSentinel:
+		if cfg.MasterName == "" {
+			return nil, fmt.Errorf("redis master name is required in sentinel mode")
+		}
+		if len(cfg.Addrs) == 0 {
+			return nil, fmt.Errorf("at least one sentinel address is required in sentinel mode")
+		}
+		opts.Addrs = cfg.Addrs
+		opts.MasterName = cfg.MasterName
+	case ModeCluster:
+		if len(cfg.Addrs) == 0 {
+			return nil, fmt.Errorf("at least one node address is required in cluster mode")
+		}
+		opts.Addrs = cfg.Addrs
+	default:
+		return nil, fmt.Errorf("unsupported redis mode: %s", cfg.Mode)
+	}
+
+	if cfg.Username != "" {
+		opts.Username = cfg.Username
+	}
+	if cfg.Password != "" {
+		opts.Password = cfg.Password
+	}
+	if cfg.SentinelPassword != "" {
+		opts.SentinelPassword = cfg.SentinelPassword
+	}
+	if cfg.DB != 0 {
+		opts.DB = cfg.DB
+	}
+
+	if cfg.TLS && opts.TLSConfig == nil {
+		opts.TLSConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+	if opts.TLSConfig != nil {
+		if cfg.TLSServerName != "" {
+			opts.TLSConfig.ServerName = cfg.TLSServerName
+		}
+		opts.TLSConfig.InsecureSkipVerify = cfg.TLSInsecureSkipVerify
+	}
+
+	if cfg.PoolSize > 0 {
+		opts.PoolSize = cfg.PoolSize
+	}
+	if cfg.MinIdleConns > 0 {
+		opts.MinIdleConns = cfg.MinIdleConns
+	}
+	if cfg.DialTimeout > 0 {
+		opts.DialTimeout = cfg.DialTimeout
+	}
+	if cfg.ReadTimeout > 0 {
+		opts.ReadTimeout = cfg.ReadTimeout
+	}
+	if cfg.WriteTimeout > 0 {
+		opts.WriteTimeout = cfg.WriteTimeout
+	}
+	if cfg.PoolTimeout > 0 {
+		opts.PoolTimeout = cfg.PoolTimeout
+	}
+	if cfg.MaxRetries != 0 {
+		opts.MaxRetries = cfg.MaxRetries
+	}
+
+	return opts, nil
+}
+
+// fromOptions converts options parsed from a URL to universal options
+func fromOptions(o *redis.Options) *redis.UniversalOptions {
+	return &redis.UniversalOptions{
+		Addrs:           []string{o.Addr},
+		ClientName:      o.ClientName,
+		DB:              o.DB,
+		Protocol:        o.Protocol,
+		Username:        o.Username,
+		Password:        o.Password,
+		MaxRetries:      o.MaxRetries,
+		MinRetryBackoff: o.MinRetryBackoff,
+		MaxRetryBackoff: o.MaxRetryBackoff,
+		DialTimeout:     o.DialTimeout,
+		ReadTimeout:     o.ReadTimeout,
+		WriteTimeout:    o.WriteTimeout,
+		PoolFIFO:        o.PoolFIFO,
+		PoolSize:        o.PoolSize,
+		PoolTimeout:     o.PoolTimeout,
+		MinIdleConns:    o.MinIdleConns,
+		MaxIdleConns:    o.MaxIdleConns,
+		MaxActiveConns:  o.MaxActiveConns,
+		ConnMaxIdleTime: o.ConnMaxIdleTime,
+		ConnMaxLifetime: o.ConnMaxLifetime,
+		TLSConfig:       o.TLSConfig,
+	}
+}
@@ -0,0 +1,16 @@
+package warmup
+
+import (
+	"context"
+
+	"Perion_Assignment/internal/models"
+)
+
+// Service defines the interface for pre-populating the cache at startup
+// External packages should use this interface, not the concrete implementations
+type Service interface {
+	Start(ctx context.Context)
+	Stop()
+	Done() bool
+	Progress() models.WarmupProgress
+}
@@ -0,0 +1,242 @@
+package warmup
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"Perion_Assignment/internal/domainAnalysis"
+	"Perion_Assignment/internal/logger"
+	"Perion_Assignment/internal/models"
+)
+
+// progressSteps is how many progress lines are logged over a warm-up
+const progressSteps = 10
+
+// Warmer implements Service by analyzing every domain of a list file once in the background
+// Analyses go through the analysis service, so they populate the domain cache exactly like requests do.
+type Warmer struct {
+	analysisService domainAnalysis.AnalysisService
+	logger          logger.Service
+	path            string
+	maxConcurrent   int
+
+	progress models.WarmupProgress
+	mutex    sync.RWMutex
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewWarmer creates a warm-up for the domains listed in the file at path
+// The file holds one domain per line; blank lines and lines starting with # are ignored.
+func NewWarmer(
+	analysisService domainAnalysis.AnalysisService,
+	logger logger.Service,
+	path string,
+	maxConcurrent int,
+) Service {
+	return newWarmer(analysisService, logger, path, maxConcurrent)
+}
+
+// newWarmer creates the concrete implementation
+func newWarmer(
+	analysisService domainAnalysis.AnalysisService,
+	logger logger.Service,
+	path string,
+	maxConcurrent int,
+) *Warmer {
+	if maxConcurrent <= 0 {
+		maxConcurrent = 1
+	}
+
+	return &Warmer{
+		analysisService: analysisService,
+		logger:          logger,
+		path:            path,
+		maxConcurrent:   maxConcurrent,
+	}
+}
+
+// Start runs the warm-up in the background and returns immediately
+func (w *Warmer) Start(ctx context.Context) {
+	ctx, w.cancel = context.WithCancel(ctx)
+
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		w.run(ctx)
+	}()
+}
+
+// Stop cancels a running warm-up and waits for in-flight analyses to finish
+func (w *Warmer) Stop() {
+	if w.cancel != nil {
+		w.cancel()
+	}
+	w.wg.Wait()
+}
+
+// Done reports whether the warm-up has finished, successfully or not
+func (w *Warmer) Done() bool {
+	w.mutex.RLock()
+	defer w.mutex.RUnlock()
+	return w.progress.Done
+}
+
+// Progress returns a snapshot of the warm-up progress
+func (w *Warmer) Progress() models.WarmupProgress {
+	w.mutex.RLock()
+	defer w.mutex.RUnlock()
+	return w.progress
+}
+
+// run loads the domain list and analyzes its domains with bounded concurrency
+func (w *Warmer) run(ctx context.Context) {
+	start := time.Now().UTC()
+	w.mutex.Lock()
+	w.progress.StartedAt = &start
+	w.mutex.Unlock()
+
+	domains, err := loadDomains(w.path)
+	if err != nil {
+		w.logger.LogError(ctx, logger.OpCacheWarmup, w.path, "Failed to read warm-up domain list", err, models.LogSeverityMedium, nil)
+		w.finish(err)
+		return
+	}
+
+	w.mutex.Lock()
+	w.progress.Total = len(domains)
+	w.mutex.Unlock()
+
+	w.logger.LogInfo(ctx, logger.OpCacheWarmup, "Starting cache warm-up", map[string]interface{}{
+		"domains":        len(domains),
+		"max_concurrent": w.maxConcurrent,
+		"source":         w.path,
+	})
+
+	progressEvery := len(domains) / progressSteps
+	if progressEvery == 0 {
+		progressEvery = 1
+	}
+
+	semaphore := make(chan struct{}, w.maxConcurrent)
+	var wg sync.WaitGroup
+	for _, domain := range domains {
+		select {
+		case semaphore <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+		go func(domain string) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+
+			_, err := w.analysisService.AnalyzeDomain(ctx, domain)
+			if completed := w.record(err == nil); completed%progressEvery == 0 && completed < len(domains) {
+				w.logProgress(ctx)
+			}
+		}(domain)
+	}
+	wg.Wait()
+
+	if ctx.Err() != nil {
+		w.logger.LogInfo(ctx, logger.OpCacheWarmup, "Cache warm-up cancelled", w.progressMetadata())
+		w.finish(ctx.Err())
+		return
+	}
+
+	w.finish(nil)
+	metadata := w.progressMetadata()
+	metadata["duration_ms"] = time.Since(start).Milliseconds()
+	w.logger.LogSuccess(ctx, logger.OpCacheWarmup, w.path, "Cache warm-up completed", metadata)
+}
+
+// record counts a finished analysis and returns the number completed so far
+func (w *Warmer) record(succeeded bool) int {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	w.progress.Completed++
+	if succeeded {
+		w.progress.Succeeded++
+	} else {
+		w.progress.Failed++
+	}
+	return w.progress.Completed
+}
+
+// finish marks the warm-up as done
+func (w *Warmer) finish(err error) {
+	now := time.Now().UTC()
+
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	w.progress.Done = true
+	w.progress.FinishedAt = &now
+	if err != nil {
+		w.progress.Error = err.Error()
+	}
+}
+
+// logProgress logs the current counters
+func (w *Warmer) logProgress(ctx context.Context) {
+	w.logger.LogInfo(ctx, logger.OpCacheWarmup, "Cache warm-up progress", w.progressMetadata())
+}
+
+// progressMetadata returns the counters as log metadata
+func (w *Warmer) progressMetadata() map[string]interface{} {
+	progress := w.Progress()
+	return map[string]interface{}{
+		"total":     progress.Total,
+		"completed": progress.Completed,
+		"succeeded": progress.Succeeded,
+		"failed":    progress.Failed,
+	}
+}
+
+// loadDomains reads the domain list file
+func loadDomains(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open domain list: %w", err)
+	}
+	defer file.Close()
+
+	return parseDomains(file)
+}
+
+// parseDomains reads one domain per line, skipping blank lines, # comments and duplicates
+func parseDomains(reader io.Reader) ([]string, error) {
+	var domains []string
+	seen := make(map[string]bool)
+
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if index := strings.Index(line, "#"); index >= 0 {
+			line = line[:index]
+		}
+		domain := strings.ToLower(strings.TrimSpace(line))
+		if domain == "" || seen[domain] {
+			continue
+		}
+		seen[domain] = true
+		domains = append(domains, domain)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read domain list: %w", err)
+	}
+
+	return domains, nil
+}
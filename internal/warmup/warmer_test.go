@@ -0,0 +1,140 @@
+package warmup
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	httpMocks "Perion_Assignment/internal/http/mocks"
+	"Perion_Assignment/internal/mocks"
+	"Perion_Assignment/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// writeDomainList writes a domain list file and returns its path
+func writeDomainList(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "domains.txt")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	return path
+}
+
+func waitForDone(t *testing.T, warmer Service) models.WarmupProgress {
+	require.Eventually(t, warmer.Done, 5*time.Second, 10*time.Millisecond)
+	return warmer.Progress()
+}
+
+func TestWarmer_AnalyzesEveryDomain(t *testing.T) {
+	mockAnalysis := &httpMocks.MockAnalysisService{}
+	mockAnalysis.On("AnalyzeDomain", mock.Anything, "cnn.com").Return(&models.DomainAnalysis{Domain: "cnn.com"}, nil).Once()
+	mockAnalysis.On("AnalyzeDomain", mock.Anything, "bbc.com").Return(&models.DomainAnalysis{Domain: "bbc.com"}, nil).Once()
+	mockAnalysis.On("AnalyzeDomain", mock.Anything, "missing.com").Return(nil, models.ErrDomainNotFound).Once()
+
+	path := writeDomainList(t, "cnn.com\nbbc.com\n\n# comment\nmissing.com\n")
+	warmer := NewWarmer(mockAnalysis, mocks.NewAnyLogger(), path, 2)
+	warmer.Start(context.Background())
+	defer warmer.Stop()
+
+	progress := waitForDone(t, warmer)
+	assert.Equal(t, 3, progress.Total)
+	assert.Equal(t, 3, progress.Completed)
+	assert.Equal(t, 2, progress.Succeeded)
+	assert.Equal(t, 1, progress.Failed)
+	assert.Empty(t, progress.Error)
+	assert.NotNil(t, progress.StartedAt)
+	assert.NotNil(t, progress.FinishedAt)
+	mockAnalysis.AssertExpectations(t)
+}
+
+func TestWarmer_BoundsConcurrency(t *testing.T) {
+	var inFlight, maxInFlight atomic.Int32
+	mockAnalysis := &httpMocks.MockAnalysisService{}
+	mockAnalysis.On("AnalyzeDomain", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		current := inFlight.Add(1)
+		for {
+			previous := maxInFlight.Load()
+			if current <= previous || maxInFlight.CompareAndSwap(previous, current) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		inFlight.Add(-1)
+	}).Return(&models.DomainAnalysis{}, nil)
+
+	var domains []string
+	for i := 0; i < 20; i++ {
+		domains = append(domains, "domain"+string(rune('a'+i))+".com")
+	}
+	warmer := NewWarmer(mockAnalysis, mocks.NewAnyLogger(), writeDomainList(t, strings.Join(domains, "\n")), 3)
+	warmer.Start(context.Background())
+	defer warmer.Stop()
+
+	progress := waitForDone(t, warmer)
+	assert.Equal(t, 20, progress.Succeeded)
+	assert.LessOrEqual(t, maxInFlight.Load(), int32(3))
+}
+
+func TestWarmer_MissingFile(t *testing.T) {
+	mockAnalysis := &httpMocks.MockAnalysisService{}
+	mockLogger := &mocks.MockLogger{}
+	mockLogger.On("LogError", mock.Anything, "cache_warmup", mock.Anything, "Failed to read warm-up domain list", mock.Anything, models.LogSeverityMedium, mock.Anything).Return().Once()
+
+	warmer := NewWarmer(mockAnalysis, mockLogger, filepath.Join(t.TempDir(), "missing.txt"), 1)
+	warmer.Start(context.Background())
+	defer warmer.Stop()
+
+	progress := waitForDone(t, warmer)
+	assert.Contains(t, progress.Error, "failed to open domain list")
+	assert.Zero(t, progress.Total)
+	mockAnalysis.AssertNotCalled(t, "AnalyzeDomain", mock.Anything, mock.Anything)
+	mockLogger.AssertExpectations(t)
+}
+
+func TestWarmer_Stop_CancelsRemainingDomains(t *testing.T) {
+	started := make(chan struct{}, 1)
+	mockAnalysis := &httpMocks.MockAnalysisService{}
+	mockAnalysis.On("AnalyzeDomain", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		select {
+		case started <- struct{}{}:
+		default:
+		}
+		<-args.Get(0).(context.Context).Done()
+	}).Return(nil, context.Canceled)
+
+	warmer := NewWarmer(mockAnalysis, mocks.NewAnyLogger(), writeDomainList(t, "a.com\nb.com\nc.com\n"), 1)
+	warmer.Start(context.Background())
+	<-started
+
+	warmer.Stop()
+
+	progress := warmer.Progress()
+	assert.True(t, progress.Done)
+	assert.Equal(t, 1, progress.Completed)
+	assert.Equal(t, context.Canceled.Error(), progress.Error)
+}
+
+func TestParseDomains(t *testing.T) {
+	domains, err := parseDomains(strings.NewReader("CNN.com\n  bbc.com  \n\n# header\nnyt.com # news\ncnn.com\n"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"cnn.com", "bbc.com", "nyt.com"}, domains)
+}
+
+func TestParseDomains_ReadError(t *testing.T) {
+	_, err := parseDomains(&failingReader{})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to read domain list")
+}
+
+// failingReader returns an error on every read
+type failingReader struct{}
+
+func (f *failingReader) Read(p []byte) (int, error) {
+	return 0, errors.New("disk error")
+}
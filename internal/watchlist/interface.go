@@ -0,0 +1,22 @@
+package watchlist
+
+import (
+	"context"
+
+	"Perion_Assignment/internal/models"
+)
+
+// Service defines the interface for managing watched domains
+// External packages should use this interface, not the concrete implementations
+type Service interface {
+	Add(ctx context.Context, domain string) (*models.WatchlistEntry, error)
+	Remove(ctx context.Context, domain string) (*models.WatchlistEntry, error)
+	List(ctx context.Context) []models.WatchlistEntry
+	Start(ctx context.Context)
+	Stop()
+}
+
+// Notifier defines the interface for delivering change notifications
+type Notifier interface {
+	Notify(ctx context.Context, notification *models.ChangeNotification) error
+}
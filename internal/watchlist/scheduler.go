@@ -0,0 +1,364 @@
+package watchlist
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"Perion_Assignment/internal/fetcher"
+	"Perion_Assignment/internal/logger"
+	"Perion_Assignment/internal/models"
+	"Perion_Assignment/internal/parser"
+)
+
+const (
+	// EventAdsTxtChanged is the event name sent with change notifications
+	EventAdsTxtChanged = "adstxt.changed"
+
+	// defaultInterval is the time between re-checks when no positive interval is configured
+	defaultInterval = time.Hour
+)
+
+// Scheduler implements Service by periodically re-fetching watched domains
+// and comparing their ads.txt records against the previous snapshot
+type Scheduler struct {
+	fetcher       fetcher.Service
+	parser        parser.Service
+	notifier      Notifier
+	logger        logger.Service
+	interval      time.Duration
+	maxConcurrent int
+
+	entries map[string]*watchedDomain
+	mutex   sync.RWMutex
+
+	// pending receives newly added domains so their baseline is taken immediately
+	pending chan string
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+}
+
+// watchedDomain holds a watchlist entry together with its last known records
+type watchedDomain struct {
+	entry      models.WatchlistEntry
+	snapshot   map[string]models.AdsTxtEntry // nil until the first successful check
+	snapshotAt time.Time
+}
+
+// NewScheduler creates a new watchlist scheduler
+// notifier may be nil, in which case changes are only recorded on the entries.
+// A non-positive interval re-checks domains every hour.
+func NewScheduler(
+	fetcher fetcher.Service,
+	parser parser.Service,
+	notifier Notifier,
+	logger logger.Service,
+	interval time.Duration,
+	maxConcurrent int,
+) Service {
+	return newScheduler(fetcher, parser, notifier, logger, interval, maxConcurrent)
+}
+
+// newScheduler creates the concrete implementation
+func newScheduler(
+	fetcher fetcher.Service,
+	parser parser.Service,
+	notifier Notifier,
+	logger logger.Service,
+	interval time.Duration,
+	maxConcurrent int,
+) *Scheduler {
+	if maxConcurrent <= 0 {
+		maxConcurrent = 1
+	}
+	if interval <= 0 {
+		interval = defaultInterval
+	}
+
+	return &Scheduler{
+		fetcher:       fetcher,
+		parser:        parser,
+		notifier:      notifier,
+		logger:        logger,
+		interval:      interval,
+		maxConcurrent: maxConcurrent,
+		entries:       make(map[string]*watchedDomain),
+		pending:       make(chan string, 100),
+	}
+}
+
+// Add registers a domain in the watchlist
+// Domains are validated like the fetcher validates the domains it analyzes.
+func (s *Scheduler) Add(ctx context.Context, domain string) (*models.WatchlistEntry, error) {
+	domain = normalizeDomain(domain)
+	if err := fetcher.ValidateDomain(domain); err != nil {
+		return nil, err
+	}
+
+	s.mutex.Lock()
+	if _, exists := s.entries[domain]; exists {
+		s.mutex.Unlock()
+		return nil, models.ErrWatchlistEntryExists
+	}
+
+	watched := &watchedDomain{
+		entry: models.WatchlistEntry{
+			Domain:  domain,
+			AddedAt: time.Now().UTC(),
+		},
+	}
+	s.entries[domain] = watched
+	entry := watched.entry
+	s.mutex.Unlock()
+
+	// Request an immediate baseline check without blocking the caller
+	select {
+	case s.pending <- domain:
+	default:
+		// Queue is full - the baseline will be taken on the next scheduled run
+	}
+
+	s.logger.LogSuccess(ctx, logger.OpWatchlist, domain, "Added domain to watchlist", nil)
+
+	return &entry, nil
+}
+
+// Remove unregisters a domain from the watchlist
+func (s *Scheduler) Remove(ctx context.Context, domain string) (*models.WatchlistEntry, error) {
+	domain = normalizeDomain(domain)
+
+	s.mutex.Lock()
+	watched, exists := s.entries[domain]
+	if !exists {
+		s.mutex.Unlock()
+		return nil, models.ErrWatchlistEntryNotFound
+	}
+	delete(s.entries, domain)
+	entry := watched.entry
+	s.mutex.Unlock()
+
+	s.logger.LogSuccess(ctx, logger.OpWatchlist, domain, "Removed domain from watchlist", nil)
+
+	return &entry, nil
+}
+
+// List returns all watched domains sorted by domain name
+func (s *Scheduler) List(ctx context.Context) []models.WatchlistEntry {
+	s.mutex.RLock()
+	entries := make([]models.WatchlistEntry, 0, len(s.entries))
+	for _, watched := range s.entries {
+		entries = append(entries, watched.entry)
+	}
+	s.mutex.RUnlock()
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Domain < entries[j].Domain
+	})
+
+	return entries
+}
+
+// Start launches the background scheduler loop
+func (s *Scheduler) Start(ctx context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	s.cancel = cancel
+
+	s.wg.Add(1)
+	go s.run(ctx)
+}
+
+// Stop stops the background scheduler and waits for in-flight checks
+func (s *Scheduler) Stop() {
+	if s.cancel != nil {
+		s.cancel()
+	}
+	s.wg.Wait()
+}
+
+// run re-checks all watched domains on every tick and baselines newly added ones
+func (s *Scheduler) run(ctx context.Context) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case domain := <-s.pending:
+			s.checkDomain(ctx, domain)
+		case <-ticker.C:
+			s.checkAll(ctx)
+		}
+	}
+}
+
+// checkAll re-checks every watched domain with bounded concurrency
+func (s *Scheduler) checkAll(ctx context.Context) {
+	s.mutex.RLock()
+	domains := make([]string, 0, len(s.entries))
+	for domain := range s.entries {
+		domains = append(domains, domain)
+	}
+	s.mutex.RUnlock()
+
+	sem := make(chan struct{}, s.maxConcurrent)
+	var wg sync.WaitGroup
+
+	for _, domain := range domains {
+		wg.Add(1)
+
+		go func(dom string) {
+			defer wg.Done()
+
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			s.checkDomain(ctx, dom)
+		}(domain)
+	}
+
+	wg.Wait()
+}
+
+// checkDomain fetches a domain's ads.txt, records the new snapshot and notifies on changes
+func (s *Scheduler) checkDomain(ctx context.Context, domain string) {
+	// Each check is an internal process with its own ProcessID
+	ctx = logger.WithLogEvent(ctx, logger.NewInternalLogEvent())
+	start := time.Now()
+
+	var records []models.AdsTxtEntry
+	content, err := s.fetcher.Fetch(ctx, domain)
+	if err == nil {
+		records, err = s.parser.Parse(content)
+	}
+
+	checkedAt := time.Now().UTC()
+
+	s.mutex.Lock()
+	watched, exists := s.entries[domain]
+	if !exists {
+		// Domain was removed while the check was running
+		s.mutex.Unlock()
+		return
+	}
+
+	watched.entry.LastCheckedAt = &checkedAt
+	if err != nil {
+		watched.entry.LastError = err.Error()
+		s.mutex.Unlock()
+
+		s.logger.LogError(ctx, logger.OpWatchlistCheck, domain, "Failed to check watched domain", err, models.LogSeverityLow, map[string]interface{}{
+			"duration_ms": time.Since(start).Milliseconds(),
+		})
+		return
+	}
+
+	current := indexRecords(records)
+	previous, previousAt := watched.snapshot, watched.snapshotAt
+
+	watched.entry.LastError = ""
+	watched.entry.RecordsCount = len(current)
+	watched.snapshot = current
+	watched.snapshotAt = checkedAt
+
+	var notification *models.ChangeNotification
+	if previous != nil {
+		added, removed := diffRecords(previous, current)
+		if len(added) > 0 || len(removed) > 0 {
+			watched.entry.LastChangedAt = &checkedAt
+			notification = &models.ChangeNotification{
+				Event:             EventAdsTxtChanged,
+				Domain:            domain,
+				Added:             added,
+				Removed:           removed,
+				PreviousCheckedAt: previousAt,
+				CheckedAt:         checkedAt,
+			}
+		}
+	}
+	s.mutex.Unlock()
+
+	s.logger.LogSuccess(ctx, logger.OpWatchlistCheck, domain, "Checked watched domain", map[string]interface{}{
+		"records_count": len(current),
+		"baseline":      previous == nil,
+		"changed":       notification != nil,
+		"duration_ms":   time.Since(start).Milliseconds(),
+	})
+
+	if notification == nil || s.notifier == nil {
+		return
+	}
+
+	if err := s.notifier.Notify(ctx, notification); err != nil {
+		s.logger.LogError(ctx, logger.OpWatchlistNotify, domain, "Failed to deliver change notification", err, models.LogSeverityMedium, map[string]interface{}{
+			"added":   len(notification.Added),
+			"removed": len(notification.Removed),
+		})
+		return
+	}
+
+	s.logger.LogSuccess(ctx, logger.OpWatchlistNotify, domain, "Delivered change notification", map[string]interface{}{
+		"added":   len(notification.Added),
+		"removed": len(notification.Removed),
+	})
+}
+
+// indexRecords builds a set of records keyed by their identifying fields
+func indexRecords(records []models.AdsTxtEntry) map[string]models.AdsTxtEntry {
+	index := make(map[string]models.AdsTxtEntry, len(records))
+	for _, record := range records {
+		index[recordKey(record)] = record
+	}
+	return index
+}
+
+// diffRecords returns the records present only in current (added) and only in previous (removed)
+func diffRecords(previous, current map[string]models.AdsTxtEntry) (added, removed []models.AdsTxtEntry) {
+	added = []models.AdsTxtEntry{}
+	removed = []models.AdsTxtEntry{}
+
+	for key, record := range current {
+		if _, ok := previous[key]; !ok {
+			added = append(added, record)
+		}
+	}
+	for key, record := range previous {
+		if _, ok := current[key]; !ok {
+			removed = append(removed, record)
+		}
+	}
+
+	sortRecords(added)
+	sortRecords(removed)
+
+	return added, removed
+}
+
+// recordKey identifies an ads.txt record for comparison purposes
+func recordKey(record models.AdsTxtEntry) string {
+	return strings.Join([]string{
+		record.ExchangeDomain,
+		record.PublisherID,
+		record.AccountType,
+		record.CertificationAuth,
+	}, "|")
+}
+
+// sortRecords sorts records by exchange domain, then publisher ID
+func sortRecords(records []models.AdsTxtEntry) {
+	sort.Slice(records, func(i, j int) bool {
+		if records[i].ExchangeDomain == records[j].ExchangeDomain {
+			return recordKey(records[i]) < recordKey(records[j])
+		}
+		return records[i].ExchangeDomain < records[j].ExchangeDomain
+	})
+}
+
+// normalizeDomain lowercases and trims a domain for use as a watchlist key
+func normalizeDomain(domain string) string {
+	return strings.ToLower(strings.TrimSpace(domain))
+}
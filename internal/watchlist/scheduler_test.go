@@ -0,0 +1,226 @@
+package watchlist
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"Perion_Assignment/internal/mocks"
+	"Perion_Assignment/internal/models"
+	"Perion_Assignment/internal/parser"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingNotifier captures notifications for assertions
+type recordingNotifier struct {
+	mutex         sync.Mutex
+	notifications []*models.ChangeNotification
+	err           error
+}
+
+func (r *recordingNotifier) Notify(ctx context.Context, notification *models.ChangeNotification) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.notifications = append(r.notifications, notification)
+	return r.err
+}
+
+func TestScheduler_AddListRemove(t *testing.T) {
+	scheduler := newScheduler(&mocks.MockFetcher{}, parser.NewParser(), nil, mocks.NewAnyLogger(), time.Hour, 2)
+	ctx := context.Background()
+
+	entry, err := scheduler.Add(ctx, " CNN.com ")
+	require.NoError(t, err)
+	assert.Equal(t, "cnn.com", entry.Domain)
+	assert.Nil(t, entry.LastCheckedAt)
+
+	_, err = scheduler.Add(ctx, "cnn.com")
+	assert.ErrorIs(t, err, models.ErrWatchlistEntryExists)
+
+	_, err = scheduler.Add(ctx, "msn.com")
+	require.NoError(t, err)
+
+	entries := scheduler.List(ctx)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "cnn.com", entries[0].Domain)
+	assert.Equal(t, "msn.com", entries[1].Domain)
+
+	removed, err := scheduler.Remove(ctx, "cnn.com")
+	require.NoError(t, err)
+	assert.Equal(t, "cnn.com", removed.Domain)
+
+	_, err = scheduler.Remove(ctx, "cnn.com")
+	assert.ErrorIs(t, err, models.ErrWatchlistEntryNotFound)
+	assert.Len(t, scheduler.List(ctx), 1)
+}
+
+func TestScheduler_NonPositiveInterval(t *testing.T) {
+	for _, interval := range []time.Duration{0, -time.Second} {
+		scheduler := newScheduler(&mocks.MockFetcher{}, parser.NewParser(), nil, mocks.NewAnyLogger(), interval, 2)
+		assert.Equal(t, defaultInterval, scheduler.interval)
+
+		// Starting doesn't panic on the ticker
+		scheduler.Start(context.Background())
+		scheduler.Stop()
+	}
+}
+
+func TestScheduler_Add_InvalidDomain(t *testing.T) {
+	scheduler := newScheduler(&mocks.MockFetcher{}, parser.NewParser(), nil, mocks.NewAnyLogger(), time.Hour, 2)
+
+	for _, domain := range []string{"   ", "not a domain", "exa_mple.com"} {
+		_, err := scheduler.Add(context.Background(), domain)
+		assert.ErrorIs(t, err, models.ErrInvalidDomain, domain)
+	}
+	assert.Empty(t, scheduler.List(context.Background()))
+}
+
+func TestScheduler_CheckDomain_DetectsChanges(t *testing.T) {
+	mockFetcher := &mocks.MockFetcher{}
+	notifier := &recordingNotifier{}
+	scheduler := newScheduler(mockFetcher, parser.NewParser(), notifier, mocks.NewAnyLogger(), time.Hour, 2)
+	ctx := context.Background()
+
+	_, err := scheduler.Add(ctx, "example.com")
+	require.NoError(t, err)
+
+	// First check establishes the baseline and must not notify
+	mockFetcher.On("Fetch", mock.Anything, "example.com").Return(
+		"google.com, pub-1, DIRECT\nappnexus.com, 123, RESELLER", nil).Once()
+	scheduler.checkDomain(ctx, "example.com")
+
+	assert.Empty(t, notifier.notifications)
+	entries := scheduler.List(ctx)
+	require.Len(t, entries, 1)
+	assert.Equal(t, 2, entries[0].RecordsCount)
+	assert.NotNil(t, entries[0].LastCheckedAt)
+	assert.Nil(t, entries[0].LastChangedAt)
+
+	// Second check adds a seller line and drops another
+	mockFetcher.On("Fetch", mock.Anything, "example.com").Return(
+		"google.com, pub-1, DIRECT\nvidazoo.com, abc, DIRECT", nil).Once()
+	scheduler.checkDomain(ctx, "example.com")
+
+	require.Len(t, notifier.notifications, 1)
+	notification := notifier.notifications[0]
+	assert.Equal(t, EventAdsTxtChanged, notification.Event)
+	assert.Equal(t, "example.com", notification.Domain)
+	require.Len(t, notification.Added, 1)
+	assert.Equal(t, "vidazoo.com", notification.Added[0].ExchangeDomain)
+	require.Len(t, notification.Removed, 1)
+	assert.Equal(t, "appnexus.com", notification.Removed[0].ExchangeDomain)
+	assert.False(t, notification.PreviousCheckedAt.IsZero())
+
+	entries = scheduler.List(ctx)
+	assert.NotNil(t, entries[0].LastChangedAt)
+
+	mockFetcher.AssertExpectations(t)
+}
+
+func TestScheduler_CheckDomain_NoChangeNoNotification(t *testing.T) {
+	mockFetcher := &mocks.MockFetcher{}
+	notifier := &recordingNotifier{}
+	scheduler := newScheduler(mockFetcher, parser.NewParser(), notifier, mocks.NewAnyLogger(), time.Hour, 2)
+	ctx := context.Background()
+
+	_, err := scheduler.Add(ctx, "example.com")
+	require.NoError(t, err)
+
+	mockFetcher.On("Fetch", mock.Anything, "example.com").Return("google.com, pub-1, DIRECT", nil)
+	scheduler.checkDomain(ctx, "example.com")
+	scheduler.checkDomain(ctx, "example.com")
+
+	assert.Empty(t, notifier.notifications)
+}
+
+func TestScheduler_CheckDomain_FetchErrorKeepsSnapshot(t *testing.T) {
+	mockFetcher := &mocks.MockFetcher{}
+	notifier := &recordingNotifier{}
+	scheduler := newScheduler(mockFetcher, parser.NewParser(), notifier, mocks.NewAnyLogger(), time.Hour, 2)
+	ctx := context.Background()
+
+	_, err := scheduler.Add(ctx, "example.com")
+	require.NoError(t, err)
+
+	mockFetcher.On("Fetch", mock.Anything, "example.com").Return("google.com, pub-1, DIRECT", nil).Once()
+	scheduler.checkDomain(ctx, "example.com")
+
+	mockFetcher.On("Fetch", mock.Anything, "example.com").Return("", errors.New("connection refused")).Once()
+	scheduler.checkDomain(ctx, "example.com")
+
+	entries := scheduler.List(ctx)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "connection refused", entries[0].LastError)
+	assert.Equal(t, 1, entries[0].RecordsCount)
+
+	// Recovery with identical content is not reported as a change
+	mockFetcher.On("Fetch", mock.Anything, "example.com").Return("google.com, pub-1, DIRECT", nil).Once()
+	scheduler.checkDomain(ctx, "example.com")
+
+	entries = scheduler.List(ctx)
+	assert.Empty(t, entries[0].LastError)
+	assert.Empty(t, notifier.notifications)
+}
+
+func TestScheduler_CheckAll_ChecksEveryDomain(t *testing.T) {
+	mockFetcher := &mocks.MockFetcher{}
+	scheduler := newScheduler(mockFetcher, parser.NewParser(), nil, mocks.NewAnyLogger(), time.Hour, 2)
+	ctx := context.Background()
+
+	domains := []string{"a.com", "b.com", "c.com"}
+	for _, domain := range domains {
+		_, err := scheduler.Add(ctx, domain)
+		require.NoError(t, err)
+		mockFetcher.On("Fetch", mock.Anything, domain).Return("google.com, pub-1, DIRECT", nil).Once()
+	}
+
+	scheduler.checkAll(ctx)
+
+	for _, entry := range scheduler.List(ctx) {
+		assert.NotNil(t, entry.LastCheckedAt, entry.Domain)
+	}
+	mockFetcher.AssertExpectations(t)
+}
+
+func TestScheduler_StartTakesBaselineForNewDomains(t *testing.T) {
+	mockFetcher := &mocks.MockFetcher{}
+	scheduler := newScheduler(mockFetcher, parser.NewParser(), nil, mocks.NewAnyLogger(), time.Hour, 2)
+	ctx := context.Background()
+
+	mockFetcher.On("Fetch", mock.Anything, "example.com").Return("google.com, pub-1, DIRECT", nil)
+
+	scheduler.Start(ctx)
+	defer scheduler.Stop()
+
+	_, err := scheduler.Add(ctx, "example.com")
+	require.NoError(t, err)
+
+	assert.Eventually(t, func() bool {
+		entries := scheduler.List(ctx)
+		return len(entries) == 1 && entries[0].LastCheckedAt != nil
+	}, 2*time.Second, 10*time.Millisecond)
+}
+
+func TestDiffRecords(t *testing.T) {
+	previous := indexRecords([]models.AdsTxtEntry{
+		{ExchangeDomain: "google.com", PublisherID: "1", AccountType: "DIRECT"},
+		{ExchangeDomain: "google.com", PublisherID: "2", AccountType: "DIRECT"},
+	})
+	current := indexRecords([]models.AdsTxtEntry{
+		{ExchangeDomain: "google.com", PublisherID: "1", AccountType: "DIRECT"},
+		{ExchangeDomain: "google.com", PublisherID: "2", AccountType: "RESELLER"},
+	})
+
+	added, removed := diffRecords(previous, current)
+
+	require.Len(t, added, 1)
+	assert.Equal(t, "RESELLER", added[0].AccountType)
+	require.Len(t, removed, 1)
+	assert.Equal(t, "DIRECT", removed[0].AccountType)
+	assert.Equal(t, "2", removed[0].PublisherID)
+}
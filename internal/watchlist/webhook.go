@@ -0,0 +1,107 @@
+package watchlist
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"Perion_Assignment/internal/models"
+)
+
+const (
+	// SignatureHeader carries the HMAC-SHA256 signature of the request body
+	SignatureHeader = "X-Watchlist-Signature"
+
+	// EventHeader carries the notification event name
+	EventHeader = "X-Watchlist-Event"
+)
+
+// WebhookNotifier implements Notifier by POSTing signed JSON payloads to webhooks
+type WebhookNotifier struct {
+	client *http.Client
+	urls   []string
+	secret string
+}
+
+// NewWebhookNotifier creates a new webhook notifier
+// The secret is required, as receivers authenticate payloads by their signature.
+func NewWebhookNotifier(urls []string, secret string, timeout time.Duration) (Notifier, error) {
+	return newWebhookNotifier(urls, secret, timeout)
+}
+
+// newWebhookNotifier creates the concrete implementation
+func newWebhookNotifier(urls []string, secret string, timeout time.Duration) (*WebhookNotifier, error) {
+	if secret == "" {
+		return nil, errors.New("a webhook secret is required to sign payloads")
+	}
+
+	return &WebhookNotifier{
+		client: &http.Client{Timeout: timeout},
+		urls:   urls,
+		secret: secret,
+	}, nil
+}
+
+// Notify delivers the notification to every configured webhook
+// Delivery continues past failing webhooks; all failures are returned together
+func (n *WebhookNotifier) Notify(ctx context.Context, notification *models.ChangeNotification) error {
+	body, err := json.Marshal(notification)
+	if err != nil {
+		return fmt.Errorf("failed to marshal notification: %w", err)
+	}
+
+	signature := Sign(n.secret, body)
+
+	var errs []error
+	for _, url := range n.urls {
+		if err := n.deliver(ctx, url, notification.Event, body, signature); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// deliver sends the payload to a single webhook
+func (n *WebhookNotifier) deliver(ctx context.Context, url, event string, body []byte, signature string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create webhook request for %s: %w", url, err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "AdsTxt-Analyzer/1.0")
+	req.Header.Set(EventHeader, event)
+	req.Header.Set(SignatureHeader, signature)
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook %s failed: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	// Drain the body so the connection can be reused
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook %s returned unexpected status: %d", url, resp.StatusCode)
+	}
+
+	return nil
+}
+
+// Sign computes the signature header value for a payload: "sha256=" followed by the
+// hex-encoded HMAC-SHA256 of the payload keyed with the shared secret
+func Sign(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,91 @@
+package watchlist
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"Perion_Assignment/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookNotifier_Notify_SignsPayload(t *testing.T) {
+	var receivedBody []byte
+	var receivedSignature, receivedEvent string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		receivedBody, _ = io.ReadAll(r.Body)
+		receivedSignature = r.Header.Get(SignatureHeader)
+		receivedEvent = r.Header.Get(EventHeader)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	notifier, err := NewWebhookNotifier([]string{server.URL}, "s3cret", 5*time.Second)
+	require.NoError(t, err)
+
+	notification := &models.ChangeNotification{
+		Event:  EventAdsTxtChanged,
+		Domain: "example.com",
+		Added: []models.AdsTxtEntry{
+			{ExchangeDomain: "google.com", PublisherID: "pub-1", AccountType: "DIRECT"},
+		},
+		Removed:   []models.AdsTxtEntry{},
+		CheckedAt: time.Now().UTC(),
+	}
+
+	err = notifier.Notify(context.Background(), notification)
+	require.NoError(t, err)
+
+	assert.Equal(t, EventAdsTxtChanged, receivedEvent)
+	assert.Equal(t, Sign("s3cret", receivedBody), receivedSignature)
+
+	var decoded models.ChangeNotification
+	require.NoError(t, json.Unmarshal(receivedBody, &decoded))
+	assert.Equal(t, "example.com", decoded.Domain)
+	require.Len(t, decoded.Added, 1)
+	assert.Equal(t, "pub-1", decoded.Added[0].PublisherID)
+}
+
+func TestWebhookNotifier_Notify_ReportsFailuresAndContinues(t *testing.T) {
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer failing.Close()
+
+	delivered := false
+	healthy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		delivered = true
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer healthy.Close()
+
+	notifier, err := NewWebhookNotifier([]string{failing.URL, healthy.URL}, "s3cret", 5*time.Second)
+	require.NoError(t, err)
+
+	err = notifier.Notify(context.Background(), &models.ChangeNotification{Event: EventAdsTxtChanged, Domain: "example.com"})
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "unexpected status: 500")
+	assert.True(t, delivered, "healthy webhook should still receive the notification")
+}
+
+func TestSign_IsDeterministicAndKeyed(t *testing.T) {
+	payload := []byte(`{"domain":"example.com"}`)
+
+	assert.Equal(t, Sign("a", payload), Sign("a", payload))
+	assert.NotEqual(t, Sign("a", payload), Sign("b", payload))
+	assert.Contains(t, Sign("a", payload), "sha256=")
+}
+
+func TestNewWebhookNotifier_RequiresSecret(t *testing.T) {
+	notifier, err := NewWebhookNotifier([]string{"https://example.com/hook"}, "", 5*time.Second)
+	assert.Error(t, err)
+	assert.Nil(t, notifier)
+}
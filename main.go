@@ -9,16 +9,24 @@ import (
 	"syscall"
 	"time"
 
+	"Perion_Assignment/internal/apikeys"
 	"Perion_Assignment/internal/cache"
 	"Perion_Assignment/internal/cache/domainCache"
+	"Perion_Assignment/internal/concurrency"
 	"Perion_Assignment/internal/config"
 	"Perion_Assignment/internal/domainAnalysis"
 	"Perion_Assignment/internal/models"
 	"Perion_Assignment/internal/fetcher"
 	"Perion_Assignment/internal/http"
+	"Perion_Assignment/internal/ipfilter"
+	"Perion_Assignment/internal/jobs"
+	"Perion_Assignment/internal/lock"
 	"Perion_Assignment/internal/logger"
 	"Perion_Assignment/internal/parser"
 	"Perion_Assignment/internal/ratelimit"
+	"Perion_Assignment/internal/redisclient"
+	"Perion_Assignment/internal/warmup"
+	"Perion_Assignment/internal/watchlist"
 )
 
 func main() {
@@ -45,6 +53,7 @@ func main() {
 			"port":        cfg.Port,
 			"cache_type": cfg.CacheType,
 			"cache_ttl":  cfg.CacheTTL.Seconds(),
+			"redis_mode": cfg.RedisMode,
 		},
 	})
 	
@@ -63,8 +72,27 @@ func main() {
 		log.Fatalf("Failed to initialize cache: %v", err)
 	}
 
+	cacheCodec, err := cache.CodecByName(cfg.CacheCodec)
+	if err != nil {
+		appLogger.LogError(
+			startupCtx,
+			"cache_init",
+			"",
+			"Failed to initialize cache codec",
+			err,
+			models.LogSeverityHigh,
+			nil,
+		)
+		log.Fatalf("Failed to initialize cache codec: %v", err)
+	}
+
 	// Initialize domain cache
-	domainCacheService := domainCache.New(cacheService, cfg.CacheTTL)
+	domainCacheService := domainCache.New(
+		cacheService,
+		cfg.CacheTTL,
+		domainCache.WithStaleWhileRevalidate(cfg.CacheStaleTTL, cfg.CacheStaleGrace),
+		domainCache.WithCodec(cacheCodec),
+	)
 	
 	// Initialize components
 	adsTxtParser := parser.NewParser()
@@ -73,22 +101,241 @@ func main() {
 	// Debug: Print rate limit configuration
 	fmt.Printf("🔧 Rate Limit Config: Global=%d/sec, Per-IP=%d/sec\n", cfg.GlobalRateLimitPerSec, cfg.PerIPRateLimitPerSec)
 	
-	rateLimiter := ratelimit.NewTwoTierRateLimiter(
-		int64(cfg.GlobalRateLimitPerSec),
-		int64(cfg.GlobalRateLimitPerSec),
-		int64(cfg.PerIPRateLimitPerSec),
-		int64(cfg.PerIPRateLimitPerSec),
-	)
+	rateLimiter, err := initializeRateLimiter(cfg)
+	if err != nil {
+		// Limit each replica on its own rather than refusing to start
+		appLogger.LogError(
+			startupCtx,
+			"rate_limiter_init",
+			"",
+			"Failed to initialize Redis rate limiter, using in-process limits",
+			err,
+			models.LogSeverityHigh,
+			nil,
+		)
+		rateLimiter = ratelimit.NewTwoTierRateLimiter(
+			int64(cfg.GlobalRateLimitPerSec),
+			int64(cfg.GlobalRateLimitPerSec),
+			int64(cfg.PerIPRateLimitPerSec),
+			int64(cfg.PerIPRateLimitPerSec),
+		)
+	}
 	
 	// Initialize service
+	analysisOptions := []domainAnalysis.Option{
+		domainAnalysis.WithLimits(domainAnalysis.Limits{
+			DefaultTimeout: cfg.AnalysisDefaultTimeout,
+			MaxTimeout:     cfg.AnalysisMaxTimeout,
+			MaxCacheTTL:    cfg.AnalysisMaxCacheTTL,
+		}),
+		domainAnalysis.WithNegativeCache(map[string]time.Duration{
+			models.FailureClassNotFound: cfg.NegativeCacheTTLNotFound,
+			models.FailureClassDNS:      cfg.NegativeCacheTTLDNS,
+			models.FailureClassParse:    cfg.NegativeCacheTTLParse,
+			models.FailureClassTimeout:  cfg.NegativeCacheTTLTimeout,
+		}),
+	}
+	if cfg.CacheTTLFromOrigin {
+		analysisOptions = append(analysisOptions, domainAnalysis.WithOriginTTL(cfg.CacheTTL, cfg.CacheTTLMin, cfg.CacheTTLMax))
+	}
+	
+	// Optionally bound concurrent fetches across all analyses, adapting the bound to fetch latency
+	var fetchLimiter concurrency.Service
+	if cfg.FetchConcurrencyLimit {
+		fetchLimiter = concurrency.NewAIMDLimiter(concurrency.AIMDConfig{
+			InitialLimit:  cfg.MaxConcurrentFetches,
+			MinLimit:      cfg.FetchConcurrencyMin,
+			MaxLimit:      cfg.FetchConcurrencyMax,
+			MaxQueue:      cfg.FetchQueueSize,
+			LatencyTarget: cfg.FetchLatencyTarget,
+		})
+		analysisOptions = append(analysisOptions, domainAnalysis.WithFetchLimiter(fetchLimiter))
+	}
+	
+	// With a shared Redis cache, only one replica fetches a given domain at a time
+	if cfg.CacheType == "redis" {
+		domainLocker, err := lock.NewRedisLocker(redisConfig(cfg))
+		if err != nil {
+			appLogger.LogError(
+				startupCtx,
+				"domain_lock_init",
+				"",
+				"Failed to initialize domain lock",
+				err,
+				models.LogSeverityHigh,
+				nil,
+			)
+			log.Fatalf("Failed to initialize domain lock: %v", err)
+		}
+		analysisOptions = append(analysisOptions, domainAnalysis.WithLocker(domainLocker, cfg.AnalysisLockTTL))
+	}
+	
 	analysisService := domainAnalysis.NewService(
 		adsTxtParser,
 		adsTxtFetcher,
 		domainCacheService,
 		appLogger,
 		cfg.MaxConcurrentFetches,
+		analysisOptions...,
 	)
 	
+	// Initialize watchlist scheduler (webhooks are optional)
+	var watchlistNotifier watchlist.Notifier
+	if len(cfg.WatchlistWebhookURLs) > 0 {
+		watchlistNotifier, err = watchlist.NewWebhookNotifier(cfg.WatchlistWebhookURLs, cfg.WatchlistWebhookSecret, cfg.WatchlistWebhookTimeout)
+		if err != nil {
+			appLogger.LogError(
+				startupCtx,
+				"watchlist_init",
+				"",
+				"Invalid watchlist webhook configuration",
+				err,
+				models.LogSeverityHigh,
+				nil,
+			)
+			log.Fatalf("Invalid watchlist webhook configuration: %v", err)
+		}
+	}
+	watchlistService := watchlist.NewScheduler(
+		adsTxtFetcher,
+		adsTxtParser,
+		watchlistNotifier,
+		appLogger,
+		cfg.WatchlistInterval,
+		cfg.MaxConcurrentFetches,
+	)
+	watchlistService.Start(startupCtx)
+	
+	// Initialize asynchronous batch jobs (persisted in Redis when configured)
+	jobStore, err := initializeJobStore(cfg)
+	if err != nil {
+		appLogger.LogError(
+			startupCtx,
+			"job_store_init",
+			"",
+			"Failed to initialize job store",
+			err,
+			models.LogSeverityHigh,
+			nil,
+		)
+		log.Fatalf("Failed to initialize job store: %v", err)
+	}
+	if cfg.CacheType == "redis" && cfg.RedisMode == redisclient.ModeCluster {
+		appLogger.LogInfo(startupCtx, "job_store_init", "Redis Cluster mode: batch jobs are kept in memory and don't survive restarts", nil)
+	}
+	jobsService := jobs.NewManager(
+		jobStore,
+		analysisService,
+		appLogger,
+		cfg.JobWorkers,
+		cfg.MaxConcurrentFetches,
+		cfg.AnalysisDefaultTimeout,
+	)
+	jobsService.Start(startupCtx)
+	
+	// Optionally pre-populate the cache in the background
+	var warmupService warmup.Service
+	if cfg.CacheWarmupFile != "" {
+		warmupService = warmup.NewWarmer(analysisService, appLogger, cfg.CacheWarmupFile, cfg.CacheWarmupConcurrency)
+	}
+	
+	// Batch requests are charged rate limit tokens by their domains
+	batchCost, err := http.BatchCostByName(cfg.RateLimitBatchCost)
+	if err != nil {
+		appLogger.LogError(
+			startupCtx,
+			"rate_limiter_init",
+			"",
+			"Invalid batch rate limit cost",
+			err,
+			models.LogSeverityHigh,
+			nil,
+		)
+		log.Fatalf("Invalid batch rate limit cost: %v", err)
+	}
+	
+	// Routes are rate limited by the policy the router attaches to them
+	rateLimitPolicies, err := http.ParseRateLimitPolicies(cfg.RateLimitPolicies)
+	if err != nil {
+		appLogger.LogError(
+			startupCtx,
+			"rate_limiter_init",
+			"",
+			"Invalid rate limit policies",
+			err,
+			models.LogSeverityHigh,
+			nil,
+		)
+		log.Fatalf("Invalid rate limit policies: %v", err)
+	}
+	
+	// Forwarding headers are only believed from trusted proxies
+	trustedProxies, err := http.ParseTrustedProxies(cfg.TrustedProxies)
+	if err != nil {
+		appLogger.LogError(
+			startupCtx,
+			"server_init",
+			"",
+			"Invalid trusted proxies",
+			err,
+			models.LogSeverityHigh,
+			nil,
+		)
+		log.Fatalf("Invalid trusted proxies: %v", err)
+	}
+	serverOptions := []http.ServerOption{
+		http.WithBatchCost(batchCost),
+		http.WithTrustedProxies(trustedProxies),
+		http.WithRateLimitPolicies(rateLimitPolicies),
+	}
+	
+	// Shed analysis requests while the fetch queue is full
+	if fetchLimiter != nil {
+		serverOptions = append(serverOptions, http.WithLoadShedding(fetchLimiter))
+	}
+	
+	// Optionally hold requests over the rate limit until their tokens are available
+	if cfg.RateLimitQueueDelay > 0 {
+		serverOptions = append(serverOptions, http.WithRateLimitQueue(ratelimit.NewQueue(cfg.RateLimitQueueSize, cfg.RateLimitQueueDelay)))
+	}
+	
+	// Optionally authenticate requests with API keys
+	apiKeyStore, err := initializeAPIKeyStore(cfg)
+	if err != nil {
+		appLogger.LogError(
+			startupCtx,
+			"api_keys_init",
+			"",
+			"Failed to initialize API key store",
+			err,
+			models.LogSeverityHigh,
+			nil,
+		)
+		log.Fatalf("Failed to initialize API key store: %v", err)
+	}
+	if apiKeyStore != nil {
+		defer apiKeyStore.Close()
+		serverOptions = append(serverOptions, http.WithAPIKeys(apikeys.NewManager(apiKeyStore, cfg.APIKeysCacheTTL), cfg.APIKeysRequired))
+	}
+	
+	// Optionally filter client IPs with allow and deny lists
+	ipFilter, err := initializeIPFilter(cfg)
+	if err != nil {
+		appLogger.LogError(
+			startupCtx,
+			"ip_filter_init",
+			"",
+			"Failed to load IP lists",
+			err,
+			models.LogSeverityHigh,
+			nil,
+		)
+		log.Fatalf("Failed to load IP lists: %v", err)
+	}
+	if ipFilter != nil {
+		serverOptions = append(serverOptions, http.WithIPFilter(ipFilter))
+	}
+	
 	// Initialize HTTP handler
 	handler := http.NewHandler(analysisService, appLogger)
 	
@@ -101,7 +348,37 @@ func main() {
 		rateLimiter,
 		cfg.ServerReadTimeout,
 		cfg.ServerWriteTimeout,
+		serverOptions...,
 	)
+	server.RegisterWatchlistRoutes(http.NewWatchlistHandler(handler, watchlistService))
+	server.RegisterJobsRoutes(http.NewJobsHandler(handler, jobsService, cfg.JobMaxDomains))
+	server.RegisterReadinessRoutes(http.NewReadinessHandler(handler, warmupService, cfg.CacheWarmupWaitForReadiness))
+	
+	// The cache admin API is only exposed when a token is configured
+	cacheAdmin, cacheAdminSupported := cacheService.(cache.Admin)
+	cacheAdminEnabled := cfg.AdminAPIToken != "" && cacheAdminSupported
+	if cacheAdminEnabled {
+		server.RegisterCacheAdminRoutes(http.NewCacheAdminHandler(handler, domainCache.NewAdmin(cacheAdmin, cacheCodec)), cfg.AdminAPIToken)
+	}
+	ipListsAdminEnabled := cfg.AdminAPIToken != "" && ipFilter != nil
+	if ipListsAdminEnabled {
+		server.RegisterIPListsAdminRoutes(http.NewIPListsAdminHandler(handler, ipFilter), cfg.AdminAPIToken)
+	}
+	
+	// Reload the IP lists on SIGHUP
+	if ipFilter != nil {
+		reload := make(chan os.Signal, 1)
+		signal.Notify(reload, syscall.SIGHUP)
+		go func() {
+			for range reload {
+				if err := ipFilter.Reload(); err != nil {
+					appLogger.LogError(context.Background(), logger.OpIPFilter, "", "Failed to reload IP lists", err, models.LogSeverityMedium, nil)
+					continue
+				}
+				appLogger.LogInfo(context.Background(), logger.OpIPFilter, "Reloaded IP lists", nil)
+			}
+		}()
+	}
 	
 	// Start server in goroutine
 	go func() {
@@ -119,11 +396,39 @@ func main() {
 		}
 	}()
 	
+	// The warm-up runs while the server is already serving traffic
+	if warmupService != nil {
+		warmupService.Start(startupCtx)
+	}
+	
 	fmt.Printf("🚀 AdsTxt Analysis API server started on %s\n", addr)
 	fmt.Println("📋 Available endpoints:")
 	fmt.Println("  GET  /health                    - Health check")
+	fmt.Println("  GET  /ready                     - Readiness check")
 	fmt.Println("  GET  /api/analyze/{domain}      - Analyze single domain")
 	fmt.Println("  POST /api/batch-analysis        - Analyze multiple domains")
+	fmt.Println("  POST /api/batch-analysis/stream - Analyze multiple domains, streaming results (NDJSON/SSE)")
+	fmt.Println("  POST /api/watchlist             - Watch a domain for ads.txt changes")
+	fmt.Println("  GET  /api/watchlist             - List watched domains")
+	fmt.Println("  DELETE /api/watchlist/{domain}  - Stop watching a domain")
+	fmt.Println("  POST /api/jobs                  - Submit an asynchronous batch job")
+	fmt.Println("  GET  /api/jobs/{id}             - Get batch job progress")
+	fmt.Println("  GET  /api/jobs/{id}/results     - Get paginated batch job results")
+	fmt.Println("  POST /api/jobs/{id}/cancel      - Cancel a batch job")
+	if apiKeyStore != nil {
+		fmt.Println("  GET  /api/usage                 - API key tier and usage today")
+	}
+	if cacheAdminEnabled {
+		fmt.Println("  GET  /api/admin/cache/stats     - Cache statistics (admin)")
+		fmt.Println("  GET  /api/admin/cache/entries/{domain} - Inspect a cached domain (admin)")
+		fmt.Println("  DELETE /api/admin/cache/entries/{domain} - Evict a cached domain (admin)")
+		fmt.Println("  POST /api/admin/cache/delete    - Evict several cached domains (admin)")
+		fmt.Println("  POST /api/admin/cache/purge     - Evict cached domains by pattern or prefix (admin)")
+	}
+	if ipListsAdminEnabled {
+		fmt.Println("  GET  /api/admin/ip-lists        - IP allow and deny lists in effect (admin)")
+		fmt.Println("  POST /api/admin/ip-lists/reload - Reload the IP lists (admin)")
+	}
 	
 	// Wait for interrupt signal to gracefully shutdown
 	quit := make(chan os.Signal, 1)
@@ -136,6 +441,13 @@ func main() {
 	ctx, cancel := context.WithTimeout(context.Background(), cfg.ServerShutdownTimeout)
 	defer cancel()
 	
+	// Stop background watchlist checks and job workers (unfinished jobs resume on restart)
+	watchlistService.Stop()
+	jobsService.Stop()
+	if warmupService != nil {
+		warmupService.Stop()
+	}
+	
 	// Shutdown server gracefully
 	if err := server.Shutdown(ctx); err != nil {
 		appLogger.LogError(
@@ -157,10 +469,103 @@ func main() {
 func initializeCache(cfg *config.Config) (cache.Service, error) {
 	switch cfg.CacheType {
 	case "redis":
-		return cache.NewRedisCache(cfg.RedisURL)
+		redisOptions := []cache.RedisOption{
+			cache.WithKeyPrefix(cfg.CacheKeyPrefix),
+			cache.WithCompression(cfg.CacheCompression, cfg.CacheCompressionThreshold),
+			cache.WithFailoverBackoff(cfg.CacheFailoverBackoff),
+		}
+		if cfg.CacheL1MaxEntries > 0 {
+			return cache.NewTieredCache(redisConfig(cfg), cfg.CacheL1MaxEntries, cfg.CacheL1MaxBytes, cfg.CacheL1TTL, redisOptions...)
+		}
+		return cache.NewRedisCache(redisConfig(cfg), redisOptions...)
 	case "memory":
-		return cache.NewMemoryCache(), nil
+		return cache.NewBoundedMemoryCache(cfg.CacheMaxEntries, cfg.CacheMaxBytes), nil
+	case "file":
+		return cache.NewFileCache(cfg.CacheFileDir, cfg.CacheCompactInterval)
 	default:
 		return nil, fmt.Errorf("unsupported cache type: %s", cfg.CacheType)
 	}
 }
+
+
+// initializeJobStore persists jobs in Redis, except in cluster mode, which the Redis job store doesn't support
+func initializeJobStore(cfg *config.Config) (jobs.Store, error) {
+	switch {
+	case cfg.CacheType == "redis" && cfg.RedisMode != redisclient.ModeCluster:
+		return jobs.NewRedisStore(redisConfig(cfg), cfg.JobRetention)
+	default:
+		return jobs.NewMemoryStore(cfg.JobRetention), nil
+	}
+}
+
+// initializeIPFilter creates the IP allow and deny lists, or returns nil when none are configured
+func initializeIPFilter(cfg *config.Config) (ipfilter.Service, error) {
+	if len(cfg.IPAllowlist) == 0 && len(cfg.IPDenylist) == 0 && cfg.IPListsFile == "" {
+		return nil, nil
+	}
+	return ipfilter.NewFilter(models.IPLists{Allow: cfg.IPAllowlist, Deny: cfg.IPDenylist}, cfg.IPListsFile)
+}
+
+// initializeRateLimiter creates the rate limiter selected by RATE_LIMIT_BACKEND
+func initializeRateLimiter(cfg *config.Config) (ratelimit.Service, error) {
+	switch cfg.RateLimitBackend {
+	case "redis":
+		return ratelimit.NewRedisRateLimiter(
+			redisConfig(cfg),
+			int64(cfg.GlobalRateLimitPerSec),
+			int64(cfg.GlobalRateLimitPerSec),
+			int64(cfg.PerIPRateLimitPerSec),
+			int64(cfg.PerIPRateLimitPerSec),
+			ratelimit.WithFailoverBackoff(cfg.CacheFailoverBackoff),
+		)
+	default:
+		return ratelimit.NewTwoTierRateLimiter(
+			int64(cfg.GlobalRateLimitPerSec),
+			int64(cfg.GlobalRateLimitPerSec),
+			int64(cfg.PerIPRateLimitPerSec),
+			int64(cfg.PerIPRateLimitPerSec),
+		), nil
+	}
+}
+
+// initializeAPIKeyStore creates the API key store selected by API_KEYS_SOURCE (nil when API keys are disabled)
+func initializeAPIKeyStore(cfg *config.Config) (apikeys.Store, error) {
+	switch cfg.APIKeysSource {
+	case "none", "":
+		return nil, nil
+	case "file":
+		return apikeys.NewFileStore(cfg.APIKeysFile)
+	case "postgres":
+		databaseURL := cfg.APIKeysDatabaseURL
+		if databaseURL == "" {
+			databaseURL = cfg.DatabaseURL
+		}
+		return apikeys.NewPostgresStore(databaseURL)
+	default:
+		return nil, fmt.Errorf("unsupported API key source: %s", cfg.APIKeysSource)
+	}
+}
+
+// redisConfig returns the Redis connection settings shared by the cache, lock, job store and rate limiter
+func redisConfig(cfg *config.Config) redisclient.Config {
+	return redisclient.Config{
+		Mode:                  cfg.RedisMode,
+		URL:                   cfg.RedisURL,
+		Addrs:                 cfg.RedisAddrs,
+		MasterName:            cfg.RedisMasterName,
+		Username:              cfg.RedisUsername,
+		Password:              cfg.RedisPassword,
+		SentinelPassword:      cfg.RedisSentinelPassword,
+		DB:                    cfg.RedisDB,
+		TLS:                   cfg.RedisTLS,
+		TLSServerName:         cfg.RedisTLSServerName,
+		TLSInsecureSkipVerify: cfg.RedisTLSInsecureSkipVerify,
+		PoolSize:              cfg.RedisPoolSize,
+		MinIdleConns:          cfg.RedisMinIdleConns,
+		DialTimeout:           cfg.RedisDialTimeout,
+		ReadTimeout:           cfg.RedisReadTimeout,
+		WriteTimeout:          cfg.RedisWriteTimeout,
+		PoolTimeout:           cfg.RedisPoolTimeout,
+		MaxRetries:            cfg.RedisMaxRetries,
+	}
+}
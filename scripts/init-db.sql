@@ -52,4 +52,30 @@ SELECT
 FROM application_logs 
 WHERE timestamp >= NOW() - INTERVAL '24 hours'
 GROUP BY process_id, process_type
-ORDER BY process_start DESC;
\ No newline at end of file
+ORDER BY process_start DESC;
+
+-- API key tiers, keys and daily usage (used with API_KEYS_SOURCE=postgres)
+CREATE TABLE IF NOT EXISTS api_key_tiers (
+    name VARCHAR(50) PRIMARY KEY,
+    requests_per_sec INTEGER NOT NULL CHECK (requests_per_sec >= 0),
+    daily_domain_quota INTEGER NOT NULL DEFAULT 0 CHECK (daily_domain_quota >= 0),
+    max_batch_size INTEGER NOT NULL DEFAULT 0 CHECK (max_batch_size >= 0)
+);
+
+-- key_hash is the SHA-256 hex digest of the key; the key itself is never stored
+CREATE TABLE IF NOT EXISTS api_keys (
+    id VARCHAR(100) PRIMARY KEY,
+    key_hash CHAR(64) NOT NULL UNIQUE,
+    name VARCHAR(255) NOT NULL,
+    tier VARCHAR(50) NOT NULL REFERENCES api_key_tiers(name),
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS api_key_usage (
+    key_id VARCHAR(100) NOT NULL REFERENCES api_keys(id) ON DELETE CASCADE,
+    day DATE NOT NULL,
+    requests BIGINT NOT NULL DEFAULT 0,
+    domains BIGINT NOT NULL DEFAULT 0,
+    PRIMARY KEY (key_id, day)
+);